@@ -15,6 +15,7 @@ import (
 
 	"github.com/blend/go-sdk/async"
 	"github.com/blend/go-sdk/ex"
+	"github.com/blend/go-sdk/logger"
 )
 
 var (
@@ -47,6 +48,11 @@ func New(options ...Option) *Breaker {
 // Breaker is a state machine to prevent performing actions that are likely to fail.
 type Breaker struct {
 	sync.Mutex
+	// Name is an optional name for the breaker, e.g. the host or method it guards.
+	// It is included in errors and state change events.
+	Name string
+	// Log is an optional logger that state change events are triggered on.
+	Log logger.Triggerable
 	// OpenAction is an optional actioner to be called when the breaker is open (i.e. preventing calls
 	// to intercepted action(er)s)
 	OpenAction Actioner
@@ -114,6 +120,29 @@ func (b *Breaker) Intercept(action Actioner) Actioner {
 	})
 }
 
+// Allow checks if an action is allowed, returning a function that must be called with the
+// outcome of the action if it is.
+/*
+It is useful for actions that do not fit the Actioner interface, or where whether the
+action succeeded depends on its result, e.g. the status code of an http response.
+
+If the breaker rejects the action, it returns an `ErrOpenState` or `ErrTooManyRequests` error.
+*/
+func (b *Breaker) Allow(ctx context.Context) (done func(success bool), err error) {
+	var generation int64
+	generation, err = b.beforeAction(ctx)
+	if err != nil {
+		return
+	}
+	var once sync.Once
+	done = func(success bool) {
+		once.Do(func() {
+			b.afterAction(ctx, generation, success)
+		})
+	}
+	return
+}
+
 // EvaluateState returns the current state of the CircuitBreaker.
 //
 // It takes a context because there is a chance that evaluating
@@ -140,9 +169,9 @@ func (b *Breaker) beforeAction(ctx context.Context) (int64, error) {
 	state, generation := b.evaluateStateUnsafe(ctx, now)
 
 	if state == StateOpen {
-		return generation, ex.New(ErrOpenState)
+		return generation, b.err(ErrOpenState)
 	} else if state == StateHalfOpen && b.Counts.Requests >= b.HalfOpenMaxActions {
-		return generation, ex.New(ErrTooManyRequests)
+		return generation, b.err(ErrTooManyRequests)
 	}
 
 	atomic.AddInt64(&b.Counts.Requests, 1)
@@ -220,6 +249,7 @@ func (b *Breaker) setStateUnsafe(ctx context.Context, state State, now time.Time
 	if b.OnStateChange != nil {
 		b.OnStateChange(ctx, previousState, b.state, b.generation)
 	}
+	logger.MaybeTriggerContext(ctx, b.Log, NewStateChangeEvent(b.Name, previousState, b.state, b.generation))
 }
 
 func (b *Breaker) incrementGeneration(now time.Time) {
@@ -250,6 +280,13 @@ func (b *Breaker) shouldOpen(ctx context.Context) bool {
 	return b.Counts.ConsecutiveFailures > b.OpenFailureThreshold
 }
 
+func (b *Breaker) err(class ex.Class) error {
+	if b.Name != "" {
+		return ex.New(class, ex.OptMessagef("breaker: %s", b.Name))
+	}
+	return ex.New(class)
+}
+
 func (b *Breaker) now() time.Time {
 	if b.NowProvider != nil {
 		return b.NowProvider()
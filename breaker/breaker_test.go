@@ -8,6 +8,7 @@ Use of this source code is governed by a MIT license that can be found in the LI
 package breaker
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"testing"
@@ -15,6 +16,7 @@ import (
 
 	"github.com/blend/go-sdk/assert"
 	"github.com/blend/go-sdk/ex"
+	"github.com/blend/go-sdk/logger"
 )
 
 func TestNew(t *testing.T) {
@@ -184,3 +186,42 @@ func Test_Breaker_callsOnOpenHandler(t *testing.T) {
 	its.True(didCallOpen)
 	its.Equal("on open", res)
 }
+
+func Test_Breaker_Allow(t *testing.T) {
+	its := assert.New(t)
+	ctx := context.Background()
+
+	b := New(OptName("test"), OptOpenFailureThreshold(1), OptClosedExpiryInterval(0))
+
+	done, err := b.Allow(ctx)
+	its.Nil(err)
+	done(true)
+	done(false) // only the first outcome is recorded
+	its.Equal(Counts{1, 1, 0, 1, 0}, b.Counts)
+
+	for x := 0; x < 2; x++ {
+		done, err = b.Allow(ctx)
+		its.Nil(err)
+		done(false)
+	}
+	its.Equal(StateOpen, b.EvaluateState(ctx))
+
+	done, err = b.Allow(ctx)
+	its.Nil(done)
+	its.True(ErrIsOpen(err))
+	its.Equal("breaker: test", ex.ErrMessage(err))
+}
+
+func Test_Breaker_Log(t *testing.T) {
+	its := assert.New(t)
+	ctx := context.Background()
+
+	buffer := new(bytes.Buffer)
+	log := logger.Memory(buffer, logger.OptText(logger.OptTextNoColor(), logger.OptTextHideTimestamp()))
+	defer log.Close()
+
+	b := New(OptName("test"), OptLog(log), OptOpenFailureThreshold(0), OptClosedExpiryInterval(0))
+	its.Nil(fail(b))
+	its.Equal(StateOpen, b.EvaluateState(ctx))
+	its.Equal("[breaker.state_change] [test] closed -> open\n", buffer.String())
+}
@@ -18,5 +18,18 @@ An example of using a circuit breaker for an http call might be:
     })
 
 In the above, `phoneHome` now will be wrapped with circuit breaker mechanics. You would call it with `phoneHome.Action(ctx, nil)` etc.
+
+For calls that don't fit the actioner interface, `Allow` returns a function to record the outcome of the call:
+
+	done, err := b.Allow(ctx)
+	if err != nil {
+		return err // the breaker is open
+	}
+	res, err := http.DefaultClient.Do(req)
+	done(err == nil && res.StatusCode < http.StatusInternalServerError)
+
+A `Group` holds a breaker per name (e.g. per host or per method); it is used by `r2.OptCircuitBreaker` and
+`grpcutil.BreakerUnaryClientInterceptor`. Breakers with a logger (see `OptLog`) trigger `StateChangeEvent`s
+when they change state, which can be written to a stats collector with `stats/breakerstats`.
 */
 package breaker // import "github.com/blend/go-sdk/breaker"
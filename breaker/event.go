@@ -0,0 +1,89 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package breaker
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/blend/go-sdk/ansi"
+	"github.com/blend/go-sdk/logger"
+)
+
+// Logger flags
+const (
+	Flag = "breaker.state_change"
+)
+
+// these are compile time assertions
+var (
+	_ logger.Event        = (*StateChangeEvent)(nil)
+	_ logger.TextWritable = (*StateChangeEvent)(nil)
+	_ logger.JSONWritable = (*StateChangeEvent)(nil)
+)
+
+// NewStateChangeEvent returns a new state change event.
+func NewStateChangeEvent(name string, from, to State, generation int64) StateChangeEvent {
+	return StateChangeEvent{
+		Name:       name,
+		From:       from,
+		To:         to,
+		Generation: generation,
+	}
+}
+
+// NewStateChangeEventListener returns a new listener for state change events.
+func NewStateChangeEventListener(listener func(context.Context, StateChangeEvent)) logger.Listener {
+	return func(ctx context.Context, e logger.Event) {
+		if typed, isTyped := e.(StateChangeEvent); isTyped {
+			listener(ctx, typed)
+		}
+	}
+}
+
+// StateChangeEvent is an event triggered when a breaker changes state.
+type StateChangeEvent struct {
+	Name       string
+	From       State
+	To         State
+	Generation int64
+}
+
+// GetFlag implements logger.Event.
+func (e StateChangeEvent) GetFlag() string { return Flag }
+
+// WriteText implements logger.TextWritable.
+func (e StateChangeEvent) WriteText(tf logger.TextFormatter, wr io.Writer) {
+	if e.Name != "" {
+		fmt.Fprintf(wr, "[%s]", tf.Colorize(e.Name, ansi.ColorLightWhite))
+		fmt.Fprint(wr, logger.Space)
+	}
+	fmt.Fprintf(wr, "%s -> %s", e.From, tf.Colorize(e.To.String(), stateColor(e.To)))
+}
+
+// Decompose implements logger.JSONWritable.
+func (e StateChangeEvent) Decompose() map[string]interface{} {
+	return map[string]interface{}{
+		"name":       e.Name,
+		"from":       e.From.String(),
+		"to":         e.To.String(),
+		"generation": e.Generation,
+	}
+}
+
+func stateColor(state State) ansi.Color {
+	switch state {
+	case StateClosed:
+		return ansi.ColorGreen
+	case StateHalfOpen:
+		return ansi.ColorYellow
+	default:
+		return ansi.ColorRed
+	}
+}
@@ -0,0 +1,46 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package breaker
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/blend/go-sdk/assert"
+	"github.com/blend/go-sdk/logger"
+)
+
+func TestStateChangeEvent(t *testing.T) {
+	its := assert.New(t)
+
+	e := NewStateChangeEvent("test", StateClosed, StateOpen, 3)
+	its.Equal(Flag, e.GetFlag())
+
+	buffer := new(bytes.Buffer)
+	e.WriteText(logger.NewTextOutputFormatter(logger.OptTextNoColor()), buffer)
+	its.Equal("[test] closed -> open", buffer.String())
+
+	decomposed := e.Decompose()
+	its.Equal("test", decomposed["name"])
+	its.Equal("closed", decomposed["from"])
+	its.Equal("open", decomposed["to"])
+	its.Equal(3, decomposed["generation"])
+}
+
+func TestStateChangeEventListener(t *testing.T) {
+	its := assert.New(t)
+
+	var didCall bool
+	listener := NewStateChangeEventListener(func(_ context.Context, e StateChangeEvent) {
+		didCall = true
+		its.Equal(StateHalfOpen, e.To)
+	})
+	listener(context.Background(), NewStateChangeEvent("test", StateOpen, StateHalfOpen, 1))
+	its.True(didCall)
+}
@@ -0,0 +1,60 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package breaker
+
+import (
+	"sort"
+	"sync"
+)
+
+// NewGroup returns a new group whose breakers are created with the given options.
+func NewGroup(options ...Option) *Group {
+	return &Group{
+		Options:  options,
+		breakers: make(map[string]*Breaker),
+	}
+}
+
+// Group is a set of breakers by name, e.g. a breaker per host or per method.
+//
+// Breakers are created on first use with the group options and the name as `OptName`.
+type Group struct {
+	sync.Mutex
+	// Options are the options used to create each breaker.
+	Options []Option
+
+	breakers map[string]*Breaker
+}
+
+// Get returns the breaker for a given name, creating it if it does not exist.
+func (g *Group) Get(name string) *Breaker {
+	g.Lock()
+	defer g.Unlock()
+
+	if g.breakers == nil {
+		g.breakers = make(map[string]*Breaker)
+	}
+	if b, ok := g.breakers[name]; ok {
+		return b
+	}
+	b := New(append(append([]Option{}, g.Options...), OptName(name))...)
+	g.breakers[name] = b
+	return b
+}
+
+// Names returns the sorted names of the breakers in the group.
+func (g *Group) Names() (output []string) {
+	g.Lock()
+	defer g.Unlock()
+
+	for name := range g.breakers {
+		output = append(output, name)
+	}
+	sort.Strings(output)
+	return
+}
@@ -0,0 +1,33 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package breaker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/blend/go-sdk/assert"
+)
+
+func TestGroup(t *testing.T) {
+	its := assert.New(t)
+
+	g := NewGroup(OptOpenExpiryInterval(time.Second))
+	foo := g.Get("foo")
+	its.Equal("foo", foo.Name)
+	its.Equal(time.Second, foo.OpenExpiryInterval)
+	its.True(foo == g.Get("foo"))
+
+	bar := g.Get("bar")
+	its.Equal("bar", bar.Name)
+	its.False(foo == bar)
+	its.Equal([]string{"bar", "foo"}, g.Names())
+
+	var zero Group
+	its.NotNil(zero.Get("foo"))
+}
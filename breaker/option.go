@@ -9,11 +9,27 @@ package breaker
 
 import (
 	"time"
+
+	"github.com/blend/go-sdk/logger"
 )
 
 // Option is a mutator for a breaker.
 type Option func(*Breaker)
 
+// OptName sets the Name.
+func OptName(name string) Option {
+	return func(b *Breaker) {
+		b.Name = name
+	}
+}
+
+// OptLog sets the logger that state change events are triggered on.
+func OptLog(log logger.Triggerable) Option {
+	return func(b *Breaker) {
+		b.Log = log
+	}
+}
+
 // OptOpenFailureThreshold sets the OpenFailureThreshold.
 func OptOpenFailureThreshold(openFailureThreshold int64) Option {
 	return func(b *Breaker) {
@@ -13,6 +13,7 @@ import (
 	"time"
 
 	"github.com/blend/go-sdk/assert"
+	"github.com/blend/go-sdk/logger"
 )
 
 func TestOptHalfOpenMaxActions(t *testing.T) {
@@ -94,3 +95,20 @@ func TestOptNowProvider(t *testing.T) {
 	OptNowProvider(time.Now)(b)
 	assert.NotNil(b.NowProvider)
 }
+
+func TestOptName(t *testing.T) {
+	assert := assert.New(t)
+
+	b := new(Breaker)
+	OptName("test")(b)
+	assert.Equal("test", b.Name)
+}
+
+func TestOptLog(t *testing.T) {
+	assert := assert.New(t)
+
+	b := new(Breaker)
+	assert.Nil(b.Log)
+	OptLog(logger.None())(b)
+	assert.NotNil(b.Log)
+}
@@ -0,0 +1,143 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package db
+
+import (
+	"database/sql"
+	"strconv"
+	"strings"
+
+	"github.com/blend/go-sdk/ex"
+)
+
+const (
+	// ErrBuilderTableUnset is returned when building a statement without a table.
+	ErrBuilderTableUnset ex.Class = "db: builder table is unset"
+	// ErrBuilderColumnsUnset is returned when building an insert or update statement without any columns.
+	ErrBuilderColumnsUnset ex.Class = "db: builder columns are unset"
+	// ErrBuilderValuesMismatch is returned when building an insert statement with a row that does not have a value for each column.
+	ErrBuilderValuesMismatch ex.Class = "db: builder values do not match columns"
+	// ErrBuilderUnsupported is returned when building a statement that uses a clause the dialect does not support.
+	ErrBuilderUnsupported ex.Class = "db: builder clause is not supported by the dialect"
+	// ErrBuilderArgsMismatch is returned when building a statement with a raw expression that does not have an argument for each placeholder.
+	ErrBuilderArgsMismatch ex.Class = "db: builder arguments do not match placeholders"
+)
+
+// Builder is a type that builds sql statements.
+//
+// Builders are executed with `Invocation.QueryBuilder` or `Invocation.ExecBuilder`, e.g.
+//
+//	var users []User
+//	err := conn.Invoke().QueryBuilder(
+//		db.SelectFrom(User{}).Where(db.Eq("team_id", teamID)).OrderBy("created_utc DESC").Limit(10),
+//	).OutMany(&users)
+type Builder interface {
+	// Label returns the default statement label.
+	Label() string
+	// Build returns the statement and its arguments for a given dialect.
+	Build(Dialect) (statement string, args []interface{}, err error)
+}
+
+// WriteBuilder is a builder for statements that write to tables.
+//
+// The cached results (see `OptCache`) that depend on the tables are invalidated when
+// the statement succeeds.
+type WriteBuilder interface {
+	Builder
+	// Tables returns the tables the statement writes to.
+	Tables() []string
+}
+
+// QueryBuilder builds a statement with the invocation dialect and returns a query for it.
+//
+// If the invocation does not already have a label, the builder label is used. The results of
+// write builders (e.g. with `RETURNING`) are never cached, and invalidate the tables they write to.
+func (i *Invocation) QueryBuilder(b Builder) *Query {
+	statement, args, err := b.Build(i.Config.DialectOrDefault())
+	if err != nil {
+		return &Query{Invocation: i, Err: err}
+	}
+	i.maybeSetLabel(b.Label())
+	return i.query(statement, args, builderTables(b))
+}
+
+// ExecBuilder builds a statement with the invocation dialect and executes it.
+//
+// If the invocation does not already have a label, the builder label is used.
+func (i *Invocation) ExecBuilder(b Builder) (sql.Result, error) {
+	statement, args, err := b.Build(i.Config.DialectOrDefault())
+	if err != nil {
+		return nil, err
+	}
+	i.maybeSetLabel(b.Label())
+	return i.exec(statement, args, builderTables(b))
+}
+
+// builderTables returns the tables a builder writes to, if it is a write builder.
+func builderTables(b Builder) []string {
+	if typed, ok := b.(WriteBuilder); ok {
+		return typed.Tables()
+	}
+	return nil
+}
+
+// builderArgs collects statement arguments, returning numbered parameter tokens for them.
+//
+// Err holds the first error rendering an expression, which is returned from `Build`.
+type builderArgs struct {
+	Args []interface{}
+	Err  error
+}
+
+// Add adds an argument and returns its parameter token.
+func (ba *builderArgs) Add(value interface{}) string {
+	ba.Args = append(ba.Args, value)
+	return "$" + strconv.Itoa(len(ba.Args))
+}
+
+// writeWhere writes a where clause for a set of predicates that are joined with `AND`, skipping nil predicates.
+func writeWhere(sb *strings.Builder, args *builderArgs, where []Predicate) {
+	var rendered []string
+	for _, predicate := range where {
+		if predicate != nil {
+			rendered = append(rendered, predicate.renderPredicate(args))
+		}
+	}
+	if len(rendered) == 0 {
+		return
+	}
+	sb.WriteString(" WHERE ")
+	sb.WriteString(strings.Join(rendered, " AND "))
+}
+
+// writeReturning writes a returning clause, if the dialect supports it.
+func writeReturning(sb *strings.Builder, dialect Dialect, returning []string) error {
+	if len(returning) == 0 {
+		return nil
+	}
+	if dialect.Is(DialectRedshift) {
+		return ex.New(ErrBuilderUnsupported, ex.OptMessagef("dialect: %s; clause: RETURNING", dialect))
+	}
+	sb.WriteString(" RETURNING ")
+	sb.WriteString(strings.Join(returning, ","))
+	return nil
+}
+
+// objectPrimaryKeyPredicates returns predicates matching the primary keys of an object.
+func objectPrimaryKeyPredicates(object DatabaseMapped) ([]Predicate, error) {
+	pks := Columns(object).PrimaryKeys()
+	if pks.Len() == 0 {
+		return nil, ex.New(ErrNoPrimaryKey, ex.OptMessagef("table: %s", TableName(object)))
+	}
+	values := pks.ColumnValues(object)
+	predicates := make([]Predicate, pks.Len())
+	for index, name := range pks.ColumnNames() {
+		predicates[index] = Eq(name, values[index])
+	}
+	return predicates, nil
+}
@@ -0,0 +1,85 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package db
+
+import (
+	"strings"
+
+	"github.com/blend/go-sdk/ex"
+)
+
+// DeleteFrom returns a delete builder for a table.
+func DeleteFrom(table string) *DeleteBuilder {
+	return &DeleteBuilder{Table: table}
+}
+
+// DeleteObject returns a delete builder for an object that deletes the row where its primary keys match.
+func DeleteObject(object DatabaseMapped) *DeleteBuilder {
+	d := &DeleteBuilder{Table: TableName(object)}
+	d.Predicates, d.Err = objectPrimaryKeyPredicates(object)
+	return d
+}
+
+// DeleteBuilder builds `DELETE` statements.
+type DeleteBuilder struct {
+	Table            string
+	Predicates       []Predicate
+	ReturningColumns []string
+	Err              error
+}
+
+// Where adds predicates to the where clause; all predicates must be true.
+func (d *DeleteBuilder) Where(predicates ...Predicate) *DeleteBuilder {
+	d.Predicates = append(d.Predicates, predicates...)
+	return d
+}
+
+// Returning sets the columns to return from the deleted rows.
+func (d *DeleteBuilder) Returning(columns ...string) *DeleteBuilder {
+	d.ReturningColumns = append(d.ReturningColumns, columns...)
+	return d
+}
+
+// Tables implements WriteBuilder.
+func (d *DeleteBuilder) Tables() []string {
+	return []string{d.Table}
+}
+
+// Label implements Builder.
+func (d *DeleteBuilder) Label() string {
+	return d.Table + "_delete"
+}
+
+// Build implements Builder.
+func (d *DeleteBuilder) Build(dialect Dialect) (statement string, args []interface{}, err error) {
+	if d.Err != nil {
+		err = d.Err
+		return
+	}
+	if d.Table == "" {
+		err = ex.New(ErrBuilderTableUnset)
+		return
+	}
+
+	var params builderArgs
+	var query strings.Builder
+	query.WriteString("DELETE FROM ")
+	query.WriteString(d.Table)
+	writeWhere(&query, &params, d.Predicates)
+	if params.Err != nil {
+		err = params.Err
+		return
+	}
+	if err = writeReturning(&query, dialect, d.ReturningColumns); err != nil {
+		return
+	}
+
+	statement = query.String()
+	args = params.Args
+	return
+}
@@ -0,0 +1,37 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package db
+
+import (
+	"testing"
+
+	"github.com/blend/go-sdk/assert"
+	"github.com/blend/go-sdk/ex"
+)
+
+func Test_DeleteBuilder(t *testing.T) {
+	its := assert.New(t)
+
+	d := DeleteFrom("users").Where(In("id", []int{1, 2})).Returning("id")
+	its.Equal("users_delete", d.Label())
+	statement, args, err := d.Build(DialectPostgres)
+	its.Nil(err)
+	its.Equal("DELETE FROM users WHERE id IN ($1,$2) RETURNING id", statement)
+	its.Equal([]interface{}{1, 2}, args)
+
+	statement, args, err = DeleteObject(benchObj{ID: 5}).Build(DialectPostgres)
+	its.Nil(err)
+	its.Equal("DELETE FROM bench_object WHERE id = $1", statement)
+	its.Equal([]interface{}{5}, args)
+
+	_, _, err = DeleteObject(copyTestNoKeysObj{}).Build(DialectPostgres)
+	its.True(ex.Is(err, ErrNoPrimaryKey))
+
+	_, _, err = DeleteFrom("").Build(DialectPostgres)
+	its.True(ex.Is(err, ErrBuilderTableUnset))
+}
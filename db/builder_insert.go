@@ -0,0 +1,154 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package db
+
+import (
+	"strings"
+
+	"github.com/blend/go-sdk/ex"
+)
+
+// InsertInto returns an insert builder for a table.
+func InsertInto(table string) *InsertBuilder {
+	return &InsertBuilder{Table: table}
+}
+
+// InsertObject returns an insert builder for an object into its table.
+//
+// As with `Invocation.Create`, the insert columns are used along with any auto columns that are set on the object.
+func InsertObject(object DatabaseMapped) *InsertBuilder {
+	cols := Columns(object)
+	insertCols := cols.InsertColumns().ConcatWith(cols.Autos().NotZero(object))
+	return &InsertBuilder{
+		Table:   TableName(object),
+		Columns: insertCols.ColumnNames(),
+		Rows:    [][]interface{}{insertCols.ColumnValues(object)},
+	}
+}
+
+// InsertBuilder builds `INSERT` statements.
+type InsertBuilder struct {
+	Table            string
+	Columns          []string
+	Rows             [][]interface{}
+	ConflictColumns  []string
+	ConflictNothing  bool
+	ConflictUpdates  []string
+	ReturningColumns []string
+}
+
+// Column sets the columns to insert.
+func (ib *InsertBuilder) Column(columns ...string) *InsertBuilder {
+	ib.Columns = append(ib.Columns, columns...)
+	return ib
+}
+
+// Values adds a row of values to insert, one value for each column.
+func (ib *InsertBuilder) Values(values ...interface{}) *InsertBuilder {
+	ib.Rows = append(ib.Rows, values)
+	return ib
+}
+
+// OnConflictDoNothing skips rows that conflict on a given set of columns (or any constraint if no columns are given).
+func (ib *InsertBuilder) OnConflictDoNothing(conflictColumns ...string) *InsertBuilder {
+	ib.ConflictColumns = conflictColumns
+	ib.ConflictNothing = true
+	ib.ConflictUpdates = nil
+	return ib
+}
+
+// OnConflictDoUpdate updates rows that conflict on a given set of columns, setting
+// the given update columns to their inserted values.
+func (ib *InsertBuilder) OnConflictDoUpdate(conflictColumns []string, updateColumns ...string) *InsertBuilder {
+	ib.ConflictColumns = conflictColumns
+	ib.ConflictNothing = false
+	ib.ConflictUpdates = updateColumns
+	return ib
+}
+
+// Returning sets the columns to return from the inserted rows.
+func (ib *InsertBuilder) Returning(columns ...string) *InsertBuilder {
+	ib.ReturningColumns = append(ib.ReturningColumns, columns...)
+	return ib
+}
+
+// Tables implements WriteBuilder.
+func (ib *InsertBuilder) Tables() []string {
+	return []string{ib.Table}
+}
+
+// Label implements Builder.
+func (ib *InsertBuilder) Label() string {
+	return ib.Table + "_insert"
+}
+
+// Build implements Builder.
+func (ib *InsertBuilder) Build(dialect Dialect) (statement string, args []interface{}, err error) {
+	if ib.Table == "" {
+		err = ex.New(ErrBuilderTableUnset)
+		return
+	}
+	if len(ib.Columns) == 0 || len(ib.Rows) == 0 {
+		err = ex.New(ErrBuilderColumnsUnset, ex.OptMessagef("table: %s", ib.Table))
+		return
+	}
+
+	var params builderArgs
+	var query strings.Builder
+	query.WriteString("INSERT INTO ")
+	query.WriteString(ib.Table)
+	query.WriteString(" (")
+	query.WriteString(strings.Join(ib.Columns, ","))
+	query.WriteString(") VALUES ")
+	for index, row := range ib.Rows {
+		if len(row) != len(ib.Columns) {
+			err = ex.New(ErrBuilderValuesMismatch, ex.OptMessagef("table: %s; row: %d; columns: %d; values: %d", ib.Table, index, len(ib.Columns), len(row)))
+			return
+		}
+		if index > 0 {
+			query.WriteString(",")
+		}
+		tokens := make([]string, len(row))
+		for valueIndex, value := range row {
+			tokens[valueIndex] = params.Add(value)
+		}
+		query.WriteString("(")
+		query.WriteString(strings.Join(tokens, ","))
+		query.WriteString(")")
+	}
+
+	if ib.ConflictNothing || len(ib.ConflictUpdates) > 0 {
+		if dialect.Is(DialectRedshift) {
+			err = ex.New(ErrBuilderUnsupported, ex.OptMessagef("dialect: %s; clause: ON CONFLICT", dialect))
+			return
+		}
+		query.WriteString(" ON CONFLICT")
+		if len(ib.ConflictColumns) > 0 {
+			query.WriteString(" (")
+			query.WriteString(strings.Join(ib.ConflictColumns, ","))
+			query.WriteString(")")
+		}
+		if ib.ConflictNothing {
+			query.WriteString(" DO NOTHING")
+		} else {
+			updates := make([]string, len(ib.ConflictUpdates))
+			for index, column := range ib.ConflictUpdates {
+				updates[index] = column + " = EXCLUDED." + column
+			}
+			query.WriteString(" DO UPDATE SET ")
+			query.WriteString(strings.Join(updates, ","))
+		}
+	}
+	if err = writeReturning(&query, dialect, ib.ReturningColumns); err != nil {
+		return
+	}
+
+	statement = query.String()
+	args = params.Args
+	return
+}
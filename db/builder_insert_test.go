@@ -0,0 +1,68 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/blend/go-sdk/assert"
+	"github.com/blend/go-sdk/ex"
+)
+
+func Test_InsertBuilder(t *testing.T) {
+	its := assert.New(t)
+
+	ib := InsertInto("users").Column("id", "name").Values(1, "foo").Values(2, "bar").Returning("id")
+	its.Equal("users_insert", ib.Label())
+	statement, args, err := ib.Build(DialectPostgres)
+	its.Nil(err)
+	its.Equal("INSERT INTO users (id,name) VALUES ($1,$2),($3,$4) RETURNING id", statement)
+	its.Equal([]interface{}{1, "foo", 2, "bar"}, args)
+
+	statement, _, err = InsertInto("users").Column("id", "name").Values(1, "foo").OnConflictDoNothing().Build(DialectPostgres)
+	its.Nil(err)
+	its.Equal("INSERT INTO users (id,name) VALUES ($1,$2) ON CONFLICT DO NOTHING", statement)
+
+	statement, _, err = InsertInto("users").Column("id", "name").Values(1, "foo").OnConflictDoUpdate([]string{"id"}, "name").Build(DialectCockroachDB)
+	its.Nil(err)
+	its.Equal("INSERT INTO users (id,name) VALUES ($1,$2) ON CONFLICT (id) DO UPDATE SET name = EXCLUDED.name", statement)
+}
+
+func Test_InsertBuilder_errors(t *testing.T) {
+	its := assert.New(t)
+
+	_, _, err := InsertInto("").Column("id").Values(1).Build(DialectPostgres)
+	its.True(ex.Is(err, ErrBuilderTableUnset))
+
+	_, _, err = InsertInto("users").Build(DialectPostgres)
+	its.True(ex.Is(err, ErrBuilderColumnsUnset))
+
+	_, _, err = InsertInto("users").Column("id", "name").Values(1).Build(DialectPostgres)
+	its.True(ex.Is(err, ErrBuilderValuesMismatch))
+
+	_, _, err = InsertInto("users").Column("id").Values(1).OnConflictDoNothing().Build(DialectRedshift)
+	its.True(ex.Is(err, ErrBuilderUnsupported))
+
+	_, _, err = InsertInto("users").Column("id").Values(1).Returning("id").Build(DialectRedshift)
+	its.True(ex.Is(err, ErrBuilderUnsupported))
+}
+
+func Test_InsertObject(t *testing.T) {
+	its := assert.New(t)
+
+	now := time.Now().UTC()
+	statement, args, err := InsertObject(benchObj{UUID: "uuid", Name: "foo", Timestamp: now, Amount: 1.5, Category: "bar"}).Build(DialectPostgres)
+	its.Nil(err)
+	its.Equal("INSERT INTO bench_object (uuid,name,timestamp_utc,amount,pending,category) VALUES ($1,$2,$3,$4,$5,$6)", statement)
+	its.Equal([]interface{}{"uuid", "foo", now, float32(1.5), false, "bar"}, args)
+
+	statement, _, err = InsertObject(benchObj{ID: 5}).Build(DialectPostgres)
+	its.Nil(err)
+	its.Equal("INSERT INTO bench_object (uuid,name,timestamp_utc,amount,pending,category,id) VALUES ($1,$2,$3,$4,$5,$6,$7)", statement, "set auto columns should be inserted")
+}
@@ -0,0 +1,246 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package db
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/blend/go-sdk/ex"
+)
+
+// Predicate is a boolean condition used in where, join and having clauses.
+//
+// Predicates are created with the condition functions (`Eq`, `In`, `IsNull` etc.), combined with
+// `And`, `Or` and `Not`, and `Raw` can be used for any condition the functions do not cover.
+type Predicate interface {
+	renderPredicate(*builderArgs) string
+}
+
+// Eq returns a predicate that a column equals a value.
+func Eq(column string, value interface{}) Predicate {
+	return comparisonPredicate{Column: column, Operator: "=", Value: value}
+}
+
+// NotEq returns a predicate that a column does not equal a value.
+func NotEq(column string, value interface{}) Predicate {
+	return comparisonPredicate{Column: column, Operator: "<>", Value: value}
+}
+
+// Gt returns a predicate that a column is greater than a value.
+func Gt(column string, value interface{}) Predicate {
+	return comparisonPredicate{Column: column, Operator: ">", Value: value}
+}
+
+// Gte returns a predicate that a column is greater than or equal to a value.
+func Gte(column string, value interface{}) Predicate {
+	return comparisonPredicate{Column: column, Operator: ">=", Value: value}
+}
+
+// Lt returns a predicate that a column is less than a value.
+func Lt(column string, value interface{}) Predicate {
+	return comparisonPredicate{Column: column, Operator: "<", Value: value}
+}
+
+// Lte returns a predicate that a column is less than or equal to a value.
+func Lte(column string, value interface{}) Predicate {
+	return comparisonPredicate{Column: column, Operator: "<=", Value: value}
+}
+
+// Like returns a predicate that a column matches a `LIKE` pattern.
+func Like(column string, pattern string) Predicate {
+	return comparisonPredicate{Column: column, Operator: "LIKE", Value: pattern}
+}
+
+// ILike returns a predicate that a column matches a case insensitive `ILIKE` pattern.
+func ILike(column string, pattern string) Predicate {
+	return comparisonPredicate{Column: column, Operator: "ILIKE", Value: pattern}
+}
+
+// In returns a predicate that a column is one of a set of values.
+//
+// A single slice value is expanded into its elements; an empty set of values is always false.
+func In(column string, values ...interface{}) Predicate {
+	return inPredicate{Column: column, Values: expandValues(values)}
+}
+
+// NotIn returns a predicate that a column is not one of a set of values.
+//
+// A single slice value is expanded into its elements; an empty set of values is always true.
+func NotIn(column string, values ...interface{}) Predicate {
+	return inPredicate{Column: column, Values: expandValues(values), Not: true}
+}
+
+// IsNull returns a predicate that a column is null.
+func IsNull(column string) Predicate {
+	return nullPredicate{Column: column}
+}
+
+// IsNotNull returns a predicate that a column is not null.
+func IsNotNull(column string) Predicate {
+	return nullPredicate{Column: column, Not: true}
+}
+
+// And returns a predicate that all of a set of predicates are true.
+//
+// Nil predicates are skipped; if there are no predicates it is always true.
+func And(predicates ...Predicate) Predicate {
+	return junctionPredicate{Operator: "AND", Predicates: predicates, Empty: "TRUE"}
+}
+
+// Or returns a predicate that any of a set of predicates are true.
+//
+// Nil predicates are skipped; if there are no predicates it is always false.
+func Or(predicates ...Predicate) Predicate {
+	return junctionPredicate{Operator: "OR", Predicates: predicates, Empty: "FALSE"}
+}
+
+// Not returns a predicate that negates a predicate.
+func Not(predicate Predicate) Predicate {
+	return notPredicate{Predicate: predicate}
+}
+
+// Raw returns a predicate from a raw sql expression.
+//
+// Each `?` in the expression is replaced with a numbered parameter for the matching argument; use `??` for a literal `?`.
+// Building a statement returns an `ErrBuilderArgsMismatch` error if the number of placeholders and arguments differ.
+//
+//	db.Raw("u.id = p.user_id")
+//	db.Raw("created_utc > now() - ?::interval", "1 day")
+func Raw(expression string, args ...interface{}) Predicate {
+	return rawPredicate{Expression: expression, Args: args}
+}
+
+type comparisonPredicate struct {
+	Column   string
+	Operator string
+	Value    interface{}
+}
+
+func (cp comparisonPredicate) renderPredicate(args *builderArgs) string {
+	return cp.Column + " " + cp.Operator + " " + args.Add(cp.Value)
+}
+
+type inPredicate struct {
+	Column string
+	Values []interface{}
+	Not    bool
+}
+
+func (ip inPredicate) renderPredicate(args *builderArgs) string {
+	if len(ip.Values) == 0 {
+		if ip.Not {
+			return "TRUE"
+		}
+		return "FALSE"
+	}
+	tokens := make([]string, len(ip.Values))
+	for index, value := range ip.Values {
+		tokens[index] = args.Add(value)
+	}
+	operator := " IN ("
+	if ip.Not {
+		operator = " NOT IN ("
+	}
+	return ip.Column + operator + strings.Join(tokens, ",") + ")"
+}
+
+type nullPredicate struct {
+	Column string
+	Not    bool
+}
+
+func (np nullPredicate) renderPredicate(_ *builderArgs) string {
+	if np.Not {
+		return np.Column + " IS NOT NULL"
+	}
+	return np.Column + " IS NULL"
+}
+
+type junctionPredicate struct {
+	Operator   string
+	Predicates []Predicate
+	Empty      string
+}
+
+func (jp junctionPredicate) renderPredicate(args *builderArgs) string {
+	var rendered []string
+	for _, predicate := range jp.Predicates {
+		if predicate != nil {
+			rendered = append(rendered, predicate.renderPredicate(args))
+		}
+	}
+	switch len(rendered) {
+	case 0:
+		return jp.Empty
+	case 1:
+		return rendered[0]
+	default:
+		return "(" + strings.Join(rendered, " "+jp.Operator+" ") + ")"
+	}
+}
+
+type notPredicate struct {
+	Predicate Predicate
+}
+
+func (np notPredicate) renderPredicate(args *builderArgs) string {
+	return "NOT (" + np.Predicate.renderPredicate(args) + ")"
+}
+
+type rawPredicate struct {
+	Expression string
+	Args       []interface{}
+}
+
+func (rp rawPredicate) renderPredicate(args *builderArgs) string {
+	return renderRaw(rp.Expression, rp.Args, args)
+}
+
+// renderRaw replaces the `?` placeholders of a raw expression with numbered parameters.
+//
+// If the number of placeholders does not match the number of values, the error is set on the args.
+func renderRaw(expression string, values []interface{}, args *builderArgs) string {
+	var sb strings.Builder
+	var index int
+	for x := 0; x < len(expression); x++ {
+		if expression[x] != '?' {
+			sb.WriteByte(expression[x])
+			continue
+		}
+		if x+1 < len(expression) && expression[x+1] == '?' {
+			sb.WriteByte('?')
+			x++
+			continue
+		}
+		if index < len(values) {
+			sb.WriteString(args.Add(values[index]))
+		}
+		index++
+	}
+	if index != len(values) && args.Err == nil {
+		args.Err = ex.New(ErrBuilderArgsMismatch, ex.OptMessagef("expression: %s; placeholders: %d; args: %d", expression, index, len(values)))
+	}
+	return sb.String()
+}
+
+// expandValues expands a single slice value (other than a byte slice) into its elements.
+func expandValues(values []interface{}) []interface{} {
+	if len(values) != 1 || values[0] == nil {
+		return values
+	}
+	rv := reflect.ValueOf(values[0])
+	if rv.Kind() != reflect.Slice || rv.Type().Elem().Kind() == reflect.Uint8 {
+		return values
+	}
+	expanded := make([]interface{}, rv.Len())
+	for index := 0; index < rv.Len(); index++ {
+		expanded[index] = rv.Index(index).Interface()
+	}
+	return expanded
+}
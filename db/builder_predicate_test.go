@@ -0,0 +1,94 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package db
+
+import (
+	"testing"
+
+	"github.com/blend/go-sdk/assert"
+	"github.com/blend/go-sdk/ex"
+)
+
+func renderTestPredicate(predicate Predicate) (string, []interface{}) {
+	var args builderArgs
+	return predicate.renderPredicate(&args), args.Args
+}
+
+func Test_Predicates(t *testing.T) {
+	its := assert.New(t)
+
+	testCases := [...]struct {
+		Predicate Predicate
+		Expected  string
+		Args      []interface{}
+	}{
+		{Predicate: Eq("id", 1), Expected: "id = $1", Args: []interface{}{1}},
+		{Predicate: NotEq("id", 1), Expected: "id <> $1", Args: []interface{}{1}},
+		{Predicate: Gt("id", 1), Expected: "id > $1", Args: []interface{}{1}},
+		{Predicate: Gte("id", 1), Expected: "id >= $1", Args: []interface{}{1}},
+		{Predicate: Lt("id", 1), Expected: "id < $1", Args: []interface{}{1}},
+		{Predicate: Lte("id", 1), Expected: "id <= $1", Args: []interface{}{1}},
+		{Predicate: Like("name", "foo%"), Expected: "name LIKE $1", Args: []interface{}{"foo%"}},
+		{Predicate: ILike("name", "foo%"), Expected: "name ILIKE $1", Args: []interface{}{"foo%"}},
+		{Predicate: In("id", 1, 2), Expected: "id IN ($1,$2)", Args: []interface{}{1, 2}},
+		{Predicate: In("id", []int{1, 2, 3}), Expected: "id IN ($1,$2,$3)", Args: []interface{}{1, 2, 3}},
+		{Predicate: In("id"), Expected: "FALSE"},
+		{Predicate: NotIn("id", 1), Expected: "id NOT IN ($1)", Args: []interface{}{1}},
+		{Predicate: NotIn("id", []string{}), Expected: "TRUE"},
+		{Predicate: In("data", []byte("foo")), Expected: "data IN ($1)", Args: []interface{}{[]byte("foo")}},
+		{Predicate: IsNull("deleted_utc"), Expected: "deleted_utc IS NULL"},
+		{Predicate: IsNotNull("deleted_utc"), Expected: "deleted_utc IS NOT NULL"},
+		{Predicate: And(), Expected: "TRUE"},
+		{Predicate: Or(), Expected: "FALSE"},
+		{Predicate: And(nil, Eq("id", 1)), Expected: "id = $1", Args: []interface{}{1}},
+		{
+			Predicate: Or(Eq("id", 1), And(Eq("name", "foo"), IsNull("deleted_utc"))),
+			Expected:  "(id = $1 OR (name = $2 AND deleted_utc IS NULL))",
+			Args:      []interface{}{1, "foo"},
+		},
+		{Predicate: Not(Eq("id", 1)), Expected: "NOT (id = $1)", Args: []interface{}{1}},
+		{Predicate: Raw("u.id = p.user_id"), Expected: "u.id = p.user_id"},
+		{Predicate: Raw("metadata ?? 'key' AND id BETWEEN ? AND ?", 1, 10), Expected: "metadata ? 'key' AND id BETWEEN $1 AND $2", Args: []interface{}{1, 10}},
+	}
+
+	for _, tc := range testCases {
+		rendered, args := renderTestPredicate(tc.Predicate)
+		its.Equal(tc.Expected, rendered)
+		its.Equal(tc.Args, args, tc.Expected)
+	}
+}
+
+func Test_renderRaw_argsMismatch(t *testing.T) {
+	its := assert.New(t)
+
+	var args builderArgs
+	its.Equal("a = $1 AND b = ?", renderRaw("a = ? AND b = ??", []interface{}{1}, &args))
+	its.Nil(args.Err)
+
+	// more placeholders than args.
+	args = builderArgs{}
+	its.Equal("a = $1 AND b = ", renderRaw("a = ? AND b = ?", []interface{}{1}, &args))
+	its.True(ex.Is(args.Err, ErrBuilderArgsMismatch))
+	its.Equal([]interface{}{1}, args.Args)
+
+	// more args than placeholders.
+	args = builderArgs{}
+	renderRaw("a = ?", []interface{}{1, 2}, &args)
+	its.True(ex.Is(args.Err, ErrBuilderArgsMismatch))
+
+	_, _, err := Select().From("users").Where(Raw("id = ? AND team_id = ?", 1)).Build(DialectPostgres)
+	its.True(ex.Is(err, ErrBuilderArgsMismatch))
+	_, _, err = Select().From("users").Where(Raw("id = ?", 1, 2)).Build(DialectPostgres)
+	its.True(ex.Is(err, ErrBuilderArgsMismatch))
+	_, _, err = Select().From("users", "u").LeftJoin("posts p", Raw("p.user_id = ?")).Build(DialectPostgres)
+	its.True(ex.Is(err, ErrBuilderArgsMismatch))
+	_, _, err = Update("users").SetExpr("count", "count + ?").Build(DialectPostgres)
+	its.True(ex.Is(err, ErrBuilderArgsMismatch))
+	_, _, err = DeleteFrom("users").Where(Raw("id = ?", 1, 2)).Build(DialectPostgres)
+	its.True(ex.Is(err, ErrBuilderArgsMismatch))
+}
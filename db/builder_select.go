@@ -0,0 +1,175 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package db
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/blend/go-sdk/ex"
+)
+
+// Select returns a select builder for a given set of columns.
+func Select(columns ...string) *SelectBuilder {
+	return &SelectBuilder{Columns: columns}
+}
+
+// SelectFrom returns a select builder for the columns of an object from its table.
+//
+// As with `Invocation.All`, read only columns are not selected.
+func SelectFrom(object DatabaseMapped) *SelectBuilder {
+	return &SelectBuilder{
+		Table:   TableName(object),
+		Columns: Columns(object).NotReadOnly().ColumnNames(),
+	}
+}
+
+// SelectFromAs returns a select builder for the columns of an object from its table with
+// a given alias, qualifying each column with the alias (e.g. for use with joins).
+func SelectFromAs(object DatabaseMapped, alias string) *SelectBuilder {
+	return &SelectBuilder{
+		Table:   TableName(object),
+		Alias:   alias,
+		Columns: Columns(object).NotReadOnly().ColumnNamesFromAlias(alias),
+	}
+}
+
+// SelectBuilder builds `SELECT` statements.
+type SelectBuilder struct {
+	Columns    []string
+	Table      string
+	Alias      string
+	Joins      []SelectJoin
+	Predicates []Predicate
+	Orders     []string
+	LimitCount *int
+	OffsetRows *int
+}
+
+// SelectJoin is a join clause of a select statement.
+type SelectJoin struct {
+	Kind  string
+	Table string
+	On    Predicate
+}
+
+// From sets the table to select from, with an optional alias.
+func (sb *SelectBuilder) From(table string, alias ...string) *SelectBuilder {
+	sb.Table = table
+	if len(alias) > 0 {
+		sb.Alias = alias[0]
+	}
+	return sb
+}
+
+// Column adds columns or expressions to select.
+func (sb *SelectBuilder) Column(columns ...string) *SelectBuilder {
+	sb.Columns = append(sb.Columns, columns...)
+	return sb
+}
+
+// Join adds an inner join to a table (which can include an alias, e.g. `users u`).
+func (sb *SelectBuilder) Join(table string, on Predicate) *SelectBuilder {
+	sb.Joins = append(sb.Joins, SelectJoin{Kind: "INNER JOIN", Table: table, On: on})
+	return sb
+}
+
+// LeftJoin adds a left outer join to a table (which can include an alias, e.g. `users u`).
+func (sb *SelectBuilder) LeftJoin(table string, on Predicate) *SelectBuilder {
+	sb.Joins = append(sb.Joins, SelectJoin{Kind: "LEFT JOIN", Table: table, On: on})
+	return sb
+}
+
+// RightJoin adds a right outer join to a table (which can include an alias, e.g. `users u`).
+func (sb *SelectBuilder) RightJoin(table string, on Predicate) *SelectBuilder {
+	sb.Joins = append(sb.Joins, SelectJoin{Kind: "RIGHT JOIN", Table: table, On: on})
+	return sb
+}
+
+// Where adds predicates to the where clause; all predicates must be true.
+func (sb *SelectBuilder) Where(predicates ...Predicate) *SelectBuilder {
+	sb.Predicates = append(sb.Predicates, predicates...)
+	return sb
+}
+
+// OrderBy adds order by expressions, e.g. `created_utc DESC`.
+func (sb *SelectBuilder) OrderBy(expressions ...string) *SelectBuilder {
+	sb.Orders = append(sb.Orders, expressions...)
+	return sb
+}
+
+// Limit sets the maximum number of rows to return.
+func (sb *SelectBuilder) Limit(count int) *SelectBuilder {
+	sb.LimitCount = &count
+	return sb
+}
+
+// Offset sets the number of rows to skip.
+func (sb *SelectBuilder) Offset(rows int) *SelectBuilder {
+	sb.OffsetRows = &rows
+	return sb
+}
+
+// Label implements Builder.
+func (sb *SelectBuilder) Label() string {
+	return sb.Table + "_select"
+}
+
+// Build implements Builder.
+func (sb *SelectBuilder) Build(_ Dialect) (statement string, args []interface{}, err error) {
+	if sb.Table == "" {
+		err = ex.New(ErrBuilderTableUnset)
+		return
+	}
+	columns := sb.Columns
+	if len(columns) == 0 {
+		columns = []string{"*"}
+	}
+
+	var params builderArgs
+	var query strings.Builder
+	query.WriteString("SELECT ")
+	query.WriteString(strings.Join(columns, ","))
+	query.WriteString(" FROM ")
+	query.WriteString(sb.Table)
+	if sb.Alias != "" {
+		query.WriteString(" ")
+		query.WriteString(sb.Alias)
+	}
+	for _, join := range sb.Joins {
+		query.WriteString(" ")
+		query.WriteString(join.Kind)
+		query.WriteString(" ")
+		query.WriteString(join.Table)
+		if join.On != nil {
+			query.WriteString(" ON ")
+			query.WriteString(join.On.renderPredicate(&params))
+		}
+	}
+	writeWhere(&query, &params, sb.Predicates)
+	if params.Err != nil {
+		err = params.Err
+		return
+	}
+	if len(sb.Orders) > 0 {
+		query.WriteString(" ORDER BY ")
+		query.WriteString(strings.Join(sb.Orders, ","))
+	}
+	if sb.LimitCount != nil {
+		query.WriteString(" LIMIT ")
+		query.WriteString(strconv.Itoa(*sb.LimitCount))
+	}
+	if sb.OffsetRows != nil {
+		query.WriteString(" OFFSET ")
+		query.WriteString(strconv.Itoa(*sb.OffsetRows))
+	}
+
+	statement = query.String()
+	args = params.Args
+	return
+}
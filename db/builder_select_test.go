@@ -0,0 +1,54 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package db
+
+import (
+	"testing"
+
+	"github.com/blend/go-sdk/assert"
+	"github.com/blend/go-sdk/ex"
+)
+
+func Test_SelectBuilder(t *testing.T) {
+	its := assert.New(t)
+
+	statement, args, err := Select().From("users").Build(DialectPostgres)
+	its.Nil(err)
+	its.Equal("SELECT * FROM users", statement)
+	its.Empty(args)
+
+	statement, args, err = Select("u.id", "u.name", "count(p.id)").
+		From("users", "u").
+		LeftJoin("posts p", And(Raw("p.user_id = u.id"), Eq("p.published", true))).
+		Where(Eq("u.team_id", 5), Or(Like("u.name", "a%"), IsNull("u.name"))).
+		OrderBy("u.name ASC", "u.id").
+		Limit(10).
+		Offset(20).
+		Build(DialectPostgres)
+	its.Nil(err)
+	its.Equal("SELECT u.id,u.name,count(p.id) FROM users u LEFT JOIN posts p ON (p.user_id = u.id AND p.published = $1) WHERE u.team_id = $2 AND (u.name LIKE $3 OR u.name IS NULL) ORDER BY u.name ASC,u.id LIMIT 10 OFFSET 20", statement)
+	its.Equal([]interface{}{true, 5, "a%"}, args)
+
+	_, _, err = Select("id").Build(DialectPostgres)
+	its.True(ex.Is(err, ErrBuilderTableUnset))
+}
+
+func Test_SelectFrom(t *testing.T) {
+	its := assert.New(t)
+
+	sb := SelectFrom(benchObj{}).Where(Eq("id", 1))
+	its.Equal("bench_object_select", sb.Label())
+	statement, args, err := sb.Build(DialectPostgres)
+	its.Nil(err)
+	its.Equal("SELECT "+Columns(benchObj{}).NotReadOnly().ColumnNamesCSV()+" FROM bench_object WHERE id = $1", statement)
+	its.Equal([]interface{}{1}, args)
+
+	statement, _, err = SelectFromAs(benchObj{}, "b").Join("users u", Raw("u.id = b.user_id")).Build(DialectPostgres)
+	its.Nil(err)
+	its.Equal("SELECT "+Columns(benchObj{}).NotReadOnly().ColumnNamesCSVFromAlias("b")+" FROM bench_object b INNER JOIN users u ON u.id = b.user_id", statement)
+}
@@ -0,0 +1,80 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/blend/go-sdk/assert"
+	"github.com/blend/go-sdk/ex"
+)
+
+func Test_Invocation_QueryBuilder(t *testing.T) {
+	its := assert.New(t)
+
+	tx, err := defaultDB().Begin()
+	its.Nil(err)
+	defer func() { _ = tx.Rollback() }()
+	its.Nil(seedObjects(10, tx))
+
+	var objs []benchObj
+	its.Nil(defaultDB().Invoke(OptTx(tx)).QueryBuilder(
+		SelectFrom(benchObj{}).Where(Lte("id", 5)).OrderBy("id DESC").Limit(3),
+	).OutMany(&objs))
+	its.Len(objs, 3)
+	its.True(objs[0].ID > objs[1].ID)
+
+	var count int
+	_, err = defaultDB().Invoke(OptTx(tx)).QueryBuilder(Select("count(*)").From("bench_object").Where(In("id", []int{1, 2, 3}))).Scan(&count)
+	its.Nil(err)
+	its.Equal(3, count)
+
+	var inserted benchObj
+	_, err = defaultDB().Invoke(OptTx(tx)).QueryBuilder(
+		InsertObject(benchObj{UUID: "builder", Name: "builder-insert", Category: "builder"}).Returning("id"),
+	).Scan(&inserted.ID)
+	its.Nil(err)
+	its.NotZero(inserted.ID)
+
+	_, err = defaultDB().Invoke().QueryBuilder(Select("id")).Any()
+	its.True(ex.Is(err, ErrBuilderTableUnset))
+}
+
+func Test_Invocation_ExecBuilder(t *testing.T) {
+	its := assert.New(t)
+
+	tx, err := defaultDB().Begin()
+	its.Nil(err)
+	defer func() { _ = tx.Rollback() }()
+	its.Nil(seedObjects(10, tx))
+
+	var labels []string
+	interceptor := func(_ context.Context, label, statement string) (string, error) {
+		labels = append(labels, label)
+		return statement, nil
+	}
+
+	res, err := defaultDB().Invoke(OptTx(tx), OptInvocationStatementInterceptor(interceptor)).ExecBuilder(
+		Update("bench_object").Set("category", "updated").Where(Lte("id", 2)),
+	)
+	its.Nil(err)
+	affected, err := res.RowsAffected()
+	its.Nil(err)
+	its.Equal(2, affected)
+
+	res, err = defaultDB().Invoke(OptTx(tx), OptInvocationStatementInterceptor(interceptor)).ExecBuilder(DeleteObject(benchObj{ID: 1}))
+	its.Nil(err)
+	affected, err = res.RowsAffected()
+	its.Nil(err)
+	its.Equal(1, affected)
+	its.Equal([]string{"bench_object_update", "bench_object_delete"}, labels)
+
+	_, err = defaultDB().Invoke(OptLabel("custom")).ExecBuilder(Update("bench_object"))
+	its.True(ex.Is(err, ErrBuilderColumnsUnset))
+}
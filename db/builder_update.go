@@ -0,0 +1,126 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package db
+
+import (
+	"strings"
+
+	"github.com/blend/go-sdk/ex"
+)
+
+// Update returns an update builder for a table.
+func Update(table string) *UpdateBuilder {
+	return &UpdateBuilder{Table: table}
+}
+
+// UpdateObject returns an update builder for an object that sets its update columns where its primary keys match.
+//
+// As with `Invocation.Update`, the update columns exclude primary keys, auto and read only columns.
+func UpdateObject(object DatabaseMapped) *UpdateBuilder {
+	ub := &UpdateBuilder{Table: TableName(object)}
+	updateCols := Columns(object).UpdateColumns()
+	values := updateCols.ColumnValues(object)
+	for index, name := range updateCols.ColumnNames() {
+		ub.Set(name, values[index])
+	}
+	ub.Predicates, ub.Err = objectPrimaryKeyPredicates(object)
+	return ub
+}
+
+// UpdateBuilder builds `UPDATE` statements.
+type UpdateBuilder struct {
+	Table            string
+	Assignments      []UpdateAssignment
+	Predicates       []Predicate
+	ReturningColumns []string
+	Err              error
+}
+
+// UpdateAssignment is a column assignment of an update statement.
+type UpdateAssignment struct {
+	Column     string
+	Expression string
+	Args       []interface{}
+}
+
+// Set sets a column to a value.
+func (ub *UpdateBuilder) Set(column string, value interface{}) *UpdateBuilder {
+	return ub.SetExpr(column, "?", value)
+}
+
+// SetExpr sets a column to a raw sql expression, where each `?` is replaced with a
+// numbered parameter for the matching argument, e.g. `SetExpr("count", "count + ?", 1)`.
+// Building the statement returns an `ErrBuilderArgsMismatch` error if the number of placeholders and arguments differ.
+func (ub *UpdateBuilder) SetExpr(column, expression string, args ...interface{}) *UpdateBuilder {
+	ub.Assignments = append(ub.Assignments, UpdateAssignment{Column: column, Expression: expression, Args: args})
+	return ub
+}
+
+// Where adds predicates to the where clause; all predicates must be true.
+func (ub *UpdateBuilder) Where(predicates ...Predicate) *UpdateBuilder {
+	ub.Predicates = append(ub.Predicates, predicates...)
+	return ub
+}
+
+// Returning sets the columns to return from the updated rows.
+func (ub *UpdateBuilder) Returning(columns ...string) *UpdateBuilder {
+	ub.ReturningColumns = append(ub.ReturningColumns, columns...)
+	return ub
+}
+
+// Tables implements WriteBuilder.
+func (ub *UpdateBuilder) Tables() []string {
+	return []string{ub.Table}
+}
+
+// Label implements Builder.
+func (ub *UpdateBuilder) Label() string {
+	return ub.Table + "_update"
+}
+
+// Build implements Builder.
+func (ub *UpdateBuilder) Build(dialect Dialect) (statement string, args []interface{}, err error) {
+	if ub.Err != nil {
+		err = ub.Err
+		return
+	}
+	if ub.Table == "" {
+		err = ex.New(ErrBuilderTableUnset)
+		return
+	}
+	if len(ub.Assignments) == 0 {
+		err = ex.New(ErrBuilderColumnsUnset, ex.OptMessagef("table: %s", ub.Table))
+		return
+	}
+
+	var params builderArgs
+	var query strings.Builder
+	query.WriteString("UPDATE ")
+	query.WriteString(ub.Table)
+	query.WriteString(" SET ")
+	for index, assignment := range ub.Assignments {
+		if index > 0 {
+			query.WriteString(",")
+		}
+		query.WriteString(assignment.Column)
+		query.WriteString(" = ")
+		query.WriteString(renderRaw(assignment.Expression, assignment.Args, &params))
+	}
+	writeWhere(&query, &params, ub.Predicates)
+	if params.Err != nil {
+		err = params.Err
+		return
+	}
+	if err = writeReturning(&query, dialect, ub.ReturningColumns); err != nil {
+		return
+	}
+
+	statement = query.String()
+	args = params.Args
+	return
+}
@@ -0,0 +1,45 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package db
+
+import (
+	"testing"
+
+	"github.com/blend/go-sdk/assert"
+	"github.com/blend/go-sdk/ex"
+)
+
+func Test_UpdateBuilder(t *testing.T) {
+	its := assert.New(t)
+
+	ub := Update("users").Set("name", "foo").SetExpr("login_count", "login_count + ?", 1).Where(Eq("id", 5)).Returning("login_count")
+	its.Equal("users_update", ub.Label())
+	statement, args, err := ub.Build(DialectPostgres)
+	its.Nil(err)
+	its.Equal("UPDATE users SET name = $1,login_count = login_count + $2 WHERE id = $3 RETURNING login_count", statement)
+	its.Equal([]interface{}{"foo", 1, 5}, args)
+
+	_, _, err = Update("users").Build(DialectPostgres)
+	its.True(ex.Is(err, ErrBuilderColumnsUnset))
+
+	_, _, err = Update("").Set("name", "foo").Build(DialectPostgres)
+	its.True(ex.Is(err, ErrBuilderTableUnset))
+}
+
+func Test_UpdateObject(t *testing.T) {
+	its := assert.New(t)
+
+	statement, args, err := UpdateObject(benchObj{ID: 5, Name: "foo"}).Build(DialectPostgres)
+	its.Nil(err)
+	its.Equal("UPDATE bench_object SET uuid = $1,name = $2,timestamp_utc = $3,amount = $4,pending = $5,category = $6 WHERE id = $7", statement)
+	its.Len(args, 7)
+	its.Equal(5, args[6])
+
+	_, _, err = UpdateObject(copyTestNoKeysObj{Name: "foo"}).Build(DialectPostgres)
+	its.True(ex.Is(err, ErrNoPrimaryKey))
+}
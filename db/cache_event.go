@@ -0,0 +1,88 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package db
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/blend/go-sdk/ansi"
+	"github.com/blend/go-sdk/logger"
+)
+
+// Logger flags
+const (
+	CacheFlag = "db.cache"
+)
+
+// these are compile time assertions
+var (
+	_ logger.Event        = (*CacheEvent)(nil)
+	_ logger.TextWritable = (*CacheEvent)(nil)
+	_ logger.JSONWritable = (*CacheEvent)(nil)
+)
+
+// NewCacheEventListener returns a new listener for cache events.
+func NewCacheEventListener(listener func(context.Context, CacheEvent)) logger.Listener {
+	return func(ctx context.Context, e logger.Event) {
+		if typed, isTyped := e.(CacheEvent); isTyped {
+			listener(ctx, typed)
+		}
+	}
+}
+
+// CacheEvent is an event for a query cache hit or miss, or a failure to write to the query cache.
+type CacheEvent struct {
+	Database string
+	Engine   string
+	Label    string
+	// Result is one of `QueryCacheHit`, `QueryCacheMiss`, `QueryCacheSet` or `QueryCacheInvalidate`.
+	Result string
+	Tables []string
+	Err    error
+}
+
+// GetFlag implements Event.
+func (e CacheEvent) GetFlag() string { return CacheFlag }
+
+// WriteText writes the event text to the output.
+func (e CacheEvent) WriteText(tf logger.TextFormatter, wr io.Writer) {
+	fmt.Fprintf(wr, "[%s]", tf.Colorize(e.Database, ansi.ColorLightWhite))
+	if e.Label != "" {
+		fmt.Fprint(wr, logger.Space)
+		fmt.Fprint(wr, e.Label)
+	}
+	fmt.Fprint(wr, logger.Space)
+	if e.Result == QueryCacheHit {
+		fmt.Fprint(wr, tf.Colorize(e.Result, ansi.ColorGreen))
+	} else {
+		fmt.Fprint(wr, tf.Colorize(e.Result, ansi.ColorYellow))
+	}
+	if len(e.Tables) > 0 {
+		fmt.Fprint(wr, logger.Space)
+		fmt.Fprint(wr, strings.Join(e.Tables, ","))
+	}
+	if e.Err != nil {
+		fmt.Fprint(wr, logger.Space)
+		fmt.Fprint(wr, tf.Colorize(e.Err.Error(), ansi.ColorRed))
+	}
+}
+
+// Decompose implements JSONWritable.
+func (e CacheEvent) Decompose() map[string]interface{} {
+	return map[string]interface{}{
+		"database": e.Database,
+		"engine":   e.Engine,
+		"label":    e.Label,
+		"result":   e.Result,
+		"tables":   e.Tables,
+		"err":      e.Err,
+	}
+}
@@ -0,0 +1,53 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package db
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/blend/go-sdk/assert"
+	"github.com/blend/go-sdk/logger"
+)
+
+func TestCacheEvent(t *testing.T) {
+	assert := assert.New(t)
+
+	ce := CacheEvent{
+		Database: "event-database",
+		Engine:   "event-engine",
+		Label:    "event-label",
+		Result:   QueryCacheMiss,
+		Tables:   []string{"foo", "bar"},
+		Err:      fmt.Errorf("test error"),
+	}
+	assert.Equal(CacheFlag, ce.GetFlag())
+
+	buf := new(bytes.Buffer)
+	ce.WriteText(logger.TextOutputFormatter{NoColor: true}, buf)
+	assert.Equal("[event-database] event-label miss foo,bar test error", buf.String())
+
+	decomposed := ce.Decompose()
+	assert.Equal("event-engine", decomposed["engine"])
+	assert.Equal(QueryCacheMiss, decomposed["result"])
+}
+
+func TestCacheEventListener(t *testing.T) {
+	assert := assert.New(t)
+
+	var didCall bool
+	listener := NewCacheEventListener(func(_ context.Context, ce CacheEvent) {
+		didCall = true
+		assert.Equal(QueryCacheHit, ce.Result)
+	})
+	listener(context.Background(), CacheEvent{Result: QueryCacheHit})
+	listener(context.Background(), NewQueryEvent("select 1", 0))
+	assert.True(didCall)
+}
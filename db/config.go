@@ -93,6 +93,8 @@ func NewConfigFromDSN(dsn string) (config Config, err error) {
 //  -  DB_MAX_LIFETIME      = MaxLifetime
 //  -  DB_BUFFER_POOL_SIZE  = BufferPoolSize
 //  -  DB_DIALECT           = Dialect
+//  -  DB_REPLICAS          = Replicas
+//  -  DB_REPLICA_BALANCER  = ReplicaBalancer
 func NewConfigFromEnv() (config Config, err error) {
 	if err = (&config).Resolve(env.WithVars(context.Background(), env.Env())); err != nil {
 		return
@@ -183,6 +185,11 @@ type Config struct {
 	BufferPoolSize int `json:"bufferPoolSize,omitempty" yaml:"bufferPoolSize,omitempty" env:"DB_BUFFER_POOL_SIZE"`
 	// Dialect includes hints to tweak specific sql semantics by database connection.
 	Dialect string `json:"dialect,omitempty" yaml:"dialect,omitempty" env:"DB_DIALECT"`
+	// Replicas are the DSNs of read replicas. Read only queries are routed to healthy replicas
+	// with the same pool settings as the primary.
+	Replicas []string `json:"replicas,omitempty" yaml:"replicas,omitempty" env:"DB_REPLICAS"`
+	// ReplicaBalancer determines how reads are balanced across replicas, either `round_robin` (the default) or `least_loaded`.
+	ReplicaBalancer string `json:"replicaBalancer,omitempty" yaml:"replicaBalancer,omitempty" env:"DB_REPLICA_BALANCER"`
 }
 
 // IsZero returns if the config is unset.
@@ -212,6 +219,8 @@ func (c *Config) Resolve(ctx context.Context) error {
 		configutil.SetDuration(&c.MaxIdleTime, configutil.Env(EnvVarDBMaxIdleTime), configutil.Duration(c.MaxIdleTime), configutil.Duration(DefaultMaxIdleTime)),
 		configutil.SetInt(&c.BufferPoolSize, configutil.Env(EnvVarDBBufferPoolSize), configutil.Int(c.BufferPoolSize), configutil.Int(DefaultBufferPoolSize)),
 		configutil.SetString(&c.Dialect, configutil.Env(EnvVarDBDialect), configutil.String(c.Dialect), configutil.String(DialectPostgres)),
+		configutil.SetStrings(&c.Replicas, configutil.Env(EnvVarDBReplicas), configutil.Strings(c.Replicas)),
+		configutil.SetString(&c.ReplicaBalancer, configutil.Env(EnvVarDBReplicaBalancer), configutil.String(c.ReplicaBalancer), configutil.String(ReplicaBalancerRoundRobin)),
 	)
 }
 
@@ -226,6 +235,8 @@ func (c Config) Reparse() (Config, error) {
 	cfg.MaxConnections = c.MaxConnections
 	cfg.BufferPoolSize = c.BufferPoolSize
 	cfg.MaxLifetime = c.MaxLifetime
+	cfg.Replicas = c.Replicas
+	cfg.ReplicaBalancer = c.ReplicaBalancer
 
 	return cfg, nil
 }
@@ -329,6 +340,14 @@ func (c Config) DialectOrDefault() Dialect {
 	return DialectPostgres
 }
 
+// ReplicaBalancerOrDefault returns the replica balancer or a default.
+func (c Config) ReplicaBalancerOrDefault() string {
+	if c.ReplicaBalancer != "" {
+		return c.ReplicaBalancer
+	}
+	return ReplicaBalancerRoundRobin
+}
+
 // CreateDSN creates a postgres connection string from the config.
 func (c Config) CreateDSN() string {
 	if c.DSN != "" {
@@ -407,6 +426,9 @@ func (c Config) Validate() error {
 	if c.StatementTimeout.Round(time.Millisecond) != c.StatementTimeout {
 		return ex.New(ErrDurationConversion, ex.OptMessagef("statement_timeout=%s", c.StatementTimeout))
 	}
+	if balancer := c.ReplicaBalancerOrDefault(); balancer != ReplicaBalancerRoundRobin && balancer != ReplicaBalancerLeastLoaded {
+		return ex.New(ErrInvalidReplicaBalancer, ex.OptMessagef("replica_balancer=%s", balancer))
+	}
 
 	return nil
 }
@@ -14,6 +14,7 @@ import (
 
 	"github.com/blend/go-sdk/assert"
 	"github.com/blend/go-sdk/configutil"
+	"github.com/blend/go-sdk/ex"
 )
 
 var (
@@ -212,6 +213,8 @@ func TestConfigValidateProduction(t *testing.T) {
 	assert.Nil(Config{Username: "foo", Password: "bar", SSLMode: SSLModeVerifyFull}.ValidateProduction())
 	assert.True(IsDurationConversion(Config{Username: "foo", Password: "bar", SSLMode: SSLModeVerifyFull, LockTimeout: time.Nanosecond}.ValidateProduction()))
 	assert.True(IsDurationConversion(Config{Username: "foo", Password: "bar", SSLMode: SSLModeVerifyFull, StatementTimeout: time.Nanosecond}.ValidateProduction()))
+	assert.True(ex.Is(Config{Username: "foo", Password: "bar", SSLMode: SSLModeVerifyFull, ReplicaBalancer: "random"}.ValidateProduction(), ErrInvalidReplicaBalancer))
+	assert.Nil(Config{Username: "foo", Password: "bar", SSLMode: SSLModeVerifyFull, ReplicaBalancer: ReplicaBalancerLeastLoaded}.ValidateProduction())
 }
 
 func TestConfigReparse(t *testing.T) {
@@ -68,11 +68,13 @@ type Connection struct {
 	Log                  logger.Log
 	Tracer               Tracer
 	StatementInterceptor StatementInterceptor
+	Replicas             *ReplicaSet
+	QueryCache           QueryCache
 }
 
 // Close implements a closer.
 func (dbc *Connection) Close() error {
-	return dbc.Connection.Close()
+	return ex.Nest(dbc.Connection.Close(), dbc.Replicas.Close())
 }
 
 // Open returns a connection object, either a cached connection object or creating a new one in the process.
@@ -88,26 +90,51 @@ func (dbc *Connection) Open() error {
 		dbc.BufferPool = bufferutil.NewPool(dbc.Config.BufferPoolSizeOrDefault())
 	}
 
-	dsn := dbc.Config.CreateDSN()
-	namedValues, err := ParseURL(dsn)
+	// open the connection
+	dbConn, err := dbc.openPool(dbc.Config.CreateDSN())
 	if err != nil {
 		return err
 	}
 
-	// open the connection
-	dbConn, err := sql.Open(dbc.Config.EngineOrDefault(), namedValues)
-	if err != nil {
-		return Error(err)
+	// open the read replicas, if any
+	if len(dbc.Config.Replicas) > 0 {
+		replicas := &ReplicaSet{Balancer: dbc.Config.ReplicaBalancerOrDefault()}
+		for _, replicaDSN := range dbc.Config.Replicas {
+			name, err := replicaName(replicaDSN)
+			if err != nil {
+				_ = ex.Nest(dbConn.Close(), replicas.Close())
+				return err
+			}
+			replicaConn, err := dbc.openPool(replicaDSN)
+			if err != nil {
+				_ = ex.Nest(dbConn.Close(), replicas.Close())
+				return err
+			}
+			replicas.Replicas = append(replicas.Replicas, &Replica{Name: name, Connection: replicaConn})
+		}
+		dbc.Replicas = replicas
 	}
-
 	dbc.Connection = dbConn
-	dbc.Connection.SetConnMaxLifetime(dbc.Config.MaxLifetimeOrDefault())
-	dbc.Connection.SetConnMaxIdleTime(dbc.Config.MaxIdleTimeOrDefault())
-	dbc.Connection.SetMaxIdleConns(dbc.Config.IdleConnectionsOrDefault())
-	dbc.Connection.SetMaxOpenConns(dbc.Config.MaxConnectionsOrDefault())
 	return nil
 }
 
+// openPool opens a connection pool for a dsn with the pool settings from the config.
+func (dbc *Connection) openPool(dsn string) (*sql.DB, error) {
+	namedValues, err := ParseURL(dsn)
+	if err != nil {
+		return nil, err
+	}
+	dbConn, err := sql.Open(dbc.Config.EngineOrDefault(), namedValues)
+	if err != nil {
+		return nil, Error(err)
+	}
+	dbConn.SetConnMaxLifetime(dbc.Config.MaxLifetimeOrDefault())
+	dbConn.SetConnMaxIdleTime(dbc.Config.MaxIdleTimeOrDefault())
+	dbConn.SetMaxIdleConns(dbc.Config.IdleConnectionsOrDefault())
+	dbConn.SetMaxOpenConns(dbc.Config.MaxConnectionsOrDefault())
+	return dbConn, nil
+}
+
 // Begin starts a new transaction.
 func (dbc *Connection) Begin(opts ...func(*sql.TxOptions)) (*sql.Tx, error) {
 	if dbc.Connection == nil {
@@ -160,9 +187,12 @@ func (dbc *Connection) Invoke(options ...InvocationOption) *Invocation {
 		Log:                  dbc.Log,
 		Tracer:               dbc.Tracer,
 		StatementInterceptor: dbc.StatementInterceptor,
+		Replicas:             dbc.Replicas,
+		QueryCache:           dbc.QueryCache,
 	}
 	if dbc.Connection != nil {
 		i.DB = dbc.Connection
+		i.primary = i.DB
 	}
 	for _, option := range options {
 		option(&i)
@@ -191,7 +221,14 @@ func (dbc *Connection) QueryContext(ctx context.Context, statement string, args
 }
 
 // Check implements a status check.
+//
+// If the connection has read replicas, each replica is also checked and reads are only routed
+// to replicas that passed their most recent check. Replica failures are logged as warnings rather
+// than failing the check, as reads fall back to the primary.
 func (dbc *Connection) Check(ctx context.Context) error {
-	_, err := dbc.QueryContext(ctx, "select 1").Any()
+	if replicaErr := dbc.Replicas.Check(ctx); replicaErr != nil {
+		logger.MaybeWarningContext(ctx, dbc.Log, replicaErr)
+	}
+	_, err := dbc.QueryContext(WithForcePrimary(ctx), "select 1").Any()
 	return err
 }
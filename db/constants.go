@@ -74,6 +74,12 @@ const (
 	// EnvVarDBDialect is the environment variable used to set the dialect
 	// on a connection configuration (e.g. `postgres` or `cockroachdb`).
 	EnvVarDBDialect = "DB_DIALECT"
+	// EnvVarDBReplicas is the environment variable used to set the read
+	// replica DSNs as a comma separated list.
+	EnvVarDBReplicas = "DB_REPLICAS"
+	// EnvVarDBReplicaBalancer is the environment variable used to set how
+	// reads are balanced across read replicas (e.g. `round_robin` or `least_loaded`).
+	EnvVarDBReplicaBalancer = "DB_REPLICA_BALANCER"
 
 	// DefaultHost is the default database hostname, typically used
 	// when developing locally.
@@ -9,6 +9,7 @@ package db
 
 import (
 	"context"
+	"sync/atomic"
 )
 
 type connectionKey struct{}
@@ -42,3 +43,45 @@ func IsSkipQueryLogging(ctx context.Context) bool {
 	}
 	return false
 }
+
+type forcePrimaryKey struct{}
+
+// WithForcePrimary sets the context to route all queries to the primary, rather than to read replicas.
+func WithForcePrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, forcePrimaryKey{}, true)
+}
+
+// IsForcePrimary returns if queries for a context should be routed to the primary.
+func IsForcePrimary(ctx context.Context) bool {
+	if v := ctx.Value(forcePrimaryKey{}); v != nil {
+		return true
+	}
+	return false
+}
+
+type readYourWritesKey struct{}
+
+type readYourWrites struct {
+	written int32
+}
+
+// WithReadYourWrites sets the context to route reads to the primary once a write has been made
+// with it (or a context derived from it), so that the reads observe the write regardless of replica lag.
+func WithReadYourWrites(ctx context.Context) context.Context {
+	return context.WithValue(ctx, readYourWritesKey{}, new(readYourWrites))
+}
+
+// HasWritten returns if a write has been made with a context set up with `WithReadYourWrites`.
+func HasWritten(ctx context.Context) bool {
+	if typed, ok := ctx.Value(readYourWritesKey{}).(*readYourWrites); ok {
+		return atomic.LoadInt32(&typed.written) == 1
+	}
+	return false
+}
+
+// markWritten marks that a write has been made with a context set up with `WithReadYourWrites`.
+func markWritten(ctx context.Context) {
+	if typed, ok := ctx.Value(readYourWritesKey{}).(*readYourWrites); ok {
+		atomic.StoreInt32(&typed.written, 1)
+	}
+}
@@ -0,0 +1,253 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/stdlib"
+
+	"github.com/blend/go-sdk/ex"
+)
+
+const (
+	// ErrCopyUnsupportedDB is returned by copy operations if the invocation db is not a `*sql.DB` or `*sql.Conn`.
+	ErrCopyUnsupportedDB ex.Class = "db: copy requires a *sql.DB or *sql.Conn; use a *sql.Conn to copy within a transaction"
+	// ErrCopyUnsupportedDriver is returned by copy operations if the underlying driver is not pgx.
+	ErrCopyUnsupportedDriver ex.Class = "db: copy requires the pgx driver"
+	// ErrCopyNoUniqueKeys is returned by copy upserts if the object has no unique or primary keys to resolve conflicts with.
+	ErrCopyNoUniqueKeys ex.Class = "db: copy upsert requires unique or primary keys"
+	// ErrCopyStatementRewritten is returned by copy operations if a statement interceptor changes the copy statement,
+	// as copies are sent with the copy protocol rather than as statements.
+	ErrCopyStatementRewritten ex.Class = "db: copy statements cannot be rewritten by statement interceptors"
+)
+
+// CopySource is an iterator of objects to copy into a table.
+type CopySource interface {
+	// Next advances to the next object, and returns false when there are no more objects or an error occurred.
+	Next() bool
+	// Object returns the current object.
+	Object() DatabaseMapped
+	// Err returns any error encountered while iterating.
+	Err() error
+}
+
+// CopySlice returns a copy source for a slice of objects.
+func CopySlice(objects interface{}) CopySource {
+	return &copySliceSource{Slice: ReflectValue(objects), Index: -1}
+}
+
+type copySliceSource struct {
+	Slice reflect.Value
+	Index int
+}
+
+func (css *copySliceSource) Next() bool {
+	css.Index++
+	return css.Index < css.Slice.Len()
+}
+
+func (css *copySliceSource) Object() DatabaseMapped {
+	return css.Slice.Index(css.Index).Interface()
+}
+
+func (css *copySliceSource) Err() error { return nil }
+
+// CopyMany writes many objects to the database with `COPY FROM STDIN`, which is substantially faster
+// than `CreateMany` for large numbers of objects and is not subject to parameter limits.
+//
+// Like `CreateMany`, the insert columns of the objects are copied (i.e. auto and read-only columns are skipped).
+// It requires the pgx driver, and the invocation db to be a `*sql.DB` or `*sql.Conn`; to copy
+// within a transaction, begin the transaction on a `*sql.Conn` and pass it with `OptInvocationDB`.
+//
+// Statement interceptors are called with the copy statement, but cannot change it; copies return
+// `ErrCopyStatementRewritten` if an interceptor returns a different statement.
+func (i *Invocation) CopyMany(objects interface{}) (rowsCopied int64, err error) {
+	sliceType := ReflectSliceType(objects)
+	tableName := TableNameByType(sliceType)
+	return i.copyFrom(tableName, ColumnsFromType(tableName, sliceType), CopySlice(objects), false)
+}
+
+// CopyUpsertMany upserts many objects with `COPY FROM STDIN` by way of a temporary table.
+//
+// Objects are copied into a temporary table, and then inserted into the table with conflicts on the unique
+// keys of the object (or its primary keys if it has no unique keys) updating the existing rows.
+func (i *Invocation) CopyUpsertMany(objects interface{}) (rowsCopied int64, err error) {
+	sliceType := ReflectSliceType(objects)
+	tableName := TableNameByType(sliceType)
+	return i.copyFrom(tableName, ColumnsFromType(tableName, sliceType), CopySlice(objects), true)
+}
+
+// CopyFrom writes the objects from a source to the table for a given sample object with `COPY FROM STDIN`.
+//
+// The objects are streamed to the database as they are read from the source, so they do not need to
+// be held in memory at once. If `upsert` is true, the objects are upserted as with `CopyUpsertMany`.
+func (i *Invocation) CopyFrom(sample DatabaseMapped, source CopySource, upsert bool) (rowsCopied int64, err error) {
+	return i.copyFrom(TableName(sample), Columns(sample), source, upsert)
+}
+
+func (i *Invocation) copyFrom(tableName string, cols *ColumnCollection, source CopySource, upsert bool) (rowsCopied int64, err error) {
+	insertCols := cols.InsertColumns()
+	columnNames := insertCols.ColumnNames()
+
+	var queryBody string
+	defer func() { err = i.finish(queryBody, recover(), driver.RowsAffected(rowsCopied), err) }()
+	defer i.invalidateCacheTablesOnSuccess(&err, tableName)
+
+	var upsertBody, tempTableName string
+	if upsert {
+		uks := insertCols.UniqueKeys()
+		if uks.Len() == 0 {
+			uks = insertCols.PrimaryKeys()
+		}
+		if uks.Len() == 0 {
+			err = Error(ErrCopyNoUniqueKeys, ex.OptMessagef("table: %s", tableName))
+			return
+		}
+		tempTableName = "copy_" + strings.ReplaceAll(tableName, ".", "_")
+		upsertBody = generateCopyUpsert(tableName, tempTableName, columnNames, uks.ColumnNames())
+		i.maybeSetLabel(tableName + "_copy_upsert")
+		queryBody = fmt.Sprintf("COPY %s (%s) FROM STDIN; %s", tempTableName, strings.Join(columnNames, ","), upsertBody)
+	} else {
+		i.maybeSetLabel(tableName + "_copy")
+		queryBody = fmt.Sprintf("COPY %s (%s) FROM STDIN", tableName, strings.Join(columnNames, ","))
+	}
+
+	statement := queryBody
+	queryBody, err = i.start(queryBody)
+	if err != nil {
+		return
+	}
+	if queryBody != statement {
+		err = Error(ErrCopyStatementRewritten, ex.OptMessagef("table: %s", tableName))
+		return
+	}
+	rows := &copyRows{Source: source, Columns: insertCols}
+	err = i.withPgxConn(func(conn *pgx.Conn) error {
+		if !upsert {
+			var copyErr error
+			rowsCopied, copyErr = conn.CopyFrom(i.Context, copyIdentifier(tableName), columnNames, rows)
+			return copyErr
+		}
+		// only manage a transaction if the connection is not already in one.
+		if conn.PgConn().TxStatus() != 'I' {
+			var copyErr error
+			rowsCopied, copyErr = copyUpsert(i.Context, conn, tableName, tempTableName, columnNames, upsertBody, rows)
+			return copyErr
+		}
+		return conn.BeginFunc(i.Context, func(tx pgx.Tx) error {
+			var copyErr error
+			rowsCopied, copyErr = copyUpsert(i.Context, tx, tableName, tempTableName, columnNames, upsertBody, rows)
+			return copyErr
+		})
+	})
+	if err != nil {
+		err = Error(err)
+	}
+	return
+}
+
+// withPgxConn calls an action with the underlying pgx connection of the invocation db.
+func (i *Invocation) withPgxConn(action func(*pgx.Conn) error) error {
+	raw := func(conn *sql.Conn) error {
+		return conn.Raw(func(driverConn interface{}) error {
+			typed, ok := driverConn.(*stdlib.Conn)
+			if !ok {
+				return ex.New(ErrCopyUnsupportedDriver)
+			}
+			return action(typed.Conn())
+		})
+	}
+	switch typed := i.DB.(type) {
+	case *sql.DB:
+		conn, err := typed.Conn(i.Context)
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+		return raw(conn)
+	case *sql.Conn:
+		return raw(typed)
+	default:
+		return ex.New(ErrCopyUnsupportedDB)
+	}
+}
+
+// copyConn is the subset of a pgx connection or transaction used by copy upserts.
+type copyConn interface {
+	Exec(context.Context, string, ...interface{}) (pgconn.CommandTag, error)
+	CopyFrom(context.Context, pgx.Identifier, []string, pgx.CopyFromSource) (int64, error)
+}
+
+func copyUpsert(ctx context.Context, conn copyConn, tableName, tempTableName string, columnNames []string, upsertBody string, rows pgx.CopyFromSource) (rowsCopied int64, err error) {
+	if _, err = conn.Exec(ctx, fmt.Sprintf("CREATE TEMP TABLE %s (LIKE %s INCLUDING DEFAULTS) ON COMMIT DROP", tempTableName, tableName)); err != nil {
+		return
+	}
+	if rowsCopied, err = conn.CopyFrom(ctx, pgx.Identifier{tempTableName}, columnNames, rows); err != nil {
+		return
+	}
+	if _, err = conn.Exec(ctx, upsertBody); err != nil {
+		return
+	}
+	// drop the table explicitly in case the enclosing transaction copies into the same table again.
+	_, err = conn.Exec(ctx, "DROP TABLE "+tempTableName)
+	return
+}
+
+func generateCopyUpsert(tableName, tempTableName string, columnNames, uniqueKeyNames []string) string {
+	updates := make([]string, 0, len(columnNames))
+	for _, name := range columnNames {
+		updates = append(updates, fmt.Sprintf("%s=Excluded.%s", name, name))
+	}
+	columns := strings.Join(columnNames, ",")
+	return fmt.Sprintf("INSERT INTO %s (%s) SELECT %s FROM %s ON CONFLICT (%s) DO UPDATE SET %s",
+		tableName, columns, columns, tempTableName, strings.Join(uniqueKeyNames, ","), strings.Join(updates, ","),
+	)
+}
+
+// copyIdentifier returns a pgx identifier for a (possibly schema qualified) table name.
+func copyIdentifier(tableName string) pgx.Identifier {
+	return pgx.Identifier(strings.Split(tableName, "."))
+}
+
+// copyRows adapts a copy source to a pgx copy from source.
+type copyRows struct {
+	Source  CopySource
+	Columns *ColumnCollection
+}
+
+func (cr *copyRows) Next() bool { return cr.Source.Next() }
+
+func (cr *copyRows) Values() ([]interface{}, error) {
+	values := cr.Columns.ColumnValues(cr.Source.Object())
+	for index, value := range values {
+		valuer, ok := value.(driver.Valuer)
+		if !ok {
+			continue
+		}
+		if rv := reflect.ValueOf(valuer); rv.Kind() == reflect.Ptr && rv.IsNil() {
+			values[index] = nil
+			continue
+		}
+		converted, err := valuer.Value()
+		if err != nil {
+			return nil, err
+		}
+		values[index] = converted
+	}
+	return values, nil
+}
+
+func (cr *copyRows) Err() error { return cr.Source.Err() }
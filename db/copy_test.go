@@ -0,0 +1,177 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+
+	"github.com/blend/go-sdk/assert"
+	"github.com/blend/go-sdk/ex"
+)
+
+type copyTestObj struct {
+	ID       int               `db:"id,pk"`
+	Name     string            `db:"name"`
+	Metadata map[string]string `db:"metadata,json"`
+	Serial   int               `db:"serial,auto"`
+}
+
+func (copyTestObj) TableName() string {
+	return "copy_test_obj"
+}
+
+type copyTestNoKeysObj struct {
+	Name string `db:"name"`
+}
+
+func (copyTestNoKeysObj) TableName() string {
+	return "copy_test_no_keys_obj"
+}
+
+// copyTestConn returns a connection within a transaction that is rolled back when the test completes.
+func copyTestConn(t *testing.T) *sql.Conn {
+	t.Helper()
+	conn, err := defaultDB().Connection.Conn(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_, _ = conn.ExecContext(context.Background(), "ROLLBACK")
+		_ = conn.Close()
+	})
+	if _, err = conn.ExecContext(context.Background(), "BEGIN"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = conn.ExecContext(context.Background(), "CREATE TABLE copy_test_obj (id int not null primary key, name varchar(255), metadata jsonb, serial serial)"); err != nil {
+		t.Fatal(err)
+	}
+	return conn
+}
+
+type copyTestSource struct {
+	Count int
+	Index int
+}
+
+func (cts *copyTestSource) Next() bool {
+	cts.Index++
+	return cts.Index <= cts.Count
+}
+
+func (cts *copyTestSource) Object() DatabaseMapped {
+	return copyTestObj{ID: cts.Index, Name: fmt.Sprintf("streamed-%d", cts.Index)}
+}
+
+func (cts *copyTestSource) Err() error { return nil }
+
+func Test_Invocation_CopyMany(t *testing.T) {
+	its := assert.New(t)
+	conn := copyTestConn(t)
+
+	var objects []copyTestObj
+	for x := 0; x < 1000; x++ {
+		objects = append(objects, copyTestObj{ID: x, Name: fmt.Sprintf("object-%d", x), Metadata: map[string]string{"index": fmt.Sprint(x)}})
+	}
+	copied, err := defaultDB().Invoke(OptInvocationDB(conn)).CopyMany(objects)
+	its.Nil(err)
+	its.Equal(1000, copied)
+
+	var verify []copyTestObj
+	its.Nil(defaultDB().Invoke(OptInvocationDB(conn)).Query("SELECT * FROM copy_test_obj ORDER BY id").OutMany(&verify))
+	its.Len(verify, 1000)
+	its.Equal("object-10", verify[10].Name)
+	its.Equal("10", verify[10].Metadata["index"])
+	its.NotZero(verify[10].Serial)
+
+	// conflicts fail without an upsert
+	_, err = defaultDB().Invoke(OptInvocationDB(conn)).CopyMany(objects[:1])
+	its.NotNil(err)
+}
+
+func Test_Invocation_CopyUpsertMany(t *testing.T) {
+	its := assert.New(t)
+	conn := copyTestConn(t)
+
+	copied, err := defaultDB().Invoke(OptInvocationDB(conn)).CopyMany([]copyTestObj{
+		{ID: 1, Name: "one"},
+		{ID: 2, Name: "two"},
+	})
+	its.Nil(err)
+	its.Equal(2, copied)
+
+	copied, err = defaultDB().Invoke(OptInvocationDB(conn)).CopyUpsertMany([]copyTestObj{
+		{ID: 2, Name: "two-updated"},
+		{ID: 3, Name: "three"},
+	})
+	its.Nil(err)
+	its.Equal(2, copied)
+
+	// the temp table is dropped so upserts can run again in the same transaction.
+	_, err = defaultDB().Invoke(OptInvocationDB(conn)).CopyUpsertMany([]copyTestObj{{ID: 3, Name: "three-updated"}})
+	its.Nil(err)
+
+	var verify []copyTestObj
+	its.Nil(defaultDB().Invoke(OptInvocationDB(conn)).Query("SELECT * FROM copy_test_obj ORDER BY id").OutMany(&verify))
+	its.Len(verify, 3)
+	its.Equal("one", verify[0].Name)
+	its.Equal("two-updated", verify[1].Name)
+	its.Equal("three-updated", verify[2].Name)
+}
+
+func Test_Invocation_CopyFrom(t *testing.T) {
+	its := assert.New(t)
+	conn := copyTestConn(t)
+
+	copied, err := defaultDB().Invoke(OptInvocationDB(conn)).CopyFrom(copyTestObj{}, &copyTestSource{Count: 500}, false)
+	its.Nil(err)
+	its.Equal(500, copied)
+
+	copied, err = defaultDB().Invoke(OptInvocationDB(conn)).CopyFrom(copyTestObj{}, &copyTestSource{Count: 600}, true)
+	its.Nil(err)
+	its.Equal(600, copied)
+
+	var count int
+	_, err = defaultDB().Invoke(OptInvocationDB(conn)).Query("SELECT count(*) FROM copy_test_obj").Scan(&count)
+	its.Nil(err)
+	its.Equal(600, count)
+}
+
+func Test_Invocation_CopyMany_errors(t *testing.T) {
+	its := assert.New(t)
+
+	tx, err := defaultDB().Begin()
+	its.Nil(err)
+	defer func() { _ = tx.Rollback() }()
+
+	_, err = defaultDB().Invoke(OptTx(tx)).CopyMany([]copyTestObj{{ID: 1}})
+	its.True(ex.Is(err, ErrCopyUnsupportedDB))
+
+	_, err = defaultDB().Invoke().CopyUpsertMany([]copyTestNoKeysObj{{Name: "foo"}})
+	its.True(ex.Is(err, ErrCopyNoUniqueKeys))
+
+	_, err = defaultDB().Invoke(OptInvocationStatementInterceptor(failInterceptor)).CopyMany([]copyTestObj{{ID: 1}})
+	its.Equal(failInterceptorError, err.Error())
+
+	rewriteInterceptor := func(_ context.Context, _, statement string) (string, error) {
+		return "/* rewritten */ " + statement, nil
+	}
+	_, err = defaultDB().Invoke(OptInvocationStatementInterceptor(rewriteInterceptor)).CopyMany([]copyTestObj{{ID: 1}})
+	its.True(ex.Is(err, ErrCopyStatementRewritten))
+}
+
+func Test_generateCopyUpsert(t *testing.T) {
+	its := assert.New(t)
+
+	its.Equal(
+		"INSERT INTO foo (id,name) SELECT id,name FROM copy_foo ON CONFLICT (id) DO UPDATE SET id=Excluded.id,name=Excluded.name",
+		generateCopyUpsert("foo", "copy_foo", []string{"id", "name"}, []string{"id"}),
+	)
+}
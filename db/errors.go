@@ -46,6 +46,8 @@ const (
 	ErrRowsNotColumnsProvider ex.Class = "db: rows is not a columns provider"
 	// ErrTooManyRows is returned by Out if there is more than one row returned by the query
 	ErrTooManyRows ex.Class = "db: too many rows returned to map to single object"
+	// ErrInvalidReplicaBalancer is returned by `Config.Validate` if the replica balancer is not a known balancer.
+	ErrInvalidReplicaBalancer ex.Class = "db: invalid replica balancer"
 
 	// ErrNetwork is a grouped error for network issues.
 	ErrNetwork ex.Class = "db: network error"
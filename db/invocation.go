@@ -33,15 +33,30 @@ type Invocation struct {
 	Tracer               Tracer
 	StartTime            time.Time
 	TraceFinisher        TraceFinisher
+	Replicas             *ReplicaSet
+	ForcePrimary         bool
+	Node                 string
+	QueryCache           QueryCache
+	CacheTTL             time.Duration
+	CacheTables          []string
+
+	primary  DB
+	readOnly bool
 }
 
 // Exec executes a sql statement with a given set of arguments and returns the rows affected.
-func (i *Invocation) Exec(statement string, args ...interface{}) (res sql.Result, err error) {
+func (i *Invocation) Exec(statement string, args ...interface{}) (sql.Result, error) {
+	return i.exec(statement, args, nil)
+}
+
+// exec executes a statement, invalidating the cached results for the tables it writes to if it succeeds.
+func (i *Invocation) exec(statement string, args []interface{}, tables []string) (res sql.Result, err error) {
 	statement, err = i.start(statement)
 	if err != nil {
 		return
 	}
 	defer func() { err = i.finish(statement, recover(), res, err) }()
+	defer i.invalidateCacheTablesOnSuccess(&err, tables...)
 
 	res, err = i.DB.ExecContext(i.Context, statement, args...)
 	if err != nil {
@@ -51,11 +66,38 @@ func (i *Invocation) Exec(statement string, args ...interface{}) (res sql.Result
 	return
 }
 
+// Notify sends a notification with a payload on a channel with `pg_notify`.
+//
+// If the invocation is within a transaction, the notification is delivered to listeners when the transaction commits.
+func (i *Invocation) Notify(channel, payload string) error {
+	i.maybeSetLabel("notify")
+	return IgnoreExecResult(i.Exec("SELECT pg_notify($1, $2)", channel, payload))
+}
+
 // Query returns a new query object for a given sql query and arguments.
+//
+// If the connection has read replicas, plain `SELECT` statements are routed to a replica;
+// use `OptForcePrimary` for selects with side effects (e.g. calling `nextval`).
 func (i *Invocation) Query(statement string, args ...interface{}) *Query {
+	return i.query(statement, args, nil)
+}
+
+// query returns a query for a statement; if the statement writes to tables, its results are not cached
+// and the cached results for the tables are invalidated when the query finishes without an error.
+func (i *Invocation) query(statement string, args []interface{}, tables []string) *Query {
+	if isReadOnlyStatement(statement) {
+		i.routeRead()
+	}
 	q := &Query{
 		Invocation: i,
 		Args:       args,
+		tables:     tables,
+	}
+	if len(tables) == 0 && i.cacheable() {
+		// the query is started only if the results are not cached.
+		q.Statement = statement
+		q.deferStart = true
+		return q
 	}
 	q.Statement, q.Err = i.start(statement)
 	return q
@@ -81,6 +123,7 @@ func (i *Invocation) Get(object DatabaseMapped, ids ...interface{}) (found bool,
 		return
 	}
 	i.maybeSetLabel(label)
+	i.addCacheTable(TableName(object))
 	return i.Query(queryBody, ids...).Out(object)
 }
 
@@ -88,6 +131,7 @@ func (i *Invocation) Get(object DatabaseMapped, ids ...interface{}) (found bool,
 func (i *Invocation) All(collection interface{}) (err error) {
 	label, queryBody := i.generateGetAll(collection)
 	i.maybeSetLabel(label)
+	i.addCacheTable(TableNameByType(ReflectSliceType(collection)))
 	return i.Query(queryBody).OutMany(collection)
 }
 
@@ -100,6 +144,7 @@ func (i *Invocation) Create(object DatabaseMapped) (err error) {
 
 	label, queryBody, insertCols, autos = i.generateCreate(object)
 	i.maybeSetLabel(label)
+	defer i.invalidateCacheTablesOnSuccess(&err, TableName(object))
 
 	queryBody, err = i.start(queryBody)
 	if err != nil {
@@ -137,6 +182,7 @@ func (i *Invocation) CreateIfNotExists(object DatabaseMapped) (err error) {
 
 	label, queryBody, insertCols = i.generateCreateIfNotExists(object)
 	i.maybeSetLabel(label)
+	defer i.invalidateCacheTablesOnSuccess(&err, TableName(object))
 
 	queryBody, err = i.start(queryBody)
 	if err != nil {
@@ -175,6 +221,7 @@ func (i *Invocation) insertOrUpsertMany(objects interface{}, overwrite bool) (er
 		// If there is nothing to create, then we're done here
 		return
 	}
+	defer i.invalidateCacheTablesOnSuccess(&err, TableNameByType(ReflectSliceType(objects)))
 
 	queryBody, err = i.start(queryBody)
 	if err != nil {
@@ -205,6 +252,7 @@ func (i *Invocation) Update(object DatabaseMapped) (updated bool, err error) {
 
 	label, queryBody, pks, updateCols = i.generateUpdate(object)
 	i.maybeSetLabel(label)
+	defer i.invalidateCacheTablesOnSuccess(&err, TableName(object))
 
 	queryBody, err = i.start(queryBody)
 	if err != nil {
@@ -244,6 +292,7 @@ func (i *Invocation) Upsert(object DatabaseMapped) (err error) {
 
 	i.Label, queryBody, autos, upsertCols = i.generateUpsert(object)
 	i.maybeSetLabel(label)
+	defer i.invalidateCacheTablesOnSuccess(&err, TableName(object))
 
 	queryBody, err = i.start(queryBody)
 	if err != nil {
@@ -279,6 +328,7 @@ func (i *Invocation) Exists(object DatabaseMapped) (exists bool, err error) {
 		return
 	}
 	i.maybeSetLabel(label)
+	i.routeRead()
 	queryBody, err = i.start(queryBody)
 	if err != nil {
 		return
@@ -307,6 +357,7 @@ func (i *Invocation) Delete(object DatabaseMapped) (deleted bool, err error) {
 	}
 
 	i.maybeSetLabel(label)
+	defer i.invalidateCacheTablesOnSuccess(&err, TableName(object))
 	queryBody, err = i.start(queryBody)
 	if err != nil {
 		return
@@ -773,6 +824,12 @@ func (i *Invocation) start(statement string) (string, error) {
 		return "", ex.New(ErrConnectionClosed)
 	}
 	i.StartTime = time.Now()
+	if i.Replicas != nil && i.Node == "" {
+		i.Node = NodePrimary
+	}
+	if !i.readOnly {
+		markWritten(i.Context)
+	}
 	if i.StatementInterceptor != nil {
 		var err error
 		statement, err = i.StatementInterceptor(i.Context, i.Label, statement)
@@ -808,6 +865,7 @@ func (i *Invocation) finish(statement string, r interface{}, res sql.Result, err
 		qe.Database = i.Config.DatabaseOrDefault()
 		qe.Label = i.Label
 		qe.Engine = i.Config.EngineOrDefault()
+		qe.Node = i.Node
 		qe.Err = err
 		i.Log.TriggerContext(i.Context, qe)
 	}
@@ -62,6 +62,36 @@ func OptInvocationDB(db DB) InvocationOption {
 	}
 }
 
+// OptForcePrimary is an invocation option that routes reads to the primary rather than to read replicas.
+func OptForcePrimary() InvocationOption {
+	return func(i *Invocation) {
+		i.ForcePrimary = true
+	}
+}
+
+// OptCache is an invocation option that caches `Get`, `All`, `Query.Out` and `Query.OutMany` results
+// for a given ttl, keyed by the label, statement and arguments. It has no effect if the connection
+// does not have a query cache (see `OptQueryCache`), or within a transaction. Reads that may be cached are
+// sent to the primary rather than to read replicas, so that rows from a lagging replica are not cached.
+//
+// Cached results are invalidated when the given tables (along with the table of the object for `Get` and `All`)
+// are written to through a connection with the same query cache with `Create`, `CreateIfNotExists`, `CreateMany`,
+// `Update`, `Upsert`, `Delete`, the copy helpers (e.g. `CopyMany`), or a write builder (see `WriteBuilder`) executed
+// with `ExecBuilder` or `QueryBuilder`; other statements (e.g. `Exec`) do not invalidate cached results.
+// Writes within a transaction begun with `InTx` invalidate the tables again after the transaction commits;
+// writes within other transactions only invalidate the tables when the write succeeds.
+func OptCache(ttl time.Duration, tables ...string) InvocationOption {
+	return func(i *Invocation) {
+		i.CacheTTL = ttl
+		i.CacheTables = append(i.CacheTables, tables...)
+	}
+}
+
+// OptInvocationQueryCache sets the invocation query cache.
+func OptInvocationQueryCache(queryCache QueryCache) InvocationOption {
+	return func(i *Invocation) { i.QueryCache = queryCache }
+}
+
 // invocation specific options
 
 // OptInvocationStatementInterceptor sets the invocation statement interceptor.
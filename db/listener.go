@@ -0,0 +1,304 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package db
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+
+	"github.com/blend/go-sdk/async"
+	"github.com/blend/go-sdk/ex"
+)
+
+// Listener defaults.
+const (
+	DefaultListenerMinBackoff = 100 * time.Millisecond
+	DefaultListenerMaxBackoff = 30 * time.Second
+	DefaultListenerBufferSize = 64
+)
+
+// errListenerResubscribe is returned from a listener session interrupted to change its channels.
+var errListenerResubscribe = errors.New("db: listener resubscribing")
+
+// Notification is an asynchronous notification sent with NOTIFY.
+type Notification struct {
+	// PID is the process id of the server backend that sent the notification.
+	PID uint32
+	// Channel is the channel the notification was sent on.
+	Channel string
+	// Payload is the notification payload.
+	Payload string
+}
+
+// NotificationHandler handles notifications for a listener.
+type NotificationHandler func(context.Context, Notification)
+
+// ListenerOption mutates a listener.
+type ListenerOption func(*Listener)
+
+// OptListenerChannels sets the channels the listener subscribes to.
+func OptListenerChannels(channels ...string) ListenerOption {
+	return func(l *Listener) { l.Channels = channels }
+}
+
+// OptListenerHandler sets a handler that is called for each notification, instead of delivering notifications on the channel.
+func OptListenerHandler(handler NotificationHandler) ListenerOption {
+	return func(l *Listener) { l.Handler = handler }
+}
+
+// OptListenerNotifications sets the channel notifications are delivered on.
+func OptListenerNotifications(notifications chan Notification) ListenerOption {
+	return func(l *Listener) { l.Notifications = notifications }
+}
+
+// OptListenerBackoff sets the minimum and maximum delay between reconnect attempts.
+func OptListenerBackoff(minBackoff, maxBackoff time.Duration) ListenerOption {
+	return func(l *Listener) {
+		l.MinBackoff = minBackoff
+		l.MaxBackoff = maxBackoff
+	}
+}
+
+// OptListenerConnect sets the function that opens the listener connection.
+func OptListenerConnect(connect func(context.Context) (*pgx.Conn, error)) ListenerOption {
+	return func(l *Listener) { l.Connect = connect }
+}
+
+// Listen returns a new listener for a given set of channels.
+func (dbc *Connection) Listen(channels ...string) *Listener {
+	return NewListener(dbc, OptListenerChannels(channels...))
+}
+
+// NewListener returns a new LISTEN/NOTIFY listener for a connection.
+//
+// The listener opens its own connection with the connection config, rather than using a connection from the pool.
+//
+//	listener := db.NewListener(conn, db.OptListenerChannels("cache_invalidation"), db.OptListenerHandler(func(_ context.Context, n db.Notification) {
+//		localCache.Remove(n.Payload)
+//	}))
+//	go listener.Start()
+//	<-listener.NotifyStarted()
+func NewListener(dbc *Connection, options ...ListenerOption) *Listener {
+	l := &Listener{
+		Latch:      async.NewLatch(),
+		Connection: dbc,
+		MinBackoff: DefaultListenerMinBackoff,
+		MaxBackoff: DefaultListenerMaxBackoff,
+	}
+	l.Connect = l.connect
+	for _, option := range options {
+		option(l)
+	}
+	if l.Notifications == nil {
+		l.Notifications = make(chan Notification, DefaultListenerBufferSize)
+	}
+	return l
+}
+
+// Listener holds a dedicated connection that subscribes to notification channels with LISTEN.
+//
+// Notifications are delivered to the handler if one is set, or otherwise on the `Notifications` channel.
+// If the connection fails, the listener reconnects and subscribes to its channels again with exponential backoff;
+// notifications sent while the listener is disconnected are not delivered.
+//
+// Connects, disconnects and notifications are triggered as `db.listener` events on the connection logger.
+type Listener struct {
+	*async.Latch
+
+	Connection    *Connection
+	Channels      []string
+	Handler       NotificationHandler
+	Notifications chan Notification
+	MinBackoff    time.Duration
+	MaxBackoff    time.Duration
+	Connect       func(context.Context) (*pgx.Conn, error)
+
+	mu        sync.Mutex
+	cancel    context.CancelFunc
+	interrupt context.CancelFunc
+}
+
+// Start starts the listener.
+//
+// This call will block until the listener is stopped.
+func (l *Listener) Start() error {
+	if !l.CanStart() {
+		return ex.New(async.ErrCannotStart)
+	}
+	l.Starting()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	l.mu.Lock()
+	l.cancel = cancel
+	l.mu.Unlock()
+	defer func() {
+		cancel()
+		l.Stopped()
+	}()
+	l.Started()
+
+	backoff := l.MinBackoff
+	for {
+		connected, err := l.session(ctx)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if errors.Is(err, errListenerResubscribe) {
+			continue
+		}
+		l.trigger(ctx, ListenerEvent{Phase: ListenerEventDisconnected, Err: err})
+		if connected {
+			backoff = l.MinBackoff
+		}
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case <-timer.C:
+		}
+		if backoff *= 2; backoff > l.MaxBackoff {
+			backoff = l.MaxBackoff
+		}
+	}
+}
+
+// Stop stops the listener and closes its connection.
+func (l *Listener) Stop() error {
+	if !l.CanStop() {
+		return ex.New(async.ErrCannotStop)
+	}
+	l.Stopping()
+	l.mu.Lock()
+	if l.cancel != nil {
+		l.cancel()
+	}
+	l.mu.Unlock()
+	<-l.NotifyStopped()
+	l.Latch.Reset()
+	return nil
+}
+
+// Subscribe adds channels to the listener.
+//
+// If the listener is running, it reconnects to subscribe to the new channels.
+func (l *Listener) Subscribe(channels ...string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, channel := range channels {
+		if !l.hasChannel(channel) {
+			l.Channels = append(l.Channels, channel)
+		}
+	}
+	l.resubscribe()
+}
+
+// Unsubscribe removes channels from the listener.
+//
+// If the listener is running, it reconnects to subscribe to the remaining channels.
+func (l *Listener) Unsubscribe(channels ...string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	remove := make(map[string]bool, len(channels))
+	for _, channel := range channels {
+		remove[channel] = true
+	}
+	var remaining []string
+	for _, channel := range l.Channels {
+		if !remove[channel] {
+			remaining = append(remaining, channel)
+		}
+	}
+	l.Channels = remaining
+	l.resubscribe()
+}
+
+// session connects, subscribes to the channels, and delivers notifications until the connection fails or is interrupted.
+func (l *Listener) session(ctx context.Context) (connected bool, err error) {
+	sessionCtx, interrupt := context.WithCancel(ctx)
+	defer interrupt()
+
+	l.mu.Lock()
+	l.interrupt = interrupt
+	channels := append([]string(nil), l.Channels...)
+	l.mu.Unlock()
+
+	conn, err := l.Connect(sessionCtx)
+	if err != nil {
+		return false, l.sessionErr(ctx, sessionCtx, err)
+	}
+	defer func() { _ = conn.Close(context.Background()) }()
+
+	for _, channel := range channels {
+		if _, err = conn.Exec(sessionCtx, "LISTEN "+pgx.Identifier{channel}.Sanitize()); err != nil {
+			return false, l.sessionErr(ctx, sessionCtx, err)
+		}
+	}
+	l.trigger(ctx, ListenerEvent{Phase: ListenerEventConnected, Channels: channels})
+
+	for {
+		notification, err := conn.WaitForNotification(sessionCtx)
+		if err != nil {
+			return true, l.sessionErr(ctx, sessionCtx, err)
+		}
+		l.deliver(ctx, Notification{PID: notification.PID, Channel: notification.Channel, Payload: notification.Payload})
+	}
+}
+
+func (l *Listener) sessionErr(ctx, sessionCtx context.Context, err error) error {
+	if ctx.Err() == nil && sessionCtx.Err() != nil {
+		return errListenerResubscribe
+	}
+	return Error(err)
+}
+
+func (l *Listener) deliver(ctx context.Context, notification Notification) {
+	l.trigger(ctx, ListenerEvent{Phase: ListenerEventNotification, Channel: notification.Channel, Payload: notification.Payload})
+	if l.Handler != nil {
+		l.Handler(ctx, notification)
+		return
+	}
+	select {
+	case l.Notifications <- notification:
+	case <-ctx.Done():
+	}
+}
+
+func (l *Listener) trigger(ctx context.Context, e ListenerEvent) {
+	if l.Connection == nil || l.Connection.Log == nil {
+		return
+	}
+	e.Database = l.Connection.Config.DatabaseOrDefault()
+	l.Connection.Log.TriggerContext(ctx, e)
+}
+
+func (l *Listener) connect(ctx context.Context) (*pgx.Conn, error) {
+	return pgx.Connect(ctx, l.Connection.Config.CreateDSN())
+}
+
+func (l *Listener) hasChannel(channel string) bool {
+	for _, existing := range l.Channels {
+		if existing == channel {
+			return true
+		}
+	}
+	return false
+}
+
+// resubscribe interrupts the current session, if any, so the listener reconnects with the current channels.
+//
+// It must be called with the mutex held.
+func (l *Listener) resubscribe() {
+	if l.interrupt != nil {
+		l.interrupt()
+	}
+}
@@ -0,0 +1,93 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package db
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/blend/go-sdk/ansi"
+	"github.com/blend/go-sdk/logger"
+)
+
+// Logger flags
+const (
+	ListenerFlag = "db.listener"
+)
+
+// Listener event phases.
+const (
+	ListenerEventConnected    = "connected"
+	ListenerEventDisconnected = "disconnected"
+	ListenerEventNotification = "notification"
+)
+
+// these are compile time assertions
+var (
+	_ logger.Event        = (*ListenerEvent)(nil)
+	_ logger.TextWritable = (*ListenerEvent)(nil)
+	_ logger.JSONWritable = (*ListenerEvent)(nil)
+)
+
+// NewListenerEventListener returns a new listener for listener events.
+func NewListenerEventListener(listener func(context.Context, ListenerEvent)) logger.Listener {
+	return func(ctx context.Context, e logger.Event) {
+		if typed, isTyped := e.(ListenerEvent); isTyped {
+			listener(ctx, typed)
+		}
+	}
+}
+
+// ListenerEvent is an event for a LISTEN/NOTIFY listener connecting, disconnecting, or receiving a notification.
+type ListenerEvent struct {
+	Phase    string
+	Database string
+	Channels []string
+	Channel  string
+	Payload  string
+	Err      error
+}
+
+// GetFlag implements Event.
+func (e ListenerEvent) GetFlag() string { return ListenerFlag }
+
+// WriteText writes the event text to the output.
+func (e ListenerEvent) WriteText(tf logger.TextFormatter, wr io.Writer) {
+	fmt.Fprintf(wr, "[%s]", tf.Colorize(e.Database, ansi.ColorLightWhite))
+	fmt.Fprint(wr, logger.Space)
+	fmt.Fprint(wr, e.Phase)
+	if e.Channel != "" {
+		fmt.Fprint(wr, logger.Space)
+		fmt.Fprint(wr, tf.Colorize(e.Channel, ansi.ColorLightWhite))
+	} else if len(e.Channels) > 0 {
+		fmt.Fprint(wr, logger.Space)
+		fmt.Fprint(wr, tf.Colorize(strings.Join(e.Channels, ","), ansi.ColorLightWhite))
+	}
+	if e.Payload != "" {
+		fmt.Fprint(wr, logger.Space)
+		fmt.Fprint(wr, e.Payload)
+	}
+	if e.Err != nil {
+		fmt.Fprint(wr, logger.Space)
+		fmt.Fprint(wr, tf.Colorize(e.Err.Error(), ansi.ColorRed))
+	}
+}
+
+// Decompose implements JSONWritable.
+func (e ListenerEvent) Decompose() map[string]interface{} {
+	return map[string]interface{}{
+		"phase":    e.Phase,
+		"database": e.Database,
+		"channels": e.Channels,
+		"channel":  e.Channel,
+		"payload":  e.Payload,
+		"err":      e.Err,
+	}
+}
@@ -0,0 +1,188 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package db
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+
+	"github.com/blend/go-sdk/assert"
+	"github.com/blend/go-sdk/logger"
+	"github.com/blend/go-sdk/uuid"
+)
+
+func listenerTestChannel() string {
+	return "listener_test_" + uuid.V4().String()
+}
+
+func startTestListener(t *testing.T, listener *Listener) {
+	t.Helper()
+	connected := make(chan struct{}, 1)
+	connect := listener.Connect
+	listener.Connect = func(ctx context.Context) (*pgx.Conn, error) {
+		conn, err := connect(ctx)
+		if err == nil {
+			select {
+			case connected <- struct{}{}:
+			default:
+			}
+		}
+		return conn, err
+	}
+	go func() { _ = listener.Start() }()
+	t.Cleanup(func() { _ = listener.Stop() })
+	select {
+	case <-connected:
+	case <-time.After(5 * time.Second):
+		t.Fatal("listener did not connect")
+	}
+	// give the listener a moment to LISTEN on its channels.
+	time.Sleep(100 * time.Millisecond)
+}
+
+func receiveNotification(t *testing.T, notifications <-chan Notification) Notification {
+	t.Helper()
+	select {
+	case notification := <-notifications:
+		return notification
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for notification")
+	}
+	return Notification{}
+}
+
+func Test_Listener_notifications(t *testing.T) {
+	its := assert.New(t)
+
+	channel := listenerTestChannel()
+	listener := defaultDB().Listen(channel)
+	startTestListener(t, listener)
+
+	its.Nil(defaultDB().Invoke().Notify(channel, "this is only a test"))
+	notification := receiveNotification(t, listener.Notifications)
+	its.Equal(channel, notification.Channel)
+	its.Equal("this is only a test", notification.Payload)
+	its.NotZero(notification.PID)
+}
+
+func Test_Listener_handler(t *testing.T) {
+	its := assert.New(t)
+
+	channel := listenerTestChannel()
+	received := make(chan Notification, 1)
+	listener := NewListener(defaultDB(),
+		OptListenerChannels(channel),
+		OptListenerHandler(func(_ context.Context, n Notification) { received <- n }),
+	)
+	startTestListener(t, listener)
+
+	its.Nil(defaultDB().Invoke().Notify(channel, "handled"))
+	its.Equal("handled", receiveNotification(t, received).Payload)
+	its.Empty(listener.Notifications)
+}
+
+func Test_Listener_reconnect(t *testing.T) {
+	its := assert.New(t)
+
+	channel := listenerTestChannel()
+	listener := NewListener(defaultDB(), OptListenerChannels(channel), OptListenerBackoff(time.Millisecond, 10*time.Millisecond))
+	startTestListener(t, listener)
+
+	_, err := defaultDB().Exec("SELECT pg_terminate_backend(pid) FROM pg_stat_activity WHERE query LIKE 'LISTEN%' AND pid <> pg_backend_pid()")
+	its.Nil(err)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		its.Nil(defaultDB().Invoke().Notify(channel, "reconnected"))
+		select {
+		case notification := <-listener.Notifications:
+			its.Equal("reconnected", notification.Payload)
+			return
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+	t.Fatal("listener did not reconnect")
+}
+
+func Test_Listener_subscribe(t *testing.T) {
+	its := assert.New(t)
+
+	first, second := listenerTestChannel(), listenerTestChannel()
+	listener := defaultDB().Listen(first)
+	startTestListener(t, listener)
+
+	listener.Subscribe(second)
+	listener.Unsubscribe(first)
+	its.Equal([]string{second}, listener.Channels)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		its.Nil(defaultDB().Invoke().Notify(second, "subscribed"))
+		select {
+		case notification := <-listener.Notifications:
+			its.Equal(second, notification.Channel)
+			return
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+	t.Fatal("listener did not subscribe")
+}
+
+func Test_Listener_Start_cannotStart(t *testing.T) {
+	its := assert.New(t)
+
+	listener := defaultDB().Listen(listenerTestChannel())
+	startTestListener(t, listener)
+	its.NotNil(listener.Start())
+}
+
+func Test_Invocation_Notify_inTx(t *testing.T) {
+	its := assert.New(t)
+
+	channel := listenerTestChannel()
+	listener := defaultDB().Listen(channel)
+	startTestListener(t, listener)
+
+	err := defaultDB().InTx(context.Background(), func(i *Invocation) error {
+		if err := i.Notify(channel, "committed"); err != nil {
+			return err
+		}
+		select {
+		case <-listener.Notifications:
+			return fmt.Errorf("notification delivered before commit")
+		case <-time.After(100 * time.Millisecond):
+		}
+		return nil
+	})
+	its.Nil(err)
+	its.Equal("committed", receiveNotification(t, listener.Notifications).Payload)
+}
+
+func Test_ListenerEvent(t *testing.T) {
+	its := assert.New(t)
+
+	e := ListenerEvent{Phase: ListenerEventNotification, Database: "postgres", Channel: "invalidate", Payload: "key"}
+	its.Equal(ListenerFlag, e.GetFlag())
+
+	buf := new(bytes.Buffer)
+	e.WriteText(logger.TextOutputFormatter{NoColor: true}, buf)
+	its.Equal("[postgres] notification invalidate key", buf.String())
+
+	buf.Reset()
+	ListenerEvent{Phase: ListenerEventConnected, Database: "postgres", Channels: []string{"one", "two"}}.WriteText(logger.TextOutputFormatter{NoColor: true}, buf)
+	its.Equal("[postgres] connected one,two", buf.String())
+
+	decomposed := e.Decompose()
+	its.Equal("invalidate", decomposed["channel"])
+	its.Equal("key", decomposed["payload"])
+}
@@ -10,6 +10,7 @@ package migration
 import (
 	"context"
 	"database/sql"
+	"fmt"
 
 	"github.com/blend/go-sdk/db"
 )
@@ -32,35 +33,98 @@ func NoOp(_ context.Context, _ *db.Connection, _ *sql.Tx) error { return nil }
 
 // Statements returns a body func that executes the statments serially.
 func Statements(statements ...string) Action {
-	return ActionFunc(func(ctx context.Context, c *db.Connection, tx *sql.Tx) (err error) {
-		for _, statement := range statements {
-			err = db.IgnoreExecResult(c.Invoke(db.OptContext(ctx), db.OptTx(tx)).Exec(statement))
-			if err != nil {
-				return
-			}
+	return StatementsAction(statements)
+}
+
+// StatementsAction is an action that executes a list of statements serially.
+type StatementsAction []string
+
+// Action implements Action.
+func (sa StatementsAction) Action(ctx context.Context, c *db.Connection, tx *sql.Tx) (err error) {
+	for _, statement := range sa {
+		err = db.IgnoreExecResult(c.Invoke(db.OptContext(ctx), db.OptTx(tx)).Exec(statement))
+		if err != nil {
+			return
 		}
-		return
-	})
+	}
+	return
+}
+
+// Checksum implements Checksummer.
+func (sa StatementsAction) Checksum() string {
+	return checksum(sa...)
+}
+
+// DryRun implements DryRunner.
+func (sa StatementsAction) DryRun(ctx context.Context, _ *db.Connection, _ *sql.Tx) error {
+	for _, statement := range sa {
+		writeDryRun(ctx, statement)
+	}
+	return nil
 }
 
 // Exec creates an Action that will run a statement with a given set of arguments.
 // It can be used in lieu of Statements, when parameterization is needed
 func Exec(statement string, args ...interface{}) Action {
-	return ActionFunc(func(ctx context.Context, c *db.Connection, tx *sql.Tx) (err error) {
-		err = db.IgnoreExecResult(c.Invoke(db.OptContext(ctx), db.OptTx(tx)).Exec(statement, args...))
-		return
-	})
+	return &ExecAction{Statement: statement, Args: args}
+}
+
+// ExecAction is an action that runs a statement with a given set of arguments.
+type ExecAction struct {
+	Statement string
+	Args      []interface{}
+}
+
+// Action implements Action.
+func (ea *ExecAction) Action(ctx context.Context, c *db.Connection, tx *sql.Tx) error {
+	return db.IgnoreExecResult(c.Invoke(db.OptContext(ctx), db.OptTx(tx)).Exec(ea.Statement, ea.Args...))
+}
+
+// Checksum implements Checksummer.
+func (ea *ExecAction) Checksum() string {
+	return checksum(ea.Statement, fmt.Sprintf("%v", ea.Args))
+}
+
+// DryRun implements DryRunner.
+func (ea *ExecAction) DryRun(ctx context.Context, _ *db.Connection, _ *sql.Tx) error {
+	if len(ea.Args) > 0 {
+		writeDryRun(ctx, fmt.Sprintf("%s -- args: %v", ea.Statement, ea.Args))
+		return nil
+	}
+	writeDryRun(ctx, ea.Statement)
+	return nil
 }
 
 // Actions creates an Action with a single body func that executes all the variadic argument actions serially
 func Actions(actions ...Action) Action {
-	return ActionFunc(func(ctx context.Context, c *db.Connection, tx *sql.Tx) (err error) {
-		for _, action := range actions {
-			err = action.Action(ctx, c, tx)
-			if err != nil {
-				return err
-			}
+	return SerialActions(actions)
+}
+
+// SerialActions is an action that executes a list of actions serially.
+type SerialActions []Action
+
+// Action implements Action.
+func (sa SerialActions) Action(ctx context.Context, c *db.Connection, tx *sql.Tx) (err error) {
+	for _, action := range sa {
+		err = action.Action(ctx, c, tx)
+		if err != nil {
+			return err
+		}
+	}
+	return
+}
+
+// Checksum implements Checksummer.
+func (sa SerialActions) Checksum() string {
+	return checksumActions(sa)
+}
+
+// DryRun implements DryRunner.
+func (sa SerialActions) DryRun(ctx context.Context, c *db.Connection, tx *sql.Tx) (err error) {
+	for _, action := range sa {
+		if err = DryRun(ctx, c, tx, action); err != nil {
+			return
 		}
-		return
-	})
+	}
+	return
 }
@@ -0,0 +1,190 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package migration
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"strings"
+
+	"github.com/blend/go-sdk/db"
+)
+
+// Catalog is the live schema of a database, loaded from `information_schema` and `pg_catalog`.
+type Catalog struct {
+	Schema string
+	Tables map[string]*CatalogTable
+}
+
+// TableNames returns the sorted table names in the catalog.
+func (c Catalog) TableNames() (output []string) {
+	for name := range c.Tables {
+		output = append(output, name)
+	}
+	sort.Strings(output)
+	return
+}
+
+// CatalogTable is a table in a catalog.
+type CatalogTable struct {
+	Name        string
+	Columns     []CatalogColumn
+	Indexes     []CatalogIndex
+	Constraints []CatalogConstraint
+}
+
+// Column returns a column by name.
+func (ct CatalogTable) Column(name string) (column CatalogColumn, ok bool) {
+	for _, column = range ct.Columns {
+		if column.Name == name {
+			ok = true
+			return
+		}
+	}
+	column = CatalogColumn{}
+	return
+}
+
+// CatalogColumn is a column of a table in a catalog.
+type CatalogColumn struct {
+	Name string
+	// DataType is the column type, e.g. `text` or `timestamp without time zone`.
+	//
+	// Array and user defined types use the underlying type name, e.g. `_text` or `citext`.
+	DataType   string
+	IsNullable bool
+	Default    string
+	Position   int
+}
+
+// CatalogIndex is an index of a table in a catalog.
+type CatalogIndex struct {
+	Name       string
+	Definition string
+	IsUnique   bool
+}
+
+// CatalogConstraint is a constraint of a table in a catalog.
+type CatalogConstraint struct {
+	Name string
+	// Type is the constraint type, one of `p` (primary key), `u` (unique), `f` (foreign key), `c` (check) or `x` (exclusion).
+	Type    string
+	Columns []string
+}
+
+// LoadCatalog loads the catalog of the default schema of the given connection.
+func LoadCatalog(ctx context.Context, c *db.Connection, tx *sql.Tx) (*Catalog, error) {
+	return LoadCatalogInSchema(ctx, c, tx, c.Config.SchemaOrDefault())
+}
+
+// LoadCatalogInSchema loads the catalog of a specific schema on the given connection.
+func LoadCatalogInSchema(ctx context.Context, c *db.Connection, tx *sql.Tx, schemaName string) (*Catalog, error) {
+	catalog := Catalog{
+		Schema: schemaName,
+		Tables: make(map[string]*CatalogTable),
+	}
+	invoke := func(label string) *db.Invocation {
+		return c.Invoke(db.OptContext(ctx), db.OptTx(tx), db.OptForcePrimary(), db.OptLabel(label))
+	}
+
+	err := invoke("migration_catalog_tables").Query(
+		`SELECT table_name FROM information_schema.tables WHERE table_schema = $1 AND table_type = 'BASE TABLE'`,
+		schemaName,
+	).Each(func(r db.Rows) error {
+		var name string
+		if err := r.Scan(&name); err != nil {
+			return err
+		}
+		catalog.Tables[name] = &CatalogTable{Name: name}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = invoke("migration_catalog_columns").Query(
+		`SELECT table_name, column_name, data_type, udt_name, is_nullable, coalesce(column_default, ''), ordinal_position
+		FROM information_schema.columns WHERE table_schema = $1 ORDER BY table_name, ordinal_position`,
+		schemaName,
+	).Each(func(r db.Rows) error {
+		var tableName, dataType, udtName, isNullable string
+		var column CatalogColumn
+		if err := r.Scan(&tableName, &column.Name, &dataType, &udtName, &isNullable, &column.Default, &column.Position); err != nil {
+			return err
+		}
+		table, ok := catalog.Tables[tableName]
+		if !ok { // views
+			return nil
+		}
+		column.DataType = dataType
+		if dataType == "ARRAY" || dataType == "USER-DEFINED" {
+			column.DataType = udtName
+		}
+		column.IsNullable = isNullable == "YES"
+		table.Columns = append(table.Columns, column)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = invoke("migration_catalog_indexes").Query(
+		`SELECT tablename, indexname, indexdef FROM pg_catalog.pg_indexes WHERE schemaname = $1 ORDER BY tablename, indexname`,
+		schemaName,
+	).Each(func(r db.Rows) error {
+		var tableName string
+		var index CatalogIndex
+		if err := r.Scan(&tableName, &index.Name, &index.Definition); err != nil {
+			return err
+		}
+		table, ok := catalog.Tables[tableName]
+		if !ok {
+			return nil
+		}
+		index.IsUnique = strings.HasPrefix(index.Definition, "CREATE UNIQUE INDEX")
+		table.Indexes = append(table.Indexes, index)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = invoke("migration_catalog_constraints").Query(
+		`SELECT rel.relname, con.conname, con.contype::text,
+			coalesce(array_to_string(array(
+				SELECT att.attname FROM unnest(con.conkey) WITH ORDINALITY AS k(attnum, ord)
+				JOIN pg_catalog.pg_attribute att ON att.attrelid = con.conrelid AND att.attnum = k.attnum
+				ORDER BY k.ord
+			), ','), '')
+		FROM pg_catalog.pg_constraint con
+		JOIN pg_catalog.pg_class rel ON rel.oid = con.conrelid
+		JOIN pg_catalog.pg_namespace nsp ON nsp.oid = rel.relnamespace
+		WHERE nsp.nspname = $1 ORDER BY rel.relname, con.conname`,
+		schemaName,
+	).Each(func(r db.Rows) error {
+		var tableName, columns string
+		var constraint CatalogConstraint
+		if err := r.Scan(&tableName, &constraint.Name, &constraint.Type, &columns); err != nil {
+			return err
+		}
+		table, ok := catalog.Tables[tableName]
+		if !ok {
+			return nil
+		}
+		if columns != "" {
+			constraint.Columns = strings.Split(columns, ",")
+		}
+		table.Constraints = append(table.Constraints, constraint)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &catalog, nil
+}
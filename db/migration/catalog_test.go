@@ -0,0 +1,75 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package migration
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/blend/go-sdk/assert"
+	"github.com/blend/go-sdk/db"
+	"github.com/blend/go-sdk/logger"
+)
+
+func TestLoadCatalogInSchema(t *testing.T) {
+	its := assert.New(t)
+
+	testSchemaName := buildTestSchemaName()
+	its.Nil(db.IgnoreExecResult(defaultDB().Exec(fmt.Sprintf("CREATE SCHEMA %s", testSchemaName))))
+	defer func() {
+		its.Nil(db.IgnoreExecResult(defaultDB().Exec(fmt.Sprintf("DROP SCHEMA IF EXISTS %s CASCADE", testSchemaName))))
+	}()
+	its.Nil(db.IgnoreExecResult(defaultDB().Exec(fmt.Sprintf(`CREATE TABLE %s.drift_test (
+		id serial not null primary key,
+		uuid uuid not null unique,
+		name varchar(64) not null,
+		active text,
+		legacy text
+	)`, testSchemaName))))
+	its.Nil(db.IgnoreExecResult(defaultDB().Exec(fmt.Sprintf("CREATE INDEX idx_drift_test_name ON %s.drift_test(name)", testSchemaName))))
+
+	catalog, err := LoadCatalogInSchema(context.Background(), defaultDB(), nil, testSchemaName)
+	its.Nil(err)
+	its.Equal([]string{"drift_test"}, catalog.TableNames())
+
+	table := catalog.Tables["drift_test"]
+	its.Len(table.Columns, 5)
+	column, ok := table.Column("name")
+	its.True(ok)
+	its.Equal("character varying", column.DataType)
+	its.False(column.IsNullable)
+	column, ok = table.Column("id")
+	its.True(ok)
+	its.Equal("integer", column.DataType)
+	its.NotEmpty(column.Default)
+
+	its.Len(table.Indexes, 3)
+	var constraintTypes []string
+	for _, constraint := range table.Constraints {
+		constraintTypes = append(constraintTypes, constraint.Type)
+	}
+	its.Contains(strings.Join(constraintTypes, ","), "p")
+	its.Contains(strings.Join(constraintTypes, ","), "u")
+
+	drifts := catalog.Diff(driftTestObj{})
+	its.NotEmpty(drifts.Filter(DriftMissingColumn))
+	its.Len(drifts.Filter(DriftTypeMismatch), 1)
+	its.Len(drifts.Filter(DriftExtraColumn), 1)
+
+	// reconcile everything but the extra column and check the schema no longer drifts.
+	suite := New(OptLog(logger.None()), OptGroups(NewGroup(OptGroupActions(drifts.Filter(DriftMissingColumn, DriftTypeMismatch).Actions()...))))
+	its.Nil(suite.Apply(context.Background(), defaultDB()))
+
+	catalog, err = LoadCatalogInSchema(context.Background(), defaultDB(), nil, testSchemaName)
+	its.Nil(err)
+	drifts = catalog.Diff(driftTestObj{})
+	its.Len(drifts, 1)
+	its.Equal(DriftExtraColumn, drifts[0].Kind)
+}
@@ -0,0 +1,51 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package migration
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+)
+
+// Checksummer is an action that can describe its contents as a checksum.
+//
+// Checksums are used by history mode to detect groups that have been edited after they were applied.
+// The actions returned by `Statements`, `Exec` and `Actions`, as well as steps, implement this interface;
+// plain `ActionFunc` actions do not, so edits to their bodies cannot be detected.
+type Checksummer interface {
+	Checksum() string
+}
+
+// Checksum returns the checksum for an action.
+//
+// If the action does not implement `Checksummer`, the checksum is computed from its type name.
+func Checksum(action Action) string {
+	if typed, ok := action.(Checksummer); ok {
+		return typed.Checksum()
+	}
+	return checksum(fmt.Sprintf("%T", action))
+}
+
+// checksum returns the hex encoded sha256 of a given set of parts.
+func checksum(parts ...string) string {
+	hash := sha256.New()
+	for _, part := range parts {
+		fmt.Fprintf(hash, "%d:%s;", len(part), part)
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// checksumActions returns the combined checksum of a list of actions.
+func checksumActions(actions []Action) string {
+	parts := make([]string, len(actions))
+	for index, action := range actions {
+		parts[index] = Checksum(action)
+	}
+	return checksum(parts...)
+}
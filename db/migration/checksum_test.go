@@ -0,0 +1,59 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package migration
+
+import (
+	"testing"
+
+	"github.com/blend/go-sdk/assert"
+)
+
+func TestChecksum_Statements(t *testing.T) {
+	its := assert.New(t)
+
+	its.Equal(Checksum(Statements("CREATE TABLE foo (id int)")), Checksum(Statements("CREATE TABLE foo (id int)")))
+	its.NotEqual(Checksum(Statements("CREATE TABLE foo (id int)")), Checksum(Statements("CREATE TABLE foo (id bigint)")))
+	its.NotEqual(Checksum(Statements("a", "b")), Checksum(Statements("ab")))
+	its.Len(Checksum(Statements("a")), 64)
+}
+
+func TestChecksum_Exec(t *testing.T) {
+	its := assert.New(t)
+
+	its.Equal(Checksum(Exec("INSERT INTO foo VALUES ($1)", 1)), Checksum(Exec("INSERT INTO foo VALUES ($1)", 1)))
+	its.NotEqual(Checksum(Exec("INSERT INTO foo VALUES ($1)", 1)), Checksum(Exec("INSERT INTO foo VALUES ($1)", 2)))
+}
+
+func TestChecksum_Composite(t *testing.T) {
+	its := assert.New(t)
+
+	step := NewStep(Always(), Statements("a"))
+	its.Equal(Checksum(Statements("a")), Checksum(step))
+	its.Equal(Checksum(NewStep(TableNotExists("foo"), Statements("a"))), Checksum(step))
+
+	its.Equal(Checksum(Actions(Statements("a"), Statements("b"))), Checksum(Actions(Statements("a"), Statements("b"))))
+	its.NotEqual(Checksum(Actions(Statements("a"), Statements("b"))), Checksum(Actions(Statements("b"), Statements("a"))))
+}
+
+func TestChecksum_ActionFunc(t *testing.T) {
+	its := assert.New(t)
+
+	its.Equal(Checksum(ActionFunc(NoOp)), Checksum(ActionFunc(NoOp)))
+	its.NotEqual(Checksum(ActionFunc(NoOp)), Checksum(Statements()))
+}
+
+func TestGroup_ChecksumOrDefault(t *testing.T) {
+	its := assert.New(t)
+
+	group := NewGroup(OptGroupName("0001_foo"), OptGroupActions(Statements("a")))
+	its.Equal(checksumActions([]Action{Statements("a")}), group.ChecksumOrDefault())
+	its.NotEqual(group.ChecksumOrDefault(), NewGroup(OptGroupActions(Statements("b"))).ChecksumOrDefault())
+
+	group = NewGroup(OptGroupChecksum("v1"), OptGroupActions(Statements("a")))
+	its.Equal("v1", group.ChecksumOrDefault())
+}
@@ -7,10 +7,46 @@ Use of this source code is governed by a MIT license that can be found in the LI
 
 package migration
 
+import "github.com/blend/go-sdk/ex"
+
 // Migration Stats
 const (
 	StatApplied = "applied"
 	StatFailed  = "failed"
 	StatSkipped = "skipped"
 	StatTotal   = "total"
+
+	StatDryRun     = "dry-run"
+	StatRolledBack = "rolled back"
+)
+
+// History defaults.
+const (
+	// DefaultHistoryTable is the default table used to record applied migrations.
+	DefaultHistoryTable = "migration_history"
+)
+
+// Status states.
+const (
+	StatusApplied  = "applied"
+	StatusPending  = "pending"
+	StatusModified = "modified"
+)
+
+// Errors
+const (
+	// ErrHistoryGroupNameUnset is returned when a suite with history enabled has a group without a name.
+	ErrHistoryGroupNameUnset ex.Class = "migration: group name is required when history is enabled"
+	// ErrHistoryChecksumMismatch is returned when an applied group has been changed since it was applied.
+	ErrHistoryChecksumMismatch ex.Class = "migration: applied group checksum mismatch"
+	// ErrHistoryDuplicateGroupName is returned when a suite with history enabled has more than one group with the same name.
+	ErrHistoryDuplicateGroupName ex.Class = "migration: duplicate group name"
+	// ErrHistoryUnset is returned when rolling back a suite that does not have a history.
+	ErrHistoryUnset ex.Class = "migration: history is required to roll back"
+	// ErrRollbackVersionNotFound is returned when rolling back to a version that has not been applied.
+	ErrRollbackVersionNotFound ex.Class = "migration: rollback version has not been applied"
+	// ErrRollbackGroupNotFound is returned when rolling back an applied group that is not in the suite.
+	ErrRollbackGroupNotFound ex.Class = "migration: applied group is not in the suite"
+	// ErrRollbackUnset is returned when rolling back a group that does not have rollback actions.
+	ErrRollbackUnset ex.Class = "migration: group does not have rollback actions"
 )
@@ -9,5 +9,13 @@ Use of this source code is governed by a MIT license that can be found in the LI
 Package migration provides helpers for writing rerunnable database migrations.
 
 These are built around Suites, which are sets of Groups that execute within a transaction, those Groups are composed of Steps, which are a Guard and an Action.
+
+Suites can optionally record applied Groups in a history table with `OptHistory`. In history mode each Group must be named with `OptGroupName`; Groups that have already been applied are skipped, Groups that have been edited since they were applied (detected by checksum) fail the Suite, and an advisory lock prevents more than one process from applying the Suite at a time. `Suite.Status` reports which Groups are pending or applied.
+
+Groups with rollback actions (see `OptGroupRollback`) can be rolled back with `Suite.Rollback`, which requires history mode.
+
+To preview a Suite, `OptDryRun` evaluates guards but writes the statements that would be executed as events rather than executing them, and `OptCheck` runs the whole Suite within a single transaction that is always rolled back.
+
+To detect drift between database mapped types and the live schema, load the schema with `LoadCatalog` and compare it with `Catalog.Diff`; the resulting `Drifts` can be reconciled with the steps returned by `Drifts.Actions`.
 */
 package migration // import "github.com/blend/go-sdk/db/migration"
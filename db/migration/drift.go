@@ -0,0 +1,309 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package migration
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/blend/go-sdk/db"
+	"github.com/blend/go-sdk/uuid"
+)
+
+// Drift kinds.
+const (
+	DriftMissingTable  = "missing_table"
+	DriftMissingColumn = "missing_column"
+	DriftTypeMismatch  = "type_mismatch"
+	DriftExtraColumn   = "extra_column"
+)
+
+// Drift is a difference between a database mapped type and the live schema.
+type Drift struct {
+	// Kind is one of `DriftMissingTable`, `DriftMissingColumn`, `DriftTypeMismatch` or `DriftExtraColumn`.
+	Kind   string
+	Schema string
+	Table  string
+	Column string
+	// Expected is the column type for the field, if it could be inferred.
+	Expected string
+	// Actual is the column type in the live schema.
+	Actual string
+	// Statement is the statement that reconciles the drift; it is empty if the column type could not be inferred.
+	Statement string
+}
+
+// String returns a description of the drift.
+func (d Drift) String() string {
+	name := d.Table
+	if d.Column != "" {
+		name = d.Table + "." + d.Column
+	}
+	switch d.Kind {
+	case DriftMissingColumn:
+		return fmt.Sprintf("%s: %s (expected %s)", d.Kind, name, d.Expected)
+	case DriftTypeMismatch:
+		return fmt.Sprintf("%s: %s (expected %s, actual %s)", d.Kind, name, d.Expected, d.Actual)
+	case DriftExtraColumn:
+		return fmt.Sprintf("%s: %s (%s)", d.Kind, name, d.Actual)
+	default:
+		return fmt.Sprintf("%s: %s", d.Kind, name)
+	}
+}
+
+// Step returns a guarded step that reconciles the drift, or nil if there is no statement for it.
+func (d Drift) Step() *Step {
+	if d.Statement == "" {
+		return nil
+	}
+	switch d.Kind {
+	case DriftMissingTable:
+		return NewStep(TableNotExistsInSchema(d.Schema, d.Table), Statements(d.Statement))
+	case DriftMissingColumn:
+		return NewStep(ColumnNotExistsInSchema(d.Schema, d.Table, d.Column), Statements(d.Statement))
+	default:
+		return NewStep(ColumnExistsInSchema(d.Schema, d.Table, d.Column), Statements(d.Statement))
+	}
+}
+
+// Drifts is a list of drifts.
+type Drifts []Drift
+
+// Filter returns the drifts of the given kinds.
+func (d Drifts) Filter(kinds ...string) (output Drifts) {
+	for _, drift := range d {
+		for _, kind := range kinds {
+			if drift.Kind == kind {
+				output = append(output, drift)
+				break
+			}
+		}
+	}
+	return
+}
+
+// Actions returns the steps that reconcile the drifts, skipping drifts without a statement.
+//
+// Extra columns are reconciled by dropping them, so you may want to filter them out first, e.g.
+//
+//	drifts.Filter(migration.DriftMissingTable, migration.DriftMissingColumn).Actions()
+func (d Drifts) Actions() (output []Action) {
+	for _, drift := range d {
+		if step := drift.Step(); step != nil {
+			output = append(output, step)
+		}
+	}
+	return
+}
+
+// Diff compares the catalog with the columns of database mapped types, returning
+// missing tables, missing columns, columns whose types do not match their fields and
+// columns that are not mapped to a field.
+//
+// Read only columns are not expected to exist in the table. Fields whose column type
+// cannot be inferred are not checked for type mismatches.
+func (c Catalog) Diff(objects ...db.DatabaseMapped) (output Drifts) {
+	for _, object := range objects {
+		tableName := db.TableName(object)
+		allColumns := db.Columns(object)
+		columns := allColumns.NotReadOnly()
+
+		table, ok := c.table(tableName)
+		if !ok {
+			output = append(output, Drift{
+				Kind:      DriftMissingTable,
+				Schema:    c.Schema,
+				Table:     tableName,
+				Statement: createTableStatement(c.qualify(tableName), columns),
+			})
+			continue
+		}
+
+		mapped := make(map[string]bool, allColumns.Len())
+		for _, column := range allColumns.Columns() {
+			mapped[strings.ToLower(column.ColumnName)] = true
+		}
+		for _, column := range columns.Columns() {
+			expected, compatible := columnTypes(column)
+			actual, ok := table.Column(column.ColumnName)
+			if !ok {
+				actual, ok = table.Column(strings.ToLower(column.ColumnName))
+			}
+			if !ok {
+				drift := Drift{Kind: DriftMissingColumn, Schema: c.Schema, Table: table.Name, Column: column.ColumnName, Expected: expected}
+				if expected != "" {
+					drift.Statement = fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", c.qualify(table.Name), column.ColumnName, expected)
+				}
+				output = append(output, drift)
+				continue
+			}
+			if expected != "" && !isCompatibleType(actual.DataType, compatible) {
+				output = append(output, Drift{
+					Kind:      DriftTypeMismatch,
+					Schema:    c.Schema,
+					Table:     table.Name,
+					Column:    actual.Name,
+					Expected:  expected,
+					Actual:    actual.DataType,
+					Statement: fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s TYPE %s USING %s::%s", c.qualify(table.Name), actual.Name, expected, actual.Name, expected),
+				})
+			}
+		}
+		for _, actual := range table.Columns {
+			if !mapped[strings.ToLower(actual.Name)] {
+				output = append(output, Drift{
+					Kind:      DriftExtraColumn,
+					Schema:    c.Schema,
+					Table:     table.Name,
+					Column:    actual.Name,
+					Actual:    actual.DataType,
+					Statement: fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s", c.qualify(table.Name), actual.Name),
+				})
+			}
+		}
+	}
+	return
+}
+
+// table returns a table by name, falling back to the lower case name as postgres folds unquoted identifiers.
+func (c Catalog) table(name string) (*CatalogTable, bool) {
+	if table, ok := c.Tables[name]; ok {
+		return table, true
+	}
+	table, ok := c.Tables[strings.ToLower(name)]
+	return table, ok
+}
+
+// qualify returns a table name qualified with the catalog schema.
+func (c Catalog) qualify(tableName string) string {
+	if c.Schema == "" {
+		return tableName
+	}
+	return c.Schema + "." + tableName
+}
+
+// createTableStatement returns a `CREATE TABLE` statement for a set of columns, or an empty
+// string if the type of any column cannot be inferred.
+func createTableStatement(tableName string, columns *db.ColumnCollection) string {
+	var definitions, primaryKeys []string
+	for _, column := range columns.Columns() {
+		columnType, _ := columnTypes(column)
+		if columnType == "" {
+			return ""
+		}
+		if column.IsAuto {
+			switch columnType {
+			case "bigint":
+				columnType = "bigserial"
+			case "integer":
+				columnType = "serial"
+			}
+		}
+		definition := column.ColumnName + " " + columnType
+		if column.IsPrimaryKey {
+			primaryKeys = append(primaryKeys, column.ColumnName)
+			definition = definition + " NOT NULL"
+		}
+		definitions = append(definitions, definition)
+	}
+	if len(primaryKeys) > 0 {
+		definitions = append(definitions, fmt.Sprintf("PRIMARY KEY (%s)", strings.Join(primaryKeys, ", ")))
+	}
+	return fmt.Sprintf("CREATE TABLE %s (%s)", tableName, strings.Join(definitions, ", "))
+}
+
+func isCompatibleType(actual string, compatible []string) bool {
+	for _, columnType := range compatible {
+		if strings.EqualFold(actual, columnType) {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	integerTypes = []string{"smallint", "integer", "bigint"}
+	floatTypes   = []string{"real", "double precision", "numeric"}
+	stringTypes  = []string{"text", "character varying", "character", "citext"}
+	timeTypes    = []string{"timestamp without time zone", "timestamp with time zone", "date"}
+
+	typeTime        = reflect.TypeOf(time.Time{})
+	typeDuration    = reflect.TypeOf(time.Duration(0))
+	typeUUID        = reflect.TypeOf(uuid.UUID(nil))
+	typeBytes       = reflect.TypeOf([]byte(nil))
+	typeStrings     = reflect.TypeOf([]string(nil))
+	typeNullString  = reflect.TypeOf(sql.NullString{})
+	typeNullBool    = reflect.TypeOf(sql.NullBool{})
+	typeNullInt16   = reflect.TypeOf(sql.NullInt16{})
+	typeNullInt32   = reflect.TypeOf(sql.NullInt32{})
+	typeNullInt64   = reflect.TypeOf(sql.NullInt64{})
+	typeNullFloat64 = reflect.TypeOf(sql.NullFloat64{})
+	typeNullTime    = reflect.TypeOf(sql.NullTime{})
+)
+
+// columnTypes returns the column type for a mapped column, and the column types that are compatible with it.
+//
+// It returns an empty type if the column type cannot be inferred from the field type.
+func columnTypes(column db.Column) (columnType string, compatible []string) {
+	if column.IsJSON {
+		return "jsonb", []string{"jsonb", "json"}
+	}
+	fieldType := column.FieldType
+	for fieldType != nil && fieldType.Kind() == reflect.Ptr {
+		fieldType = fieldType.Elem()
+	}
+	if fieldType == nil {
+		return
+	}
+
+	switch fieldType {
+	case typeTime, typeNullTime:
+		return "timestamp", timeTypes
+	case typeDuration:
+		return "bigint", integerTypes
+	case typeUUID:
+		return "uuid", []string{"uuid"}
+	case typeBytes:
+		return "bytea", []string{"bytea"}
+	case typeStrings:
+		return "text[]", []string{"_text", "_varchar"}
+	case typeNullString:
+		return "text", stringTypes
+	case typeNullBool:
+		return "boolean", []string{"boolean"}
+	case typeNullInt16:
+		return "smallint", integerTypes
+	case typeNullInt32:
+		return "integer", integerTypes
+	case typeNullInt64:
+		return "bigint", integerTypes
+	case typeNullFloat64:
+		return "double precision", floatTypes
+	}
+
+	switch fieldType.Kind() {
+	case reflect.String:
+		return "text", stringTypes
+	case reflect.Bool:
+		return "boolean", []string{"boolean"}
+	case reflect.Int8, reflect.Int16, reflect.Uint8:
+		return "smallint", integerTypes
+	case reflect.Int32, reflect.Uint16:
+		return "integer", integerTypes
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint32, reflect.Uint64:
+		return "bigint", integerTypes
+	case reflect.Float32:
+		return "real", floatTypes
+	case reflect.Float64:
+		return "double precision", floatTypes
+	}
+	return
+}
@@ -0,0 +1,110 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package migration
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/blend/go-sdk/assert"
+	"github.com/blend/go-sdk/uuid"
+)
+
+type driftTestObj struct {
+	ID        int64          `db:"id,pk,auto"`
+	UUID      uuid.UUID      `db:"uuid"`
+	Name      string         `db:"name"`
+	Nickname  sql.NullString `db:"nickname"`
+	Active    bool           `db:"active"`
+	Score     float64        `db:"score"`
+	CreatedAt time.Time      `db:"created_at"`
+	Timeout   time.Duration  `db:"timeout"`
+	Labels    []string       `db:"labels"`
+	Data      map[string]int `db:"data,json"`
+	Total     int            `db:"total,readonly"`
+}
+
+func (driftTestObj) TableName() string { return "drift_test" }
+
+type driftTestUnknownObj struct {
+	ID    int      `db:"id,pk"`
+	Value struct{} `db:"value"`
+}
+
+func (driftTestUnknownObj) TableName() string { return "drift_test_unknown" }
+
+func TestCatalog_DiffMissingTable(t *testing.T) {
+	its := assert.New(t)
+
+	catalog := Catalog{Schema: "public", Tables: map[string]*CatalogTable{}}
+	drifts := catalog.Diff(driftTestObj{}, driftTestUnknownObj{})
+	its.Len(drifts, 2)
+	its.Equal(DriftMissingTable, drifts[0].Kind)
+	its.Equal("drift_test", drifts[0].Table)
+	its.Equal("CREATE TABLE public.drift_test (id bigserial NOT NULL, uuid uuid, name text, nickname text, active boolean, score double precision, created_at timestamp, timeout bigint, labels text[], data jsonb, PRIMARY KEY (id))", drifts[0].Statement)
+	its.NotNil(drifts[0].Step())
+
+	its.Equal(DriftMissingTable, drifts[1].Kind)
+	its.Empty(drifts[1].Statement, "the statement cannot be built for unknown column types")
+	its.Nil(drifts[1].Step())
+	its.Len(drifts.Actions(), 1)
+}
+
+func TestCatalog_Diff(t *testing.T) {
+	its := assert.New(t)
+
+	catalog := Catalog{
+		Schema: "public",
+		Tables: map[string]*CatalogTable{
+			"drift_test": {
+				Name: "drift_test",
+				Columns: []CatalogColumn{
+					{Name: "id", DataType: "integer"},
+					{Name: "uuid", DataType: "uuid"},
+					{Name: "name", DataType: "character varying"},
+					{Name: "nickname", DataType: "text"},
+					{Name: "active", DataType: "text"},
+					{Name: "score", DataType: "numeric"},
+					{Name: "created_at", DataType: "timestamp with time zone"},
+					{Name: "timeout", DataType: "bigint"},
+					{Name: "labels", DataType: "_text"},
+					{Name: "data", DataType: "json"},
+					{Name: "total", DataType: "bigint"},
+					{Name: "legacy", DataType: "text"},
+				},
+			},
+		},
+	}
+
+	drifts := catalog.Diff(driftTestObj{})
+	its.Len(drifts, 2)
+
+	its.Equal(DriftTypeMismatch, drifts[0].Kind)
+	its.Equal("active", drifts[0].Column)
+	its.Equal("boolean", drifts[0].Expected)
+	its.Equal("text", drifts[0].Actual)
+	its.Equal("ALTER TABLE public.drift_test ALTER COLUMN active TYPE boolean USING active::boolean", drifts[0].Statement)
+	its.Equal("type_mismatch: drift_test.active (expected boolean, actual text)", drifts[0].String())
+
+	its.Equal(DriftExtraColumn, drifts[1].Kind)
+	its.Equal("legacy", drifts[1].Column)
+	its.Equal("ALTER TABLE public.drift_test DROP COLUMN legacy", drifts[1].Statement)
+	its.Equal("extra_column: drift_test.legacy (text)", drifts[1].String())
+
+	its.Len(drifts.Filter(DriftTypeMismatch), 1)
+	its.Len(drifts.Filter(DriftMissingTable, DriftMissingColumn), 0)
+
+	catalog.Tables["drift_test"].Columns = catalog.Tables["drift_test"].Columns[:9]
+	drifts = catalog.Diff(driftTestObj{}).Filter(DriftMissingColumn)
+	its.Len(drifts, 1)
+	its.Equal("data", drifts[0].Column)
+	its.Equal("jsonb", drifts[0].Expected)
+	its.Equal("ALTER TABLE public.drift_test ADD COLUMN data jsonb", drifts[0].Statement)
+	its.Equal("missing_column: drift_test.data (expected jsonb)", drifts[0].String())
+}
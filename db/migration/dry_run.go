@@ -0,0 +1,59 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package migration
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/blend/go-sdk/db"
+)
+
+// DryRunner is an action that can describe what it would execute without executing it.
+//
+// The actions returned by `Statements`, `Exec` and `Actions`, as well as steps, implement this interface.
+type DryRunner interface {
+	DryRun(context.Context, *db.Connection, *sql.Tx) error
+}
+
+// IsDryRun returns if the suite on the context is a dry run.
+func IsDryRun(ctx context.Context) bool {
+	if suite := GetContextSuite(ctx); suite != nil {
+		return suite.DryRun
+	}
+	return false
+}
+
+// DryRun describes an action without executing it.
+//
+// Actions that do not implement `DryRunner` are not executed, and are described by their type.
+func DryRun(ctx context.Context, c *db.Connection, tx *sql.Tx, action Action) error {
+	if typed, ok := action.(DryRunner); ok {
+		return typed.DryRun(ctx, c, tx)
+	}
+	writeDryRun(ctx, fmt.Sprintf("%T", action))
+	return nil
+}
+
+// dryRunAction is an action that dry runs another action.
+type dryRunAction struct {
+	Body Action
+}
+
+// Action implements Action.
+func (dra dryRunAction) Action(ctx context.Context, c *db.Connection, tx *sql.Tx) error {
+	return DryRun(ctx, c, tx, dra.Body)
+}
+
+// writeDryRun writes a dry run event if there is a suite on the context.
+func writeDryRun(ctx context.Context, body string) {
+	if suite := GetContextSuite(ctx); suite != nil {
+		suite.Write(ctx, StatDryRun, body)
+	}
+}
@@ -0,0 +1,73 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package migration
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+
+	"github.com/blend/go-sdk/assert"
+	"github.com/blend/go-sdk/db"
+	"github.com/blend/go-sdk/logger"
+)
+
+func TestSuite_DryRun(t *testing.T) {
+	its := assert.New(t)
+
+	buffer := new(bytes.Buffer)
+	log := logger.Memory(buffer)
+	defer log.Close()
+
+	var executed bool
+	s := New(
+		OptLog(log),
+		OptDryRun(),
+		OptGroups(NewGroup(
+			OptGroupSkipTransaction(),
+			OptGroupActions(
+				NewStep(Always(), Statements("CREATE TABLE foo (id int)")),
+				Exec("INSERT INTO foo (id) VALUES ($1)", 1),
+				ActionFunc(func(_ context.Context, _ *db.Connection, _ *sql.Tx) error {
+					executed = true
+					return nil
+				}),
+				NewStep(
+					Guard("never run", func(_ context.Context, _ *db.Connection, _ *sql.Tx) (bool, error) { return false, nil }),
+					Statements("DROP TABLE foo"),
+				),
+			),
+		)),
+	)
+	its.Nil(s.Apply(context.Background(), nil))
+	its.False(executed)
+
+	applied, skipped, failed, total := s.Results()
+	its.Equal(1, applied)
+	its.Equal(1, skipped)
+	its.Equal(0, failed)
+	its.Equal(2, total)
+
+	output := buffer.String()
+	its.Contains(output, "dry-run -- CREATE TABLE foo (id int)")
+	its.Contains(output, "dry-run -- always run")
+	its.Contains(output, "dry-run -- INSERT INTO foo (id) VALUES ($1) -- args: [1]")
+	its.Contains(output, fmt.Sprintf("dry-run -- %T", ActionFunc(nil)))
+	its.Contains(output, "skipped -- never run")
+	its.NotContains(output, "DROP TABLE foo")
+}
+
+func TestIsDryRun(t *testing.T) {
+	its := assert.New(t)
+
+	its.False(IsDryRun(context.Background()))
+	its.False(IsDryRun(WithSuite(context.Background(), New())))
+	its.True(IsDryRun(WithSuite(context.Background(), New(OptDryRun()))))
+}
@@ -35,8 +35,15 @@ func NewGroupWithAction(guard GuardFunc, action Action, options ...GroupOption)
 // It uses normally transactions to apply these actions as an atomic unit, but this transaction can be bypassed by
 // setting the SkipTransaction flag to true. This allows the use of CONCURRENT index creation and other operations that
 // postgres will not allow within a transaction.
+//
+// When the suite has history enabled, the group Name identifies the group in the history table,
+// and the Checksum (or the checksum of the actions if unset) is used to detect edits after it was applied.
+// The RollbackActions undo the Actions when the suite is rolled back.
 type Group struct {
+	Name            string
+	Checksum        string
 	Actions         []Action
+	RollbackActions []Action
 	Tx              *sql.Tx
 	SkipTransaction bool
 }
@@ -64,8 +71,13 @@ func (ga *Group) Action(ctx context.Context, c *db.Connection) (err error) {
 		}()
 	}
 
+	dryRun := IsDryRun(ctx)
 	for _, a := range ga.Actions {
-		err = a.Action(ctx, c, tx)
+		if dryRun {
+			err = DryRun(ctx, c, tx, a)
+		} else {
+			err = a.Action(ctx, c, tx)
+		}
 		if err != nil {
 			return
 		}
@@ -73,3 +85,11 @@ func (ga *Group) Action(ctx context.Context, c *db.Connection) (err error) {
 
 	return
 }
+
+// ChecksumOrDefault returns the group checksum or the combined checksum of the group actions.
+func (ga *Group) ChecksumOrDefault() string {
+	if ga.Checksum != "" {
+		return ga.Checksum
+	}
+	return checksumActions(ga.Actions)
+}
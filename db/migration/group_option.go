@@ -24,6 +24,13 @@ func OptGroupActions(actions ...Action) GroupOption {
 	}
 }
 
+// OptGroupRollback adds actions that undo the group actions when the suite is rolled back. They are additive.
+func OptGroupRollback(actions ...Action) GroupOption {
+	return func(g *Group) {
+		g.RollbackActions = append(g.RollbackActions, actions...)
+	}
+}
+
 // OptGroupSkipTransaction will allow this group to be run outside of a transaction. Use this to concurrently create indices
 // and perform other actions that cannot be executed in a Tx
 func OptGroupSkipTransaction() GroupOption {
@@ -38,3 +45,19 @@ func OptGroupTx(tx *sql.Tx) GroupOption {
 		g.Tx = tx
 	}
 }
+
+// OptGroupName sets the group name, which identifies the group in the history table.
+func OptGroupName(name string) GroupOption {
+	return func(g *Group) {
+		g.Name = name
+	}
+}
+
+// OptGroupChecksum sets an explicit group checksum, which is used instead of the checksum of the group actions.
+//
+// This is useful for groups made of actions that cannot be checksummed, e.g. `ActionFunc` actions.
+func OptGroupChecksum(checksum string) GroupOption {
+	return func(g *Group) {
+		g.Checksum = checksum
+	}
+}
@@ -32,3 +32,15 @@ type Step struct {
 func (ga *Step) Action(ctx context.Context, c *db.Connection, tx *sql.Tx) error {
 	return ga.Guard(ctx, c, tx, ga.Body)
 }
+
+// Checksum implements Checksummer.
+//
+// Guards are functions and cannot be compared, so the checksum reflects only the body.
+func (ga *Step) Checksum() string {
+	return Checksum(ga.Body)
+}
+
+// DryRun implements DryRunner, evaluating the guard and dry running the body if it passes.
+func (ga *Step) DryRun(ctx context.Context, c *db.Connection, tx *sql.Tx) error {
+	return ga.Guard(ctx, c, tx, dryRunAction{Body: ga.Body})
+}
@@ -0,0 +1,200 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package migration
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"hash/fnv"
+	"time"
+
+	"github.com/blend/go-sdk/db"
+	"github.com/blend/go-sdk/ex"
+)
+
+// NewHistory returns a new history.
+func NewHistory(options ...HistoryOption) *History {
+	var h History
+	for _, option := range options {
+		option(&h)
+	}
+	return &h
+}
+
+// HistoryOption is an option for histories.
+type HistoryOption func(*History)
+
+// OptHistoryTable sets the history table name.
+func OptHistoryTable(table string) HistoryOption {
+	return func(h *History) {
+		h.Table = table
+	}
+}
+
+// OptHistoryBuild sets the build (e.g. a version or commit sha) recorded with applied groups.
+func OptHistoryBuild(build string) HistoryOption {
+	return func(h *History) {
+		h.Build = build
+	}
+}
+
+// OptHistoryLockID sets the advisory lock id held while a suite is applied.
+func OptHistoryLockID(lockID int64) HistoryOption {
+	return func(h *History) {
+		h.LockID = lockID
+	}
+}
+
+// History records applied groups in a table.
+//
+// When a suite has a history, each group is applied at most once; groups that
+// are recorded in the history table are skipped, and groups that have been changed
+// since they were applied (that is, their checksums differ) fail the suite.
+//
+// The suite holds a postgres advisory lock while it is applied, so that
+// only one process migrates a given database at a time.
+type History struct {
+	// Table is the history table name.
+	Table string
+	// Build is recorded with each applied group.
+	Build string
+	// LockID is the advisory lock id; it defaults to a hash of the table name.
+	LockID int64
+}
+
+// TableOrDefault returns the history table or a default.
+func (h *History) TableOrDefault() string {
+	if h.Table != "" {
+		return h.Table
+	}
+	return DefaultHistoryTable
+}
+
+// LockIDOrDefault returns the lock id or a default derived from the table name.
+func (h *History) LockIDOrDefault() int64 {
+	if h.LockID != 0 {
+		return h.LockID
+	}
+	hash := fnv.New64a()
+	_, _ = hash.Write([]byte("migration:" + h.TableOrDefault()))
+	return int64(hash.Sum64())
+}
+
+// HistoryRecord is a row in the history table.
+type HistoryRecord struct {
+	Name      string
+	Checksum  string
+	Build     string
+	Duration  time.Duration
+	AppliedAt time.Time
+}
+
+// Lock acquires the advisory lock on a dedicated connection, blocking until it is available
+// or the context is cancelled, and returns a function that releases it.
+func (h *History) Lock(ctx context.Context, c *db.Connection) (unlock func() error, err error) {
+	conn, err := c.Connection.Conn(ctx)
+	if err != nil {
+		return
+	}
+	lockID := h.LockIDOrDefault()
+	err = db.IgnoreExecResult(c.Invoke(db.OptContext(ctx), db.OptInvocationDB(conn), db.OptLabel("migration_history_lock")).Exec("SELECT pg_advisory_lock($1)", lockID))
+	if err != nil {
+		err = ex.Nest(err, conn.Close())
+		return
+	}
+	unlock = func() error {
+		// use a fresh context so the lock is released even if the apply context was cancelled.
+		_, unlockErr := conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", lockID)
+		return ex.Nest(unlockErr, conn.Close())
+	}
+	return
+}
+
+// Ensure creates the history table if it does not exist, within a transaction if one is provided.
+func (h *History) Ensure(ctx context.Context, c *db.Connection, tx *sql.Tx) error {
+	return db.IgnoreExecResult(c.Invoke(db.OptContext(ctx), db.OptTx(tx), db.OptLabel("migration_history_ensure")).Exec(fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+	name TEXT NOT NULL PRIMARY KEY,
+	checksum TEXT NOT NULL,
+	build TEXT NOT NULL,
+	duration_ms BIGINT NOT NULL,
+	applied_at TIMESTAMPTZ NOT NULL
+)`, h.TableOrDefault())))
+}
+
+// Exists returns if the history table exists.
+func (h *History) Exists(ctx context.Context, c *db.Connection, tx *sql.Tx) (bool, error) {
+	return c.Invoke(db.OptContext(ctx), db.OptTx(tx), db.OptForcePrimary()).Query(`SELECT 1 FROM pg_catalog.pg_tables WHERE tablename = $1 AND schemaname = current_schema()`, h.TableOrDefault()).Any()
+}
+
+// Records returns the recorded groups in the order they were applied.
+func (h *History) Records(ctx context.Context, c *db.Connection, tx *sql.Tx) (output []HistoryRecord, err error) {
+	err = c.Invoke(db.OptContext(ctx), db.OptTx(tx), db.OptForcePrimary(), db.OptLabel("migration_history_records")).Query(
+		fmt.Sprintf("SELECT name, checksum, build, duration_ms, applied_at FROM %s ORDER BY applied_at ASC, name ASC", h.TableOrDefault()),
+	).Each(func(r db.Rows) error {
+		var record HistoryRecord
+		var durationMillis int64
+		if err := r.Scan(&record.Name, &record.Checksum, &record.Build, &durationMillis, &record.AppliedAt); err != nil {
+			return err
+		}
+		record.Duration = time.Duration(durationMillis) * time.Millisecond
+		output = append(output, record)
+		return nil
+	})
+	return
+}
+
+// Record inserts a history record, within a transaction if one is provided.
+func (h *History) Record(ctx context.Context, c *db.Connection, tx *sql.Tx, record HistoryRecord) error {
+	return db.IgnoreExecResult(c.Invoke(db.OptContext(ctx), db.OptTx(tx), db.OptLabel("migration_history_record")).Exec(
+		fmt.Sprintf("INSERT INTO %s (name, checksum, build, duration_ms, applied_at) VALUES ($1, $2, $3, $4, $5)", h.TableOrDefault()),
+		record.Name, record.Checksum, record.Build, record.Duration.Milliseconds(), record.AppliedAt,
+	))
+}
+
+// Remove deletes a history record, within a transaction if one is provided.
+func (h *History) Remove(ctx context.Context, c *db.Connection, tx *sql.Tx, name string) error {
+	return db.IgnoreExecResult(c.Invoke(db.OptContext(ctx), db.OptTx(tx), db.OptLabel("migration_history_remove")).Exec(
+		fmt.Sprintf("DELETE FROM %s WHERE name = $1", h.TableOrDefault()), name,
+	))
+}
+
+// load returns the recorded groups, or no records if the history table does not exist.
+func (h *History) load(ctx context.Context, c *db.Connection, tx *sql.Tx) ([]HistoryRecord, error) {
+	exists, err := h.Exists(ctx, c, tx)
+	if err != nil || !exists {
+		return nil, err
+	}
+	return h.Records(ctx, c, tx)
+}
+
+// withRecord returns a copy of a group that records it as its final action, so the
+// record is committed with the group transaction.
+func (h *History) withRecord(group *Group, checksum string) *Group {
+	started := time.Now()
+	recorded := *group
+	recorded.Actions = append(append([]Action{}, group.Actions...), ActionFunc(func(ctx context.Context, c *db.Connection, tx *sql.Tx) error {
+		return h.Record(ctx, c, tx, HistoryRecord{
+			Name:      group.Name,
+			Checksum:  checksum,
+			Build:     h.Build,
+			Duration:  time.Since(started),
+			AppliedAt: time.Now().UTC(),
+		})
+	}))
+	return &recorded
+}
+
+// withRemove returns a copy of a group that removes its record as its final action.
+func (h *History) withRemove(group *Group) *Group {
+	removed := *group
+	removed.Actions = append(append([]Action{}, group.Actions...), ActionFunc(func(ctx context.Context, c *db.Connection, tx *sql.Tx) error {
+		return h.Remove(ctx, c, tx, group.Name)
+	}))
+	return &removed
+}
@@ -0,0 +1,135 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package migration
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/blend/go-sdk/assert"
+	"github.com/blend/go-sdk/db"
+	"github.com/blend/go-sdk/ex"
+	"github.com/blend/go-sdk/logger"
+	"github.com/blend/go-sdk/stringutil"
+)
+
+func TestHistory_Defaults(t *testing.T) {
+	its := assert.New(t)
+
+	history := NewHistory()
+	its.Equal(DefaultHistoryTable, history.TableOrDefault())
+	its.NotZero(history.LockIDOrDefault())
+	its.Equal(history.LockIDOrDefault(), NewHistory().LockIDOrDefault())
+
+	history = NewHistory(OptHistoryTable("other_history"), OptHistoryBuild("v1.2.3"))
+	its.Equal("other_history", history.TableOrDefault())
+	its.Equal("v1.2.3", history.Build)
+	its.NotEqual(NewHistory().LockIDOrDefault(), history.LockIDOrDefault())
+
+	history = NewHistory(OptHistoryLockID(1234))
+	its.Equal(1234, history.LockIDOrDefault())
+}
+
+func TestSuite_validateHistory(t *testing.T) {
+	its := assert.New(t)
+
+	its.Nil(New(OptHistory(), OptGroups(
+		NewGroup(OptGroupName("0001")),
+		NewGroup(OptGroupName("0002")),
+	)).validateHistory())
+
+	err := New(OptHistory(), OptGroups(NewGroup(OptGroupName("0001")), NewGroup())).validateHistory()
+	its.Equal(ErrHistoryGroupNameUnset, ex.ErrClass(err))
+
+	err = New(OptHistory(), OptGroups(NewGroup(OptGroupName("0001")), NewGroup(OptGroupName("0001")))).validateHistory()
+	its.Equal(ErrHistoryDuplicateGroupName, ex.ErrClass(err))
+}
+
+func createHistoryTestGroups(tableName string) []*Group {
+	return []*Group{
+		NewGroup(
+			OptGroupName("0001_create"),
+			OptGroupActions(Statements(fmt.Sprintf("CREATE TABLE %s (id serial not null primary key)", tableName))),
+		),
+		NewGroup(
+			OptGroupName("0002_alter"),
+			OptGroupActions(Statements(fmt.Sprintf("ALTER TABLE %s ADD COLUMN name varchar(32)", tableName))),
+		),
+	}
+}
+
+func TestSuite_ApplyHistory(t *testing.T) {
+	its := assert.New(t)
+
+	historyTable := fmt.Sprintf("test_history_%s", stringutil.Random(stringutil.LowerLetters, 10))
+	tableName := fmt.Sprintf("test_history_target_%s", stringutil.Random(stringutil.LowerLetters, 10))
+	defer func() {
+		its.Nil(db.IgnoreExecResult(defaultDB().Exec(fmt.Sprintf("DROP TABLE IF EXISTS %s", historyTable))))
+		its.Nil(db.IgnoreExecResult(defaultDB().Exec(fmt.Sprintf("DROP TABLE IF EXISTS %s", tableName))))
+	}()
+
+	s := New(OptLog(logger.None()), OptHistory(OptHistoryTable(historyTable), OptHistoryBuild("v1")), OptGroups(createHistoryTestGroups(tableName)...))
+	status, err := s.Status(context.Background(), defaultDB())
+	its.Nil(err)
+	its.Len(status.Pending(), 2)
+
+	its.Nil(s.Apply(context.Background(), defaultDB()))
+	applied, skipped, failed, _ := s.Results()
+	its.Equal(0, applied)
+	its.Equal(0, skipped)
+	its.Equal(0, failed)
+
+	records, err := s.History.Records(context.Background(), defaultDB(), nil)
+	its.Nil(err)
+	its.Len(records, 2)
+	its.Equal("0001_create", records[0].Name)
+	its.Equal("v1", records[0].Build)
+	its.False(records[0].AppliedAt.IsZero())
+
+	// applying again skips the recorded groups rather than failing to create the table again.
+	s = New(OptLog(logger.None()), OptHistory(OptHistoryTable(historyTable), OptHistoryBuild("v2")), OptGroups(createHistoryTestGroups(tableName)...))
+	its.Nil(s.Apply(context.Background(), defaultDB()))
+	_, skipped, _, _ = s.Results()
+	its.Equal(2, skipped)
+
+	status, err = s.Status(context.Background(), defaultDB())
+	its.Nil(err)
+	its.Len(status.Applied(), 2)
+	its.Empty(status.Pending())
+
+	// editing an applied group fails the suite.
+	groups := createHistoryTestGroups(tableName)
+	groups[1].Actions = []Action{Statements(fmt.Sprintf("ALTER TABLE %s ADD COLUMN name varchar(64)", tableName))}
+	s = New(OptLog(logger.None()), OptHistory(OptHistoryTable(historyTable)), OptGroups(groups...))
+	err = s.Apply(context.Background(), defaultDB())
+	its.Equal(ErrHistoryChecksumMismatch, ex.ErrClass(err))
+}
+
+func TestHistory_Lock(t *testing.T) {
+	its := assert.New(t)
+
+	history := NewHistory(OptHistoryTable(fmt.Sprintf("test_history_%s", stringutil.Random(stringutil.LowerLetters, 10))))
+	unlock, err := history.Lock(context.Background(), defaultDB())
+	its.Nil(err)
+
+	// session level advisory locks must be checked and released on the same connection.
+	conn, err := defaultDB().Connection.Conn(context.Background())
+	its.Nil(err)
+	defer conn.Close()
+
+	var acquired bool
+	its.Nil(conn.QueryRowContext(context.Background(), "SELECT pg_try_advisory_lock($1)", history.LockIDOrDefault()).Scan(&acquired))
+	its.False(acquired, "the lock should be held by the other session")
+
+	its.Nil(unlock())
+	its.Nil(conn.QueryRowContext(context.Background(), "SELECT pg_try_advisory_lock($1)", history.LockIDOrDefault()).Scan(&acquired))
+	its.True(acquired)
+	_, err = conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", history.LockIDOrDefault())
+	its.Nil(err)
+}
@@ -0,0 +1,90 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package migration
+
+import (
+	"fmt"
+	"io"
+	"time"
+)
+
+// Status is a report of which groups of a suite have been applied.
+type Status struct {
+	Entries []StatusEntry
+}
+
+// StatusEntry is the status of a single group.
+type StatusEntry struct {
+	Name     string
+	Checksum string
+	// State is one of `StatusApplied`, `StatusPending` or `StatusModified`.
+	State string
+	// Record is the history record for the group if it has been applied.
+	Record *HistoryRecord
+}
+
+// Applied returns the entries that have been applied.
+func (s Status) Applied() []StatusEntry {
+	return s.filter(StatusApplied)
+}
+
+// Pending returns the entries that have not been applied.
+func (s Status) Pending() []StatusEntry {
+	return s.filter(StatusPending)
+}
+
+// Modified returns the entries that have been changed since they were applied.
+func (s Status) Modified() []StatusEntry {
+	return s.filter(StatusModified)
+}
+
+// WriteText writes the status as text, one group per line.
+func (s Status) WriteText(wr io.Writer) {
+	for _, entry := range s.Entries {
+		if entry.Record != nil {
+			fmt.Fprintf(wr, "%-8s %s (applied %s build %q in %v)\n", entry.State, entry.Name, entry.Record.AppliedAt.Format(time.RFC3339), entry.Record.Build, entry.Record.Duration)
+			continue
+		}
+		fmt.Fprintf(wr, "%-8s %s\n", entry.State, entry.Name)
+	}
+}
+
+func (s Status) filter(state string) (output []StatusEntry) {
+	for _, entry := range s.Entries {
+		if entry.State == state {
+			output = append(output, entry)
+		}
+	}
+	return
+}
+
+// newStatus returns the status of a list of groups given the history records.
+func newStatus(groups []*Group, records []HistoryRecord) *Status {
+	lookup := make(map[string]HistoryRecord, len(records))
+	for _, record := range records {
+		lookup[record.Name] = record
+	}
+	var status Status
+	for _, group := range groups {
+		entry := StatusEntry{
+			Name:     group.Name,
+			Checksum: group.ChecksumOrDefault(),
+			State:    StatusPending,
+		}
+		if record, ok := lookup[group.Name]; ok {
+			entry.Record = &record
+			if record.Checksum == entry.Checksum {
+				entry.State = StatusApplied
+			} else {
+				entry.State = StatusModified
+			}
+		}
+		status.Entries = append(status.Entries, entry)
+	}
+	return &status
+}
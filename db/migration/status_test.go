@@ -0,0 +1,50 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package migration
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/blend/go-sdk/assert"
+)
+
+func TestStatus(t *testing.T) {
+	its := assert.New(t)
+
+	groups := []*Group{
+		NewGroup(OptGroupName("0001_create"), OptGroupActions(Statements("CREATE TABLE foo (id int)"))),
+		NewGroup(OptGroupName("0002_alter"), OptGroupActions(Statements("ALTER TABLE foo ADD COLUMN name text"))),
+		NewGroup(OptGroupName("0003_index"), OptGroupActions(Statements("CREATE INDEX idx_foo ON foo(name)"))),
+	}
+	appliedAt := time.Date(2022, 01, 02, 03, 04, 05, 0, time.UTC)
+	records := []HistoryRecord{
+		{Name: "0001_create", Checksum: groups[0].ChecksumOrDefault(), Build: "v1", Duration: time.Second, AppliedAt: appliedAt},
+		{Name: "0002_alter", Checksum: "edited", Build: "v1", Duration: time.Second, AppliedAt: appliedAt},
+	}
+
+	status := newStatus(groups, records)
+	its.Len(status.Entries, 3)
+	its.Len(status.Applied(), 1)
+	its.Equal("0001_create", status.Applied()[0].Name)
+	its.NotNil(status.Applied()[0].Record)
+	its.Equal("v1", status.Applied()[0].Record.Build)
+	its.Len(status.Modified(), 1)
+	its.Equal("0002_alter", status.Modified()[0].Name)
+	its.Len(status.Pending(), 1)
+	its.Equal("0003_index", status.Pending()[0].Name)
+	its.Nil(status.Pending()[0].Record)
+
+	buffer := new(bytes.Buffer)
+	status.WriteText(buffer)
+	its.Equal(`applied  0001_create (applied 2022-01-02T03:04:05Z build "v1" in 1s)
+modified 0002_alter (applied 2022-01-02T03:04:05Z build "v1" in 1s)
+pending  0003_index
+`, buffer.String())
+}
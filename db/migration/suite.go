@@ -9,7 +9,9 @@ package migration
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
+	"time"
 
 	"github.com/blend/go-sdk/db"
 	"github.com/blend/go-sdk/ex"
@@ -34,8 +36,13 @@ func NewWithActions(actions ...Action) *Suite {
 
 // Suite is a migration suite.
 type Suite struct {
-	Log    logger.Log
-	Groups []*Group
+	Log     logger.Log
+	Groups  []*Group
+	History *History
+	// DryRun evaluates guards but writes the statements that would be executed as events rather than executing them.
+	DryRun bool
+	// Check runs the suite within a single transaction that is always rolled back.
+	Check bool
 
 	Applied int
 	Skipped int
@@ -52,18 +59,237 @@ func (s *Suite) Apply(ctx context.Context, c *db.Connection) (err error) {
 		}
 	}()
 
+	// guards should never read from a replica that may be behind the primary.
+	ctx = db.WithForcePrimary(ctx)
+	err = s.run(ctx, c, s.apply)
+	return
+}
+
+// Rollback rolls back applied groups, most recently applied first, until the group named
+// by `toVersion` is the most recently applied group. If `toVersion` is empty, every
+// applied group is rolled back.
+//
+// Rollback requires the suite to have a history, and each group that is rolled back
+// must have rollback actions; see `OptGroupRollback`.
+func (s *Suite) Rollback(ctx context.Context, c *db.Connection, toVersion string) (err error) {
+	defer s.WriteStats(ctx)
+	defer func() {
+		if r := recover(); r != nil {
+			err = ex.New(r)
+		}
+	}()
+
+	if s.History == nil {
+		err = ex.New(ErrHistoryUnset)
+		return
+	}
+	ctx = db.WithForcePrimary(ctx)
+	err = s.run(ctx, c, func(ctx context.Context, c *db.Connection, tx *sql.Tx) error {
+		return s.rollback(ctx, c, tx, toVersion)
+	})
+	return
+}
+
+// Status returns which groups have been applied according to the suite history.
+//
+// If the suite does not have a history, the default history table is used.
+func (s *Suite) Status(ctx context.Context, c *db.Connection) (*Status, error) {
+	if err := s.validateHistory(); err != nil {
+		return nil, err
+	}
+	history := s.History
+	if history == nil {
+		history = NewHistory()
+	}
+	records, err := history.load(db.WithForcePrimary(ctx), c, nil)
+	if err != nil {
+		return nil, err
+	}
+	return newStatus(s.Groups, records), nil
+}
+
+// run calls an action, within a transaction that is rolled back if the suite is in check mode.
+func (s *Suite) run(ctx context.Context, c *db.Connection, action ActionFunc) (err error) {
+	if !s.Check {
+		err = action(ctx, c, nil)
+		return
+	}
+	var tx *sql.Tx
+	if tx, err = c.BeginContext(ctx); err != nil {
+		return
+	}
+	defer func() {
+		if txErr := tx.Rollback(); txErr != nil {
+			err = ex.Nest(err, txErr)
+		}
+	}()
+	err = action(ctx, c, tx)
+	return
+}
+
+func (s *Suite) apply(ctx context.Context, c *db.Connection, tx *sql.Tx) (err error) {
+	if s.History != nil {
+		err = s.applyWithHistory(ctx, c, tx)
+		return
+	}
 	for _, group := range s.Groups {
-		if err = group.Action(WithSuite(ctx, s), c); err != nil {
+		if err = s.applyGroup(WithSuite(ctx, s), c, tx, group); err != nil {
 			return
 		}
 	}
 	return
 }
 
+// applyGroup applies a group, within the check transaction if one is provided.
+func (s *Suite) applyGroup(ctx context.Context, c *db.Connection, tx *sql.Tx, group *Group) error {
+	if tx == nil {
+		return group.Action(ctx, c)
+	}
+	if group.SkipTransaction {
+		s.Skipf(ctx, "group cannot be checked within a transaction")
+		return nil
+	}
+	checked := *group
+	checked.Tx = tx
+	return checked.Action(ctx, c)
+}
+
+// lock acquires the history lock, unless the suite is a dry run.
+func (s *Suite) lock(ctx context.Context, c *db.Connection) (unlock func() error, err error) {
+	if s.DryRun {
+		unlock = func() error { return nil }
+		return
+	}
+	unlock, err = s.History.Lock(ctx, c)
+	return
+}
+
+func (s *Suite) applyWithHistory(ctx context.Context, c *db.Connection, tx *sql.Tx) (err error) {
+	if err = s.validateHistory(); err != nil {
+		return
+	}
+
+	var unlock func() error
+	if unlock, err = s.lock(ctx, c); err != nil {
+		return
+	}
+	defer func() {
+		if unlockErr := unlock(); unlockErr != nil {
+			err = ex.Nest(err, unlockErr)
+		}
+	}()
+
+	if !s.DryRun {
+		if err = s.History.Ensure(ctx, c, tx); err != nil {
+			return
+		}
+	}
+	var records []HistoryRecord
+	if records, err = s.History.load(ctx, c, tx); err != nil {
+		return
+	}
+
+	for index, entry := range newStatus(s.Groups, records).Entries {
+		groupCtx := WithLabel(WithSuite(ctx, s), entry.Name)
+		switch entry.State {
+		case StatusApplied:
+			s.Skipf(groupCtx, "already applied at %s", entry.Record.AppliedAt.Format(time.RFC3339))
+		case StatusModified:
+			err = s.Error(groupCtx, ex.New(ErrHistoryChecksumMismatch, ex.OptMessagef("group: %s; applied: %s; current: %s", entry.Name, entry.Record.Checksum, entry.Checksum)))
+			return
+		default:
+			group := s.Groups[index]
+			if !s.DryRun {
+				group = s.History.withRecord(group, entry.Checksum)
+			}
+			if err = s.applyGroup(groupCtx, c, tx, group); err != nil {
+				return
+			}
+		}
+	}
+	return
+}
+
+func (s *Suite) rollback(ctx context.Context, c *db.Connection, tx *sql.Tx, toVersion string) (err error) {
+	if err = s.validateHistory(); err != nil {
+		return
+	}
+
+	var unlock func() error
+	if unlock, err = s.lock(ctx, c); err != nil {
+		return
+	}
+	defer func() {
+		if unlockErr := unlock(); unlockErr != nil {
+			err = ex.Nest(err, unlockErr)
+		}
+	}()
+
+	var records []HistoryRecord
+	if records, err = s.History.load(ctx, c, tx); err != nil {
+		return
+	}
+	var first int
+	if toVersion != "" {
+		first = -1
+		for index, record := range records {
+			if record.Name == toVersion {
+				first = index + 1
+				break
+			}
+		}
+		if first < 0 {
+			err = ex.New(ErrRollbackVersionNotFound, ex.OptMessagef("version: %s", toVersion))
+			return
+		}
+	}
+
+	// check every group can be rolled back before rolling any of them back.
+	groups := make(map[string]*Group, len(s.Groups))
+	for _, group := range s.Groups {
+		groups[group.Name] = group
+	}
+	for index := len(records) - 1; index >= first; index-- {
+		group, ok := groups[records[index].Name]
+		if !ok {
+			err = ex.New(ErrRollbackGroupNotFound, ex.OptMessagef("group: %s", records[index].Name))
+			return
+		}
+		if len(group.RollbackActions) == 0 {
+			err = ex.New(ErrRollbackUnset, ex.OptMessagef("group: %s", group.Name))
+			return
+		}
+	}
+
+	for index := len(records) - 1; index >= first; index-- {
+		group := groups[records[index].Name]
+		groupCtx := WithLabel(WithSuite(ctx, s), group.Name)
+		rollback := &Group{
+			Name:            group.Name,
+			Actions:         group.RollbackActions,
+			SkipTransaction: group.SkipTransaction,
+		}
+		if !s.DryRun {
+			rollback = s.History.withRemove(rollback)
+		}
+		if err = s.applyGroup(groupCtx, c, tx, rollback); err != nil {
+			return
+		}
+		s.Write(groupCtx, StatRolledBack, group.Name)
+	}
+	return
+}
+
 // Applyf writes an applied step message.
+//
+// If the suite is a dry run, the step is written as a dry run step.
 func (s *Suite) Applyf(ctx context.Context, format string, args ...interface{}) {
 	s.Applied++
 	s.Total++
+	if s.DryRun {
+		s.Write(ctx, StatDryRun, fmt.Sprintf(format, args...))
+		return
+	}
 	s.Write(ctx, StatApplied, fmt.Sprintf(format, args...))
 }
 
@@ -102,3 +328,18 @@ func (s *Suite) WriteStats(ctx context.Context) {
 func (s *Suite) Results() (applied, skipped, failed, total int) {
 	return s.Applied, s.Skipped, s.Failed, s.Total
 }
+
+// validateHistory returns an error if any group is unnamed or shares a name with another group.
+func (s *Suite) validateHistory() error {
+	names := make(map[string]bool, len(s.Groups))
+	for index, group := range s.Groups {
+		if group.Name == "" {
+			return ex.New(ErrHistoryGroupNameUnset, ex.OptMessagef("group index: %d", index))
+		}
+		if names[group.Name] {
+			return ex.New(ErrHistoryDuplicateGroupName, ex.OptMessagef("group: %s", group.Name))
+		}
+		names[group.Name] = true
+	}
+	return nil
+}
@@ -32,3 +32,28 @@ func OptLog(log logger.Log) SuiteOption {
 		s.Log = log
 	}
 }
+
+// OptHistory enables history mode, recording applied groups in a history table.
+//
+// In history mode every group must have a unique name; see `OptGroupName`.
+func OptHistory(options ...HistoryOption) SuiteOption {
+	return func(s *Suite) {
+		s.History = NewHistory(options...)
+	}
+}
+
+// OptDryRun sets the suite to evaluate guards and write the statements it would execute as events, rather than executing them.
+func OptDryRun() SuiteOption {
+	return func(s *Suite) {
+		s.DryRun = true
+	}
+}
+
+// OptCheck sets the suite to run within a single transaction that is always rolled back.
+//
+// Groups that skip transactions cannot be rolled back, and are skipped.
+func OptCheck() SuiteOption {
+	return func(s *Suite) {
+		s.Check = true
+	}
+}
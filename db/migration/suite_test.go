@@ -15,7 +15,9 @@ import (
 
 	"github.com/blend/go-sdk/assert"
 	"github.com/blend/go-sdk/db"
+	"github.com/blend/go-sdk/ex"
 	"github.com/blend/go-sdk/logger"
+	"github.com/blend/go-sdk/stringutil"
 )
 
 func TestSuite_Apply(t *testing.T) {
@@ -69,6 +71,107 @@ func TestSuite_ApplyFails(t *testing.T) {
 	a.Equal(6, tot)
 }
 
+func createRollbackTestGroups(tableName string) []*Group {
+	return []*Group{
+		NewGroup(
+			OptGroupName("0001_create"),
+			OptGroupActions(Statements(fmt.Sprintf("CREATE TABLE %s (id serial not null primary key)", tableName))),
+			OptGroupRollback(Statements(fmt.Sprintf("DROP TABLE %s", tableName))),
+		),
+		NewGroup(
+			OptGroupName("0002_alter"),
+			OptGroupActions(Statements(fmt.Sprintf("ALTER TABLE %s ADD COLUMN name varchar(32)", tableName))),
+			OptGroupRollback(Statements(fmt.Sprintf("ALTER TABLE %s DROP COLUMN name", tableName))),
+		),
+	}
+}
+
+func TestSuite_Rollback(t *testing.T) {
+	a := assert.New(t)
+	historyTable := fmt.Sprintf("test_history_%s", stringutil.Random(stringutil.LowerLetters, 10))
+	tableName := fmt.Sprintf("test_rollback_%s", stringutil.Random(stringutil.LowerLetters, 10))
+	defer func() {
+		a.Nil(db.IgnoreExecResult(defaultDB().Exec(fmt.Sprintf("DROP TABLE IF EXISTS %s", historyTable))))
+		a.Nil(db.IgnoreExecResult(defaultDB().Exec(fmt.Sprintf("DROP TABLE IF EXISTS %s", tableName))))
+	}()
+
+	s := New(OptLog(logger.None()), OptHistory(OptHistoryTable(historyTable)), OptGroups(createRollbackTestGroups(tableName)...))
+	a.Nil(s.Apply(context.Background(), defaultDB()))
+
+	err := s.Rollback(context.Background(), defaultDB(), "0003_missing")
+	a.Equal(ErrRollbackVersionNotFound, ex.ErrClass(err))
+
+	a.Nil(s.Rollback(context.Background(), defaultDB(), "0001_create"))
+	exists, err := PredicateColumnExists(context.Background(), defaultDB(), nil, tableName, "name")
+	a.Nil(err)
+	a.False(exists)
+	status, err := s.Status(context.Background(), defaultDB())
+	a.Nil(err)
+	a.Len(status.Applied(), 1)
+	a.Len(status.Pending(), 1)
+
+	a.Nil(s.Rollback(context.Background(), defaultDB(), ""))
+	exists, err = PredicateTableExists(context.Background(), defaultDB(), nil, tableName)
+	a.Nil(err)
+	a.False(exists)
+	status, err = s.Status(context.Background(), defaultDB())
+	a.Nil(err)
+	a.Len(status.Pending(), 2)
+}
+
+func TestSuite_RollbackHistoryUnset(t *testing.T) {
+	a := assert.New(t)
+	s := New(OptLog(logger.None()), OptGroups(createRollbackTestGroups("test_rollback")...))
+	err := s.Rollback(context.Background(), defaultDB(), "")
+	a.Equal(ErrHistoryUnset, ex.ErrClass(err))
+}
+
+func TestSuite_RollbackUnset(t *testing.T) {
+	a := assert.New(t)
+	historyTable := fmt.Sprintf("test_history_%s", stringutil.Random(stringutil.LowerLetters, 10))
+	tableName := fmt.Sprintf("test_rollback_%s", stringutil.Random(stringutil.LowerLetters, 10))
+	defer func() {
+		a.Nil(db.IgnoreExecResult(defaultDB().Exec(fmt.Sprintf("DROP TABLE IF EXISTS %s", historyTable))))
+		a.Nil(db.IgnoreExecResult(defaultDB().Exec(fmt.Sprintf("DROP TABLE IF EXISTS %s", tableName))))
+	}()
+
+	groups := createRollbackTestGroups(tableName)
+	groups[0].RollbackActions = nil
+	s := New(OptLog(logger.None()), OptHistory(OptHistoryTable(historyTable)), OptGroups(groups...))
+	a.Nil(s.Apply(context.Background(), defaultDB()))
+
+	err := s.Rollback(context.Background(), defaultDB(), "")
+	a.Equal(ErrRollbackUnset, ex.ErrClass(err))
+	// nothing is rolled back if any group cannot be rolled back.
+	exists, err := PredicateColumnExists(context.Background(), defaultDB(), nil, tableName, "name")
+	a.Nil(err)
+	a.True(exists)
+}
+
+func TestSuite_Check(t *testing.T) {
+	a := assert.New(t)
+	historyTable := fmt.Sprintf("test_history_%s", stringutil.Random(stringutil.LowerLetters, 10))
+	tableName := fmt.Sprintf("test_check_%s", stringutil.Random(stringutil.LowerLetters, 10))
+	defer func() {
+		a.Nil(db.IgnoreExecResult(defaultDB().Exec(fmt.Sprintf("DROP TABLE IF EXISTS %s", historyTable))))
+		a.Nil(db.IgnoreExecResult(defaultDB().Exec(fmt.Sprintf("DROP TABLE IF EXISTS %s", tableName))))
+	}()
+
+	s := New(OptLog(logger.None()), OptCheck(), OptHistory(OptHistoryTable(historyTable)), OptGroups(createRollbackTestGroups(tableName)...))
+	a.Nil(s.Apply(context.Background(), defaultDB()))
+
+	exists, err := PredicateTableExists(context.Background(), defaultDB(), nil, tableName)
+	a.Nil(err)
+	a.False(exists)
+	exists, err = PredicateTableExists(context.Background(), defaultDB(), nil, historyTable)
+	a.Nil(err)
+	a.False(exists)
+
+	s = New(OptLog(logger.None()), OptCheck(), OptGroups(createRollbackTestGroups(tableName)...))
+	s.Groups[1].Actions = []Action{Statements("ALTER TABLE table_not_exists ADD COLUMN name varchar(32)")}
+	a.NotNil(s.Apply(context.Background(), defaultDB()))
+}
+
 func createTestMigrations(testSchemaName string) []*Group {
 	return []*Group{
 		NewGroupWithAction(
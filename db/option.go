@@ -49,6 +49,17 @@ func OptStatementInterceptor(interceptor StatementInterceptor) Option {
 	}
 }
 
+// OptQueryCache sets the query cache backend on the connection.
+//
+// Results are only cached for invocations with `OptCache`, but writes through the
+// connection always invalidate the results cached for the tables they write to.
+func OptQueryCache(queryCache QueryCache) Option {
+	return func(c *Connection) error {
+		c.QueryCache = queryCache
+		return nil
+	}
+}
+
 // OptConfig sets the config on a connection.
 func OptConfig(cfg Config) Option {
 	return func(c *Connection) error {
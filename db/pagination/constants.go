@@ -0,0 +1,40 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package pagination
+
+import (
+	"github.com/blend/go-sdk/db"
+	"github.com/blend/go-sdk/ex"
+)
+
+// Defaults
+const (
+	DefaultLimit = 20
+	MaxLimit     = 1000
+)
+
+// Cursor directions.
+const (
+	DirectionNext     = "next"
+	DirectionPrevious = "prev"
+)
+
+// Errors
+const (
+	ErrCursorInvalid        ex.Class = "pagination: cursor is invalid"
+	ErrKeyUnset             ex.Class = "pagination: cursor key is unset"
+	ErrOrdersUnset          ex.Class = "pagination: keyset orders are unset"
+	ErrQueryUnset           ex.Class = "pagination: query is unset"
+	ErrCollectionNotSlice   ex.Class = "pagination: collection is not a pointer to a slice"
+	ErrOrderColumnNotMapped ex.Class = "pagination: order column is not mapped on the collection type"
+)
+
+// Invoker is a type that can create invocations, e.g. a `*db.Connection`.
+type Invoker interface {
+	Invoke(...db.InvocationOption) *db.Invocation
+}
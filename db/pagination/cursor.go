@@ -0,0 +1,83 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package pagination
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+
+	"github.com/blend/go-sdk/crypto"
+	"github.com/blend/go-sdk/ex"
+)
+
+// Cursor is the position of a page boundary in a keyset.
+type Cursor struct {
+	// Direction is the direction to page from the position, either `next` or `prev`.
+	Direction string `json:"d"`
+	// Order identifies the keyset orders the cursor was created for.
+	Order string `json:"o"`
+	// Values are the values of the keyset order columns at the position.
+	Values []interface{} `json:"v"`
+}
+
+// Encode encodes the cursor as an opaque token signed with a given key.
+//
+// The token is the base64 encoded cursor json and its HMAC-SHA256 signature, separated by a `.`.
+func (c Cursor) Encode(key []byte) (string, error) {
+	if len(key) == 0 {
+		return "", ex.New(ErrKeyUnset)
+	}
+	payload, err := json.Marshal(c)
+	if err != nil {
+		return "", ex.New(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(payload) + "." + base64.RawURLEncoding.EncodeToString(crypto.HMAC256(key, payload)), nil
+}
+
+// DecodeCursor decodes a cursor token, verifying it was signed with a given key.
+//
+// Numeric values are decoded as `json.Number` so they are passed to the database unchanged.
+func DecodeCursor(key []byte, token string) (cursor Cursor, err error) {
+	if len(key) == 0 {
+		err = ex.New(ErrKeyUnset)
+		return
+	}
+	pieces := strings.Split(token, ".")
+	if len(pieces) != 2 {
+		err = ex.New(ErrCursorInvalid)
+		return
+	}
+	payload, decodeErr := base64.RawURLEncoding.DecodeString(pieces[0])
+	if decodeErr != nil {
+		err = ex.New(ErrCursorInvalid, ex.OptInner(decodeErr))
+		return
+	}
+	signature, decodeErr := base64.RawURLEncoding.DecodeString(pieces[1])
+	if decodeErr != nil {
+		err = ex.New(ErrCursorInvalid, ex.OptInner(decodeErr))
+		return
+	}
+	if !hmac.Equal(signature, crypto.HMAC256(key, payload)) {
+		err = ex.New(ErrCursorInvalid, ex.OptMessage("signature mismatch"))
+		return
+	}
+	decoder := json.NewDecoder(bytes.NewReader(payload))
+	decoder.UseNumber()
+	if decodeErr = decoder.Decode(&cursor); decodeErr != nil {
+		err = ex.New(ErrCursorInvalid, ex.OptInner(decodeErr))
+		return
+	}
+	if cursor.Direction != DirectionNext && cursor.Direction != DirectionPrevious {
+		err = ex.New(ErrCursorInvalid, ex.OptMessagef("direction: %s", cursor.Direction))
+		return
+	}
+	return
+}
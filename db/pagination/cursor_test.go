@@ -0,0 +1,64 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package pagination
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/blend/go-sdk/assert"
+	"github.com/blend/go-sdk/ex"
+)
+
+func Test_Cursor_Encode(t *testing.T) {
+	its := assert.New(t)
+
+	key := []byte("this is only a test")
+	token, err := Cursor{Direction: DirectionNext, Order: "id ASC", Values: []interface{}{"foo", 12}}.Encode(key)
+	its.Nil(err)
+	its.NotContains(token, "foo", "the token should be opaque")
+
+	cursor, err := DecodeCursor(key, token)
+	its.Nil(err)
+	its.Equal(DirectionNext, cursor.Direction)
+	its.Equal("id ASC", cursor.Order)
+	its.Equal([]interface{}{"foo", json.Number("12")}, cursor.Values)
+
+	_, err = Cursor{}.Encode(nil)
+	its.True(ex.Is(err, ErrKeyUnset))
+	_, err = DecodeCursor(nil, token)
+	its.True(ex.Is(err, ErrKeyUnset))
+}
+
+func Test_DecodeCursor_invalid(t *testing.T) {
+	its := assert.New(t)
+
+	key := []byte("this is only a test")
+	token, err := Cursor{Direction: DirectionPrevious, Order: "id ASC", Values: []interface{}{1}}.Encode(key)
+	its.Nil(err)
+
+	_, err = DecodeCursor([]byte("another key"), token)
+	its.True(ex.Is(err, ErrCursorInvalid))
+
+	tampered, err := Cursor{Direction: DirectionPrevious, Order: "id ASC", Values: []interface{}{2}}.Encode([]byte("another key"))
+	its.Nil(err)
+	pieces := strings.Split(tampered, ".")
+	_, err = DecodeCursor(key, pieces[0]+"."+strings.Split(token, ".")[1])
+	its.True(ex.Is(err, ErrCursorInvalid), "a modified payload should fail verification")
+
+	_, err = DecodeCursor(key, "not-a-token")
+	its.True(ex.Is(err, ErrCursorInvalid))
+	_, err = DecodeCursor(key, "!!!.!!!")
+	its.True(ex.Is(err, ErrCursorInvalid))
+
+	badDirection, err := Cursor{Direction: "sideways"}.Encode(key)
+	its.Nil(err)
+	_, err = DecodeCursor(key, badDirection)
+	its.True(ex.Is(err, ErrCursorInvalid))
+}
@@ -0,0 +1,28 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+/*
+Package pagination provides keyset and offset pagination for select statements built with `db.SelectBuilder`.
+
+Keyset pagination pages through results ordered by one or more columns using opaque cursor tokens
+that are signed so they cannot be tampered with:
+
+	keyset := pagination.Keyset{
+		Key:    cursorKey,
+		Orders: []pagination.Order{pagination.Desc("created_utc"), pagination.Desc("id")},
+		Limit:  params.Limit,
+	}
+	var posts []Post
+	page, err := keyset.Page(conn, db.SelectFrom(Post{}).Where(db.Eq("team_id", teamID)), params.Cursor, &posts)
+
+The returned page includes the `Next` and `Previous` cursors to fetch the adjacent pages with.
+
+Offset pagination pages with `LIMIT` and `OFFSET`, optionally counting the total number of results.
+
+`ParamsFromCtx` reads the cursor, offset and limit from the query string of a `web.Ctx`.
+*/
+package pagination // import "github.com/blend/go-sdk/db/pagination"
@@ -0,0 +1,168 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package pagination
+
+import (
+	"reflect"
+
+	"github.com/blend/go-sdk/db"
+	"github.com/blend/go-sdk/ex"
+)
+
+// Keyset pages through the results of a query ordered by one or more columns.
+//
+// The orders should identify rows uniquely (e.g. by ending with the primary key), and the order
+// columns should not be null, otherwise rows can be skipped or repeated between pages.
+type Keyset struct {
+	// Key is the key cursors are signed with.
+	Key []byte
+	// Orders are the ordered columns of the keyset.
+	Orders []Order
+	// Limit is the maximum number of results in a page.
+	Limit int
+}
+
+// LimitOrDefault returns the limit or a default.
+func (k Keyset) LimitOrDefault() int {
+	if k.Limit > 0 {
+		return k.Limit
+	}
+	return DefaultLimit
+}
+
+// Page is a page of keyset results.
+type Page struct {
+	// Next is the cursor for the next page, if there is one.
+	Next string `json:"next,omitempty"`
+	// Previous is the cursor for the previous page, if there is one.
+	Previous string `json:"previous,omitempty"`
+	// HasNext indicates if there is a next page.
+	HasNext bool `json:"hasNext"`
+	// HasPrevious indicates if there is a previous page.
+	HasPrevious bool `json:"hasPrevious"`
+}
+
+// Page reads a page of results for a query from a given cursor into a collection, which must be a pointer to a slice.
+//
+// An empty cursor reads the first page. The query should not have its own order by, limit or offset.
+func (k Keyset) Page(conn Invoker, query *db.SelectBuilder, cursor string, collection interface{}, options ...db.InvocationOption) (page Page, err error) {
+	if len(k.Orders) == 0 {
+		err = ex.New(ErrOrdersUnset)
+		return
+	}
+	if query == nil {
+		err = ex.New(ErrQueryUnset)
+		return
+	}
+	slice := reflect.ValueOf(collection)
+	if slice.Kind() != reflect.Ptr || slice.Elem().Kind() != reflect.Slice {
+		err = ex.New(ErrCollectionNotSlice)
+		return
+	}
+	slice = slice.Elem()
+
+	fingerprint := orderFingerprint(k.Orders)
+	var position Cursor
+	if cursor != "" {
+		if position, err = DecodeCursor(k.Key, cursor); err != nil {
+			return
+		}
+		if position.Order != fingerprint || len(position.Values) != len(k.Orders) {
+			err = ex.New(ErrCursorInvalid, ex.OptMessage("cursor does not match keyset orders"))
+			return
+		}
+	}
+	backward := position.Direction == DirectionPrevious
+
+	// page backward by reversing the orders, and then reversing the results.
+	orders := k.Orders
+	if backward {
+		orders = make([]Order, len(k.Orders))
+		for index, order := range k.Orders {
+			orders[index] = order.Reverse()
+		}
+	}
+
+	limit := k.LimitOrDefault()
+	paged := copySelect(query)
+	if cursor != "" {
+		paged.Where(keysetPredicate(orders, position.Values))
+	}
+	for _, order := range orders {
+		paged.OrderBy(order.String())
+	}
+	// read one extra row to determine if there are more results.
+	paged.Limit(limit + 1)
+
+	if err = conn.Invoke(options...).QueryBuilder(paged).OutMany(collection); err != nil {
+		return
+	}
+
+	hasMore := slice.Len() > limit
+	if hasMore {
+		slice.Set(slice.Slice(0, limit))
+	}
+	if backward {
+		reverseSlice(slice)
+		page.HasPrevious = hasMore
+		page.HasNext = true
+	} else {
+		page.HasNext = hasMore
+		page.HasPrevious = cursor != ""
+	}
+	if slice.Len() == 0 {
+		return
+	}
+	if page.HasNext {
+		if page.Next, err = k.cursor(DirectionNext, fingerprint, slice.Index(slice.Len()-1)); err != nil {
+			return
+		}
+	}
+	if page.HasPrevious {
+		if page.Previous, err = k.cursor(DirectionPrevious, fingerprint, slice.Index(0)); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// cursor returns an encoded cursor for the order column values of a result.
+func (k Keyset) cursor(direction, fingerprint string, result reflect.Value) (string, error) {
+	object := result.Interface()
+	lookup := db.Columns(object).Lookup()
+	values := make([]interface{}, len(k.Orders))
+	for index, order := range k.Orders {
+		col, ok := lookup[order.ColumnName()]
+		if !ok {
+			return "", ex.New(ErrOrderColumnNotMapped, ex.OptMessagef("column: %s", order.Column))
+		}
+		values[index] = col.GetValue(object)
+	}
+	return Cursor{Direction: direction, Order: fingerprint, Values: values}.Encode(k.Key)
+}
+
+// copySelect returns a copy of a select builder that can be modified without affecting the original.
+func copySelect(query *db.SelectBuilder) *db.SelectBuilder {
+	return &db.SelectBuilder{
+		Columns:    append([]string(nil), query.Columns...),
+		Table:      query.Table,
+		Alias:      query.Alias,
+		Joins:      append([]db.SelectJoin(nil), query.Joins...),
+		Predicates: append([]db.Predicate(nil), query.Predicates...),
+		Orders:     append([]string(nil), query.Orders...),
+		LimitCount: query.LimitCount,
+		OffsetRows: query.OffsetRows,
+	}
+}
+
+func reverseSlice(slice reflect.Value) {
+	swap := reflect.Swapper(slice.Interface())
+	for left, right := 0, slice.Len()-1; left < right; left, right = left+1, right-1 {
+		swap(left, right)
+	}
+}
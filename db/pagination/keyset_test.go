@@ -0,0 +1,157 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package pagination
+
+import (
+	"database/sql"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/blend/go-sdk/assert"
+	"github.com/blend/go-sdk/db"
+	"github.com/blend/go-sdk/ex"
+)
+
+type paginationTestObj struct {
+	ID         int       `db:"id,pk"`
+	Name       string    `db:"name"`
+	CreatedUTC time.Time `db:"created_utc"`
+}
+
+func (paginationTestObj) TableName() string {
+	return "pagination_test_obj"
+}
+
+// paginationTestTx returns a transaction with a seeded table that is rolled back when the test completes.
+//
+// Every pair of objects shares a created timestamp so that pages have to break ties on the id.
+func paginationTestTx(t *testing.T, count int) *sql.Tx {
+	t.Helper()
+	tx, err := defaultDB().Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = tx.Rollback() })
+	if _, err = defaultDB().Invoke(db.OptTx(tx)).Exec("CREATE TABLE pagination_test_obj (id int not null primary key, name varchar(255), created_utc timestamp not null)"); err != nil {
+		t.Fatal(err)
+	}
+	start := time.Date(2022, 01, 01, 12, 0, 0, 0, time.UTC)
+	for x := 1; x <= count; x++ {
+		obj := paginationTestObj{ID: x, Name: fmt.Sprintf("object-%d", x), CreatedUTC: start.Add(time.Duration(x/2) * time.Minute)}
+		if err = defaultDB().Invoke(db.OptTx(tx)).Create(&obj); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return tx
+}
+
+func paginationTestIDs(objs []paginationTestObj) (ids []int) {
+	for _, obj := range objs {
+		ids = append(ids, obj.ID)
+	}
+	return
+}
+
+func Test_Keyset_Page(t *testing.T) {
+	its := assert.New(t)
+	tx := paginationTestTx(t, 7)
+
+	keyset := Keyset{
+		Key:    []byte("this is only a test"),
+		Orders: []Order{Desc("created_utc"), Desc("id")},
+		Limit:  3,
+	}
+	query := db.SelectFrom(paginationTestObj{})
+
+	var objs []paginationTestObj
+	page, err := keyset.Page(defaultDB(), query, "", &objs, db.OptTx(tx))
+	its.Nil(err)
+	its.Equal([]int{7, 6, 5}, paginationTestIDs(objs))
+	its.True(page.HasNext)
+	its.False(page.HasPrevious)
+	its.Empty(page.Previous)
+
+	objs = nil
+	page, err = keyset.Page(defaultDB(), query, page.Next, &objs, db.OptTx(tx))
+	its.Nil(err)
+	its.Equal([]int{4, 3, 2}, paginationTestIDs(objs))
+	its.True(page.HasNext)
+	its.True(page.HasPrevious)
+	previous := page.Previous
+
+	objs = nil
+	page, err = keyset.Page(defaultDB(), query, page.Next, &objs, db.OptTx(tx))
+	its.Nil(err)
+	its.Equal([]int{1}, paginationTestIDs(objs))
+	its.False(page.HasNext)
+	its.Empty(page.Next)
+	its.True(page.HasPrevious)
+
+	objs = nil
+	page, err = keyset.Page(defaultDB(), query, page.Previous, &objs, db.OptTx(tx))
+	its.Nil(err)
+	its.Equal([]int{4, 3, 2}, paginationTestIDs(objs))
+	its.True(page.HasNext)
+	its.True(page.HasPrevious)
+
+	objs = nil
+	page, err = keyset.Page(defaultDB(), query, previous, &objs, db.OptTx(tx))
+	its.Nil(err)
+	its.Equal([]int{7, 6, 5}, paginationTestIDs(objs))
+	its.True(page.HasNext)
+	its.False(page.HasPrevious)
+	its.Empty(page.Previous)
+
+	// the query itself should not be modified by paging.
+	its.Empty(query.Orders)
+	its.Empty(query.Predicates)
+}
+
+func Test_Keyset_Page_filtered(t *testing.T) {
+	its := assert.New(t)
+	tx := paginationTestTx(t, 10)
+
+	keyset := Keyset{Key: []byte("this is only a test"), Orders: []Order{Asc("id")}, Limit: 2}
+	query := db.SelectFrom(paginationTestObj{}).Where(db.Gt("id", 5))
+
+	var objs []paginationTestObj
+	page, err := keyset.Page(defaultDB(), query, "", &objs, db.OptTx(tx))
+	its.Nil(err)
+	its.Equal([]int{6, 7}, paginationTestIDs(objs))
+
+	objs = nil
+	_, err = keyset.Page(defaultDB(), query, page.Next, &objs, db.OptTx(tx))
+	its.Nil(err)
+	its.Equal([]int{8, 9}, paginationTestIDs(objs))
+}
+
+func Test_Keyset_Page_errors(t *testing.T) {
+	its := assert.New(t)
+
+	var objs []paginationTestObj
+	query := db.SelectFrom(paginationTestObj{})
+
+	_, err := Keyset{Key: []byte("key")}.Page(defaultDB(), query, "", &objs)
+	its.True(ex.Is(err, ErrOrdersUnset))
+
+	keyset := Keyset{Key: []byte("key"), Orders: []Order{Asc("id")}}
+	_, err = keyset.Page(defaultDB(), nil, "", &objs)
+	its.True(ex.Is(err, ErrQueryUnset))
+
+	_, err = keyset.Page(defaultDB(), query, "", objs)
+	its.True(ex.Is(err, ErrCollectionNotSlice))
+
+	_, err = keyset.Page(defaultDB(), query, "garbage", &objs)
+	its.True(ex.Is(err, ErrCursorInvalid))
+
+	otherOrder, err := Cursor{Direction: DirectionNext, Order: "id DESC", Values: []interface{}{1}}.Encode(keyset.Key)
+	its.Nil(err)
+	_, err = keyset.Page(defaultDB(), query, otherOrder, &objs)
+	its.True(ex.Is(err, ErrCursorInvalid), "cursors for a different order should be rejected")
+}
@@ -0,0 +1,37 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package pagination
+
+import (
+	"os"
+	"testing"
+
+	"github.com/blend/go-sdk/db"
+	"github.com/blend/go-sdk/logger"
+)
+
+func TestMain(m *testing.M) {
+	conn, err := db.Open(db.New(
+		db.OptConfigFromEnv(),
+		db.OptSSLMode(db.SSLModeDisable),
+	))
+	if err != nil {
+		logger.FatalExit(err)
+	}
+	defaultConnection = conn
+	defer conn.Close()
+	os.Exit(m.Run())
+}
+
+var (
+	defaultConnection *db.Connection
+)
+
+func defaultDB() *db.Connection {
+	return defaultConnection
+}
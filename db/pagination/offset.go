@@ -0,0 +1,109 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package pagination
+
+import (
+	"reflect"
+
+	"github.com/blend/go-sdk/db"
+	"github.com/blend/go-sdk/ex"
+)
+
+// Offset pages through the results of a query with `LIMIT` and `OFFSET`.
+//
+// Offset pagination allows jumping to arbitrary pages, but gets slower for later pages
+// and can skip or repeat rows if rows are added or removed between requests.
+type Offset struct {
+	// Limit is the maximum number of results in a page.
+	Limit int
+	// Count determines if the total number of results is counted with a separate query.
+	Count bool
+}
+
+// LimitOrDefault returns the limit or a default.
+func (o Offset) LimitOrDefault() int {
+	if o.Limit > 0 {
+		return o.Limit
+	}
+	return DefaultLimit
+}
+
+// OffsetPage is a page of offset results.
+type OffsetPage struct {
+	// Offset is the number of results skipped before the page.
+	Offset int `json:"offset"`
+	// Limit is the maximum number of results in the page.
+	Limit int `json:"limit"`
+	// HasNext indicates if there is a next page.
+	HasNext bool `json:"hasNext"`
+	// Total is the total number of results, if counted.
+	Total *int64 `json:"total,omitempty"`
+}
+
+// Page reads a page of results for a query starting at an offset into a collection, which must be a pointer to a slice.
+//
+// The query should have an order by so that pages are stable, and should not have its own limit or offset.
+func (o Offset) Page(conn Invoker, query *db.SelectBuilder, offset int, collection interface{}, options ...db.InvocationOption) (page OffsetPage, err error) {
+	if query == nil {
+		err = ex.New(ErrQueryUnset)
+		return
+	}
+	slice := reflect.ValueOf(collection)
+	if slice.Kind() != reflect.Ptr || slice.Elem().Kind() != reflect.Slice {
+		err = ex.New(ErrCollectionNotSlice)
+		return
+	}
+	slice = slice.Elem()
+	if offset < 0 {
+		offset = 0
+	}
+
+	page.Offset = offset
+	page.Limit = o.LimitOrDefault()
+
+	// read one extra row to determine if there are more results.
+	paged := copySelect(query).Limit(page.Limit + 1).Offset(offset)
+	if err = conn.Invoke(options...).QueryBuilder(paged).OutMany(collection); err != nil {
+		return
+	}
+	if page.HasNext = slice.Len() > page.Limit; page.HasNext {
+		slice.Set(slice.Slice(0, page.Limit))
+	}
+
+	if o.Count {
+		var total int64
+		if _, err = conn.Invoke(options...).QueryBuilder(countBuilder{Query: query}).Scan(&total); err != nil {
+			return
+		}
+		page.Total = &total
+	}
+	return
+}
+
+// countBuilder builds a statement that counts the results of a select statement.
+type countBuilder struct {
+	Query *db.SelectBuilder
+}
+
+// Label implements db.Builder.
+func (cb countBuilder) Label() string {
+	return cb.Query.Table + "_count"
+}
+
+// Build implements db.Builder.
+func (cb countBuilder) Build(dialect db.Dialect) (statement string, args []interface{}, err error) {
+	counted := copySelect(cb.Query)
+	counted.Orders = nil
+	counted.LimitCount = nil
+	counted.OffsetRows = nil
+	if statement, args, err = counted.Build(dialect); err != nil {
+		return
+	}
+	statement = "SELECT count(*) FROM (" + statement + ") AS pagination_count"
+	return
+}
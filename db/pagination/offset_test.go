@@ -0,0 +1,50 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package pagination
+
+import (
+	"testing"
+
+	"github.com/blend/go-sdk/assert"
+	"github.com/blend/go-sdk/db"
+)
+
+func Test_Offset_Page(t *testing.T) {
+	its := assert.New(t)
+	tx := paginationTestTx(t, 7)
+
+	query := db.SelectFrom(paginationTestObj{}).Where(db.Gt("id", 1)).OrderBy("id ASC")
+
+	var objs []paginationTestObj
+	page, err := Offset{Limit: 4, Count: true}.Page(defaultDB(), query, 0, &objs, db.OptTx(tx))
+	its.Nil(err)
+	its.Equal([]int{2, 3, 4, 5}, paginationTestIDs(objs))
+	its.True(page.HasNext)
+	its.Equal(4, page.Limit)
+	its.NotNil(page.Total)
+	its.Equal(6, *page.Total)
+
+	objs = nil
+	page, err = Offset{Limit: 4}.Page(defaultDB(), query, 4, &objs, db.OptTx(tx))
+	its.Nil(err)
+	its.Equal([]int{6, 7}, paginationTestIDs(objs))
+	its.False(page.HasNext)
+	its.Equal(4, page.Offset)
+	its.Nil(page.Total)
+}
+
+func Test_countBuilder(t *testing.T) {
+	its := assert.New(t)
+
+	cb := countBuilder{Query: db.Select("id").From("posts").Where(db.Eq("team_id", 1)).OrderBy("id").Limit(10)}
+	its.Equal("posts_count", cb.Label())
+	statement, args, err := cb.Build(db.DialectPostgres)
+	its.Nil(err)
+	its.Equal("SELECT count(*) FROM (SELECT id FROM posts WHERE team_id = $1) AS pagination_count", statement)
+	its.Equal([]interface{}{1}, args)
+}
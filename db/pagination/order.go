@@ -0,0 +1,87 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package pagination
+
+import (
+	"strings"
+
+	"github.com/blend/go-sdk/db"
+)
+
+// Asc returns an ascending order on a column.
+func Asc(column string) Order {
+	return Order{Column: column}
+}
+
+// Desc returns a descending order on a column.
+func Desc(column string) Order {
+	return Order{Column: column, Descending: true}
+}
+
+// Order is an ordered column of a keyset.
+//
+// The column can be qualified with a table alias (e.g. `p.created_utc`); the
+// unqualified column name is used to read the cursor value from results.
+type Order struct {
+	Column     string
+	Descending bool
+}
+
+// String returns the order by expression for the order.
+func (o Order) String() string {
+	if o.Descending {
+		return o.Column + " DESC"
+	}
+	return o.Column + " ASC"
+}
+
+// Reverse returns the order with the opposite direction.
+func (o Order) Reverse() Order {
+	return Order{Column: o.Column, Descending: !o.Descending}
+}
+
+// ColumnName returns the column name without any table alias.
+func (o Order) ColumnName() string {
+	if index := strings.LastIndex(o.Column, "."); index >= 0 {
+		return o.Column[index+1:]
+	}
+	return o.Column
+}
+
+// after returns a predicate that the column sorts after a value in the order.
+func (o Order) after(value interface{}) db.Predicate {
+	if o.Descending {
+		return db.Lt(o.Column, value)
+	}
+	return db.Gt(o.Column, value)
+}
+
+// keysetPredicate returns the predicate that rows sort after a set of values for a set of orders, i.e. for (a ASC, b DESC):
+//
+//	(a > $1 OR (a = $1 AND b < $2))
+func keysetPredicate(orders []Order, values []interface{}) db.Predicate {
+	var disjuncts []db.Predicate
+	for index, order := range orders {
+		var conjuncts []db.Predicate
+		for previous := 0; previous < index; previous++ {
+			conjuncts = append(conjuncts, db.Eq(orders[previous].Column, values[previous]))
+		}
+		conjuncts = append(conjuncts, order.after(values[index]))
+		disjuncts = append(disjuncts, db.And(conjuncts...))
+	}
+	return db.Or(disjuncts...)
+}
+
+// orderFingerprint returns a string identifying a set of orders, used to reject cursors for a different order.
+func orderFingerprint(orders []Order) string {
+	expressions := make([]string, len(orders))
+	for index, order := range orders {
+		expressions[index] = order.String()
+	}
+	return strings.Join(expressions, ",")
+}
@@ -0,0 +1,36 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package pagination
+
+import (
+	"testing"
+
+	"github.com/blend/go-sdk/assert"
+	"github.com/blend/go-sdk/db"
+)
+
+func Test_Order(t *testing.T) {
+	its := assert.New(t)
+
+	its.Equal("p.created_utc DESC", Desc("p.created_utc").String())
+	its.Equal("p.created_utc ASC", Desc("p.created_utc").Reverse().String())
+	its.Equal("created_utc", Desc("p.created_utc").ColumnName())
+	its.Equal("id", Asc("id").ColumnName())
+	its.Equal("created_utc DESC,id ASC", orderFingerprint([]Order{Desc("created_utc"), Asc("id")}))
+}
+
+func Test_keysetPredicate(t *testing.T) {
+	its := assert.New(t)
+
+	statement, args, err := db.Select("*").From("posts").Where(
+		keysetPredicate([]Order{Desc("created_utc"), Asc("id")}, []interface{}{"2022-01-01", 5}),
+	).Build(db.DialectPostgres)
+	its.Nil(err)
+	its.Equal("SELECT * FROM posts WHERE (created_utc < $1 OR (created_utc = $2 AND id > $3))", statement)
+	its.Equal([]interface{}{"2022-01-01", "2022-01-01", 5}, args)
+}
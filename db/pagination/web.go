@@ -0,0 +1,60 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package pagination
+
+import (
+	"strconv"
+
+	"github.com/blend/go-sdk/web"
+)
+
+// Query string parameter names.
+const (
+	ParamCursor = "cursor"
+	ParamOffset = "offset"
+	ParamLimit  = "limit"
+)
+
+// Params are pagination parameters read from a request.
+type Params struct {
+	Cursor string
+	Offset int
+	Limit  int
+}
+
+// ParamsFromCtx reads the `cursor`, `offset` and `limit` query string parameters from a request.
+//
+// If the limit is unset it defaults to `defaultLimit`, and it is capped at `maxLimit`; zero values
+// use `DefaultLimit` and `MaxLimit` respectively. Invalid offsets and limits return a parameter
+// error that `web.IsErrBadRequest` reports as a bad request.
+func ParamsFromCtx(ctx *web.Ctx, defaultLimit, maxLimit int) (params Params, err error) {
+	if defaultLimit <= 0 {
+		defaultLimit = DefaultLimit
+	}
+	if maxLimit <= 0 {
+		maxLimit = MaxLimit
+	}
+	params.Cursor, _ = ctx.QueryValue(ParamCursor)
+	params.Limit = defaultLimit
+	if value, _ := ctx.QueryValue(ParamLimit); value != "" {
+		if params.Limit, err = strconv.Atoi(value); err != nil || params.Limit < 1 {
+			err = web.NewParameterInvalidError(ParamLimit, "must be a positive integer")
+			return
+		}
+	}
+	if params.Limit > maxLimit {
+		params.Limit = maxLimit
+	}
+	if value, _ := ctx.QueryValue(ParamOffset); value != "" {
+		if params.Offset, err = strconv.Atoi(value); err != nil || params.Offset < 0 {
+			err = web.NewParameterInvalidError(ParamOffset, "must be a non-negative integer")
+			return
+		}
+	}
+	return
+}
@@ -0,0 +1,41 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package pagination
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/blend/go-sdk/assert"
+	"github.com/blend/go-sdk/web"
+)
+
+func Test_ParamsFromCtx(t *testing.T) {
+	its := assert.New(t)
+
+	params, err := ParamsFromCtx(web.MockCtx(http.MethodGet, "/posts"), 0, 0)
+	its.Nil(err)
+	its.Equal(Params{Limit: DefaultLimit}, params)
+
+	params, err = ParamsFromCtx(web.MockCtx(http.MethodGet, "/posts",
+		web.OptCtxQueryValue(ParamCursor, "token"),
+		web.OptCtxQueryValue(ParamLimit, "50"),
+		web.OptCtxQueryValue(ParamOffset, "100"),
+	), 10, 25)
+	its.Nil(err)
+	its.Equal(Params{Cursor: "token", Limit: 25, Offset: 100}, params)
+
+	_, err = ParamsFromCtx(web.MockCtx(http.MethodGet, "/posts", web.OptCtxQueryValue(ParamLimit, "zero")), 10, 25)
+	its.True(web.IsErrBadRequest(err))
+
+	_, err = ParamsFromCtx(web.MockCtx(http.MethodGet, "/posts", web.OptCtxQueryValue(ParamLimit, "0")), 10, 25)
+	its.True(web.IsErrBadRequest(err))
+
+	_, err = ParamsFromCtx(web.MockCtx(http.MethodGet, "/posts", web.OptCtxQueryValue(ParamOffset, "-1")), 10, 25)
+	its.True(web.IsErrBadRequest(err))
+}
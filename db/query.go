@@ -24,6 +24,10 @@ type Query struct {
 	Statement  string
 	Err        error
 	Args       []interface{}
+
+	deferStart bool
+	cacheKey   string
+	tables     []string
 }
 
 // Do runs a given query, yielding the raw results.
@@ -86,7 +90,16 @@ func (q *Query) Scan(args ...interface{}) (found bool, err error) {
 // Out writes the query result to a single object via. reflection mapping. If there is more than one result, the first
 // result is mapped to to object, and ErrTooManyRows is returned. Out() will apply column values for any colums
 // in the row result to the object, potentially zeroing existing values out.
+//
+// If the invocation caches results (see `OptCache`), the result is read from the cache when possible.
 func (q *Query) Out(object interface{}) (found bool, err error) {
+	if q.deferStart {
+		var hit bool
+		if hit, found = q.cacheGet(object, false); hit {
+			return
+		}
+	}
+
 	var rows *sql.Rows
 	defer func() {
 		err = q.finish(recover(), err)
@@ -98,11 +111,24 @@ func (q *Query) Out(object interface{}) (found bool, err error) {
 		return
 	}
 	found, err = Out(rows, object)
+	if err == nil {
+		q.cacheSet(found, object)
+	}
 	return
 }
 
 // OutMany writes the query results to a slice of objects.
+//
+// If the invocation caches results (see `OptCache`), the results are read from the cache when possible.
 func (q *Query) OutMany(collection interface{}) (err error) {
+	var existing int
+	if q.deferStart {
+		if hit, _ := q.cacheGet(collection, true); hit {
+			return
+		}
+		existing = reflect.Indirect(reflect.ValueOf(collection)).Len()
+	}
+
 	var rows *sql.Rows
 	defer func() {
 		err = q.finish(recover(), err)
@@ -114,6 +140,11 @@ func (q *Query) OutMany(collection interface{}) (err error) {
 		return
 	}
 	err = OutMany(rows, collection)
+	if err == nil && q.cacheKey != "" {
+		// only cache the results of this query, as `OutMany` appends to the collection.
+		collectionValue := reflect.Indirect(reflect.ValueOf(collection))
+		q.cacheSet(true, collectionValue.Slice(existing, collectionValue.Len()).Interface())
+	}
 	return
 }
 
@@ -162,6 +193,10 @@ func (q *Query) rowsClose(rows *sql.Rows, err error) error {
 }
 
 func (q *Query) query() (rows *sql.Rows, err error) {
+	if q.deferStart {
+		q.deferStart = false
+		q.Statement, q.Err = q.Invocation.start(q.Statement)
+	}
 	// fast abort if there was an issue ahead of returning the query.
 	if q.Err != nil {
 		err = q.Err
@@ -179,6 +214,7 @@ func (q *Query) query() (rows *sql.Rows, err error) {
 }
 
 func (q *Query) finish(r interface{}, err error) error {
+	q.Invocation.invalidateCacheTablesOnSuccess(&err, q.tables...)
 	return q.Invocation.finish(q.Statement, r, nil, err)
 }
 
@@ -0,0 +1,325 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package db
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/gob"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/blend/go-sdk/cache"
+	"github.com/blend/go-sdk/uuid"
+)
+
+// Query cache event results.
+const (
+	QueryCacheHit        = "hit"
+	QueryCacheMiss       = "miss"
+	QueryCacheSet        = "set"
+	QueryCacheInvalidate = "invalidate"
+)
+
+// Query cache key prefixes.
+const (
+	QueryCacheKeyPrefix      = "db.query_cache.result:"
+	QueryCacheTableKeyPrefix = "db.query_cache.table:"
+)
+
+// QueryCache is a backend for cached query results, e.g. a `LocalQueryCache` or a redis client.
+//
+// A ttl of zero means the value does not expire.
+type QueryCache interface {
+	Get(ctx context.Context, key string) (value []byte, ok bool, err error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+}
+
+// NewLocalQueryCache returns a new query cache backed by a local cache.
+//
+// The local cache must be started (e.g. with `go lc.Start()`) for expired results to be swept.
+func NewLocalQueryCache(c cache.Cache) *LocalQueryCache {
+	return &LocalQueryCache{Cache: c}
+}
+
+// Assert LocalQueryCache implements QueryCache.
+var (
+	_ QueryCache = (*LocalQueryCache)(nil)
+)
+
+// LocalQueryCache is a query cache backed by a local cache, e.g. a `cache.LocalCache`.
+type LocalQueryCache struct {
+	Cache cache.Cache
+}
+
+type localQueryCacheValue struct {
+	Value   []byte
+	Expires time.Time
+}
+
+// Get implements QueryCache.
+func (lqc *LocalQueryCache) Get(_ context.Context, key string) ([]byte, bool, error) {
+	value, ok := lqc.Cache.Get(key)
+	if !ok {
+		return nil, false, nil
+	}
+	typed, ok := value.(localQueryCacheValue)
+	if !ok {
+		return nil, false, nil
+	}
+	// the local cache only removes expired values when it sweeps.
+	if !typed.Expires.IsZero() && time.Now().UTC().After(typed.Expires) {
+		return nil, false, nil
+	}
+	return typed.Value, true, nil
+}
+
+// Set implements QueryCache.
+func (lqc *LocalQueryCache) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	if ttl > 0 {
+		expires := time.Now().UTC().Add(ttl)
+		lqc.Cache.Set(key, localQueryCacheValue{Value: value, Expires: expires}, cache.OptValueExpires(expires))
+		return nil
+	}
+	lqc.Cache.Set(key, localQueryCacheValue{Value: value})
+	return nil
+}
+
+// --------------------------------------------------------------------------------
+// invocation helpers
+// --------------------------------------------------------------------------------
+
+// cacheable returns if query results should be read from and written to the cache.
+//
+// Results are never cached within a transaction, as they may include uncommitted writes.
+func (i *Invocation) cacheable() bool {
+	if i.QueryCache == nil || i.CacheTTL <= 0 {
+		return false
+	}
+	_, inTx := i.DB.(*sql.Tx)
+	return !inTx
+}
+
+// addCacheTable adds a table the cached results depend on.
+func (i *Invocation) addCacheTable(table string) {
+	if i.CacheTTL <= 0 {
+		return
+	}
+	if stringsContain(i.CacheTables, table) {
+		return
+	}
+	i.CacheTables = append(i.CacheTables, table)
+}
+
+// invalidateCacheTables invalidates the cached results that depend on the given tables.
+func (i *Invocation) invalidateCacheTables(tables ...string) {
+	if i.QueryCache == nil {
+		return
+	}
+	for _, table := range tables {
+		err := i.QueryCache.Set(i.Context, QueryCacheTableKeyPrefix+table, []byte(uuid.V4().String()), 0)
+		if err != nil {
+			i.triggerCacheEvent(QueryCacheInvalidate, []string{table}, err)
+		}
+	}
+}
+
+// invalidateCacheTablesOnSuccess invalidates tables if a write did not return an error.
+//
+// Writes defer it after they defer `finish`, so it runs before the invocation context is cancelled.
+//
+// Writes within a managed transaction (see `InTx`) also record the tables on the transaction so that they
+// are invalidated again after it commits; otherwise a concurrent reader could cache the rows from before
+// the commit under the table versions set by the write.
+func (i *Invocation) invalidateCacheTablesOnSuccess(err *error, tables ...string) {
+	if *err != nil {
+		return
+	}
+	i.invalidateCacheTables(tables...)
+	if _, inTx := i.DB.(*sql.Tx); inTx && i.QueryCache != nil {
+		if state := getTxState(i.Context); state != nil && state.CacheTables != nil {
+			state.CacheTables.add(tables...)
+		}
+	}
+}
+
+// txCacheTables are the tables written to within a managed transaction, which are
+// invalidated again once the transaction commits.
+type txCacheTables struct {
+	sync.Mutex
+	tables []string
+}
+
+func (tct *txCacheTables) add(tables ...string) {
+	tct.Lock()
+	defer tct.Unlock()
+	for _, table := range tables {
+		if !stringsContain(tct.tables, table) {
+			tct.tables = append(tct.tables, table)
+		}
+	}
+}
+
+func (tct *txCacheTables) all() []string {
+	tct.Lock()
+	defer tct.Unlock()
+	return append([]string(nil), tct.tables...)
+}
+
+// cacheTableVersions returns the current version of each table the cached results depend on.
+func (i *Invocation) cacheTableVersions() ([]string, error) {
+	versions := make([]string, len(i.CacheTables))
+	for index, table := range i.CacheTables {
+		key := QueryCacheTableKeyPrefix + table
+		version, ok, err := i.QueryCache.Get(i.Context, key)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			version = []byte(uuid.V4().String())
+			if err = i.QueryCache.Set(i.Context, key, version, 0); err != nil {
+				return nil, err
+			}
+		}
+		versions[index] = table + "@" + string(version)
+	}
+	return versions, nil
+}
+
+// cacheKey returns the cache key for a statement and its arguments.
+//
+// The key includes the version of each table the results depend on, so that
+// invalidating a table orphans (rather than removes) any results cached for it.
+func (i *Invocation) cacheKey(statement string, args []interface{}) (string, error) {
+	versions, err := i.cacheTableVersions()
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%s\n", statement)
+	for _, arg := range args {
+		fmt.Fprintf(hash, "%T:%v\n", arg, arg)
+	}
+	for _, version := range versions {
+		fmt.Fprintf(hash, "%s\n", version)
+	}
+	return QueryCacheKeyPrefix + i.Label + ":" + hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// triggerCacheEvent triggers a cache event if there is a logger.
+func (i *Invocation) triggerCacheEvent(result string, tables []string, err error) {
+	if i.Log == nil || IsSkipQueryLogging(i.Context) {
+		return
+	}
+	i.Log.TriggerContext(i.Context, CacheEvent{
+		Database: i.Config.DatabaseOrDefault(),
+		Engine:   i.Config.EngineOrDefault(),
+		Label:    i.Label,
+		Result:   result,
+		Tables:   tables,
+		Err:      err,
+	})
+}
+
+// --------------------------------------------------------------------------------
+// query helpers
+// --------------------------------------------------------------------------------
+
+// cacheGet reads cached results into the output, returning if there was a hit.
+//
+// Errors from the cache backend are not returned; they are triggered as events and treated as misses.
+func (q *Query) cacheGet(output interface{}, collection bool) (hit, found bool) {
+	i := q.Invocation
+	key, err := i.cacheKey(q.Statement, q.Args)
+	if err != nil {
+		i.triggerCacheEvent(QueryCacheMiss, i.CacheTables, err)
+		return
+	}
+	q.cacheKey = key
+	value, ok, err := i.QueryCache.Get(i.Context, key)
+	if err != nil || !ok {
+		i.triggerCacheEvent(QueryCacheMiss, i.CacheTables, err)
+		return
+	}
+	if found, err = decodeCached(value, output, collection); err != nil {
+		i.triggerCacheEvent(QueryCacheMiss, i.CacheTables, err)
+		return
+	}
+	hit = true
+	if i.Cancel != nil {
+		i.Cancel()
+	}
+	i.triggerCacheEvent(QueryCacheHit, i.CacheTables, nil)
+	return
+}
+
+// cacheSet writes results to the cache.
+func (q *Query) cacheSet(found bool, output interface{}) {
+	if q.cacheKey == "" {
+		return
+	}
+	i := q.Invocation
+	value, err := encodeCached(found, output)
+	if err == nil {
+		err = i.QueryCache.Set(i.Context, q.cacheKey, value, i.CacheTTL)
+	}
+	if err != nil {
+		i.triggerCacheEvent(QueryCacheSet, i.CacheTables, err)
+	}
+}
+
+func stringsContain(values []string, value string) bool {
+	for _, existing := range values {
+		if existing == value {
+			return true
+		}
+	}
+	return false
+}
+
+func encodeCached(found bool, output interface{}) ([]byte, error) {
+	buffer := new(bytes.Buffer)
+	encoder := gob.NewEncoder(buffer)
+	if err := encoder.Encode(found); err != nil {
+		return nil, err
+	}
+	if err := encoder.Encode(output); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+func decodeCached(value []byte, output interface{}, collection bool) (found bool, err error) {
+	decoder := gob.NewDecoder(bytes.NewReader(value))
+	if err = decoder.Decode(&found); err != nil {
+		return
+	}
+	if collection {
+		// decode into a new slice and append it, as `OutMany` appends to the collection.
+		collectionValue := reflect.Indirect(reflect.ValueOf(output))
+		decoded := reflect.New(collectionValue.Type())
+		if err = decoder.Decode(decoded.Interface()); err != nil {
+			return
+		}
+		collectionValue.Set(reflect.AppendSlice(collectionValue, decoded.Elem()))
+		return
+	}
+	// gob does not write zero fields, so zero the output first.
+	if err = Zero(output); err != nil {
+		return
+	}
+	if found {
+		err = decoder.Decode(output)
+	}
+	return
+}
@@ -0,0 +1,319 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/blend/go-sdk/assert"
+	"github.com/blend/go-sdk/cache"
+	"github.com/blend/go-sdk/uuid"
+)
+
+func Test_LocalQueryCache(t *testing.T) {
+	its := assert.New(t)
+
+	lqc := NewLocalQueryCache(cache.New())
+	value, ok, err := lqc.Get(context.TODO(), "foo")
+	its.Nil(err)
+	its.False(ok)
+	its.Nil(value)
+
+	its.Nil(lqc.Set(context.TODO(), "foo", []byte("bar"), 0))
+	value, ok, err = lqc.Get(context.TODO(), "foo")
+	its.Nil(err)
+	its.True(ok)
+	its.Equal("bar", string(value))
+
+	its.Nil(lqc.Set(context.TODO(), "expired", []byte("bar"), time.Nanosecond))
+	time.Sleep(time.Millisecond)
+	_, ok, err = lqc.Get(context.TODO(), "expired")
+	its.Nil(err)
+	its.False(ok)
+}
+
+func Test_encodeCached_decodeCached(t *testing.T) {
+	its := assert.New(t)
+
+	obj := benchObj{ID: 1, UUID: uuid.V4().String(), Name: "foo", Amount: 3.14, Pending: true}
+	value, err := encodeCached(true, obj)
+	its.Nil(err)
+
+	decoded := benchObj{Category: "should be zeroed"}
+	found, err := decodeCached(value, &decoded, false)
+	its.Nil(err)
+	its.True(found)
+	its.Equal(obj.ID, decoded.ID)
+	its.Equal(obj.UUID, decoded.UUID)
+	its.Equal(obj.Name, decoded.Name)
+	its.Equal(obj.Amount, decoded.Amount)
+	its.True(decoded.Pending)
+	its.Empty(decoded.Category)
+
+	value, err = encodeCached(false, benchObj{})
+	its.Nil(err)
+	decoded = benchObj{Name: "should be zeroed"}
+	found, err = decodeCached(value, &decoded, false)
+	its.Nil(err)
+	its.False(found)
+	its.Empty(decoded.Name)
+
+	objs := []benchObj{{ID: 2, Name: "bar"}, {ID: 3, Name: "baz"}}
+	value, err = encodeCached(true, objs)
+	its.Nil(err)
+	decodedObjs := []benchObj{{ID: 1, Name: "foo"}}
+	_, err = decodeCached(value, &decodedObjs, true)
+	its.Nil(err)
+	its.Len(decodedObjs, 3)
+	its.Equal("foo", decodedObjs[0].Name)
+	its.Equal("bar", decodedObjs[1].Name)
+	its.Equal("baz", decodedObjs[2].Name)
+}
+
+func Test_Invocation_cacheable(t *testing.T) {
+	its := assert.New(t)
+
+	its.False((&Invocation{}).cacheable())
+	its.False((&Invocation{QueryCache: NewLocalQueryCache(cache.New())}).cacheable())
+	its.False((&Invocation{QueryCache: NewLocalQueryCache(cache.New()), CacheTTL: time.Minute, DB: &sql.Tx{}}).cacheable())
+	its.True((&Invocation{QueryCache: NewLocalQueryCache(cache.New()), CacheTTL: time.Minute}).cacheable())
+}
+
+func Test_Invocation_cacheKey(t *testing.T) {
+	its := assert.New(t)
+
+	i := &Invocation{
+		Context:     context.TODO(),
+		Label:       "test",
+		QueryCache:  NewLocalQueryCache(cache.New()),
+		CacheTTL:    time.Minute,
+		CacheTables: []string{"foo", "bar"},
+	}
+
+	key, err := i.cacheKey("select * from foo where id = $1", []interface{}{1})
+	its.Nil(err)
+	its.HasPrefix(key, QueryCacheKeyPrefix+"test:")
+
+	same, err := i.cacheKey("select * from foo where id = $1", []interface{}{1})
+	its.Nil(err)
+	its.Equal(key, same)
+
+	otherArgs, err := i.cacheKey("select * from foo where id = $1", []interface{}{"1"})
+	its.Nil(err)
+	its.NotEqual(key, otherArgs)
+
+	i.invalidateCacheTables("bar")
+	invalidated, err := i.cacheKey("select * from foo where id = $1", []interface{}{1})
+	its.Nil(err)
+	its.NotEqual(key, invalidated)
+
+	i.invalidateCacheTables("not_a_cache_table")
+	unchanged, err := i.cacheKey("select * from foo where id = $1", []interface{}{1})
+	its.Nil(err)
+	its.Equal(invalidated, unchanged)
+}
+
+func Test_Invocation_addCacheTable(t *testing.T) {
+	its := assert.New(t)
+
+	i := &Invocation{}
+	i.addCacheTable("foo")
+	its.Empty(i.CacheTables)
+
+	i.CacheTTL = time.Minute
+	i.addCacheTable("foo")
+	i.addCacheTable("foo")
+	i.addCacheTable("bar")
+	its.Equal([]string{"foo", "bar"}, i.CacheTables)
+}
+
+func Test_Invocation_invalidateCacheTablesOnSuccess_tx(t *testing.T) {
+	its := assert.New(t)
+
+	queryCache := NewLocalQueryCache(cache.New())
+	state := &txState{Tx: &sql.Tx{}, CacheTables: new(txCacheTables)}
+	i := &Invocation{
+		Context:     withTxState(context.TODO(), state),
+		DB:          state.Tx,
+		QueryCache:  queryCache,
+		CacheTTL:    time.Minute,
+		CacheTables: []string{"foo"},
+	}
+	before, err := i.cacheTableVersions()
+	its.Nil(err)
+
+	var writeErr error = ErrConfigUnset
+	i.invalidateCacheTablesOnSuccess(&writeErr, "foo")
+	its.Empty(state.CacheTables.all())
+
+	writeErr = nil
+	i.invalidateCacheTablesOnSuccess(&writeErr, "foo")
+	i.invalidateCacheTablesOnSuccess(&writeErr, "foo", "bar")
+	its.Equal([]string{"foo", "bar"}, state.CacheTables.all())
+
+	// the tables are also invalidated when the write succeeds.
+	after, err := i.cacheTableVersions()
+	its.Nil(err)
+	its.NotEqual(before, after)
+}
+
+type queryCacheTestObj struct {
+	ID       int    `db:"id,pk,auto"`
+	Name     string `db:"name"`
+	Category string `db:"category"`
+}
+
+func (queryCacheTestObj) TableName() string {
+	return "query_cache_test_object"
+}
+
+func Test_Invocation_QueryCache(t *testing.T) {
+	its := assert.New(t)
+
+	queryCache := NewLocalQueryCache(cache.New())
+	conn, err := OpenTestConnection(OptQueryCache(queryCache))
+	its.Nil(err)
+	defer func() { _ = conn.Close() }()
+
+	its.Nil(IgnoreExecResult(conn.Exec(`DROP TABLE IF EXISTS query_cache_test_object`)))
+	its.Nil(IgnoreExecResult(conn.Exec(`CREATE TABLE query_cache_test_object (id serial not null primary key, name text, category text)`)))
+	defer func() { _ = IgnoreExecResult(conn.Exec(`DROP TABLE IF EXISTS query_cache_test_object`)) }()
+
+	obj := queryCacheTestObj{Name: "query_cache", Category: "before"}
+	its.Nil(conn.Invoke().Create(&obj))
+
+	var cached queryCacheTestObj
+	found, err := conn.Invoke(OptCache(time.Minute)).Get(&cached, obj.ID)
+	its.Nil(err)
+	its.True(found)
+	its.Equal("before", cached.Category)
+
+	// writes that do not go through the invocation helpers do not invalidate the cache.
+	its.Nil(IgnoreExecResult(conn.Exec(`UPDATE query_cache_test_object SET category = 'stale' WHERE id = $1`, obj.ID)))
+	cached = queryCacheTestObj{}
+	found, err = conn.Invoke(OptCache(time.Minute)).Get(&cached, obj.ID)
+	its.Nil(err)
+	its.True(found)
+	its.Equal("before", cached.Category)
+
+	var queried []queryCacheTestObj
+	its.Nil(conn.Invoke(OptCache(time.Minute, "query_cache_test_object")).Query(`SELECT * FROM query_cache_test_object`).OutMany(&queried))
+	its.Len(queried, 1)
+	its.Equal("stale", queried[0].Category)
+
+	obj.Category = "after"
+	_, err = conn.Invoke().Update(&obj)
+	its.Nil(err)
+
+	cached = queryCacheTestObj{}
+	found, err = conn.Invoke(OptCache(time.Minute)).Get(&cached, obj.ID)
+	its.Nil(err)
+	its.True(found)
+	its.Equal("after", cached.Category)
+
+	queried = nil
+	its.Nil(conn.Invoke(OptCache(time.Minute, "query_cache_test_object")).Query(`SELECT * FROM query_cache_test_object`).OutMany(&queried))
+	its.Len(queried, 1)
+	its.Equal("after", queried[0].Category)
+}
+
+func Test_Invocation_QueryCache_writes(t *testing.T) {
+	its := assert.New(t)
+
+	queryCache := NewLocalQueryCache(cache.New())
+	conn, err := OpenTestConnection(OptQueryCache(queryCache))
+	its.Nil(err)
+	defer func() { _ = conn.Close() }()
+
+	its.Nil(IgnoreExecResult(conn.Exec(`DROP TABLE IF EXISTS query_cache_test_object`)))
+	its.Nil(IgnoreExecResult(conn.Exec(`CREATE TABLE query_cache_test_object (id serial not null primary key, name text, category text)`)))
+	defer func() { _ = IgnoreExecResult(conn.Exec(`DROP TABLE IF EXISTS query_cache_test_object`)) }()
+
+	all := func() (objects []queryCacheTestObj) {
+		its.Nil(conn.Invoke(OptCache(time.Minute)).All(&objects))
+		return
+	}
+	its.Empty(all())
+
+	copied, err := conn.Invoke().CopyMany([]queryCacheTestObj{{Name: "copied", Category: "before"}})
+	its.Nil(err)
+	its.Equal(1, copied)
+	objects := all()
+	its.Len(objects, 1)
+	its.Equal("before", objects[0].Category)
+
+	its.Nil(IgnoreExecResult(conn.Invoke().ExecBuilder(Update("query_cache_test_object").Set("category", "exec"))))
+	objects = all()
+	its.Len(objects, 1)
+	its.Equal("exec", objects[0].Category)
+
+	var returned []queryCacheTestObj
+	its.Nil(conn.Invoke(OptCache(time.Minute)).QueryBuilder(
+		InsertInto("query_cache_test_object").Column("name", "category").Values("inserted", "query").Returning("*"),
+	).OutMany(&returned))
+	its.Len(returned, 1)
+	its.Equal("inserted", returned[0].Name)
+	its.Len(all(), 2)
+
+	// the results of write builders are not cached.
+	returned = nil
+	its.Nil(conn.Invoke(OptCache(time.Minute)).QueryBuilder(
+		InsertInto("query_cache_test_object").Column("name", "category").Values("inserted", "query").Returning("*"),
+	).OutMany(&returned))
+	its.Len(returned, 1)
+	its.Len(all(), 3)
+}
+
+func Test_WriteBuilder_Tables(t *testing.T) {
+	its := assert.New(t)
+
+	its.Equal([]string{"foo"}, builderTables(InsertInto("foo")))
+	its.Equal([]string{"foo"}, builderTables(Update("foo")))
+	its.Equal([]string{"foo"}, builderTables(DeleteFrom("foo")))
+	its.Empty(builderTables(Select("*").From("foo")))
+}
+
+func Test_Invocation_QueryCache_inTx(t *testing.T) {
+	its := assert.New(t)
+
+	queryCache := NewLocalQueryCache(cache.New())
+	conn, err := OpenTestConnection(OptQueryCache(queryCache))
+	its.Nil(err)
+	defer func() { _ = conn.Close() }()
+
+	its.Nil(IgnoreExecResult(conn.Exec(`DROP TABLE IF EXISTS query_cache_test_object`)))
+	its.Nil(IgnoreExecResult(conn.Exec(`CREATE TABLE query_cache_test_object (id serial not null primary key, name text, category text)`)))
+	defer func() { _ = IgnoreExecResult(conn.Exec(`DROP TABLE IF EXISTS query_cache_test_object`)) }()
+
+	obj := queryCacheTestObj{Name: "query_cache", Category: "before"}
+	its.Nil(conn.Invoke().Create(&obj))
+
+	err = conn.InTx(context.Background(), func(tx *Invocation) error {
+		obj.Category = "after"
+		if _, err := tx.Update(&obj); err != nil {
+			return err
+		}
+		// a concurrent reader caches the committed row under the table version set by the write.
+		var cached queryCacheTestObj
+		found, err := conn.Invoke(OptCache(time.Minute)).Get(&cached, obj.ID)
+		its.Nil(err)
+		its.True(found)
+		its.Equal("before", cached.Category)
+		return nil
+	})
+	its.Nil(err)
+
+	var cached queryCacheTestObj
+	found, err := conn.Invoke(OptCache(time.Minute)).Get(&cached, obj.ID)
+	its.Nil(err)
+	its.True(found)
+	its.Equal("after", cached.Category)
+}
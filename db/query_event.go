@@ -90,6 +90,11 @@ func OptQueryEventLabel(label string) QueryEventOption {
 	return func(e *QueryEvent) { e.Label = label }
 }
 
+// OptQueryEventNode sets a field on the query event.
+func OptQueryEventNode(value string) QueryEventOption {
+	return func(e *QueryEvent) { e.Node = value }
+}
+
 // OptQueryEventElapsed sets a field on the query event.
 func OptQueryEventElapsed(value time.Duration) QueryEventOption {
 	return func(e *QueryEvent) { e.Elapsed = value }
@@ -106,6 +111,7 @@ type QueryEvent struct {
 	Engine   string
 	Username string
 	Label    string
+	Node     string
 	Body     string
 	Elapsed  time.Duration
 	Err      error
@@ -126,6 +132,10 @@ func (e QueryEvent) WriteText(tf logger.TextFormatter, wr io.Writer) {
 		fmt.Fprint(wr, "@")
 	}
 	fmt.Fprint(wr, tf.Colorize(e.Database, ansi.ColorLightWhite))
+	if len(e.Node) > 0 {
+		fmt.Fprint(wr, logger.Space)
+		fmt.Fprint(wr, tf.Colorize(e.Node, ansi.ColorLightWhite))
+	}
 	fmt.Fprint(wr, "]")
 
 	if len(e.Label) > 0 {
@@ -154,6 +164,7 @@ func (e QueryEvent) Decompose() map[string]interface{} {
 		"database": e.Database,
 		"username": e.Username,
 		"label":    e.Label,
+		"node":     e.Node,
 		"body":     e.Body,
 		"err":      e.Err,
 		"elapsed":  timeutil.Milliseconds(e.Elapsed),
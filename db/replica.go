@@ -0,0 +1,160 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"sync/atomic"
+
+	"github.com/blend/go-sdk/ex"
+)
+
+// Node names and replica balancers.
+const (
+	// NodePrimary is the node name for queries served by the primary.
+	NodePrimary = "primary"
+
+	// ReplicaBalancerRoundRobin balances reads across healthy replicas in turn.
+	ReplicaBalancerRoundRobin = "round_robin"
+	// ReplicaBalancerLeastLoaded balances reads to the healthy replica with the fewest connections in use.
+	ReplicaBalancerLeastLoaded = "least_loaded"
+)
+
+// Replica is a read replica connection pool.
+type Replica struct {
+	// Name is the node name of the replica, i.e. its `host:port`.
+	Name string
+	// Connection is the replica connection pool.
+	Connection *sql.DB
+
+	unhealthy int32
+}
+
+// IsHealthy returns if the replica passed its last health check.
+func (r *Replica) IsHealthy() bool {
+	return atomic.LoadInt32(&r.unhealthy) == 0
+}
+
+// SetHealthy sets if the replica is healthy.
+func (r *Replica) SetHealthy(healthy bool) {
+	if healthy {
+		atomic.StoreInt32(&r.unhealthy, 0)
+		return
+	}
+	atomic.StoreInt32(&r.unhealthy, 1)
+}
+
+// Check pings the replica and updates its health.
+func (r *Replica) Check(ctx context.Context) error {
+	err := r.Connection.PingContext(ctx)
+	r.SetHealthy(err == nil)
+	if err != nil {
+		return Error(err, ex.OptMessagef("replica: %s", r.Name))
+	}
+	return nil
+}
+
+// ReplicaSet is a set of read replicas that reads are balanced across.
+type ReplicaSet struct {
+	// Balancer is the replica balancer, either `round_robin` or `least_loaded`.
+	Balancer string
+	// Replicas are the replicas in the set.
+	Replicas []*Replica
+
+	counter uint32
+}
+
+// Next returns the next healthy replica to serve a read, or nil if there are no healthy replicas.
+func (rs *ReplicaSet) Next() *Replica {
+	if rs == nil || len(rs.Replicas) == 0 {
+		return nil
+	}
+	offset := int(atomic.AddUint32(&rs.counter, 1) - 1)
+	var selected *Replica
+	var selectedInUse int
+	for index := 0; index < len(rs.Replicas); index++ {
+		replica := rs.Replicas[(offset+index)%len(rs.Replicas)]
+		if !replica.IsHealthy() {
+			continue
+		}
+		if rs.Balancer != ReplicaBalancerLeastLoaded {
+			return replica
+		}
+		if inUse := replica.Connection.Stats().InUse; selected == nil || inUse < selectedInUse {
+			selected, selectedInUse = replica, inUse
+		}
+	}
+	return selected
+}
+
+// Check checks each replica, updating its health, and returns the errors of any unhealthy replicas.
+func (rs *ReplicaSet) Check(ctx context.Context) (err error) {
+	if rs == nil {
+		return nil
+	}
+	for _, replica := range rs.Replicas {
+		err = ex.Nest(err, replica.Check(ctx))
+	}
+	return
+}
+
+// Close closes the replica connection pools.
+func (rs *ReplicaSet) Close() (err error) {
+	if rs == nil {
+		return nil
+	}
+	for _, replica := range rs.Replicas {
+		err = ex.Nest(err, replica.Connection.Close())
+	}
+	return
+}
+
+// routeRead marks an invocation as read only, and routes it to a replica if
+// it is using the connection primary and has not been forced to the primary.
+//
+// Reads that cache their results (see `OptCache`) are not routed to replicas, as a lagging
+// replica could return rows from before a write that are then cached under the table
+// versions set by the write.
+func (i *Invocation) routeRead() {
+	i.readOnly = true
+	if i.Replicas == nil || i.DB == nil || i.DB != i.primary || i.ForcePrimary || i.cacheable() {
+		return
+	}
+	if IsForcePrimary(i.Context) || HasWritten(i.Context) {
+		return
+	}
+	if replica := i.Replicas.Next(); replica != nil {
+		i.DB = replica.Connection
+		i.Node = replica.Name
+	}
+}
+
+// isReadOnlyStatement returns if a statement is a plain select that can be served by a replica.
+func isReadOnlyStatement(statement string) bool {
+	normalized := strings.ToLower(strings.TrimSpace(statement))
+	if !strings.HasPrefix(normalized, "select") {
+		return false
+	}
+	for _, locking := range []string{"for update", "for no key update", "for share", "for key share"} {
+		if strings.Contains(normalized, locking) {
+			return false
+		}
+	}
+	return true
+}
+
+// replicaName returns the node name for a replica dsn.
+func replicaName(dsn string) (string, error) {
+	cfg, err := NewConfigFromDSN(dsn)
+	if err != nil {
+		return "", err
+	}
+	return cfg.HostOrDefault() + ":" + cfg.PortOrDefault(), nil
+}
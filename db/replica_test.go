@@ -0,0 +1,159 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/blend/go-sdk/assert"
+	"github.com/blend/go-sdk/cache"
+)
+
+// replicaTestSet returns a replica set of unopened connection pools; `sql.Open` does not connect.
+func replicaTestSet(t *testing.T, balancer string, names ...string) *ReplicaSet {
+	t.Helper()
+	rs := &ReplicaSet{Balancer: balancer}
+	for _, name := range names {
+		conn, err := sql.Open(DefaultEngine, "postgres://"+name+"/postgres")
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { _ = conn.Close() })
+		rs.Replicas = append(rs.Replicas, &Replica{Name: name, Connection: conn})
+	}
+	return rs
+}
+
+func Test_ReplicaSet_Next(t *testing.T) {
+	its := assert.New(t)
+
+	rs := replicaTestSet(t, ReplicaBalancerRoundRobin, "one:5432", "two:5432", "three:5432")
+	its.Equal("one:5432", rs.Next().Name)
+	its.Equal("two:5432", rs.Next().Name)
+	its.Equal("three:5432", rs.Next().Name)
+	its.Equal("one:5432", rs.Next().Name)
+
+	rs.Replicas[1].SetHealthy(false)
+	its.False(rs.Replicas[1].IsHealthy())
+	its.Equal("three:5432", rs.Next().Name, "unhealthy replicas should be skipped")
+	its.Equal("three:5432", rs.Next().Name)
+	its.Equal("one:5432", rs.Next().Name)
+
+	rs.Replicas[0].SetHealthy(false)
+	rs.Replicas[2].SetHealthy(false)
+	its.Nil(rs.Next())
+
+	var unset *ReplicaSet
+	its.Nil(unset.Next())
+	its.Nil(unset.Check(context.Background()))
+	its.Nil(unset.Close())
+}
+
+func Test_ReplicaSet_Next_leastLoaded(t *testing.T) {
+	its := assert.New(t)
+
+	rs := replicaTestSet(t, ReplicaBalancerLeastLoaded, "one:5432", "two:5432")
+	rs.Replicas[0].SetHealthy(false)
+	its.Equal("two:5432", rs.Next().Name)
+	its.Equal("two:5432", rs.Next().Name)
+}
+
+func Test_Invocation_routeRead(t *testing.T) {
+	its := assert.New(t)
+
+	rs := replicaTestSet(t, ReplicaBalancerRoundRobin, "replica:5432")
+	conn := &Connection{Connection: defaultDB().Connection, Config: defaultDB().Config, Replicas: rs}
+
+	i := conn.Invoke()
+	i.routeRead()
+	its.Equal(rs.Replicas[0].Connection, i.DB)
+	its.Equal("replica:5432", i.Node)
+
+	i = conn.Invoke(OptForcePrimary())
+	i.routeRead()
+	its.Equal(defaultDB().Connection, i.DB)
+
+	i = conn.Invoke(OptContext(WithForcePrimary(context.Background())))
+	i.routeRead()
+	its.Equal(defaultDB().Connection, i.DB)
+
+	cached := &Connection{Connection: defaultDB().Connection, Config: defaultDB().Config, Replicas: rs, QueryCache: NewLocalQueryCache(cache.New())}
+	i = cached.Invoke(OptCache(time.Minute))
+	i.routeRead()
+	its.Equal(defaultDB().Connection, i.DB, "cached reads should be routed to the primary")
+	i = cached.Invoke()
+	i.routeRead()
+	its.Equal(rs.Replicas[0].Connection, i.DB)
+
+	tx, err := defaultDB().Begin()
+	its.Nil(err)
+	defer func() { _ = tx.Rollback() }()
+	i = conn.Invoke(OptTx(tx))
+	i.routeRead()
+	its.Equal(tx, i.DB, "transactions should not be routed to replicas")
+
+	ctx := WithReadYourWrites(context.Background())
+	i = conn.Invoke(OptContext(ctx))
+	i.routeRead()
+	its.Equal("replica:5432", i.Node)
+	its.False(HasWritten(ctx))
+
+	_, err = conn.Invoke(OptContext(ctx)).start("UPDATE foo SET bar = 1")
+	its.Nil(err)
+	its.True(HasWritten(ctx))
+	i = conn.Invoke(OptContext(ctx))
+	i.routeRead()
+	its.Equal(defaultDB().Connection, i.DB, "reads after a write should be routed to the primary")
+}
+
+func Test_Connection_replicas(t *testing.T) {
+	its := assert.New(t)
+
+	cfg := defaultDB().Config
+	cfg.Replicas = []string{defaultDB().Config.CreateDSN()}
+	conn, err := Open(New(OptConfig(cfg)))
+	its.Nil(err)
+	defer conn.Close()
+	its.NotNil(conn.Replicas)
+	its.Len(conn.Replicas.Replicas, 1)
+	its.Nil(conn.Check(context.Background()))
+	its.True(conn.Replicas.Replicas[0].IsHealthy())
+
+	var nodes []string
+	i := conn.Invoke()
+	_, err = i.Query("select 1").Any()
+	its.Nil(err)
+	nodes = append(nodes, i.Node)
+
+	i = conn.Invoke()
+	_, err = i.Exec("select 1")
+	its.Nil(err)
+	nodes = append(nodes, i.Node)
+
+	i = conn.Invoke(OptForcePrimary())
+	_, err = i.Query("select 1").Any()
+	its.Nil(err)
+	nodes = append(nodes, i.Node)
+
+	replica := conn.Replicas.Replicas[0].Name
+	its.Equal([]string{replica, NodePrimary, NodePrimary}, nodes)
+}
+
+func Test_isReadOnlyStatement(t *testing.T) {
+	its := assert.New(t)
+
+	its.True(isReadOnlyStatement("SELECT * FROM foo"))
+	its.True(isReadOnlyStatement("\n\tselect 1"))
+	its.False(isReadOnlyStatement("SELECT * FROM foo FOR UPDATE"))
+	its.False(isReadOnlyStatement("select * from foo for share"))
+	its.False(isReadOnlyStatement("INSERT INTO foo (id) VALUES (1) RETURNING id"))
+	its.False(isReadOnlyStatement("WITH deleted AS (DELETE FROM foo RETURNING *) SELECT * FROM deleted"))
+}
@@ -0,0 +1,297 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/blend/go-sdk/ex"
+)
+
+// Transaction defaults.
+const (
+	// DefaultTxMaxRetries is the default number of times a transaction is retried after a retryable error.
+	DefaultTxMaxRetries = 3
+	// DefaultTxRetryBackoff is the default delay before the first retry; it doubles for each retry after.
+	DefaultTxRetryBackoff = 10 * time.Millisecond
+)
+
+// SQLSTATE codes.
+const (
+	// SQLStateSerializationFailure is returned when a serializable transaction conflicts with another transaction.
+	SQLStateSerializationFailure = "40001"
+	// SQLStateDeadlockDetected is returned when a transaction is aborted to break a deadlock.
+	SQLStateDeadlockDetected = "40P01"
+)
+
+// Transaction labels used for query events and traces.
+const (
+	LabelTxBegin               = "tx.begin"
+	LabelTxCommit              = "tx.commit"
+	LabelTxRollback            = "tx.rollback"
+	LabelTxSavepoint           = "tx.savepoint"
+	LabelTxReleaseSavepoint    = "tx.release_savepoint"
+	LabelTxRollbackToSavepoint = "tx.rollback_to_savepoint"
+)
+
+// Transaction statements.
+const (
+	StatementTxBegin    = "BEGIN"
+	StatementTxCommit   = "COMMIT"
+	StatementTxRollback = "ROLLBACK"
+
+	statementTxSavepointFormat  = "SAVEPOINT %s"
+	statementTxReleaseFormat    = "RELEASE SAVEPOINT %s"
+	statementTxRollbackToFormat = "ROLLBACK TO SAVEPOINT %s"
+)
+
+// DefaultTxRetryableSQLStates are the SQLSTATE codes that are retried by default.
+var DefaultTxRetryableSQLStates = []string{
+	SQLStateSerializationFailure,
+}
+
+// TxOptions are options for managed transactions.
+type TxOptions struct {
+	// Isolation is the transaction isolation level.
+	Isolation sql.IsolationLevel
+	// ReadOnly indicates the transaction is read only.
+	ReadOnly bool
+	// MaxRetries is the number of times the transaction is retried after a retryable error.
+	MaxRetries int
+	// RetryBackoff is the delay before the first retry; it doubles for each retry after.
+	RetryBackoff time.Duration
+	// RetryableSQLStates are the SQLSTATE codes that cause the transaction to be retried.
+	RetryableSQLStates []string
+}
+
+// TxOption mutates transaction options.
+type TxOption func(*TxOptions)
+
+// OptTxIsolation sets the transaction isolation level.
+func OptTxIsolation(isolation sql.IsolationLevel) TxOption {
+	return func(to *TxOptions) { to.Isolation = isolation }
+}
+
+// OptTxReadOnly sets if the transaction is read only.
+func OptTxReadOnly(readOnly bool) TxOption {
+	return func(to *TxOptions) { to.ReadOnly = readOnly }
+}
+
+// OptTxMaxRetries sets the number of times the transaction is retried after a retryable error.
+//
+// A value of 0 disables retries.
+func OptTxMaxRetries(maxRetries int) TxOption {
+	return func(to *TxOptions) { to.MaxRetries = maxRetries }
+}
+
+// OptTxRetryBackoff sets the delay before the first retry.
+func OptTxRetryBackoff(backoff time.Duration) TxOption {
+	return func(to *TxOptions) { to.RetryBackoff = backoff }
+}
+
+// OptTxRetryableSQLStates sets the SQLSTATE codes that cause the transaction to be retried.
+func OptTxRetryableSQLStates(sqlStates ...string) TxOption {
+	return func(to *TxOptions) { to.RetryableSQLStates = sqlStates }
+}
+
+// TxAction is a function run within a transaction.
+type TxAction func(*Invocation) error
+
+// InTx runs an action within a transaction, committing it if the action returns nil
+// and rolling it back if the action returns an error or panics.
+//
+// The invocation passed to the action is bound to the transaction, and its context carries the transaction.
+// If `InTx` is called with that context, the nested action runs within a savepoint of the outer
+// transaction rather than a new transaction; an error rolls back to the savepoint, and is returned
+// to the outer action.
+//
+// Cached query results (see `OptCache`) for tables written to within the transaction are invalidated
+// both when each write succeeds and again once the transaction commits.
+//
+// If the transaction fails with a retryable SQLSTATE (by default a serialization failure, as is common on
+// CockroachDB), the whole transaction is retried from the beginning, so the action must be safe to run again.
+// Nested transactions are not retried themselves, but retryable errors they return will retry the outer transaction.
+//
+// Begin, commit, rollback and savepoint statements are logged as query events, and traced, with `tx.*` labels.
+func (dbc *Connection) InTx(ctx context.Context, action TxAction, opts ...TxOption) error {
+	if dbc.Connection == nil {
+		return ex.New(ErrConnectionClosed)
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if state := getTxState(ctx); state != nil {
+		return dbc.inSavepoint(ctx, state, action)
+	}
+
+	options := TxOptions{
+		MaxRetries:         DefaultTxMaxRetries,
+		RetryBackoff:       DefaultTxRetryBackoff,
+		RetryableSQLStates: DefaultTxRetryableSQLStates,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	backoff := options.RetryBackoff
+	for attempt := 0; ; attempt++ {
+		err := dbc.inTx(ctx, action, options)
+		if err == nil || attempt >= options.MaxRetries || !IsSQLState(err, options.RetryableSQLStates...) {
+			return err
+		}
+		if backoff > 0 {
+			timer := time.NewTimer(backoff)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return ex.Nest(err, ctx.Err())
+			case <-timer.C:
+			}
+			backoff *= 2
+		}
+	}
+}
+
+// SQLState returns the SQLSTATE code of an error if the driver reports one.
+func SQLState(err error) string {
+	var typed interface{ SQLState() string }
+	if errors.As(err, &typed) {
+		return typed.SQLState()
+	}
+	return ""
+}
+
+// IsSQLState returns if an error has any of the given SQLSTATE codes.
+func IsSQLState(err error, sqlStates ...string) bool {
+	sqlState := SQLState(err)
+	if sqlState == "" {
+		return false
+	}
+	for _, candidate := range sqlStates {
+		if candidate == sqlState {
+			return true
+		}
+	}
+	return false
+}
+
+// inTx runs a single attempt of a transaction.
+func (dbc *Connection) inTx(ctx context.Context, action TxAction, options TxOptions) (err error) {
+	var tx *sql.Tx
+	if err = dbc.txStatement(ctx, LabelTxBegin, StatementTxBegin, func() (beginErr error) {
+		tx, beginErr = dbc.Connection.BeginTx(ctx, &sql.TxOptions{Isolation: options.Isolation, ReadOnly: options.ReadOnly})
+		return beginErr
+	}); err != nil {
+		return
+	}
+
+	state := &txState{Tx: tx, CacheTables: new(txCacheTables)}
+	defer func() {
+		if r := recover(); r != nil {
+			_ = dbc.txStatement(ctx, LabelTxRollback, StatementTxRollback, tx.Rollback)
+			panic(r)
+		}
+		if err != nil {
+			if rollbackErr := dbc.txStatement(ctx, LabelTxRollback, StatementTxRollback, tx.Rollback); rollbackErr != nil && !errors.Is(rollbackErr, sql.ErrTxDone) {
+				err = ex.Nest(err, rollbackErr)
+			}
+			return
+		}
+		if err = dbc.txStatement(ctx, LabelTxCommit, StatementTxCommit, tx.Commit); err != nil {
+			return
+		}
+		if tables := state.CacheTables.all(); len(tables) > 0 {
+			dbc.Invoke(OptContext(ctx)).invalidateCacheTables(tables...)
+		}
+	}()
+	err = action(dbc.Invoke(OptContext(withTxState(ctx, state)), OptTx(tx)))
+	return
+}
+
+// inSavepoint runs a nested transaction within a savepoint.
+func (dbc *Connection) inSavepoint(ctx context.Context, parent *txState, action TxAction) (err error) {
+	state := &txState{Tx: parent.Tx, Depth: parent.Depth + 1, CacheTables: parent.CacheTables}
+	name := fmt.Sprintf("db_tx_%d", state.Depth)
+	if err = dbc.txExec(ctx, parent.Tx, LabelTxSavepoint, fmt.Sprintf(statementTxSavepointFormat, name)); err != nil {
+		return
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			_ = dbc.txExec(ctx, parent.Tx, LabelTxRollbackToSavepoint, fmt.Sprintf(statementTxRollbackToFormat, name))
+			panic(r)
+		}
+		if err != nil {
+			if rollbackErr := dbc.txExec(ctx, parent.Tx, LabelTxRollbackToSavepoint, fmt.Sprintf(statementTxRollbackToFormat, name)); rollbackErr != nil {
+				err = ex.Nest(err, rollbackErr)
+			}
+			return
+		}
+		err = dbc.txExec(ctx, parent.Tx, LabelTxReleaseSavepoint, fmt.Sprintf(statementTxReleaseFormat, name))
+	}()
+	err = action(dbc.Invoke(OptContext(withTxState(ctx, state)), OptTx(parent.Tx)))
+	return
+}
+
+// txExec executes a transaction control statement on a transaction.
+func (dbc *Connection) txExec(ctx context.Context, tx *sql.Tx, label, statement string) error {
+	return dbc.txStatement(ctx, label, statement, func() error {
+		_, err := tx.ExecContext(ctx, statement)
+		return err
+	})
+}
+
+// txStatement runs a transaction control action, triggering query events and traces for it.
+func (dbc *Connection) txStatement(ctx context.Context, label, statement string, action func() error) (err error) {
+	var finisher TraceFinisher
+	if dbc.Tracer != nil && !IsSkipQueryLogging(ctx) {
+		finisher = dbc.Tracer.Query(ctx, dbc.Config, label, statement)
+	}
+	started := time.Now().UTC()
+	err = Error(action())
+	if dbc.Log != nil && !IsSkipQueryLogging(ctx) {
+		qe := NewQueryEvent(statement, time.Now().UTC().Sub(started))
+		qe.Username = dbc.Config.Username
+		qe.Database = dbc.Config.DatabaseOrDefault()
+		qe.Label = label
+		qe.Engine = dbc.Config.EngineOrDefault()
+		if dbc.Replicas != nil {
+			qe.Node = NodePrimary
+		}
+		qe.Err = err
+		dbc.Log.TriggerContext(ctx, qe)
+	}
+	if finisher != nil {
+		finisher.FinishQuery(ctx, nil, err)
+	}
+	return
+}
+
+type txStateKey struct{}
+
+// txState is the state of a managed transaction held on the context.
+type txState struct {
+	Tx    *sql.Tx
+	Depth int
+	// CacheTables are the tables written to within the transaction, shared with nested transactions.
+	CacheTables *txCacheTables
+}
+
+func withTxState(ctx context.Context, state *txState) context.Context {
+	return context.WithValue(ctx, txStateKey{}, state)
+}
+
+func getTxState(ctx context.Context) *txState {
+	if value, ok := ctx.Value(txStateKey{}).(*txState); ok {
+		return value
+	}
+	return nil
+}
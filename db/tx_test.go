@@ -0,0 +1,193 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgconn"
+
+	"github.com/blend/go-sdk/assert"
+	"github.com/blend/go-sdk/ex"
+)
+
+type txTestObj struct {
+	ID   int    `db:"id,pk"`
+	Name string `db:"name"`
+}
+
+func (txTestObj) TableName() string {
+	return "tx_test_obj"
+}
+
+func createTxTestTable(t *testing.T) {
+	t.Helper()
+	_, err := defaultDB().Exec("CREATE TABLE IF NOT EXISTS tx_test_obj (id int not null primary key, name varchar(255))")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _, _ = defaultDB().Exec("DROP TABLE IF EXISTS tx_test_obj") })
+}
+
+type txLabelTracer struct {
+	Labels []string
+}
+
+func (tlt *txLabelTracer) Prepare(context.Context, Config, string) TraceFinisher { return nil }
+func (tlt *txLabelTracer) Query(_ context.Context, _ Config, label, _ string) TraceFinisher {
+	tlt.Labels = append(tlt.Labels, label)
+	return nil
+}
+
+func Test_Connection_InTx(t *testing.T) {
+	its := assert.New(t)
+	createTxTestTable(t)
+
+	err := defaultDB().InTx(context.Background(), func(i *Invocation) error {
+		return i.Create(&txTestObj{ID: 1, Name: "committed"})
+	})
+	its.Nil(err)
+
+	err = defaultDB().InTx(context.Background(), func(i *Invocation) error {
+		if err := i.Create(&txTestObj{ID: 2, Name: "rolled back"}); err != nil {
+			return err
+		}
+		return fmt.Errorf("this is only a test")
+	})
+	its.Equal("this is only a test", err.Error())
+
+	var all []txTestObj
+	its.Nil(defaultDB().Invoke().All(&all))
+	its.Len(all, 1)
+	its.Equal("committed", all[0].Name)
+}
+
+func Test_Connection_InTx_panic(t *testing.T) {
+	its := assert.New(t)
+	createTxTestTable(t)
+
+	func() {
+		defer func() {
+			its.Equal("this is only a test", recover())
+		}()
+		_ = defaultDB().InTx(context.Background(), func(i *Invocation) error {
+			if err := i.Create(&txTestObj{ID: 1, Name: "panicked"}); err != nil {
+				return err
+			}
+			panic("this is only a test")
+		})
+	}()
+
+	var all []txTestObj
+	its.Nil(defaultDB().Invoke().All(&all))
+	its.Empty(all)
+}
+
+func Test_Connection_InTx_savepoints(t *testing.T) {
+	its := assert.New(t)
+	createTxTestTable(t)
+
+	tracer := new(txLabelTracer)
+	conn := &Connection{
+		Connection: defaultDB().Connection,
+		Config:     defaultDB().Config,
+		BufferPool: defaultDB().BufferPool,
+		Tracer:     tracer,
+	}
+
+	err := conn.InTx(context.Background(), func(outer *Invocation) error {
+		if err := outer.Create(&txTestObj{ID: 1, Name: "outer"}); err != nil {
+			return err
+		}
+		nestedErr := conn.InTx(outer.Context, func(nested *Invocation) error {
+			if err := nested.Create(&txTestObj{ID: 2, Name: "nested"}); err != nil {
+				return err
+			}
+			return fmt.Errorf("this is only a test")
+		})
+		its.NotNil(nestedErr)
+		return conn.InTx(outer.Context, func(nested *Invocation) error {
+			return nested.Create(&txTestObj{ID: 3, Name: "released"})
+		})
+	})
+	its.Nil(err)
+
+	var all []txTestObj
+	its.Nil(defaultDB().Query("SELECT * FROM tx_test_obj ORDER BY id").OutMany(&all))
+	its.Len(all, 2)
+	its.Equal("outer", all[0].Name)
+	its.Equal("released", all[1].Name)
+
+	var txLabels []string
+	for _, label := range tracer.Labels {
+		if strings.HasPrefix(label, "tx.") {
+			txLabels = append(txLabels, label)
+		}
+	}
+	its.Equal([]string{
+		LabelTxBegin,
+		LabelTxSavepoint,
+		LabelTxRollbackToSavepoint,
+		LabelTxSavepoint,
+		LabelTxReleaseSavepoint,
+		LabelTxCommit,
+	}, txLabels)
+}
+
+func Test_Connection_InTx_retry(t *testing.T) {
+	its := assert.New(t)
+
+	var attempts int
+	err := defaultDB().InTx(context.Background(), func(i *Invocation) error {
+		attempts++
+		if attempts < 3 {
+			return Error(&pgconn.PgError{Code: SQLStateSerializationFailure})
+		}
+		return nil
+	}, OptTxRetryBackoff(0), OptTxIsolation(sql.LevelSerializable))
+	its.Nil(err)
+	its.Equal(3, attempts)
+
+	attempts = 0
+	err = defaultDB().InTx(context.Background(), func(i *Invocation) error {
+		attempts++
+		return Error(&pgconn.PgError{Code: SQLStateSerializationFailure})
+	}, OptTxRetryBackoff(0), OptTxMaxRetries(1))
+	its.Equal(SQLStateSerializationFailure, SQLState(err))
+	its.Equal(2, attempts)
+
+	attempts = 0
+	err = defaultDB().InTx(context.Background(), func(i *Invocation) error {
+		attempts++
+		return Error(&pgconn.PgError{Code: SQLStateDeadlockDetected})
+	}, OptTxRetryBackoff(0))
+	its.NotNil(err)
+	its.Equal(1, attempts, "only retryable sql states should be retried")
+}
+
+func Test_Connection_InTx_closed(t *testing.T) {
+	its := assert.New(t)
+
+	err := new(Connection).InTx(context.Background(), func(*Invocation) error { return nil })
+	its.True(ex.Is(err, ErrConnectionClosed))
+}
+
+func Test_SQLState(t *testing.T) {
+	its := assert.New(t)
+
+	its.Empty(SQLState(nil))
+	its.Empty(SQLState(fmt.Errorf("this is only a test")))
+	its.Equal(SQLStateSerializationFailure, SQLState(&pgconn.PgError{Code: SQLStateSerializationFailure}))
+	its.Equal(SQLStateSerializationFailure, SQLState(Error(&pgconn.PgError{Code: SQLStateSerializationFailure})))
+	its.True(IsSQLState(Error(&pgconn.PgError{Code: SQLStateDeadlockDetected}), SQLStateSerializationFailure, SQLStateDeadlockDetected))
+	its.False(IsSQLState(Error(&pgconn.PgError{Code: SQLStateDeadlockDetected}), SQLStateSerializationFailure))
+}
@@ -0,0 +1,187 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package grpcutil
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/blend/go-sdk/breaker"
+)
+
+var (
+	// DefaultBreakerFailureCodes is the set of gRPC codes that count as failures for a client breaker.
+	//
+	// Codes that indicate a problem with the request itself (e.g. `InvalidArgument` or `NotFound`) do not
+	// count as failures, as they do not indicate the server is unhealthy.
+	DefaultBreakerFailureCodes = []codes.Code{
+		codes.Unknown,
+		codes.DeadlineExceeded,
+		codes.ResourceExhausted,
+		codes.Internal,
+		codes.Unavailable,
+		codes.DataLoss,
+	}
+)
+
+// ClientBreakerOptions are options for client breaker interceptors.
+type ClientBreakerOptions struct {
+	// Key returns the name of the breaker for a call; it defaults to the full method name.
+	Key func(ctx context.Context, method string, cc *grpc.ClientConn) string
+	// IsFailure returns if a call error counts as a failure; it defaults to the `DefaultBreakerFailureCodes`.
+	IsFailure func(error) bool
+}
+
+// ClientBreakerOption mutates client breaker options.
+type ClientBreakerOption func(*ClientBreakerOptions)
+
+// OptClientBreakerKey sets the function that returns the name of the breaker for a call.
+func OptClientBreakerKey(key func(ctx context.Context, method string, cc *grpc.ClientConn) string) ClientBreakerOption {
+	return func(o *ClientBreakerOptions) { o.Key = key }
+}
+
+// OptClientBreakerPerTarget uses a breaker per client connection target rather than per method.
+func OptClientBreakerPerTarget() ClientBreakerOption {
+	return OptClientBreakerKey(func(_ context.Context, method string, cc *grpc.ClientConn) string {
+		if cc == nil {
+			return method
+		}
+		return cc.Target()
+	})
+}
+
+// OptClientBreakerFailure sets the function that returns if a call error counts as a failure.
+func OptClientBreakerFailure(isFailure func(error) bool) ClientBreakerOption {
+	return func(o *ClientBreakerOptions) { o.IsFailure = isFailure }
+}
+
+// OptClientBreakerFailureCodes sets the gRPC codes that count as failures.
+func OptClientBreakerFailureCodes(failureCodes ...codes.Code) ClientBreakerOption {
+	return OptClientBreakerFailure(func(err error) bool {
+		return isBreakerFailure(err, failureCodes)
+	})
+}
+
+// BreakerUnaryClientInterceptor returns a unary client interceptor that wraps calls in a breaker
+// from a group, by default a breaker per method.
+//
+// Calls that are rejected by an open breaker are not invoked, and return a `breaker.ErrOpenState`
+// or `breaker.ErrTooManyRequests` error; use `breaker.ErrIsOpen(err)` and `breaker.ErrIsTooManyRequests(err)`
+// to check for them.
+func BreakerUnaryClientInterceptor(breakers *breaker.Group, opts ...ClientBreakerOption) grpc.UnaryClientInterceptor {
+	options := newClientBreakerOptions(opts...)
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, callOpts ...grpc.CallOption) error {
+		done, err := breakers.Get(options.Key(ctx, method, cc)).Allow(ctx)
+		if err != nil {
+			return err
+		}
+		err = invoker(ctx, method, req, reply, cc, callOpts...)
+		done(!options.IsFailure(err))
+		return err
+	}
+}
+
+// BreakerStreamClientInterceptor returns a stream client interceptor that wraps streams in a breaker
+// from a group, by default a breaker per method.
+//
+// The outcome of a stream is recorded when the stream fails to open, when receiving a message returns
+// an error (`io.EOF` is a success), or when the stream context is done, such that streams abandoned
+// before they are read to completion release their breaker slot once gRPC ends the stream (e.g. when
+// the call context is cancelled or the connection is closed).
+func BreakerStreamClientInterceptor(breakers *breaker.Group, opts ...ClientBreakerOption) grpc.StreamClientInterceptor {
+	options := newClientBreakerOptions(opts...)
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, callOpts ...grpc.CallOption) (grpc.ClientStream, error) {
+		done, err := breakers.Get(options.Key(ctx, method, cc)).Allow(ctx)
+		if err != nil {
+			return nil, err
+		}
+		stream, err := streamer(ctx, desc, cc, method, callOpts...)
+		if err != nil {
+			done(!options.IsFailure(err))
+			return nil, err
+		}
+		wrapped := &breakerClientStream{ClientStream: stream, done: done, isFailure: options.IsFailure, finished: make(chan struct{})}
+		go wrapped.watch(stream.Context())
+		return wrapped, nil
+	}
+}
+
+func newClientBreakerOptions(opts ...ClientBreakerOption) ClientBreakerOptions {
+	options := ClientBreakerOptions{
+		Key: func(_ context.Context, method string, _ *grpc.ClientConn) string {
+			return method
+		},
+		IsFailure: func(err error) bool {
+			return isBreakerFailure(err, DefaultBreakerFailureCodes)
+		},
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return options
+}
+
+func isBreakerFailure(err error, failureCodes []codes.Code) bool {
+	if err == nil {
+		return false
+	}
+	code := status.Code(err)
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		code = status.FromContextError(err).Code()
+	}
+	for _, failureCode := range failureCodes {
+		if code == failureCode {
+			return true
+		}
+	}
+	return false
+}
+
+// breakerClientStream records the outcome of a stream the first time receiving a message fails,
+// or when the stream context, which gRPC cancels when the stream ends, is done.
+//
+// The breaker only records the first outcome, so later errors are ignored.
+type breakerClientStream struct {
+	grpc.ClientStream
+	done      func(bool)
+	isFailure func(error) bool
+	once      sync.Once
+	finished  chan struct{}
+}
+
+// RecvMsg implements grpc.ClientStream.
+func (s *breakerClientStream) RecvMsg(m interface{}) error {
+	err := s.ClientStream.RecvMsg(m)
+	if err != nil {
+		s.finish(err == io.EOF || !s.isFailure(err))
+	}
+	return err
+}
+
+// watch records the outcome of the stream when its context is done, unless it has already finished.
+func (s *breakerClientStream) watch(ctx context.Context) {
+	select {
+	case <-ctx.Done():
+		s.finish(!s.isFailure(ctx.Err()))
+	case <-s.finished:
+	}
+}
+
+// finish records the outcome of the stream once.
+func (s *breakerClientStream) finish(success bool) {
+	s.once.Do(func() {
+		s.done(success)
+		close(s.finished)
+	})
+}
@@ -0,0 +1,183 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package grpcutil
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/blend/go-sdk/assert"
+	"github.com/blend/go-sdk/breaker"
+)
+
+func TestBreakerUnaryClientInterceptor(t *testing.T) {
+	its := assert.New(t)
+
+	breakers := breaker.NewGroup(breaker.OptOpenFailureThreshold(1), breaker.OptOpenExpiryInterval(time.Hour))
+	interceptor := BreakerUnaryClientInterceptor(breakers)
+
+	var calls int
+	invoker := grpc.UnaryInvoker(func(_ context.Context, _ string, _, _ interface{}, _ *grpc.ClientConn, _ ...grpc.CallOption) error {
+		calls++
+		return status.Error(codes.Unavailable, "unavailable")
+	})
+	for x := 0; x < 2; x++ {
+		err := interceptor(context.TODO(), "/example-string/v1/dog", nil, nil, nil, invoker)
+		its.Equal(codes.Unavailable, status.Code(err))
+	}
+	its.Equal(breaker.StateOpen, breakers.Get("/example-string/v1/dog").EvaluateState(context.TODO()))
+
+	err := interceptor(context.TODO(), "/example-string/v1/dog", nil, nil, nil, invoker)
+	its.True(breaker.ErrIsOpen(err))
+	its.Equal(2, calls)
+
+	// other methods use their own breaker.
+	err = interceptor(context.TODO(), "/example-string/v1/cat", nil, nil, nil, invoker)
+	its.Equal(codes.Unavailable, status.Code(err))
+	its.Equal(3, calls)
+}
+
+func TestBreakerUnaryClientInterceptorFailureCodes(t *testing.T) {
+	its := assert.New(t)
+
+	breakers := breaker.NewGroup(breaker.OptOpenFailureThreshold(0))
+	interceptor := BreakerUnaryClientInterceptor(breakers,
+		OptClientBreakerPerTarget(),
+		OptClientBreakerFailureCodes(codes.Internal),
+	)
+	invoker := grpc.UnaryInvoker(func(_ context.Context, _ string, _, _ interface{}, _ *grpc.ClientConn, _ ...grpc.CallOption) error {
+		return status.Error(codes.Unavailable, "unavailable")
+	})
+	for x := 0; x < 3; x++ {
+		err := interceptor(context.TODO(), "/example-string/v1/dog", nil, nil, nil, invoker)
+		its.Equal(codes.Unavailable, status.Code(err))
+	}
+	its.Equal(breaker.StateClosed, breakers.Get("/example-string/v1/dog").EvaluateState(context.TODO()))
+}
+
+type mockClientStream struct {
+	grpc.ClientStream
+	ctx context.Context
+	err error
+}
+
+func (m mockClientStream) Context() context.Context {
+	if m.ctx == nil {
+		return context.Background()
+	}
+	return m.ctx
+}
+
+func (m mockClientStream) RecvMsg(_ interface{}) error { return m.err }
+
+func TestBreakerStreamClientInterceptor(t *testing.T) {
+	its := assert.New(t)
+
+	breakers := breaker.NewGroup(breaker.OptOpenFailureThreshold(0), breaker.OptOpenExpiryInterval(time.Hour))
+	interceptor := BreakerStreamClientInterceptor(breakers, OptClientBreakerKey(func(_ context.Context, _ string, _ *grpc.ClientConn) string {
+		return "test"
+	}))
+
+	stream, err := interceptor(context.TODO(), &grpc.StreamDesc{}, nil, "/example-string/v1/dogs", func(_ context.Context, _ *grpc.StreamDesc, _ *grpc.ClientConn, _ string, _ ...grpc.CallOption) (grpc.ClientStream, error) {
+		return mockClientStream{err: io.EOF}, nil
+	})
+	its.Nil(err)
+	its.Equal(io.EOF, stream.RecvMsg(nil))
+	its.Equal(breaker.StateClosed, breakers.Get("test").EvaluateState(context.TODO()))
+
+	stream, err = interceptor(context.TODO(), &grpc.StreamDesc{}, nil, "/example-string/v1/dogs", func(_ context.Context, _ *grpc.StreamDesc, _ *grpc.ClientConn, _ string, _ ...grpc.CallOption) (grpc.ClientStream, error) {
+		return mockClientStream{err: status.Error(codes.Internal, "internal")}, nil
+	})
+	its.Nil(err)
+	its.NotNil(stream.RecvMsg(nil))
+	its.Equal(breaker.StateOpen, breakers.Get("test").EvaluateState(context.TODO()))
+
+	_, err = interceptor(context.TODO(), &grpc.StreamDesc{}, nil, "/example-string/v1/dogs", func(_ context.Context, _ *grpc.StreamDesc, _ *grpc.ClientConn, _ string, _ ...grpc.CallOption) (grpc.ClientStream, error) {
+		its.FailNow("should not open the stream")
+		return nil, nil
+	})
+	its.True(breaker.ErrIsOpen(err))
+}
+
+func TestBreakerStreamClientInterceptorAbandoned(t *testing.T) {
+	its := assert.New(t)
+
+	breakers := breaker.NewGroup(breaker.OptOpenFailureThreshold(0), breaker.OptOpenExpiryInterval(10*time.Millisecond))
+	interceptor := BreakerStreamClientInterceptor(breakers)
+	streamer := func(err error) grpc.Streamer {
+		return func(ctx context.Context, _ *grpc.StreamDesc, _ *grpc.ClientConn, _ string, _ ...grpc.CallOption) (grpc.ClientStream, error) {
+			return mockClientStream{ctx: ctx, err: err}, nil
+		}
+	}
+	b := breakers.Get("/example-string/v1/dogs")
+
+	stream, err := interceptor(context.TODO(), &grpc.StreamDesc{}, nil, "/example-string/v1/dogs", streamer(status.Error(codes.Internal, "internal")))
+	its.Nil(err)
+	its.NotNil(stream.RecvMsg(nil))
+	its.Equal(breaker.StateOpen, b.EvaluateState(context.TODO()))
+
+	time.Sleep(20 * time.Millisecond)
+	its.Equal(breaker.StateHalfOpen, b.EvaluateState(context.TODO()))
+
+	// the half open probe is abandoned without reading it to completion.
+	ctx, cancel := context.WithCancel(context.Background())
+	_, err = interceptor(ctx, &grpc.StreamDesc{}, nil, "/example-string/v1/dogs", streamer(io.EOF))
+	its.Nil(err)
+	_, err = interceptor(context.TODO(), &grpc.StreamDesc{}, nil, "/example-string/v1/dogs", streamer(io.EOF))
+	its.True(breaker.ErrIsTooManyRequests(err))
+	cancel()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for b.EvaluateState(context.TODO()) != breaker.StateClosed && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	stream, err = interceptor(context.TODO(), &grpc.StreamDesc{}, nil, "/example-string/v1/dogs", streamer(io.EOF))
+	its.Nil(err)
+	its.Equal(io.EOF, stream.RecvMsg(nil))
+}
+
+func TestBreakerStreamClientInterceptorAbandonedBackground(t *testing.T) {
+	its := assert.New(t)
+
+	breakers := breaker.NewGroup(breaker.OptOpenFailureThreshold(0), breaker.OptOpenExpiryInterval(10*time.Millisecond))
+	interceptor := BreakerStreamClientInterceptor(breakers)
+	b := breakers.Get("/example-string/v1/dogs")
+
+	stream, err := interceptor(context.Background(), &grpc.StreamDesc{}, nil, "/example-string/v1/dogs", func(_ context.Context, _ *grpc.StreamDesc, _ *grpc.ClientConn, _ string, _ ...grpc.CallOption) (grpc.ClientStream, error) {
+		return mockClientStream{err: status.Error(codes.Internal, "internal")}, nil
+	})
+	its.Nil(err)
+	its.NotNil(stream.RecvMsg(nil))
+	time.Sleep(20 * time.Millisecond)
+	its.Equal(breaker.StateHalfOpen, b.EvaluateState(context.TODO()))
+
+	// the half open probe is opened with a background context and abandoned; gRPC cancels
+	// the stream context when the stream ends (e.g. the connection is closed).
+	streamCtx, streamCancel := context.WithCancel(context.Background())
+	_, err = interceptor(context.Background(), &grpc.StreamDesc{}, nil, "/example-string/v1/dogs", func(_ context.Context, _ *grpc.StreamDesc, _ *grpc.ClientConn, _ string, _ ...grpc.CallOption) (grpc.ClientStream, error) {
+		return mockClientStream{ctx: streamCtx, err: io.EOF}, nil
+	})
+	its.Nil(err)
+	_, err = interceptor(context.Background(), &grpc.StreamDesc{}, nil, "/example-string/v1/dogs", func(_ context.Context, _ *grpc.StreamDesc, _ *grpc.ClientConn, _ string, _ ...grpc.CallOption) (grpc.ClientStream, error) {
+		return mockClientStream{err: io.EOF}, nil
+	})
+	its.True(breaker.ErrIsTooManyRequests(err))
+	streamCancel()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for b.EvaluateState(context.TODO()) != breaker.StateClosed && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	its.Equal(breaker.StateClosed, b.EvaluateState(context.TODO()))
+}
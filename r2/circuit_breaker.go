@@ -0,0 +1,81 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package r2
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/blend/go-sdk/breaker"
+)
+
+// CircuitBreakerFailurePredicate returns if an attempt counts as a failure for a circuit breaker.
+//
+// The response is nil if the error is set.
+type CircuitBreakerFailurePredicate func(*http.Request, *http.Response, error) bool
+
+// CircuitBreakerFailureOnErrors is a failure predicate that counts errors returned by the client,
+// unless the request was cancelled, and responses with 5xx status codes as failures.
+func CircuitBreakerFailureOnErrors(_ *http.Request, res *http.Response, err error) bool {
+	if err != nil {
+		return !errors.Is(err, context.Canceled)
+	}
+	return res != nil && res.StatusCode >= http.StatusInternalServerError
+}
+
+// CircuitBreaker wraps each attempt of a request in a breaker from a group, by default a breaker per host.
+type CircuitBreaker struct {
+	// Breakers is the group of breakers.
+	Breakers *breaker.Group
+	// Key returns the name of the breaker for a request; it defaults to the request host.
+	Key func(*http.Request) string
+	// IsFailure returns if an attempt is a failure; it defaults to `CircuitBreakerFailureOnErrors`.
+	IsFailure CircuitBreakerFailurePredicate
+}
+
+// KeyOrDefault returns the breaker name for a request.
+func (cb CircuitBreaker) KeyOrDefault(req *http.Request) string {
+	if cb.Key != nil {
+		return cb.Key(req)
+	}
+	if req.URL != nil && req.URL.Host != "" {
+		return req.URL.Host
+	}
+	return req.Host
+}
+
+// IsFailureOrDefault returns the failure predicate or a default.
+func (cb CircuitBreaker) IsFailureOrDefault() CircuitBreakerFailurePredicate {
+	if cb.IsFailure != nil {
+		return cb.IsFailure
+	}
+	return CircuitBreakerFailureOnErrors
+}
+
+// Allow checks if the breaker for a request allows it, returning a function
+// that records the outcome of the attempt if it does.
+//
+// If the breaker rejects the request it returns a `breaker.ErrOpenState` or
+// `breaker.ErrTooManyRequests` error; use `breaker.ErrIsOpen(err)` to check for them.
+func (cb CircuitBreaker) Allow(req *http.Request) (done func(*http.Response, error), err error) {
+	if cb.Breakers == nil {
+		done = func(*http.Response, error) {}
+		return
+	}
+	var breakerDone func(bool)
+	breakerDone, err = cb.Breakers.Get(cb.KeyOrDefault(req)).Allow(req.Context())
+	if err != nil {
+		return
+	}
+	isFailure := cb.IsFailureOrDefault()
+	done = func(res *http.Response, resErr error) {
+		breakerDone(!isFailure(req, res, resErr))
+	}
+	return
+}
@@ -0,0 +1,98 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package r2
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/blend/go-sdk/assert"
+	"github.com/blend/go-sdk/breaker"
+)
+
+func TestCircuitBreakerFailureOnErrors(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.True(CircuitBreakerFailureOnErrors(nil, nil, fmt.Errorf("connection refused")))
+	assert.False(CircuitBreakerFailureOnErrors(nil, nil, context.Canceled))
+	assert.True(CircuitBreakerFailureOnErrors(nil, &http.Response{StatusCode: http.StatusBadGateway}, nil))
+	assert.False(CircuitBreakerFailureOnErrors(nil, &http.Response{StatusCode: http.StatusNotFound}, nil))
+	assert.False(CircuitBreakerFailureOnErrors(nil, &http.Response{StatusCode: http.StatusOK}, nil))
+}
+
+func TestCircuitBreakerKeyOrDefault(t *testing.T) {
+	assert := assert.New(t)
+
+	req := &http.Request{URL: &url.URL{Host: "test.invalid:8080", Path: "/foo"}}
+	assert.Equal("test.invalid:8080", CircuitBreaker{}.KeyOrDefault(req))
+	assert.Equal("/foo", CircuitBreaker{Key: func(r *http.Request) string { return r.URL.Path }}.KeyOrDefault(req))
+}
+
+func TestRequestCircuitBreaker(t *testing.T) {
+	assert := assert.New(t)
+
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	breakers := breaker.NewGroup(breaker.OptOpenFailureThreshold(1), breaker.OptOpenExpiryInterval(time.Hour))
+
+	var didCallFinish bool
+	tracer := MockTracer{
+		FinishHandler: func(_ *http.Request, _ *http.Response, _ time.Time, _ error) {
+			didCallFinish = true
+		},
+	}
+
+	for x := 0; x < 2; x++ {
+		res, err := New(server.URL, OptCircuitBreaker(breakers)).Discard()
+		assert.Nil(err)
+		assert.Equal(http.StatusInternalServerError, res.StatusCode)
+	}
+	assert.Equal(breaker.StateOpen, breakers.Get(server.Listener.Addr().String()).EvaluateState(context.Background()))
+
+	_, err := New(server.URL,
+		OptTracer(tracer),
+		OptCircuitBreaker(breakers),
+		OptRetry(OptRetryBackoff(time.Millisecond, time.Millisecond)),
+	).Discard()
+	assert.True(breaker.ErrIsOpen(err))
+	assert.True(didCallFinish)
+	assert.Equal(2, atomic.LoadInt32(&attempts))
+}
+
+func TestRequestCircuitBreakerFailureStatusCodes(t *testing.T) {
+	assert := assert.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	breakers := breaker.NewGroup(breaker.OptOpenFailureThreshold(1))
+	for x := 0; x < 3; x++ {
+		_, err := New(server.URL,
+			OptCircuitBreaker(breakers,
+				OptCircuitBreakerKey(func(_ *http.Request) string { return "test" }),
+				OptCircuitBreakerFailureStatusCodes(http.StatusServiceUnavailable),
+			),
+		).Discard()
+		assert.Nil(err)
+	}
+	assert.Equal([]string{"test"}, breakers.Names())
+	assert.Equal(breaker.StateClosed, breakers.Get("test").EvaluateState(context.Background()))
+}
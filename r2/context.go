@@ -27,3 +27,17 @@ func GetParameterizedPath(ctx context.Context) string {
 	path, _ := ctx.Value(parameterizedPathKey{}).(string)
 	return path
 }
+
+type attemptKey struct{}
+
+// WithAttempt adds the attempt number of a retried request to a context.
+func WithAttempt(ctx context.Context, attempt uint) context.Context {
+	return context.WithValue(ctx, attemptKey{}, attempt)
+}
+
+// GetAttempt gets the attempt number (starting at 1) of a retried request off a context.
+// It returns 0 if the request does not retry. Relies on OptRetry being added to a Request.
+func GetAttempt(ctx context.Context) uint {
+	attempt, _ := ctx.Value(attemptKey{}).(uint)
+	return attempt
+}
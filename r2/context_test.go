@@ -20,3 +20,11 @@ func Test_WithParameterizedPath(t *testing.T) {
 	ctx := WithParameterizedPath(context.Background(), "/foo/:id")
 	its.Equal("/foo/:id", GetParameterizedPath(ctx))
 }
+
+func Test_WithAttempt(t *testing.T) {
+	its := assert.New(t)
+
+	its.Zero(GetAttempt(context.Background()))
+	ctx := WithAttempt(context.Background(), 2)
+	its.Equal(2, GetAttempt(ctx))
+}
@@ -43,6 +43,34 @@ You can also fire and forget the request with the `.Discard()` method:
 		r2.OptHeaderValue("X-Foo", "example-string"),
 	).Discard()
 
+Requests can be retried with `r2.OptRetry(...)`; by default only idempotent methods are retried,
+on connection errors and 429, 502, 503 and 504 responses, with exponential backoff that respects `Retry-After` headers:
+
+	meta, err := r2.New("http://example.com",
+		r2.OptRetry(r2.OptRetryMaxAttempts(5), r2.OptRetryAttemptTimeout(5*time.Second)),
+	).Discard()
+
+Large or long lived responses can be read incrementally with `.NDJSON(...)`, `.JSONArray(...)` and `.EventStream(...)`,
+which decode one item or event at a time and stop when the request context is cancelled:
+
+	meta, err := r2.New("http://example.com/events").EventStream(func(e r2.ServerSentEvent) error {
+		// handle the event
+		return nil
+	})
+
+Event streams reconnect when the stream ends, sending the last event id received as the `Last-Event-ID` header.
+
+Large uploads can stream multipart files from readers with `r2.OptStreamedFiles(...)`, and large downloads can be
+written to a file with `.DownloadFile(path)`, which resumes a partial file with a `Range` request. Transfers can report
+progress and be throttled in either direction:
+
+	written, meta, err := r2.New("http://example.com/large.tar.gz",
+		r2.OptDownloadProgress(func(transferred, total int64) {
+			// report progress
+		}),
+		r2.OptDownloadThrottle(1<<20, time.Second),
+	).DownloadFile("large.tar.gz")
+
 
 */
 package r2 // import "github.com/blend/go-sdk/r2"
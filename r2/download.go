@@ -0,0 +1,147 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package r2
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/blend/go-sdk/ex"
+	"github.com/blend/go-sdk/webutil"
+)
+
+// DownloadFile downloads the response body to a file, and returns the number of bytes written
+// and the response metadata.
+//
+// If the file exists and is not empty, the download resumes from the end of the file with
+// a `Range` header. If the server does not support ranges and returns the complete content,
+// the file is truncated and written from the start. If the file is already complete (i.e. the
+// server responds with a 416 for the range), nothing is written.
+//
+// Responses with other statuses than 200, 206 and 416 return an `ErrDownloadStatus` error and
+// leave the file unchanged. If the download fails part way, the file is left as is so that the
+// download can be resumed by calling `DownloadFile` again.
+//
+// To ensure the remote content has not changed between attempts, pass the `ETag` or `Last-Modified`
+// of the content as an `If-Range` header, e.g. `r2.OptHeaderValue("If-Range", etag)`; the server will
+// then return the complete content if it has changed.
+func (r Request) DownloadFile(path string) (written int64, res *http.Response, err error) {
+	defer func() {
+		if closeErr := r.Close(); closeErr != nil {
+			err = ex.Append(err, closeErr)
+		}
+	}()
+	if r.Err != nil {
+		err = r.Err
+		return
+	}
+
+	var file *os.File
+	file, err = os.OpenFile(path, os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		err = ex.New(err)
+		return
+	}
+	defer func() {
+		err = ex.Append(err, file.Close())
+	}()
+	var info os.FileInfo
+	if info, err = file.Stat(); err != nil {
+		err = ex.New(err)
+		return
+	}
+	offset := info.Size()
+	if offset > 0 {
+		r.Request.Header.Set(webutil.HeaderRange, fmt.Sprintf("bytes=%d-", offset))
+	}
+
+	res, err = r.Do()
+	if err != nil {
+		res = nil
+		err = ex.New(err)
+		return
+	}
+	defer func() {
+		err = ex.Append(err, res.Body.Close())
+	}()
+
+	switch res.StatusCode {
+	case http.StatusOK:
+		offset = 0
+		if err = file.Truncate(0); err != nil {
+			err = ex.New(err)
+			return
+		}
+	case http.StatusPartialContent:
+		start, _, _, ok := parseContentRange(res.Header.Get(webutil.HeaderContentRange))
+		if !ok || start != offset {
+			err = ex.New(ErrDownloadRangeMismatch, ex.OptMessagef("offset: %d, content range: %q", offset, res.Header.Get(webutil.HeaderContentRange)))
+			return
+		}
+	case http.StatusRequestedRangeNotSatisfiable:
+		// the range is only unsatisfiable for a complete file if the complete length matches.
+		if _, _, completeLength, ok := parseContentRange(res.Header.Get(webutil.HeaderContentRange)); offset > 0 && ok && completeLength == offset {
+			return
+		}
+		err = ex.New(ErrDownloadRangeMismatch, ex.OptMessagef("offset: %d, content range: %q", offset, res.Header.Get(webutil.HeaderContentRange)))
+		return
+	default:
+		err = ex.New(ErrDownloadStatus, ex.OptMessagef("status: %d", res.StatusCode))
+		return
+	}
+
+	if _, err = file.Seek(offset, io.SeekStart); err != nil {
+		err = ex.New(err)
+		return
+	}
+	written, err = io.Copy(file, res.Body)
+	if err != nil {
+		err = ex.New(err)
+		return
+	}
+	return
+}
+
+// parseContentRange parses a `Content-Range` header value, e.g. `bytes 100-199/1000` or
+// `bytes */1000`, returning -1 for the start and end of an unsatisfied range and for an
+// unknown complete length.
+func parseContentRange(value string) (start, end, completeLength int64, ok bool) {
+	if !strings.HasPrefix(value, "bytes ") {
+		return
+	}
+	value = strings.TrimSpace(strings.TrimPrefix(value, "bytes "))
+	byteRange, length, found := strings.Cut(value, "/")
+	if !found {
+		return
+	}
+	var err error
+	if length == "*" {
+		completeLength = -1
+	} else if completeLength, err = strconv.ParseInt(length, 10, 64); err != nil {
+		return
+	}
+	if byteRange == "*" {
+		return -1, -1, completeLength, completeLength >= 0
+	}
+	first, last, found := strings.Cut(byteRange, "-")
+	if !found {
+		return
+	}
+	if start, err = strconv.ParseInt(first, 10, 64); err != nil {
+		return
+	}
+	if end, err = strconv.ParseInt(last, 10, 64); err != nil || end < start {
+		return
+	}
+	ok = true
+	return
+}
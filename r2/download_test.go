@@ -0,0 +1,145 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package r2
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/blend/go-sdk/assert"
+	"github.com/blend/go-sdk/ex"
+	"github.com/blend/go-sdk/webutil"
+)
+
+const downloadTestContents = "this is a test of resumable downloads"
+
+func mockServerContent(ranges *[]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*ranges = append(*ranges, r.Header.Get(webutil.HeaderRange))
+		http.ServeContent(w, r, "download.txt", time.Time{}, strings.NewReader(downloadTestContents))
+	}))
+}
+
+func TestRequestDownloadFile(t *testing.T) {
+	its := assert.New(t)
+
+	var ranges []string
+	server := mockServerContent(&ranges)
+	defer server.Close()
+	path := filepath.Join(t.TempDir(), "download.txt")
+
+	written, res, err := New(server.URL).DownloadFile(path)
+	its.Nil(err)
+	its.Equal(http.StatusOK, res.StatusCode)
+	its.Equal(len(downloadTestContents), written)
+	contents, err := os.ReadFile(path)
+	its.Nil(err)
+	its.Equal(downloadTestContents, string(contents))
+
+	// resume a partial download.
+	its.Nil(os.WriteFile(path, []byte(downloadTestContents[:10]), 0644))
+	var transferred, total int64
+	written, res, err = New(server.URL, OptDownloadProgress(func(t, tt int64) { transferred, total = t, tt })).DownloadFile(path)
+	its.Nil(err)
+	its.Equal(http.StatusPartialContent, res.StatusCode)
+	its.Equal(len(downloadTestContents)-10, written)
+	its.Equal(len(downloadTestContents), transferred)
+	its.Equal(len(downloadTestContents), total)
+	contents, err = os.ReadFile(path)
+	its.Nil(err)
+	its.Equal(downloadTestContents, string(contents))
+
+	// a complete download is not written again.
+	written, res, err = New(server.URL).DownloadFile(path)
+	its.Nil(err)
+	its.Equal(http.StatusRequestedRangeNotSatisfiable, res.StatusCode)
+	its.Zero(written)
+	contents, err = os.ReadFile(path)
+	its.Nil(err)
+	its.Equal(downloadTestContents, string(contents))
+
+	its.Equal([]string{"", "bytes=10-", "bytes=37-"}, ranges)
+}
+
+func TestRequestDownloadFileRangeIgnored(t *testing.T) {
+	its := assert.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(downloadTestContents))
+	}))
+	defer server.Close()
+	path := filepath.Join(t.TempDir(), "download.txt")
+	its.Nil(os.WriteFile(path, bytes.Repeat([]byte("x"), 64), 0644))
+
+	written, _, err := New(server.URL).DownloadFile(path)
+	its.Nil(err)
+	its.Equal(len(downloadTestContents), written)
+	contents, err := os.ReadFile(path)
+	its.Nil(err)
+	its.Equal(downloadTestContents, string(contents))
+}
+
+func TestRequestDownloadFileErrors(t *testing.T) {
+	its := assert.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(webutil.HeaderRange) != "" {
+			w.Header().Set(webutil.HeaderContentRange, "bytes 0-3/4")
+			w.WriteHeader(http.StatusPartialContent)
+			_, _ = w.Write([]byte("test"))
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+	path := filepath.Join(t.TempDir(), "download.txt")
+
+	_, res, err := New(server.URL).DownloadFile(path)
+	its.True(ex.Is(err, ErrDownloadStatus))
+	its.Equal(http.StatusNotFound, res.StatusCode)
+
+	its.Nil(os.WriteFile(path, []byte("partial"), 0644))
+	_, _, err = New(server.URL).DownloadFile(path)
+	its.True(ex.Is(err, ErrDownloadRangeMismatch))
+	contents, err := os.ReadFile(path)
+	its.Nil(err)
+	its.Equal("partial", string(contents))
+}
+
+func TestParseContentRange(t *testing.T) {
+	its := assert.New(t)
+
+	start, end, completeLength, ok := parseContentRange("bytes 100-199/1000")
+	its.True(ok)
+	its.Equal(100, start)
+	its.Equal(199, end)
+	its.Equal(1000, completeLength)
+
+	start, _, completeLength, ok = parseContentRange("bytes 100-199/*")
+	its.True(ok)
+	its.Equal(100, start)
+	its.Equal(-1, completeLength)
+
+	start, end, completeLength, ok = parseContentRange("bytes */1000")
+	its.True(ok)
+	its.Equal(-1, start)
+	its.Equal(-1, end)
+	its.Equal(1000, completeLength)
+
+	for _, invalid := range []string{"", "bytes", "items 0-1/2", "bytes 1-0/2", "bytes 0-1", "bytes a-1/2", "bytes */*"} {
+		_, _, _, ok = parseContentRange(invalid)
+		its.False(ok, invalid)
+	}
+}
@@ -34,6 +34,21 @@ const (
 	// the parameterized path string has a different number of parameters than what was passed as
 	// variadic arguments.
 	ErrMismatchedPathParameters ex.Class = "r2; route parameters provided don't match parameters needed in path"
+	// ErrJSONArrayExpected is an error returned from `r2.Request.JSONArray()` if the response body
+	// is not a json array.
+	ErrJSONArrayExpected ex.Class = "r2; response body is not a json array"
+	// ErrEventStreamStatus is an error returned from `r2.Request.EventStream()` if the server
+	// responds with a status other than 200 or 204.
+	ErrEventStreamStatus ex.Class = "r2; event stream returned an unexpected status"
+	// ErrEventStreamContentType is an error returned from `r2.Request.EventStream()` if the server
+	// responds with a content type other than `text/event-stream`.
+	ErrEventStreamContentType ex.Class = "r2; event stream returned an unexpected content type"
+	// ErrDownloadStatus is an error returned from `r2.Request.DownloadFile()` if the server
+	// responds with a status other than 200, 206 or 416.
+	ErrDownloadStatus ex.Class = "r2; download returned an unexpected status"
+	// ErrDownloadRangeMismatch is an error returned from `r2.Request.DownloadFile()` if the server
+	// responds with a range that does not resume from the end of the file.
+	ErrDownloadRangeMismatch ex.Class = "r2; download returned a range that does not match the file"
 )
 
 // ErrIsTooManyRedirects returns if the error is too many redirects.
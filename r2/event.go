@@ -74,6 +74,8 @@ type Event struct {
 	Body []byte
 	// Elapsed is the time elapsed.
 	Elapsed time.Duration
+	// Attempt is the attempt number of a retried request, or 0 if the request does not retry.
+	Attempt uint
 }
 
 // GetFlag implements logger.Event.
@@ -86,6 +88,9 @@ func (e Event) WriteText(tf logger.TextFormatter, wr io.Writer) {
 	} else if e.Request != nil {
 		fmt.Fprintf(wr, "%s %s", e.Request.Method, e.Request.URL.String())
 	}
+	if e.Attempt > 1 {
+		fmt.Fprintf(wr, " attempt=%d", e.Attempt)
+	}
 	if e.Body != nil {
 		fmt.Fprint(wr, logger.Newline)
 		fmt.Fprint(wr, string(e.Body))
@@ -120,6 +125,9 @@ func (e Event) Decompose() map[string]interface{} {
 	if e.Body != nil {
 		output["body"] = string(e.Body)
 	}
+	if e.Attempt > 0 {
+		output["attempt"] = e.Attempt
+	}
 
 	return output
 }
@@ -138,7 +146,8 @@ type EventJSONSchema struct {
 		ContentLength int                 `json:"contentLength"`
 		Headers       map[string][]string `json:"headers"`
 	} `json:"res"`
-	Body string `json:"body"`
+	Body    string `json:"body"`
+	Attempt uint   `json:"attempt,omitempty"`
 }
 
 func tryHeader(headers http.Header, keys ...string) string {
@@ -42,3 +42,10 @@ func OptEventBody(body []byte) EventOption {
 		e.Body = body
 	}
 }
+
+// OptEventAttempt sets the attempt number.
+func OptEventAttempt(attempt uint) EventOption {
+	return func(e *Event) {
+		e.Attempt = attempt
+	}
+}
@@ -0,0 +1,241 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package r2
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/blend/go-sdk/ex"
+	"github.com/blend/go-sdk/webutil"
+)
+
+// DefaultEventStreamReconnectDelay is the delay before reconnecting to an event stream
+// until the server sets one with a `retry` field.
+const DefaultEventStreamReconnectDelay = 3 * time.Second
+
+// ServerSentEvent is an event read from an event stream, e.g. one written by a `webutil.EventSource`.
+type ServerSentEvent struct {
+	// ID is the last event id set by the stream, which may have been set by a previous event.
+	ID string
+	// Event is the event name; it is empty for unnamed (i.e. `message`) events.
+	Event string
+	// Data is the event data, with multiple `data` lines joined by newlines.
+	Data string
+}
+
+// ServerSentEventHandler handles an event read from an event stream.
+//
+// Returning an error stops reading the stream, and the error is returned.
+type ServerSentEventHandler func(ServerSentEvent) error
+
+// EventStream holds the settings for reading an event stream.
+type EventStream struct {
+	// LastEventID is sent as the `Last-Event-ID` header of the first connection.
+	LastEventID string
+	// ReconnectDelay is the delay before reconnecting, until the server sets one with a `retry` field.
+	ReconnectDelay time.Duration
+	// MaxReconnects is the maximum number of reconnects without receiving an event; zero is unlimited.
+	MaxReconnects uint
+	// DisableReconnect disables reconnecting when the stream ends or the connection fails.
+	DisableReconnect bool
+}
+
+// ReconnectDelayOrDefault returns the reconnect delay or a default.
+func (es EventStream) ReconnectDelayOrDefault() time.Duration {
+	if es.ReconnectDelay > 0 {
+		return es.ReconnectDelay
+	}
+	return DefaultEventStreamReconnectDelay
+}
+
+// EventStreamOption mutates event stream settings.
+type EventStreamOption func(*EventStream)
+
+// OptEventStreamLastEventID sets the `Last-Event-ID` header of the first connection, e.g. to resume a previous stream.
+func OptEventStreamLastEventID(lastEventID string) EventStreamOption {
+	return func(es *EventStream) { es.LastEventID = lastEventID }
+}
+
+// OptEventStreamReconnectDelay sets the delay before reconnecting, until the server sets one with a `retry` field.
+func OptEventStreamReconnectDelay(d time.Duration) EventStreamOption {
+	return func(es *EventStream) { es.ReconnectDelay = d }
+}
+
+// OptEventStreamMaxReconnects sets the maximum number of reconnects without receiving an event.
+func OptEventStreamMaxReconnects(maxReconnects uint) EventStreamOption {
+	return func(es *EventStream) { es.MaxReconnects = maxReconnects }
+}
+
+// OptEventStreamDisableReconnect disables reconnecting when the stream ends or the connection fails.
+func OptEventStreamDisableReconnect() EventStreamOption {
+	return func(es *EventStream) { es.DisableReconnect = true }
+}
+
+// EventStream reads the response as a server-sent event stream, passing each event to the handler,
+// and returns the metadata of the last response.
+//
+// When the stream ends or the connection fails the request is sent again after the reconnect delay,
+// with a `Last-Event-ID` header of the last event id received. The stream stops without an error if
+// the server responds with a 204, and with an error if the server responds with any other status than
+// a 200, or with a content type other than `text/event-stream`. The stream also stops if the request
+// context is cancelled, or the handler returns an error.
+//
+// Unlike browsers, events without data are passed to the handler if they are named, so that events
+// like the `ping` heartbeat sent by `webutil.EventSource` can be observed.
+func (r Request) EventStream(handler ServerSentEventHandler, options ...EventStreamOption) (res *http.Response, err error) {
+	defer func() {
+		if closeErr := r.Close(); closeErr != nil {
+			err = ex.Append(err, closeErr)
+		}
+	}()
+	if r.Err != nil {
+		return nil, r.Err
+	}
+
+	var es EventStream
+	for _, option := range options {
+		option(&es)
+	}
+	ctx := r.context()
+	reader := &eventStreamReader{lastEventID: es.LastEventID, reconnectDelay: es.ReconnectDelayOrDefault()}
+	var reconnects uint
+	for {
+		var received bool
+		var stop bool
+		res, received, stop, err = r.eventStreamConnect(reader, handler)
+		if stop || es.DisableReconnect || ctx.Err() != nil {
+			return
+		}
+		if received {
+			reconnects = 0
+		}
+		if es.MaxReconnects > 0 && reconnects >= es.MaxReconnects {
+			return
+		}
+		reconnects++
+
+		timer := time.NewTimer(reader.reconnectDelay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return res, ex.New(ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
+
+// eventStreamConnect sends a request for the stream and reads events until it ends, returning
+// if any events were received and if the stream should stop rather than reconnect.
+func (r Request) eventStreamConnect(reader *eventStreamReader, handler ServerSentEventHandler) (res *http.Response, received, stop bool, err error) {
+	attempt := r
+	attempt.Request = r.Request.Clone(r.context())
+	attempt.Request.Header.Set(webutil.HeaderAccept, webutil.ContentTypeEventStream)
+	attempt.Request.Header.Set(webutil.HeaderCacheControl, "no-cache")
+	if reader.lastEventID != "" {
+		attempt.Request.Header.Set(webutil.HeaderLastEventID, reader.lastEventID)
+	}
+
+	res, err = attempt.Do()
+	if err != nil {
+		return nil, false, false, ex.New(err)
+	}
+	defer func() {
+		err = ex.Append(err, res.Body.Close())
+	}()
+
+	if res.StatusCode == http.StatusNoContent {
+		return res, false, true, nil
+	}
+	if res.StatusCode != http.StatusOK {
+		return res, false, true, ex.New(ErrEventStreamStatus, ex.OptMessagef("status: %d", res.StatusCode))
+	}
+	if mediaType, _, _ := mime.ParseMediaType(res.Header.Get(webutil.HeaderContentType)); mediaType != webutil.ContentTypeEventStream {
+		return res, false, true, ex.New(ErrEventStreamContentType, ex.OptMessagef("content type: %q", res.Header.Get(webutil.HeaderContentType)))
+	}
+
+	received, err = reader.read(r.context(), res.Body, handler)
+	if ex.Is(err, errEventStreamHandler) {
+		err = ex.ErrInner(err)
+		stop = true
+	}
+	return
+}
+
+// errEventStreamHandler wraps errors returned by the handler, as they stop the stream.
+const errEventStreamHandler ex.Class = "r2; event stream handler error"
+
+// eventStreamReader parses event streams, keeping the state that persists across connections.
+type eventStreamReader struct {
+	lastEventID    string
+	reconnectDelay time.Duration
+}
+
+// read reads events from a stream body until it ends, returning if any events were received.
+//
+// See https://html.spec.whatwg.org/multipage/server-sent-events.html#event-stream-interpretation
+func (esr *eventStreamReader) read(ctx context.Context, body io.Reader, handler ServerSentEventHandler) (received bool, err error) {
+	lines := bufio.NewReader(body)
+	var eventName string
+	var data []string
+	for {
+		if err = ctx.Err(); err != nil {
+			return received, ex.New(err)
+		}
+		var line string
+		line, err = lines.ReadString('\n')
+		if err != nil {
+			// an incomplete event at the end of the stream is discarded.
+			if err == io.EOF {
+				return received, nil
+			}
+			return received, ex.New(err)
+		}
+		line = strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+
+		if line == "" {
+			if eventName != "" || len(data) > 0 {
+				received = true
+				event := ServerSentEvent{ID: esr.lastEventID, Event: eventName, Data: strings.Join(data, "\n")}
+				if handlerErr := handler(event); handlerErr != nil {
+					return received, ex.New(errEventStreamHandler, ex.OptInner(handlerErr))
+				}
+			}
+			eventName, data = "", nil
+			continue
+		}
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+
+		field, value := line, ""
+		if index := strings.IndexByte(line, ':'); index >= 0 {
+			field, value = line[:index], strings.TrimPrefix(line[index+1:], " ")
+		}
+		switch field {
+		case "event":
+			eventName = value
+		case "data":
+			data = append(data, value)
+		case "id":
+			if !strings.ContainsRune(value, 0) {
+				esr.lastEventID = value
+			}
+		case "retry":
+			if milliseconds, parseErr := strconv.ParseUint(value, 10, 32); parseErr == nil {
+				esr.reconnectDelay = time.Duration(milliseconds) * time.Millisecond
+			}
+		}
+	}
+}
@@ -0,0 +1,148 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package r2
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/blend/go-sdk/assert"
+	"github.com/blend/go-sdk/ex"
+	"github.com/blend/go-sdk/webutil"
+)
+
+func TestRequestEventStream(t *testing.T) {
+	assert := assert.New(t)
+
+	var connections int32
+	var lastEventIDs []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lastEventIDs = append(lastEventIDs, r.Header.Get(webutil.HeaderLastEventID))
+		switch atomic.AddInt32(&connections, 1) {
+		case 1:
+			es := webutil.NewEventSource(w)
+			_ = es.StartSession()
+			_ = es.EventDataWithID("message", "hello", "1")
+			fmt.Fprintf(w, ": a comment\nretry: 1\n\n")
+			// an incomplete event is discarded.
+			fmt.Fprintf(w, "data: incomplete\n")
+		case 2:
+			es := webutil.NewEventSource(w)
+			_ = es.StartSession()
+			fmt.Fprintf(w, "data: a\r\ndata:b\r\n\r\n")
+			_ = es.EventDataWithID("update", "c\nd", "2")
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer server.Close()
+
+	var events []ServerSentEvent
+	res, err := New(server.URL).EventStream(func(event ServerSentEvent) error {
+		events = append(events, event)
+		return nil
+	}, OptEventStreamLastEventID("0"))
+	assert.Nil(err)
+	assert.Equal(http.StatusNoContent, res.StatusCode)
+	assert.Equal(3, atomic.LoadInt32(&connections))
+	assert.Equal([]string{"0", "1", "2"}, lastEventIDs)
+	assert.Equal([]ServerSentEvent{
+		{ID: "0", Event: "ping"},
+		{ID: "1", Event: "message", Data: "hello"},
+		{ID: "1", Event: "ping"},
+		{ID: "1", Data: "a\nb"},
+		{ID: "2", Event: "update", Data: "c\nd"},
+	}, events)
+}
+
+func TestRequestEventStreamMaxReconnects(t *testing.T) {
+	assert := assert.New(t)
+
+	var connections int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&connections, 1)
+		w.Header().Set(webutil.HeaderContentType, webutil.ContentTypeEventStream)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	_, err := New(server.URL).EventStream(func(_ ServerSentEvent) error { return nil },
+		OptEventStreamReconnectDelay(time.Millisecond),
+		OptEventStreamMaxReconnects(2),
+	)
+	assert.Nil(err)
+	assert.Equal(3, atomic.LoadInt32(&connections))
+
+	_, err = New(server.URL).EventStream(func(_ ServerSentEvent) error { return nil }, OptEventStreamDisableReconnect())
+	assert.Nil(err)
+	assert.Equal(4, atomic.LoadInt32(&connections))
+}
+
+func TestRequestEventStreamErrors(t *testing.T) {
+	assert := assert.New(t)
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+	res, err := New(failing.URL).EventStream(func(_ ServerSentEvent) error { return nil })
+	assert.True(ex.Is(err, ErrEventStreamStatus))
+	assert.Equal(http.StatusInternalServerError, res.StatusCode)
+
+	server := mockServerOK()
+	defer server.Close()
+	_, err = New(server.URL).EventStream(func(_ ServerSentEvent) error { return nil })
+	assert.True(ex.Is(err, ErrEventStreamContentType))
+
+	_, err = New(server.URL, OptMethod("BAD METHOD")).EventStream(func(_ ServerSentEvent) error { return nil }, OptEventStreamDisableReconnect())
+	assert.True(ex.Is(err, ErrInvalidMethod))
+}
+
+func TestRequestEventStreamHandlerError(t *testing.T) {
+	assert := assert.New(t)
+
+	var connections int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&connections, 1)
+		_ = webutil.NewEventSource(w).StartSession()
+	}))
+	defer server.Close()
+
+	handlerErr := errors.New("stop")
+	_, err := New(server.URL).EventStream(func(_ ServerSentEvent) error { return handlerErr })
+	assert.True(ex.Is(err, handlerErr))
+	assert.Equal(1, atomic.LoadInt32(&connections))
+}
+
+func TestRequestEventStreamCancelled(t *testing.T) {
+	assert := assert.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		es := webutil.NewEventSource(w)
+		_ = es.StartSession()
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var count int
+	_, err := New(server.URL, OptContext(ctx)).EventStream(func(_ ServerSentEvent) error {
+		count++
+		cancel()
+		return nil
+	})
+	assert.True(errors.Is(err, context.Canceled))
+	assert.Equal(1, count)
+}
@@ -45,6 +45,12 @@ func TestEventWriteString(t *testing.T) {
 	output2 := new(bytes.Buffer)
 	e.WriteText(logger.NewTextOutputFormatter(logger.OptTextNoColor()), output2)
 	assert.Equal("GET http://localhost/http://test.com 200 (1s)\nfoo", output2.String())
+
+	e.Attempt = 2
+	output3 := new(bytes.Buffer)
+	e.WriteText(logger.NewTextOutputFormatter(logger.OptTextNoColor()), output3)
+	assert.Equal("GET http://localhost/http://test.com 200 (1s) attempt=2\nfoo", output3.String())
+	assert.Equal(2, e.Decompose()["attempt"])
 }
 
 // eventJSONSchema is the json schema of the logger event.
@@ -0,0 +1,57 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package r2
+
+import (
+	"net/http"
+
+	"github.com/blend/go-sdk/breaker"
+)
+
+// CircuitBreakerOption mutates circuit breaker settings.
+type CircuitBreakerOption func(*CircuitBreaker)
+
+// OptCircuitBreaker wraps the request in a breaker from a group, by default a breaker per host.
+//
+// The group should be shared between requests, e.g. created once per client, so that failures
+// of one request open the breaker for the next. Requests that are rejected by an open breaker
+// are not sent or retried, and return a `breaker.ErrOpenState` or `breaker.ErrTooManyRequests` error.
+func OptCircuitBreaker(breakers *breaker.Group, options ...CircuitBreakerOption) Option {
+	return func(r *Request) error {
+		r.CircuitBreaker = &CircuitBreaker{Breakers: breakers}
+		for _, option := range options {
+			option(r.CircuitBreaker)
+		}
+		return nil
+	}
+}
+
+// OptCircuitBreakerKey sets the function that returns the name of the breaker for a request.
+func OptCircuitBreakerKey(key func(*http.Request) string) CircuitBreakerOption {
+	return func(cb *CircuitBreaker) { cb.Key = key }
+}
+
+// OptCircuitBreakerFailure sets the predicate that returns if an attempt is a failure.
+func OptCircuitBreakerFailure(isFailure CircuitBreakerFailurePredicate) CircuitBreakerOption {
+	return func(cb *CircuitBreaker) { cb.IsFailure = isFailure }
+}
+
+// OptCircuitBreakerFailureStatusCodes counts errors and responses with the given status codes as failures.
+func OptCircuitBreakerFailureStatusCodes(statusCodes ...int) CircuitBreakerOption {
+	return OptCircuitBreakerFailure(func(_ *http.Request, res *http.Response, err error) bool {
+		if err != nil {
+			return CircuitBreakerFailureOnErrors(nil, nil, err)
+		}
+		for _, statusCode := range statusCodes {
+			if res != nil && res.StatusCode == statusCode {
+				return true
+			}
+		}
+		return false
+	})
+}
@@ -0,0 +1,34 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package r2
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/blend/go-sdk/assert"
+	"github.com/blend/go-sdk/breaker"
+)
+
+func TestOptCircuitBreaker(t *testing.T) {
+	assert := assert.New(t)
+
+	r := New(TestURL)
+	assert.Nil(r.CircuitBreaker)
+
+	breakers := breaker.NewGroup()
+	r = New(TestURL, OptCircuitBreaker(breakers,
+		OptCircuitBreakerKey(func(req *http.Request) string { return req.URL.Path }),
+		OptCircuitBreakerFailureStatusCodes(http.StatusServiceUnavailable),
+	))
+	assert.NotNil(r.CircuitBreaker)
+	assert.True(breakers == r.CircuitBreaker.Breakers)
+	assert.Equal("/test", r.CircuitBreaker.KeyOrDefault(r.Request))
+	assert.True(r.CircuitBreaker.IsFailure(r.Request, &http.Response{StatusCode: http.StatusServiceUnavailable}, nil))
+	assert.False(r.CircuitBreaker.IsFailure(r.Request, &http.Response{StatusCode: http.StatusInternalServerError}, nil))
+}
@@ -16,7 +16,7 @@ import (
 // OptLogRequest adds OnRequest and OnResponse listeners to log that a call was made.
 func OptLogRequest(log logger.Log) Option {
 	return OptOnRequest(func(req *http.Request) error {
-		logger.MaybeTriggerContext(req.Context(), log, NewEvent(Flag, OptEventRequest(req)))
+		logger.MaybeTriggerContext(req.Context(), log, NewEvent(Flag, OptEventRequest(req), OptEventAttempt(GetAttempt(req.Context()))))
 		return nil
 	})
 }
@@ -25,6 +25,7 @@ func OptLogResponse(log logger.Triggerable) Option {
 		event := NewEvent(FlagResponse,
 			OptEventRequest(req),
 			OptEventResponse(res),
+			OptEventAttempt(GetAttempt(req.Context())),
 			OptEventElapsed(time.Now().UTC().Sub(startedUTC)),
 		)
 
@@ -54,6 +55,7 @@ func OptLogResponseWithBody(log logger.Triggerable) Option {
 		event := NewEvent(FlagResponse,
 			OptEventRequest(req),
 			OptEventResponse(res),
+			OptEventAttempt(GetAttempt(req.Context())),
 			OptEventBody(buffer.Bytes()),
 			OptEventElapsed(time.Now().UTC().Sub(started)),
 		)
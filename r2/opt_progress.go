@@ -0,0 +1,78 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package r2
+
+import (
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/blend/go-sdk/webutil"
+)
+
+// ProgressHandler is called as a request or response body is read with the bytes transferred so far
+// and the total bytes, which is -1 if it is unknown.
+type ProgressHandler func(transferred, total int64)
+
+// OptUploadProgress adds a handler that is called as the request body is sent.
+//
+// If the request is retried, progress starts over for each attempt.
+func OptUploadProgress(handler ProgressHandler) Option {
+	return OptOnRequest(func(req *http.Request) error {
+		if req.Body == nil || req.Body == http.NoBody {
+			return nil
+		}
+		total := req.ContentLength
+		if total <= 0 {
+			total = -1
+		}
+		req.Body = &progressReader{ReadCloser: req.Body, handler: handler, total: total}
+		return nil
+	})
+}
+
+// OptDownloadProgress adds a handler that is called as the response body is read.
+//
+// Partial content responses (e.g. from `DownloadFile` resuming a download) report
+// progress of the complete content, starting from the offset of the range.
+func OptDownloadProgress(handler ProgressHandler) Option {
+	return OptOnResponse(func(_ *http.Request, res *http.Response, _ time.Time, err error) error {
+		if err != nil || res == nil || res.Body == nil {
+			return nil
+		}
+		transferred, total := int64(0), res.ContentLength
+		if res.StatusCode == http.StatusPartialContent {
+			if start, _, completeLength, ok := parseContentRange(res.Header.Get(webutil.HeaderContentRange)); ok {
+				transferred, total = start, completeLength
+			}
+		}
+		if total < 0 {
+			total = -1
+		}
+		res.Body = &progressReader{ReadCloser: res.Body, handler: handler, transferred: transferred, total: total}
+		return nil
+	})
+}
+
+// progressReader calls a progress handler as a body is read.
+type progressReader struct {
+	io.ReadCloser
+	handler     ProgressHandler
+	transferred int64
+	total       int64
+}
+
+// Read implements io.Reader.
+func (pr *progressReader) Read(p []byte) (n int, err error) {
+	n, err = pr.ReadCloser.Read(p)
+	if n > 0 {
+		pr.transferred += int64(n)
+		pr.handler(pr.transferred, pr.total)
+	}
+	return
+}
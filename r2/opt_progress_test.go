@@ -0,0 +1,77 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package r2
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/blend/go-sdk/assert"
+	"github.com/blend/go-sdk/webutil"
+)
+
+func TestOptUploadProgress(t *testing.T) {
+	its := assert.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprint(w, readString(r.Body))
+	}))
+	defer server.Close()
+
+	var transferred, total int64
+	contents, _, err := New(server.URL,
+		OptPost(),
+		OptBodyBytes(bytes.Repeat([]byte("a"), 1<<16)),
+		OptUploadProgress(func(t, tt int64) { transferred, total = t, tt }),
+	).Bytes()
+	its.Nil(err)
+	its.Len(contents, 1<<16)
+	its.Equal(1<<16, transferred)
+	its.Equal(1<<16, total)
+
+	_, err = New(server.URL, OptUploadProgress(func(_, _ int64) { its.FailNow("should not report progress for empty bodies") })).Discard()
+	its.Nil(err)
+}
+
+func TestOptDownloadProgress(t *testing.T) {
+	its := assert.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(webutil.HeaderRange) != "" {
+			w.Header().Set(webutil.HeaderContentRange, "bytes 10-13/14")
+			w.WriteHeader(http.StatusPartialContent)
+			fmt.Fprint(w, "test")
+			return
+		}
+		w.Header().Set(webutil.HeaderContentLength, "14")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprint(w, "this is a test")
+	}))
+	defer server.Close()
+
+	var transferred, total int64
+	contents, _, err := New(server.URL, OptDownloadProgress(func(t, tt int64) { transferred, total = t, tt })).Bytes()
+	its.Nil(err)
+	its.Equal("this is a test", string(contents))
+	its.Equal(14, transferred)
+	its.Equal(14, total)
+
+	transferred, total = 0, 0
+	contents, _, err = New(server.URL,
+		OptHeaderValue(webutil.HeaderRange, "bytes=10-"),
+		OptDownloadProgress(func(t, tt int64) { transferred, total = t, tt }),
+	).Bytes()
+	its.Nil(err)
+	its.Equal("test", string(contents))
+	its.Equal(14, transferred)
+	its.Equal(14, total)
+}
@@ -0,0 +1,75 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package r2
+
+import "time"
+
+// RetryOption mutates retry settings.
+type RetryOption func(*Retry)
+
+// OptRetry enables retrying the request with the given retry options.
+//
+// By default a request is attempted `DefaultRetryMaxAttempts` times, and only
+// requests with idempotent methods are retried; see `OptRetryNonIdempotent`.
+//
+// The request body is rewound between attempts. Bodies that cannot be rewound
+// (i.e. the request has no `GetBody`) are read into memory before the first attempt.
+func OptRetry(options ...RetryOption) Option {
+	return func(r *Request) error {
+		if r.Retry == nil {
+			r.Retry = new(Retry)
+		}
+		for _, option := range options {
+			option(r.Retry)
+		}
+		return nil
+	}
+}
+
+// OptRetryMaxAttempts sets the maximum number of attempts, including the first.
+func OptRetryMaxAttempts(maxAttempts uint) RetryOption {
+	return func(r *Retry) { r.MaxAttempts = maxAttempts }
+}
+
+// OptRetryBackoff sets the delay before the first retry, which doubles each retry up to a maximum.
+func OptRetryBackoff(backoff, maxBackoff time.Duration) RetryOption {
+	return func(r *Retry) {
+		r.Backoff = backoff
+		r.MaxBackoff = maxBackoff
+	}
+}
+
+// OptRetryDisableJitter disables randomizing the delays between attempts.
+func OptRetryDisableJitter() RetryOption {
+	return func(r *Retry) { r.DisableJitter = true }
+}
+
+// OptRetryIgnoreRetryAfter disables using `Retry-After` response headers as the delay between attempts.
+func OptRetryIgnoreRetryAfter() RetryOption {
+	return func(r *Retry) { r.IgnoreRetryAfter = true }
+}
+
+// OptRetryAttemptTimeout sets the timeout for each attempt.
+func OptRetryAttemptTimeout(d time.Duration) RetryOption {
+	return func(r *Retry) { r.AttemptTimeout = d }
+}
+
+// OptRetryNonIdempotent allows retrying requests with methods that are not idempotent, e.g. `POST`.
+func OptRetryNonIdempotent() RetryOption {
+	return func(r *Retry) { r.RetryNonIdempotent = true }
+}
+
+// OptRetryOn sets the retry predicate.
+func OptRetryOn(predicate RetryPredicate) RetryOption {
+	return func(r *Retry) { r.ShouldRetry = predicate }
+}
+
+// OptRetryStatusCodes retries errors and responses with the given status codes.
+func OptRetryStatusCodes(statusCodes ...int) RetryOption {
+	return OptRetryOn(RetryAny(RetryOnErrors, RetryOnStatusCodes(statusCodes...)))
+}
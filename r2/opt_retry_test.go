@@ -0,0 +1,50 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package r2
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/blend/go-sdk/assert"
+)
+
+func TestOptRetry(t *testing.T) {
+	assert := assert.New(t)
+
+	r := New(TestURL)
+	assert.Nil(r.Retry)
+
+	r = New(TestURL, OptRetry())
+	assert.NotNil(r.Retry)
+
+	r = New(TestURL,
+		OptRetry(
+			OptRetryMaxAttempts(5),
+			OptRetryBackoff(time.Second, time.Minute),
+			OptRetryDisableJitter(),
+			OptRetryIgnoreRetryAfter(),
+			OptRetryAttemptTimeout(10*time.Second),
+		),
+		OptRetry(
+			OptRetryNonIdempotent(),
+			OptRetryStatusCodes(http.StatusInternalServerError),
+		),
+	)
+	assert.Equal(5, r.Retry.MaxAttempts)
+	assert.Equal(time.Second, r.Retry.Backoff)
+	assert.Equal(time.Minute, r.Retry.MaxBackoff)
+	assert.True(r.Retry.DisableJitter)
+	assert.True(r.Retry.IgnoreRetryAfter)
+	assert.Equal(10*time.Second, r.Retry.AttemptTimeout)
+	assert.True(r.Retry.RetryNonIdempotent)
+	assert.NotNil(r.Retry.ShouldRetry)
+	assert.True(r.Retry.ShouldRetry(r.Request, &http.Response{StatusCode: http.StatusInternalServerError}, nil))
+	assert.False(r.Retry.ShouldRetry(r.Request, &http.Response{StatusCode: http.StatusServiceUnavailable}, nil))
+}
@@ -0,0 +1,158 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package r2
+
+import (
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"strings"
+	"sync"
+
+	"github.com/blend/go-sdk/ex"
+	"github.com/blend/go-sdk/webutil"
+)
+
+// StreamedFile is a file for a multipart upload whose contents are read as the request is sent.
+type StreamedFile struct {
+	Key         string
+	FileName    string
+	ContentType string
+	// Contents is read when the request body is sent; it is closed once read if it is an `io.Closer`.
+	Contents io.Reader
+}
+
+// OptStreamedFiles adds multipart uploads to the request whose contents are streamed
+// through a pipe as the request is sent, rather than read into memory like `OptPostedFiles`.
+//
+// The length of the body is unknown, so it is sent with chunked transfer encoding. Requests
+// with retries (see `OptRetry`) read the whole body into memory before the first attempt,
+// as the contents cannot be rewound.
+//
+// Usage note: this option will also encode any currently provided
+// post form fields into the body as well, so you should make this the
+// last option in a list to capture those fields.
+func OptStreamedFiles(files ...StreamedFile) Option {
+	return func(r *Request) error {
+		if r.Request == nil {
+			return ErrRequestUnset
+		}
+		if r.Request.Header == nil {
+			r.Request.Header = make(http.Header)
+		}
+		body := newMultipartBody(r.Request.PostForm, files)
+		r.Request.Header.Set(webutil.HeaderContentType, body.writer.FormDataContentType())
+		r.Request.ContentLength = -1
+		r.Request.Body = body
+		r.Request.GetBody = nil
+		return nil
+	}
+}
+
+// newMultipartBody returns a new multipart body.
+func newMultipartBody(fields map[string][]string, files []StreamedFile) *multipartBody {
+	pr, pw := io.Pipe()
+	return &multipartBody{
+		fields: fields,
+		files:  files,
+		reader: pr,
+		pipe:   pw,
+		writer: multipart.NewWriter(pw),
+	}
+}
+
+// multipartBody is a request body that writes a multipart form to a pipe as it is read.
+//
+// The form is written by a goroutine that starts on the first read, so a body that is
+// never read does not leak the goroutine.
+type multipartBody struct {
+	fields map[string][]string
+	files  []StreamedFile
+
+	start  sync.Once
+	reader *io.PipeReader
+	pipe   *io.PipeWriter
+	writer *multipart.Writer
+}
+
+// Read implements io.Reader.
+func (mb *multipartBody) Read(p []byte) (int, error) {
+	mb.start.Do(func() {
+		go func() {
+			_ = mb.pipe.CloseWithError(mb.write())
+		}()
+	})
+	return mb.reader.Read(p)
+}
+
+// Close implements io.Closer.
+//
+// Closing the body before it is fully read stops writing the form; closing a body
+// that was never read closes any file contents that are closers.
+func (mb *multipartBody) Close() (err error) {
+	mb.start.Do(func() {
+		err = mb.closeContents()
+	})
+	return ex.Append(err, mb.reader.Close())
+}
+
+// write writes the form fields and files, closing any file contents that are closers.
+func (mb *multipartBody) write() (err error) {
+	defer func() {
+		err = ex.Append(err, mb.closeContents())
+	}()
+	for key, values := range mb.fields {
+		for _, value := range values {
+			if err = mb.writer.WriteField(key, value); err != nil {
+				return ex.New(err)
+			}
+		}
+	}
+	for _, file := range mb.files {
+		var part io.Writer
+		if file.ContentType != "" {
+			// custom header since CreateFormFile uses application/octet-stream by default
+			h := make(textproto.MIMEHeader)
+			h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`, escapeQuotes(file.Key), escapeQuotes(file.FileName)))
+			h.Set(webutil.HeaderContentType, file.ContentType)
+			part, err = mb.writer.CreatePart(h)
+		} else {
+			part, err = mb.writer.CreateFormFile(file.Key, file.FileName)
+		}
+		if err != nil {
+			return ex.New(err)
+		}
+		if file.Contents != nil {
+			if _, err = io.Copy(part, file.Contents); err != nil {
+				return ex.New(err)
+			}
+		}
+	}
+	if err = mb.writer.Close(); err != nil {
+		return ex.New(err)
+	}
+	return nil
+}
+
+// closeContents closes any file contents that are closers.
+func (mb *multipartBody) closeContents() (err error) {
+	for _, file := range mb.files {
+		if closer, ok := file.Contents.(io.Closer); ok {
+			err = ex.Append(err, closer.Close())
+		}
+	}
+	return
+}
+
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+func escapeQuotes(s string) string {
+	return quoteEscaper.Replace(s)
+}
@@ -0,0 +1,105 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package r2
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/blend/go-sdk/assert"
+	"github.com/blend/go-sdk/webutil"
+)
+
+type closeTracker struct {
+	io.Reader
+	closed bool
+}
+
+func (ct *closeTracker) Close() error {
+	ct.closed = true
+	return nil
+}
+
+func TestOptStreamedFiles(t *testing.T) {
+	its := assert.New(t)
+
+	var files []webutil.PostedFile
+	var fields, contentLengths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentLengths = append(contentLengths, r.Header.Get(webutil.HeaderContentLength))
+		var err error
+		files, err = webutil.PostedFiles(r)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		fields = r.MultipartForm.Value["field"]
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	contents := &closeTracker{Reader: strings.NewReader("this is a test")}
+	res, err := New(server.URL,
+		OptPost(),
+		OptPostFormValue("field", "value"),
+		OptStreamedFiles(
+			StreamedFile{Key: "form-key", FileName: "file.txt", Contents: contents},
+			StreamedFile{Key: "form-key-2", FileName: "file2.json", ContentType: ContentTypeApplicationJSON, Contents: strings.NewReader(`{"is":"a test"}`)},
+		),
+	).Discard()
+	its.Nil(err)
+	its.Equal(http.StatusOK, res.StatusCode)
+	its.True(contents.closed)
+	its.Equal([]string{""}, contentLengths)
+	its.Equal([]string{"value"}, fields)
+	its.Len(files, 2)
+	its.AnyCount(files, 1, func(v interface{}) bool {
+		file := v.(webutil.PostedFile)
+		return file.Key == "form-key" && file.FileName == "file.txt" && string(file.Contents) == "this is a test"
+	})
+	its.AnyCount(files, 1, func(v interface{}) bool {
+		file := v.(webutil.PostedFile)
+		return file.Key == "form-key-2" && file.FileName == "file2.json" && string(file.Contents) == `{"is":"a test"}`
+	})
+}
+
+func TestOptStreamedFilesClosed(t *testing.T) {
+	its := assert.New(t)
+
+	r := New(TestURL, OptPost(), OptStreamedFiles(StreamedFile{Key: "form-key", FileName: "file.txt", Contents: strings.NewReader("this is a test")}))
+	its.Nil(r.Err)
+	its.Equal(int64(-1), r.Request.ContentLength)
+
+	buffer := make([]byte, 8)
+	n, err := r.Request.Body.Read(buffer)
+	its.Nil(err)
+	its.NotZero(n)
+	its.Nil(r.Request.Body.Close())
+	_, err = io.ReadAll(r.Request.Body)
+	its.Equal(io.ErrClosedPipe, err)
+}
+
+func TestOptStreamedFilesTransportError(t *testing.T) {
+	its := assert.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	server.Close()
+
+	contents := &closeTracker{Reader: strings.NewReader("this is a test")}
+	_, err := New(server.URL,
+		OptPost(),
+		OptStreamedFiles(StreamedFile{Key: "form-key", FileName: "file.txt", Contents: contents}),
+	).Discard()
+	its.NotNil(err)
+	its.True(contents.closed)
+}
@@ -0,0 +1,54 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package r2
+
+import (
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/blend/go-sdk/ratelimiter"
+)
+
+// OptUploadThrottle throttles sending the request body to a rate of bytes per quantum, e.g. `(1<<20, time.Second)` for 1MiB/s.
+//
+// The body is not throttled if the rate is not positive.
+func OptUploadThrottle(rateBytes int64, rateQuantum time.Duration) Option {
+	return OptOnRequest(func(req *http.Request) error {
+		if req.Body == nil || req.Body == http.NoBody {
+			return nil
+		}
+		req.Body = throttledBody{
+			Reader: ratelimiter.NewReader(req.Context(), req.Body, ratelimiter.OptCopyRateBytes(rateBytes), ratelimiter.OptCopyRateQuantum(rateQuantum)),
+			Closer: req.Body,
+		}
+		return nil
+	})
+}
+
+// OptDownloadThrottle throttles reading the response body to a rate of bytes per quantum, e.g. `(1<<20, time.Second)` for 1MiB/s.
+//
+// The body is not throttled if the rate is not positive.
+func OptDownloadThrottle(rateBytes int64, rateQuantum time.Duration) Option {
+	return OptOnResponse(func(req *http.Request, res *http.Response, _ time.Time, err error) error {
+		if err != nil || res == nil || res.Body == nil {
+			return nil
+		}
+		res.Body = throttledBody{
+			Reader: ratelimiter.NewReader(req.Context(), res.Body, ratelimiter.OptCopyRateBytes(rateBytes), ratelimiter.OptCopyRateQuantum(rateQuantum)),
+			Closer: res.Body,
+		}
+		return nil
+	})
+}
+
+// throttledBody is a throttled reader that closes the body it reads from.
+type throttledBody struct {
+	io.Reader
+	io.Closer
+}
@@ -0,0 +1,75 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package r2
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/blend/go-sdk/assert"
+)
+
+func TestOptUploadThrottle(t *testing.T) {
+	its := assert.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprint(w, readString(r.Body))
+	}))
+	defer server.Close()
+
+	started := time.Now()
+	contents, _, err := New(server.URL,
+		OptPost(),
+		OptBodyBytes(bytes.Repeat([]byte("a"), 1<<16)),
+		// 64KiB at 256KiB/s is at least ~250ms, less the first chunk.
+		OptUploadThrottle(1<<18, time.Second),
+	).Bytes()
+	its.Nil(err)
+	its.Len(contents, 1<<16)
+	its.True(time.Since(started) >= 150*time.Millisecond)
+}
+
+func TestOptDownloadThrottle(t *testing.T) {
+	its := assert.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(bytes.Repeat([]byte("a"), 1<<16))
+	}))
+	defer server.Close()
+
+	started := time.Now()
+	contents, _, err := New(server.URL, OptDownloadThrottle(1<<18, time.Second)).Bytes()
+	its.Nil(err)
+	its.Len(contents, 1<<16)
+	its.True(time.Since(started) >= 150*time.Millisecond)
+}
+
+func TestOptThrottleUnthrottled(t *testing.T) {
+	its := assert.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprint(w, readString(r.Body))
+	}))
+	defer server.Close()
+
+	contents, _, err := New(server.URL,
+		OptPost(),
+		OptBodyBytes([]byte("this is a test")),
+		OptUploadThrottle(0, time.Second),
+		OptDownloadThrottle(0, time.Second),
+	).Bytes()
+	its.Nil(err)
+	its.Equal("this is a test", string(contents))
+}
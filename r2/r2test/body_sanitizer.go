@@ -0,0 +1,52 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package r2test
+
+import (
+	"mime"
+	"net/http"
+	"net/url"
+
+	"github.com/blend/go-sdk/sanitize"
+	"github.com/blend/go-sdk/webutil"
+)
+
+// BodySanitizer returns a sanitized copy of a request body before it is matched or recorded.
+type BodySanitizer func(req *http.Request, body []byte) []byte
+
+// SanitizeFormBody returns a body sanitizer that redacts the values of form encoded request bodies
+// (i.e. `application/x-www-form-urlencoded`) for the disallowed query parameters of a request sanitizer,
+// e.g. the `client_secret` of an oauth token request.
+//
+// Other bodies, and form bodies without disallowed keys, are returned unchanged.
+func SanitizeFormBody(sanitizer sanitize.RequestSanitizer) BodySanitizer {
+	return func(req *http.Request, body []byte) []byte {
+		if len(body) == 0 {
+			return body
+		}
+		mediaType, _, err := mime.ParseMediaType(req.Header.Get(webutil.HeaderContentType))
+		if err != nil || mediaType != webutil.ContentTypeApplicationFormEncoded {
+			return body
+		}
+		values, err := url.ParseQuery(string(body))
+		if err != nil {
+			return body
+		}
+		var sanitized bool
+		for key, keyValues := range values {
+			if sanitizer.IsQueryParamDisallowed(key) {
+				values[key] = sanitizer.KeyValuesSanitizer.SanitizeKeyValues(key, keyValues...)
+				sanitized = true
+			}
+		}
+		if !sanitized {
+			return body
+		}
+		return []byte(values.Encode())
+	}
+}
@@ -0,0 +1,169 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package r2test
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/blend/go-sdk/ex"
+)
+
+// LoadCassette reads a cassette from a json or yaml file, based on the file extension.
+//
+// If the file does not exist, it returns an empty cassette for the path.
+func LoadCassette(path string) (*Cassette, error) {
+	cassette := Cassette{Path: path}
+	contents, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &cassette, nil
+	}
+	if err != nil {
+		return nil, ex.New(err)
+	}
+	if isYAML(path) {
+		err = yaml.Unmarshal(contents, &cassette)
+	} else {
+		err = json.Unmarshal(contents, &cassette)
+	}
+	if err != nil {
+		return nil, ex.New(ErrCassetteInvalid, ex.OptMessagef("path: %s", path), ex.OptInner(err))
+	}
+	if err = cassette.decodeBodies(); err != nil {
+		return nil, ex.New(ErrCassetteInvalid, ex.OptMessagef("path: %s", path), ex.OptInner(err))
+	}
+	return &cassette, nil
+}
+
+// BodyEncodingBase64 marks a body that is stored base64 encoded in a cassette file
+// because it is not valid utf-8.
+const BodyEncodingBase64 = "base64"
+
+// Cassette is a set of recorded interactions.
+type Cassette struct {
+	// Path is the file the cassette is read from and saved to.
+	Path string `json:"-" yaml:"-"`
+	// Interactions are the recorded request and response pairs, in the order they were recorded.
+	Interactions []Interaction `json:"interactions" yaml:"interactions"`
+}
+
+// Save writes the cassette to its path as json or yaml, based on the file extension.
+func (c *Cassette) Save() error {
+	encoded := c.encodeBodies()
+	var contents []byte
+	var err error
+	if isYAML(c.Path) {
+		contents, err = yaml.Marshal(encoded)
+	} else {
+		contents, err = json.MarshalIndent(encoded, "", "\t")
+	}
+	if err != nil {
+		return ex.New(err)
+	}
+	if dir := filepath.Dir(c.Path); dir != "" {
+		if err = os.MkdirAll(dir, 0755); err != nil {
+			return ex.New(err)
+		}
+	}
+	if err = os.WriteFile(c.Path, contents, 0644); err != nil {
+		return ex.New(err)
+	}
+	return nil
+}
+
+// encodeBodies returns a copy of the cassette with bodies that are not valid utf-8 base64 encoded.
+func (c *Cassette) encodeBodies() *Cassette {
+	encoded := Cassette{Path: c.Path, Interactions: make([]Interaction, len(c.Interactions))}
+	for index, interaction := range c.Interactions {
+		interaction.Request.Body, interaction.Request.BodyEncoding = encodeBody(interaction.Request.Body)
+		interaction.Response.Body, interaction.Response.BodyEncoding = encodeBody(interaction.Response.Body)
+		encoded.Interactions[index] = interaction
+	}
+	return &encoded
+}
+
+// decodeBodies decodes the bodies of a cassette read from a file in place.
+func (c *Cassette) decodeBodies() (err error) {
+	for index := range c.Interactions {
+		interaction := &c.Interactions[index]
+		if interaction.Request.Body, err = decodeBody(interaction.Request.Body, interaction.Request.BodyEncoding); err != nil {
+			return
+		}
+		interaction.Request.BodyEncoding = ""
+		if interaction.Response.Body, err = decodeBody(interaction.Response.Body, interaction.Response.BodyEncoding); err != nil {
+			return
+		}
+		interaction.Response.BodyEncoding = ""
+	}
+	return
+}
+
+// Interaction is a recorded request and response pair.
+type Interaction struct {
+	Request  CassetteRequest  `json:"request" yaml:"request"`
+	Response CassetteResponse `json:"response" yaml:"response"`
+}
+
+// CassetteRequest is a recorded request.
+type CassetteRequest struct {
+	Method  string      `json:"method" yaml:"method"`
+	URL     string      `json:"url" yaml:"url"`
+	Headers http.Header `json:"headers,omitempty" yaml:"headers,omitempty"`
+	Body    string      `json:"body,omitempty" yaml:"body,omitempty"`
+	// BodyEncoding is `BodyEncodingBase64` in a cassette file if the body is not valid utf-8.
+	BodyEncoding string `json:"bodyEncoding,omitempty" yaml:"bodyEncoding,omitempty"`
+}
+
+// CassetteResponse is a recorded response.
+type CassetteResponse struct {
+	StatusCode int         `json:"statusCode" yaml:"statusCode"`
+	Headers    http.Header `json:"headers,omitempty" yaml:"headers,omitempty"`
+	Body       string      `json:"body,omitempty" yaml:"body,omitempty"`
+	// BodyEncoding is `BodyEncodingBase64` in a cassette file if the body is not valid utf-8.
+	BodyEncoding string `json:"bodyEncoding,omitempty" yaml:"bodyEncoding,omitempty"`
+}
+
+// encodeBody returns the body as stored in a cassette file, and its encoding.
+func encodeBody(body string) (string, string) {
+	if utf8.ValidString(body) {
+		return body, ""
+	}
+	return base64.StdEncoding.EncodeToString([]byte(body)), BodyEncodingBase64
+}
+
+// decodeBody returns a body stored in a cassette file with a given encoding.
+func decodeBody(body, encoding string) (string, error) {
+	switch encoding {
+	case "":
+		return body, nil
+	case BodyEncodingBase64:
+		decoded, err := base64.StdEncoding.DecodeString(body)
+		if err != nil {
+			return "", ex.New(err)
+		}
+		return string(decoded), nil
+	default:
+		return "", ex.New(ErrBodyEncodingInvalid, ex.OptMessagef("encoding: %s", encoding))
+	}
+}
+
+func isYAML(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yml", ".yaml":
+		return true
+	default:
+		return false
+	}
+}
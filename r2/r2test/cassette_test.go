@@ -0,0 +1,93 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package r2test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/blend/go-sdk/assert"
+	"github.com/blend/go-sdk/ex"
+)
+
+func TestLoadCassette(t *testing.T) {
+	it := assert.New(t)
+
+	dir := t.TempDir()
+	cassette, err := LoadCassette(filepath.Join(dir, "missing.json"))
+	it.Nil(err)
+	it.Empty(cassette.Interactions)
+
+	invalid := filepath.Join(dir, "invalid.json")
+	it.Nil(os.WriteFile(invalid, []byte("{not json"), 0644))
+	_, err = LoadCassette(invalid)
+	it.True(ex.Is(err, ErrCassetteInvalid))
+
+	path := filepath.Join(dir, "cassette.yaml")
+	cassette.Path = path
+	cassette.Interactions = []Interaction{{
+		Request:  CassetteRequest{Method: "GET", URL: "https://test.invalid/foo"},
+		Response: CassetteResponse{StatusCode: 200, Body: "bar\nbaz"},
+	}}
+	it.Nil(cassette.Save())
+
+	loaded, err := LoadCassette(path)
+	it.Nil(err)
+	it.Equal(path, loaded.Path)
+	it.Equal(cassette.Interactions, loaded.Interactions)
+}
+
+func TestCassetteBinaryBody(t *testing.T) {
+	binary := string([]byte{0x00, 0xff, 0xfe, 0x80, 'a', 0xc3})
+	for _, ext := range []string{".json", ".yaml"} {
+		t.Run(ext, func(t *testing.T) {
+			it := assert.New(t)
+
+			path := filepath.Join(t.TempDir(), "cassette"+ext)
+			cassette := Cassette{
+				Path: path,
+				Interactions: []Interaction{{
+					Request:  CassetteRequest{Method: "POST", URL: "https://test.invalid/foo", Body: binary},
+					Response: CassetteResponse{StatusCode: 200, Body: binary + "bar"},
+				}, {
+					Request:  CassetteRequest{Method: "GET", URL: "https://test.invalid/bar"},
+					Response: CassetteResponse{StatusCode: 200, Body: "bar\nbaz"},
+				}},
+			}
+			it.Nil(cassette.Save())
+
+			contents, err := os.ReadFile(path)
+			it.Nil(err)
+			it.True(strings.Contains(string(contents), BodyEncodingBase64))
+			it.Equal(binary, cassette.Interactions[0].Request.Body)
+			it.Empty(cassette.Interactions[0].Request.BodyEncoding)
+
+			loaded, err := LoadCassette(path)
+			it.Nil(err)
+			it.Equal(cassette.Interactions, loaded.Interactions)
+		})
+	}
+}
+
+func TestLoadCassetteBodyEncodingInvalid(t *testing.T) {
+	it := assert.New(t)
+
+	dir := t.TempDir()
+	unknown := filepath.Join(dir, "unknown.json")
+	it.Nil(os.WriteFile(unknown, []byte(`{"interactions":[{"response":{"statusCode":200,"body":"bar","bodyEncoding":"rot13"}}]}`), 0644))
+	_, err := LoadCassette(unknown)
+	it.True(ex.Is(err, ErrCassetteInvalid))
+	it.True(ex.Is(ex.ErrInner(err), ErrBodyEncodingInvalid))
+
+	invalid := filepath.Join(dir, "invalid.json")
+	it.Nil(os.WriteFile(invalid, []byte(`{"interactions":[{"response":{"statusCode":200,"body":"!!!","bodyEncoding":"base64"}}]}`), 0644))
+	_, err = LoadCassette(invalid)
+	it.True(ex.Is(err, ErrCassetteInvalid))
+}
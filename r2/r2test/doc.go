@@ -31,5 +31,16 @@ During tests we can add a default option:
 	...
 
 We will now return the mocked response instead of reaching out to the remote for the call.
+
+For larger or more realistic fixtures, a `Recorder` records real interactions to a cassette file and replays them later:
+
+	recorder, err := r2test.NewRecorder("testdata/foos.yml", r2test.OptRecorderModeFromEnv())
+	...
+	defer recorder.Save()
+	a := APIClient{ Remote: "https://api.example.com", Defaults: []r2.Option{r2test.OptRecorder(recorder)} }
+
+Cassettes are replayed by default; set `R2TEST_RECORDER_MODE=record` to re-record them against the remote.
+Sensitive headers, query parameters and form body values (e.g. `Authorization` or `client_secret`) are redacted before they are saved.
+Bodies that are not valid utf-8 are saved base64 encoded, with a `bodyEncoding` of `base64`.
 */
 package r2test // import "github.com/blend/go-sdk/r2/r2test"
@@ -0,0 +1,22 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package r2test
+
+import "github.com/blend/go-sdk/ex"
+
+// Error Constants
+const (
+	// ErrCassetteInvalid is returned when a cassette file cannot be read.
+	ErrCassetteInvalid ex.Class = "r2test; invalid cassette"
+	// ErrBodyEncodingInvalid is returned when a cassette body has an unknown encoding.
+	ErrBodyEncodingInvalid ex.Class = "r2test; invalid cassette body encoding"
+	// ErrRecorderModeInvalid is returned when a recorder mode cannot be parsed.
+	ErrRecorderModeInvalid ex.Class = "r2test; invalid recorder mode"
+	// ErrInteractionNotFound is returned by a recorder in replay mode when no recorded interaction matches a request.
+	ErrInteractionNotFound ex.Class = "r2test; no recorded interaction matches request"
+)
@@ -0,0 +1,43 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package r2test
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Matcher returns if a recorded request matches a (sanitized) request and its body.
+type Matcher func(req *http.Request, body []byte, recorded CassetteRequest) bool
+
+// MatchMethod matches requests with the same method.
+func MatchMethod(req *http.Request, _ []byte, recorded CassetteRequest) bool {
+	return strings.EqualFold(req.Method, recorded.Method)
+}
+
+// MatchURL matches requests with the same url, including the query string.
+func MatchURL(req *http.Request, _ []byte, recorded CassetteRequest) bool {
+	return req.URL.String() == recorded.URL
+}
+
+// MatchBody matches requests with the same body.
+func MatchBody(_ *http.Request, body []byte, recorded CassetteRequest) bool {
+	return string(body) == recorded.Body
+}
+
+// MatchHeaders returns a matcher that matches requests with the same values for the given headers.
+func MatchHeaders(headers ...string) Matcher {
+	return func(req *http.Request, _ []byte, recorded CassetteRequest) bool {
+		for _, header := range headers {
+			if strings.Join(req.Header.Values(header), ",") != strings.Join(recorded.Headers.Values(header), ",") {
+				return false
+			}
+		}
+		return true
+	}
+}
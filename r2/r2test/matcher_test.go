@@ -0,0 +1,41 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package r2test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/blend/go-sdk/assert"
+)
+
+func TestMatchers(t *testing.T) {
+	it := assert.New(t)
+
+	req, err := http.NewRequest(http.MethodPost, "https://test.invalid/foo?bar=baz", nil)
+	it.Nil(err)
+	req.Header.Set("X-Tenant", "one")
+
+	recorded := CassetteRequest{
+		Method:  "post",
+		URL:     "https://test.invalid/foo?bar=baz",
+		Headers: http.Header{"X-Tenant": {"one"}},
+		Body:    "body",
+	}
+	it.True(MatchMethod(req, nil, recorded))
+	it.True(MatchURL(req, nil, recorded))
+	it.True(MatchBody(req, []byte("body"), recorded))
+	it.False(MatchBody(req, []byte("other"), recorded))
+	it.True(MatchHeaders("X-Tenant")(req, nil, recorded))
+	it.True(MatchHeaders("X-Missing")(req, nil, recorded))
+
+	recorded.URL = "https://test.invalid/foo"
+	recorded.Headers = http.Header{"X-Tenant": {"two"}}
+	it.False(MatchURL(req, nil, recorded))
+	it.False(MatchHeaders("X-Tenant")(req, nil, recorded))
+}
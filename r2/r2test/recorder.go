@@ -0,0 +1,262 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package r2test
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/blend/go-sdk/ex"
+	"github.com/blend/go-sdk/r2"
+	"github.com/blend/go-sdk/sanitize"
+)
+
+// Recorder modes.
+const (
+	// ModeReplay replays recorded interactions, and fails requests that do not match one.
+	ModeReplay RecorderMode = iota
+	// ModeRecord sends requests and records the interactions, replacing the cassette when saved.
+	ModeRecord
+	// ModeReplayOrRecord replays recorded interactions, and sends and records requests that do not match one.
+	ModeReplayOrRecord
+)
+
+// RecorderMode is the mode of a recorder.
+type RecorderMode int
+
+// EnvVarRecorderMode is the environment variable `OptRecorderModeFromEnv` reads the recorder mode from.
+const EnvVarRecorderMode = "R2TEST_RECORDER_MODE"
+
+// ParseRecorderMode parses a recorder mode, one of `replay`, `record` or `replay_or_record`.
+func ParseRecorderMode(value string) (RecorderMode, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "", "replay":
+		return ModeReplay, nil
+	case "record":
+		return ModeRecord, nil
+	case "replay_or_record":
+		return ModeReplayOrRecord, nil
+	default:
+		return ModeReplay, ex.New(ErrRecorderModeInvalid, ex.OptMessagef("mode: %q", value))
+	}
+}
+
+// Redacted is the value sensitive headers and query parameters are replaced with in cassettes.
+const Redacted = "[REDACTED]"
+
+// Assert Recorder implements http.RoundTripper.
+var (
+	_ http.RoundTripper = (*Recorder)(nil)
+)
+
+// NewRecorder returns a new recorder for a cassette file.
+//
+// By default the recorder replays interactions, matching requests by method and url, and redacts
+// the `sanitize` package's default disallowed headers and query parameters.
+func NewRecorder(path string, options ...RecorderOption) (*Recorder, error) {
+	cassette, err := LoadCassette(path)
+	if err != nil {
+		return nil, err
+	}
+	r := Recorder{
+		Cassette:  cassette,
+		Matchers:  []Matcher{MatchMethod, MatchURL},
+		Sanitizer: sanitize.NewRequestSanitizer(sanitize.OptRequestKeyValuesSanitizer(sanitize.KeyValuesSanitizerFunc(redact))),
+	}
+	for _, option := range options {
+		if err = option(&r); err != nil {
+			return nil, err
+		}
+	}
+	if r.Mode == ModeRecord {
+		r.Cassette.Interactions = nil
+	}
+	r.replayed = make([]bool, len(r.Cassette.Interactions))
+	return &r, nil
+}
+
+// Recorder is an http.RoundTripper that records interactions to, and replays them from, a cassette.
+//
+// Each recorded interaction is replayed at most once, in the order they were recorded, so a
+// sequence of identical requests replays the sequence of recorded responses.
+type Recorder struct {
+	sync.Mutex
+	// Mode is the recorder mode.
+	Mode RecorderMode
+	// Cassette holds the recorded interactions.
+	Cassette *Cassette
+	// Transport sends requests when recording; it defaults to `http.DefaultTransport`.
+	Transport http.RoundTripper
+	// Matchers must all match for a recorded interaction to be replayed for a request.
+	Matchers []Matcher
+	// Sanitizer redacts requests before they are matched or recorded.
+	Sanitizer sanitize.RequestSanitizer
+	// BodySanitizer redacts request bodies before they are matched or recorded; it defaults
+	// to `SanitizeFormBody` with the request sanitizer.
+	BodySanitizer BodySanitizer
+
+	replayed []bool
+}
+
+// RoundTrip implements http.RoundTripper.
+//
+// The recorder is only locked to find or add interactions, so requests are sent concurrently when recording.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+	sanitized := r.Sanitizer.Sanitize(req)
+	sanitizedBody := r.sanitizeBody(req, body)
+
+	if r.Mode != ModeRecord {
+		if interaction, ok := r.replay(sanitized, sanitizedBody); ok {
+			return interaction.Response.toResponse(req), nil
+		}
+		if r.Mode == ModeReplay {
+			return nil, ex.New(ErrInteractionNotFound, ex.OptMessagef("%s %s", sanitized.Method, sanitized.URL.String()))
+		}
+	}
+	return r.record(req, sanitized, sanitizedBody)
+}
+
+// Save writes the cassette if the recorder records interactions.
+func (r *Recorder) Save() error {
+	if r.Mode == ModeReplay {
+		return nil
+	}
+	r.Lock()
+	defer r.Unlock()
+	return r.Cassette.Save()
+}
+
+// replay returns the first matching interaction that has not been replayed.
+func (r *Recorder) replay(req *http.Request, body []byte) (interaction Interaction, ok bool) {
+	r.Lock()
+	defer r.Unlock()
+	for index, recorded := range r.Cassette.Interactions {
+		if r.replayed[index] || !r.matches(req, body, recorded.Request) {
+			continue
+		}
+		r.replayed[index] = true
+		interaction, ok = recorded, true
+		return
+	}
+	return
+}
+
+func (r *Recorder) matches(req *http.Request, body []byte, recorded CassetteRequest) bool {
+	for _, matcher := range r.Matchers {
+		if !matcher(req, body, recorded) {
+			return false
+		}
+	}
+	return true
+}
+
+// record sends the request and records the sanitized interaction with the sanitized request body.
+func (r *Recorder) record(req, sanitized *http.Request, body []byte) (*http.Response, error) {
+	transport := r.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	res, err := transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	resBody, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, ex.New(err)
+	}
+	res.Body = io.NopCloser(bytes.NewReader(resBody))
+
+	resHeaders := res.Header.Clone()
+	for header, values := range resHeaders {
+		if r.Sanitizer.IsHeaderDisallowed(header) {
+			resHeaders[header] = r.Sanitizer.KeyValuesSanitizer.SanitizeKeyValues(header, values...)
+		}
+	}
+	r.Lock()
+	defer r.Unlock()
+	r.Cassette.Interactions = append(r.Cassette.Interactions, Interaction{
+		Request: CassetteRequest{
+			Method:  sanitized.Method,
+			URL:     sanitized.URL.String(),
+			Headers: sanitized.Header,
+			Body:    string(body),
+		},
+		Response: CassetteResponse{
+			StatusCode: res.StatusCode,
+			Headers:    resHeaders,
+			Body:       string(resBody),
+		},
+	})
+	r.replayed = append(r.replayed, true)
+	return res, nil
+}
+
+// sanitizeBody returns the sanitized request body.
+func (r *Recorder) sanitizeBody(req *http.Request, body []byte) []byte {
+	if r.BodySanitizer != nil {
+		return r.BodySanitizer(req, body)
+	}
+	return SanitizeFormBody(r.Sanitizer)(req, body)
+}
+
+// toResponse returns the recorded response for a request.
+func (cr CassetteResponse) toResponse(req *http.Request) *http.Response {
+	header := cr.Headers.Clone()
+	if header == nil {
+		header = make(http.Header)
+	}
+	return &http.Response{
+		Status:        strconv.Itoa(cr.StatusCode) + " " + http.StatusText(cr.StatusCode),
+		StatusCode:    cr.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewBufferString(cr.Body)),
+		ContentLength: int64(len(cr.Body)),
+		Request:       req,
+	}
+}
+
+// readRequestBody reads the request body, and rewinds the request so it can be sent.
+func readRequestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, ex.New(err)
+	}
+	if err = req.Body.Close(); err != nil {
+		return nil, ex.New(err)
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+func redact(_ string, values ...string) []string {
+	output := make([]string, len(values))
+	for index := range values {
+		output[index] = Redacted
+	}
+	return output
+}
+
+// OptRecorder sets the request transport to a recorder.
+func OptRecorder(recorder *Recorder) r2.Option {
+	return r2.OptTransport(recorder)
+}
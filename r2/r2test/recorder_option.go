@@ -0,0 +1,83 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package r2test
+
+import (
+	"net/http"
+
+	"github.com/blend/go-sdk/env"
+	"github.com/blend/go-sdk/sanitize"
+)
+
+// RecorderOption mutates a recorder.
+type RecorderOption func(*Recorder) error
+
+// OptRecorderMode sets the recorder mode.
+func OptRecorderMode(mode RecorderMode) RecorderOption {
+	return func(r *Recorder) error {
+		r.Mode = mode
+		return nil
+	}
+}
+
+// OptRecorderModeFromEnv sets the recorder mode from the `R2TEST_RECORDER_MODE` environment variable,
+// leaving it unchanged if the variable is unset.
+//
+// This lets tests replay cassettes in CI, and record them locally with e.g. `R2TEST_RECORDER_MODE=record go test ./...`.
+func OptRecorderModeFromEnv() RecorderOption {
+	return func(r *Recorder) error {
+		value := env.Env().String(EnvVarRecorderMode)
+		if value == "" {
+			return nil
+		}
+		mode, err := ParseRecorderMode(value)
+		if err != nil {
+			return err
+		}
+		r.Mode = mode
+		return nil
+	}
+}
+
+// OptRecorderTransport sets the transport used to send requests when recording.
+func OptRecorderTransport(transport http.RoundTripper) RecorderOption {
+	return func(r *Recorder) error {
+		r.Transport = transport
+		return nil
+	}
+}
+
+// OptRecorderMatchers sets the matchers that must all match for a recorded interaction to be replayed.
+func OptRecorderMatchers(matchers ...Matcher) RecorderOption {
+	return func(r *Recorder) error {
+		r.Matchers = matchers
+		return nil
+	}
+}
+
+// OptRecorderSanitizer sets the request sanitizer options, e.g. `sanitize.OptRequestAddDisallowedHeaders`.
+//
+// Values of disallowed headers and query parameters are replaced with `Redacted` unless the
+// options set a different key values sanitizer.
+func OptRecorderSanitizer(options ...sanitize.RequestOption) RecorderOption {
+	return func(r *Recorder) error {
+		r.Sanitizer = sanitize.NewRequestSanitizer(append([]sanitize.RequestOption{
+			sanitize.OptRequestKeyValuesSanitizer(sanitize.KeyValuesSanitizerFunc(redact)),
+		}, options...)...)
+		return nil
+	}
+}
+
+// OptRecorderBodySanitizer sets the sanitizer for request bodies, which defaults to `SanitizeFormBody`
+// with the request sanitizer.
+func OptRecorderBodySanitizer(sanitizer BodySanitizer) RecorderOption {
+	return func(r *Recorder) error {
+		r.BodySanitizer = sanitizer
+		return nil
+	}
+}
@@ -0,0 +1,238 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package r2test
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/blend/go-sdk/assert"
+	"github.com/blend/go-sdk/ex"
+	"github.com/blend/go-sdk/r2"
+	"github.com/blend/go-sdk/sanitize"
+)
+
+func recorderTestServer() (*httptest.Server, *int) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		calls++
+		body, _ := io.ReadAll(r.Body)
+		rw.Header().Set("Set-Cookie", "session=secret")
+		rw.Header().Set("X-Call", fmt.Sprint(calls))
+		rw.WriteHeader(http.StatusOK)
+		fmt.Fprintf(rw, "%s %s %s", r.Method, r.URL.Path, string(body))
+	}))
+	return server, &calls
+}
+
+func TestRecorder(t *testing.T) {
+	for _, ext := range []string{".json", ".yml"} {
+		t.Run(ext, func(t *testing.T) {
+			it := assert.New(t)
+
+			server, calls := recorderTestServer()
+			path := filepath.Join(t.TempDir(), "testdata", "cassette"+ext)
+
+			recorder, err := NewRecorder(path, OptRecorderMode(ModeRecord))
+			it.Nil(err)
+			for x := 0; x < 2; x++ {
+				output, res, err := r2.New(server.URL+"/foo?access_token=secret",
+					OptRecorder(recorder),
+					r2.OptHeaderValue("Authorization", "Bearer secret"),
+				).Bytes()
+				it.Nil(err)
+				it.Equal(http.StatusOK, res.StatusCode)
+				it.Equal("GET /foo ", string(output))
+			}
+			output, _, err := r2.New(server.URL+"/bar", OptRecorder(recorder), r2.OptPost(), r2.OptBodyBytes([]byte("hello"))).Bytes()
+			it.Nil(err)
+			it.Equal("POST /bar hello", string(output))
+			it.Nil(recorder.Save())
+			server.Close()
+			it.Equal(3, *calls)
+
+			contents, err := os.ReadFile(path)
+			it.Nil(err)
+			it.False(strings.Contains(string(contents), "secret"))
+			it.True(strings.Contains(string(contents), Redacted))
+
+			replayer, err := NewRecorder(path)
+			it.Nil(err)
+			it.Len(replayer.Cassette.Interactions, 3)
+
+			// the server is closed, so responses must come from the cassette.
+			for x := 0; x < 2; x++ {
+				output, res, err := r2.New(server.URL+"/foo?access_token=other-secret",
+					OptRecorder(replayer),
+					r2.OptHeaderValue("Authorization", "Bearer other-secret"),
+				).Bytes()
+				it.Nil(err)
+				it.Equal(http.StatusOK, res.StatusCode)
+				it.Equal(fmt.Sprint(x+1), res.Header.Get("X-Call"))
+				it.Equal([]string{Redacted}, res.Header.Values("Set-Cookie"))
+				it.Equal("GET /foo ", string(output))
+			}
+			output, _, err = r2.New(server.URL+"/bar", OptRecorder(replayer), r2.OptPost(), r2.OptBodyBytes([]byte("hello"))).Bytes()
+			it.Nil(err)
+			it.Equal("POST /bar hello", string(output))
+
+			// each interaction is replayed once.
+			_, _, err = r2.New(server.URL+"/bar", OptRecorder(replayer), r2.OptPost()).Bytes()
+			it.True(ex.Is(err, ErrInteractionNotFound))
+		})
+	}
+}
+
+func TestRecorderMatchers(t *testing.T) {
+	it := assert.New(t)
+
+	recorder, err := NewRecorder(filepath.Join(t.TempDir(), "cassette.json"),
+		OptRecorderMatchers(MatchMethod, MatchURL, MatchBody, MatchHeaders("X-Tenant")),
+	)
+	it.Nil(err)
+	recorder.Cassette.Interactions = []Interaction{
+		{Request: CassetteRequest{Method: "POST", URL: r2.TestURL, Headers: http.Header{"X-Tenant": {"one"}}, Body: "one"}, Response: CassetteResponse{StatusCode: http.StatusOK, Body: "tenant one"}},
+		{Request: CassetteRequest{Method: "POST", URL: r2.TestURL, Headers: http.Header{"X-Tenant": {"two"}}, Body: "two"}, Response: CassetteResponse{StatusCode: http.StatusCreated, Body: "tenant two"}},
+	}
+	recorder.replayed = make([]bool, 2)
+
+	output, res, err := r2.New(r2.TestURL, OptRecorder(recorder), r2.OptPost(), r2.OptHeaderValue("X-Tenant", "two"), r2.OptBodyBytes([]byte("two"))).Bytes()
+	it.Nil(err)
+	it.Equal(http.StatusCreated, res.StatusCode)
+	it.Equal("tenant two", string(output))
+
+	_, _, err = r2.New(r2.TestURL, OptRecorder(recorder), r2.OptPost(), r2.OptHeaderValue("X-Tenant", "two"), r2.OptBodyBytes([]byte("one"))).Bytes()
+	it.True(ex.Is(err, ErrInteractionNotFound))
+}
+
+func TestRecorderReplayOrRecord(t *testing.T) {
+	it := assert.New(t)
+
+	server, calls := recorderTestServer()
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "cassette.yaml")
+	recorder, err := NewRecorder(path,
+		OptRecorderMode(ModeReplayOrRecord),
+		OptRecorderSanitizer(sanitize.OptRequestAddDisallowedHeaders("X-Api-Key")),
+	)
+	it.Nil(err)
+	_, err = r2.New(server.URL, OptRecorder(recorder), r2.OptHeaderValue("X-Api-Key", "secret")).Discard()
+	it.Nil(err)
+	it.Nil(recorder.Save())
+	it.Equal(1, *calls)
+	it.Equal([]string{Redacted}, recorder.Cassette.Interactions[0].Request.Headers.Values("X-Api-Key"))
+
+	recorder, err = NewRecorder(path, OptRecorderMode(ModeReplayOrRecord))
+	it.Nil(err)
+	_, err = r2.New(server.URL, OptRecorder(recorder)).Discard()
+	it.Nil(err)
+	it.Equal(1, *calls)
+	_, err = r2.New(server.URL+"/other", OptRecorder(recorder)).Discard()
+	it.Nil(err)
+	it.Equal(2, *calls)
+	it.Len(recorder.Cassette.Interactions, 2)
+}
+
+func TestRecorderFormBody(t *testing.T) {
+	it := assert.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+		fmt.Fprint(rw, "token")
+	}))
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	form := []byte("client_id=foo&client_secret=secret&grant_type=client_credentials")
+
+	recorder, err := NewRecorder(path, OptRecorderMode(ModeRecord))
+	it.Nil(err)
+	output, _, err := r2.New(server.URL+"/token",
+		OptRecorder(recorder),
+		r2.OptPost(),
+		r2.OptHeaderValue("Content-Type", "application/x-www-form-urlencoded"),
+		r2.OptBodyBytes(form),
+	).Bytes()
+	it.Nil(err)
+	it.Equal("token", string(output))
+	it.Nil(recorder.Save())
+	server.Close()
+
+	contents, err := os.ReadFile(path)
+	it.Nil(err)
+	it.False(strings.Contains(string(contents), "client_secret=secret"))
+	it.Equal("client_id=foo&client_secret=%5BREDACTED%5D&grant_type=client_credentials", recorder.Cassette.Interactions[0].Request.Body)
+
+	// bodies are sanitized before they are matched.
+	replayer, err := NewRecorder(path, OptRecorderMatchers(MatchMethod, MatchURL, MatchBody))
+	it.Nil(err)
+	output, _, err = r2.New(server.URL+"/token",
+		OptRecorder(replayer),
+		r2.OptPost(),
+		r2.OptHeaderValue("Content-Type", "application/x-www-form-urlencoded"),
+		r2.OptBodyBytes([]byte("client_id=foo&client_secret=other-secret&grant_type=client_credentials")),
+	).Bytes()
+	it.Nil(err)
+	it.Equal("token", string(output))
+
+	unsanitized, err := NewRecorder(path, OptRecorderBodySanitizer(func(_ *http.Request, body []byte) []byte { return body }))
+	it.Nil(err)
+	it.Equal(form, unsanitized.sanitizeBody(&http.Request{Header: http.Header{"Content-Type": {"application/x-www-form-urlencoded"}}}, form))
+	it.Equal([]byte(`{"client_secret":"secret"}`), recorder.sanitizeBody(&http.Request{Header: http.Header{"Content-Type": {"application/json"}}}, []byte(`{"client_secret":"secret"}`)))
+}
+
+func TestRecorderConcurrent(t *testing.T) {
+	it := assert.New(t)
+
+	// each request waits for the other to be sent, so they must be sent concurrently.
+	var arrived sync.WaitGroup
+	arrived.Add(2)
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		arrived.Done()
+		arrived.Wait()
+		rw.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	recorder, err := NewRecorder(filepath.Join(t.TempDir(), "cassette.json"), OptRecorderMode(ModeRecord))
+	it.Nil(err)
+
+	errs := make(chan error, 2)
+	for _, path := range []string{"/one", "/two"} {
+		go func(path string) {
+			_, err := r2.New(server.URL+path, OptRecorder(recorder), r2.OptTimeout(5*time.Second)).Discard()
+			errs <- err
+		}(path)
+	}
+	it.Nil(<-errs)
+	it.Nil(<-errs)
+	it.Len(recorder.Cassette.Interactions, 2)
+}
+
+func TestParseRecorderMode(t *testing.T) {
+	it := assert.New(t)
+
+	mode, err := ParseRecorderMode("")
+	it.Nil(err)
+	it.Equal(ModeReplay, mode)
+	mode, err = ParseRecorderMode("record")
+	it.Nil(err)
+	it.Equal(ModeRecord, mode)
+	mode, err = ParseRecorderMode("REPLAY_OR_RECORD")
+	it.Nil(err)
+	it.Equal(ModeReplayOrRecord, mode)
+	_, err = ParseRecorderMode("rewind")
+	it.True(ex.Is(err, ErrRecorderModeInvalid))
+}
@@ -68,6 +68,10 @@ type Request struct {
 	OnRequest []OnRequestListener
 	// OnResponse is an array of response lifecycle hooks used typically for logging.
 	OnResponse []OnResponseListener
+	// Retry holds the retry settings; if it is unset the request is sent once.
+	Retry *Retry
+	// CircuitBreaker is an optional circuit breaker each attempt is wrapped in.
+	CircuitBreaker *CircuitBreaker
 }
 
 // WithContext implements the `WithContext` method for the underlying request.
@@ -79,6 +83,9 @@ func (r *Request) WithContext(ctx context.Context) *Request {
 }
 
 // Do executes the request.
+//
+// If the request has retry settings (see `OptRetry`), each attempt is traced and
+// passed to the request and response listeners separately.
 func (r Request) Do() (*http.Response, error) {
 	if r.Err != nil {
 		return nil, r.Err
@@ -99,37 +106,111 @@ func (r Request) Do() (*http.Response, error) {
 		r.Request.GetBody = func() (io.ReadCloser, error) { return io.NopCloser(http.NoBody), nil }
 	}
 
+	if r.Retry != nil {
+		return r.doWithRetry(*r.Retry)
+	}
+	res, _, err := r.do(r.Request)
+	return res, err
+}
+
+// do sends a single attempt of the request, returning if it was aborted by a listener or the circuit breaker.
+func (r Request) do(req *http.Request) (res *http.Response, aborted bool, err error) {
 	started := time.Now().UTC()
 	var finisher TraceFinisher
 	if r.Tracer != nil {
-		finisher = r.Tracer.Start(r.Request)
+		finisher = r.Tracer.Start(req)
 	}
 	for _, listener := range r.OnRequest {
-		if err := listener(r.Request); err != nil {
-			return nil, err
+		if err = listener(req); err != nil {
+			return nil, true, err
 		}
 	}
 
-	var err error
-	var res *http.Response
-	if r.Client != nil {
-		res, err = r.Client.Do(r.Request)
-	} else {
-		res, err = http.DefaultClient.Do(r.Request)
+	var breakerDone func(*http.Response, error)
+	if r.CircuitBreaker != nil {
+		// requests rejected by the breaker are not sent, or retried.
+		breakerDone, err = r.CircuitBreaker.Allow(req)
+		aborted = err != nil
+	}
+	if err == nil {
+		if r.Client != nil {
+			res, err = r.Client.Do(req)
+		} else {
+			res, err = http.DefaultClient.Do(req)
+		}
+		if breakerDone != nil {
+			breakerDone(res, err)
+		}
 	}
 	if finisher != nil {
-		finisher.Finish(r.Request, res, started, err)
+		finisher.Finish(req, res, started, err)
 	}
 	for _, listener := range r.OnResponse {
-		if listenerErr := listener(r.Request, res, started, err); listenerErr != nil {
+		if listenerErr := listener(req, res, started, err); listenerErr != nil {
 			err = ex.Append(err, listenerErr)
-			return nil, err
+			return nil, true, err
 		}
 	}
 	if err != nil {
-		return nil, err
+		return nil, aborted, err
+	}
+	return res, false, nil
+}
+
+// doWithRetry sends attempts of the request until an attempt should not be retried.
+func (r Request) doWithRetry(retry Retry) (res *http.Response, err error) {
+	if r.Request.GetBody == nil {
+		if err = bufferBody(r.Request); err != nil {
+			return nil, err
+		}
+	}
+
+	ctx := r.Request.Context()
+	maxAttempts := retry.MaxAttemptsOrDefault()
+	shouldRetry := retry.ShouldRetryOrDefault()
+	for attempt := uint(1); ; attempt++ {
+		var attemptCtx context.Context
+		var cancel context.CancelFunc
+		if retry.AttemptTimeout > 0 {
+			attemptCtx, cancel = context.WithTimeout(WithAttempt(ctx, attempt), retry.AttemptTimeout)
+		} else {
+			attemptCtx, cancel = context.WithCancel(WithAttempt(ctx, attempt))
+		}
+		req := r.Request.WithContext(attemptCtx)
+		if attempt > 1 {
+			if req.Body, err = req.GetBody(); err != nil {
+				cancel()
+				return nil, ex.New(err)
+			}
+		}
+
+		var aborted bool
+		res, aborted, err = r.do(req)
+		if aborted || attempt >= maxAttempts || ctx.Err() != nil || !retry.CanRetry(req) || !shouldRetry(req, res, err) {
+			if res != nil {
+				// the attempt context must outlive reading the response body.
+				res.Body = cancelOnCloseBody{ReadCloser: res.Body, cancel: cancel}
+			} else {
+				cancel()
+			}
+			return res, err
+		}
+
+		delay := retry.Delay(attempt, res)
+		if res != nil {
+			_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, maxDiscardBytes))
+			_ = res.Body.Close()
+		}
+		cancel()
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ex.New(ctx.Err())
+		case <-timer.C:
+		}
 	}
-	return res, nil
 }
 
 // Close closes the request if there is a closer specified.
@@ -300,3 +381,36 @@ func (r Request) XML(dst interface{}) (res *http.Response, err error) {
 	}
 	return
 }
+
+// maxDiscardBytes is the most of a response body that is read before it is
+// closed between attempts, so that small bodies do not prevent connection reuse.
+const maxDiscardBytes = 4 << 10
+
+// bufferBody reads a request body into memory so that it can be rewound.
+func bufferBody(req *http.Request) error {
+	contents, err := io.ReadAll(req.Body)
+	if err != nil {
+		return ex.New(err)
+	}
+	if err = req.Body.Close(); err != nil {
+		return ex.New(err)
+	}
+	req.ContentLength = int64(len(contents))
+	req.Body = io.NopCloser(bytes.NewReader(contents))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(contents)), nil
+	}
+	return nil
+}
+
+// cancelOnCloseBody cancels a context when the body is closed.
+type cancelOnCloseBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+// Close implements io.Closer.
+func (c cancelOnCloseBody) Close() error {
+	defer c.cancel()
+	return c.ReadCloser.Close()
+}
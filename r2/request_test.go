@@ -13,9 +13,11 @@ import (
 	"encoding/xml"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -342,3 +344,152 @@ func TestRequestEmptyURL(t *testing.T) {
 	assert.Nil(err)
 	assert.Equal(http.StatusOK, res.StatusCode)
 }
+
+func mockServerFailing(failures int32, statusCode int, header http.Header) (*httptest.Server, *int32, *[]string) {
+	var attempts int32
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		bodies = append(bodies, readString(r.Body))
+		if atomic.AddInt32(&attempts, 1) <= failures {
+			for key, values := range header {
+				w.Header()[key] = values
+			}
+			w.WriteHeader(statusCode)
+			fmt.Fprintf(w, "failed!\n")
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintf(w, "OK!\n")
+	}))
+	return server, &attempts, &bodies
+}
+
+func TestRequestRetry(t *testing.T) {
+	assert := assert.New(t)
+
+	server, attempts, _ := mockServerFailing(2, http.StatusServiceUnavailable, nil)
+	defer server.Close()
+
+	var starts, finishes int
+	var eventAttempts []uint
+	tracer := MockTracer{
+		StartHandler: func(req *http.Request) {
+			starts++
+			eventAttempts = append(eventAttempts, GetAttempt(req.Context()))
+		},
+		FinishHandler: func(_ *http.Request, _ *http.Response, _ time.Time, _ error) {
+			finishes++
+		},
+	}
+
+	contents, res, err := New(server.URL,
+		OptTracer(tracer),
+		OptRetry(OptRetryBackoff(time.Millisecond, time.Millisecond)),
+	).Bytes()
+	assert.Nil(err)
+	assert.Equal(http.StatusOK, res.StatusCode)
+	assert.Equal("OK!\n", string(contents))
+	assert.Equal(3, atomic.LoadInt32(attempts))
+	assert.Equal(3, starts)
+	assert.Equal(3, finishes)
+	assert.Equal([]uint{1, 2, 3}, eventAttempts)
+}
+
+func TestRequestRetryExhausted(t *testing.T) {
+	assert := assert.New(t)
+
+	server, attempts, _ := mockServerFailing(5, http.StatusServiceUnavailable, nil)
+	defer server.Close()
+
+	res, err := New(server.URL,
+		OptRetry(OptRetryMaxAttempts(2), OptRetryBackoff(time.Millisecond, time.Millisecond)),
+	).Discard()
+	assert.Nil(err)
+	assert.Equal(http.StatusServiceUnavailable, res.StatusCode)
+	assert.Equal(2, atomic.LoadInt32(attempts))
+}
+
+func TestRequestRetryNonIdempotent(t *testing.T) {
+	assert := assert.New(t)
+
+	server, attempts, bodies := mockServerFailing(2, http.StatusServiceUnavailable, nil)
+	defer server.Close()
+
+	res, err := New(server.URL,
+		OptPost(),
+		OptBody(io.NopCloser(bytes.NewBufferString("hello"))),
+		OptRetry(OptRetryBackoff(time.Millisecond, time.Millisecond)),
+	).Discard()
+	assert.Nil(err)
+	assert.Equal(http.StatusServiceUnavailable, res.StatusCode)
+	assert.Equal(1, atomic.LoadInt32(attempts))
+
+	res, err = New(server.URL,
+		OptPost(),
+		OptBody(io.NopCloser(bytes.NewBufferString("hello"))),
+		OptRetry(OptRetryNonIdempotent(), OptRetryBackoff(time.Millisecond, time.Millisecond)),
+	).Discard()
+	assert.Nil(err)
+	assert.Equal(http.StatusOK, res.StatusCode)
+	assert.Equal(3, atomic.LoadInt32(attempts))
+	assert.Equal([]string{"hello", "hello", "hello"}, *bodies)
+}
+
+func TestRequestRetryAfter(t *testing.T) {
+	assert := assert.New(t)
+
+	server, attempts, _ := mockServerFailing(1, http.StatusTooManyRequests, http.Header{"Retry-After": []string{"0"}})
+	defer server.Close()
+
+	started := time.Now()
+	res, err := New(server.URL,
+		OptRetry(OptRetryBackoff(time.Minute, time.Minute)),
+	).Discard()
+	assert.Nil(err)
+	assert.Equal(http.StatusOK, res.StatusCode)
+	assert.Equal(2, atomic.LoadInt32(attempts))
+	assert.True(time.Since(started) < time.Minute)
+}
+
+func TestRequestRetryAttemptTimeout(t *testing.T) {
+	assert := assert.New(t)
+
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(time.Second):
+			}
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintf(w, "OK!\n")
+	}))
+	defer server.Close()
+
+	contents, res, err := New(server.URL,
+		OptRetry(OptRetryAttemptTimeout(50*time.Millisecond), OptRetryBackoff(time.Millisecond, time.Millisecond)),
+	).Bytes()
+	assert.Nil(err)
+	assert.Equal(http.StatusOK, res.StatusCode)
+	assert.Equal("OK!\n", string(contents))
+	assert.Equal(2, atomic.LoadInt32(&attempts))
+}
+
+func TestRequestRetryCancelled(t *testing.T) {
+	assert := assert.New(t)
+
+	server, attempts, _ := mockServerFailing(5, http.StatusServiceUnavailable, nil)
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := New(server.URL,
+		OptContext(ctx),
+		OptRetry(OptRetryMaxAttempts(5), OptRetryBackoff(time.Minute, time.Minute)),
+	).Discard()
+	assert.NotNil(err)
+	assert.True(errors.Is(err, context.DeadlineExceeded))
+	assert.Equal(1, atomic.LoadInt32(attempts))
+}
@@ -0,0 +1,197 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package r2
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/blend/go-sdk/webutil"
+)
+
+// Retry defaults.
+const (
+	DefaultRetryMaxAttempts = 3
+	DefaultRetryBackoff     = 100 * time.Millisecond
+	DefaultRetryMaxBackoff  = 10 * time.Second
+)
+
+// DefaultRetryStatusCodes are the response status codes that are retried by default.
+var DefaultRetryStatusCodes = []int{
+	http.StatusTooManyRequests,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// RetryPredicate returns if an attempt should be retried given its response or error.
+//
+// The response is nil if the error is set.
+type RetryPredicate func(*http.Request, *http.Response, error) bool
+
+// RetryOnErrors is a retry predicate that retries errors returned by the client,
+// e.g. connection errors or per attempt timeouts, unless the request was cancelled.
+//
+// Requests are never retried once the request context is done, regardless of the predicate.
+func RetryOnErrors(_ *http.Request, _ *http.Response, err error) bool {
+	return err != nil && !errors.Is(err, context.Canceled)
+}
+
+// RetryOnStatusCodes returns a retry predicate that retries responses with the given status codes.
+func RetryOnStatusCodes(statusCodes ...int) RetryPredicate {
+	return func(_ *http.Request, res *http.Response, err error) bool {
+		if err != nil || res == nil {
+			return false
+		}
+		for _, statusCode := range statusCodes {
+			if res.StatusCode == statusCode {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// RetryAny returns a retry predicate that retries if any of the given predicates do.
+func RetryAny(predicates ...RetryPredicate) RetryPredicate {
+	return func(req *http.Request, res *http.Response, err error) bool {
+		for _, predicate := range predicates {
+			if predicate(req, res, err) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Retry holds the retry settings for a request.
+//
+// Each attempt runs the request's tracer and its request and response listeners, so
+// each attempt is a separate span and a separate `r2.Event` if the request is logged.
+type Retry struct {
+	// MaxAttempts is the maximum number of attempts, including the first.
+	MaxAttempts uint
+	// Backoff is the delay before the first retry; it doubles each retry.
+	Backoff time.Duration
+	// MaxBackoff caps the delay between attempts, including delays from `Retry-After` headers.
+	MaxBackoff time.Duration
+	// DisableJitter disables randomizing delays between attempts.
+	DisableJitter bool
+	// IgnoreRetryAfter disables using the `Retry-After` header of a response as the delay.
+	IgnoreRetryAfter bool
+	// AttemptTimeout is the timeout for each attempt, including reading the response body of the final attempt.
+	AttemptTimeout time.Duration
+	// RetryNonIdempotent allows retrying requests with methods that are not idempotent, e.g. `POST` or `PATCH`.
+	RetryNonIdempotent bool
+	// ShouldRetry returns if an attempt should be retried.
+	// It defaults to retrying errors and `DefaultRetryStatusCodes`.
+	ShouldRetry RetryPredicate
+}
+
+// MaxAttemptsOrDefault returns the max attempts or a default.
+func (r Retry) MaxAttemptsOrDefault() uint {
+	if r.MaxAttempts > 0 {
+		return r.MaxAttempts
+	}
+	return DefaultRetryMaxAttempts
+}
+
+// BackoffOrDefault returns the backoff or a default.
+func (r Retry) BackoffOrDefault() time.Duration {
+	if r.Backoff > 0 {
+		return r.Backoff
+	}
+	return DefaultRetryBackoff
+}
+
+// MaxBackoffOrDefault returns the max backoff or a default.
+func (r Retry) MaxBackoffOrDefault() time.Duration {
+	if r.MaxBackoff > 0 {
+		return r.MaxBackoff
+	}
+	return DefaultRetryMaxBackoff
+}
+
+// ShouldRetryOrDefault returns the retry predicate or a default.
+func (r Retry) ShouldRetryOrDefault() RetryPredicate {
+	if r.ShouldRetry != nil {
+		return r.ShouldRetry
+	}
+	return RetryAny(RetryOnErrors, RetryOnStatusCodes(DefaultRetryStatusCodes...))
+}
+
+// CanRetry returns if a request can be retried based on its method.
+func (r Retry) CanRetry(req *http.Request) bool {
+	return r.RetryNonIdempotent || IsIdempotent(req.Method)
+}
+
+// Delay returns the delay before a given retry (starting at 1) after a response.
+//
+// If the response has a `Retry-After` header it is used as the delay, otherwise
+// the delay is the exponential backoff for the retry with jitter.
+func (r Retry) Delay(retry uint, res *http.Response) time.Duration {
+	maxBackoff := r.MaxBackoffOrDefault()
+	if !r.IgnoreRetryAfter && res != nil {
+		if retryAfter, ok := ParseRetryAfter(res.Header.Get(webutil.HeaderRetryAfter)); ok {
+			if retryAfter > maxBackoff {
+				return maxBackoff
+			}
+			return retryAfter
+		}
+	}
+
+	delay := r.BackoffOrDefault()
+	for index := uint(1); index < retry && delay < maxBackoff; index++ {
+		delay = delay * 2
+	}
+	if delay > maxBackoff {
+		delay = maxBackoff
+	}
+	if r.DisableJitter || delay < 2 {
+		return delay
+	}
+	// "equal" jitter keeps at least half the delay.
+	half := delay / 2
+	return half + time.Duration(rand.Int63n(int64(half)))
+}
+
+// IsIdempotent returns if a method is idempotent, and as a result can be safely retried.
+func IsIdempotent(method string) bool {
+	switch method {
+	case "", http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+// ParseRetryAfter parses a `Retry-After` header value, which is either
+// a number of seconds or an http date.
+func ParseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		delay := time.Until(date)
+		if delay < 0 {
+			delay = 0
+		}
+		return delay, true
+	}
+	return 0, false
+}
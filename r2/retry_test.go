@@ -0,0 +1,99 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package r2
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/blend/go-sdk/assert"
+)
+
+func TestRetryDefaults(t *testing.T) {
+	assert := assert.New(t)
+
+	var retry Retry
+	assert.Equal(DefaultRetryMaxAttempts, retry.MaxAttemptsOrDefault())
+	assert.Equal(DefaultRetryBackoff, retry.BackoffOrDefault())
+	assert.Equal(DefaultRetryMaxBackoff, retry.MaxBackoffOrDefault())
+
+	shouldRetry := retry.ShouldRetryOrDefault()
+	req := &http.Request{Method: MethodGet}
+	assert.True(shouldRetry(req, nil, fmt.Errorf("connection refused")))
+	assert.False(shouldRetry(req, nil, context.Canceled))
+	assert.True(shouldRetry(req, &http.Response{StatusCode: http.StatusServiceUnavailable}, nil))
+	assert.True(shouldRetry(req, &http.Response{StatusCode: http.StatusTooManyRequests}, nil))
+	assert.False(shouldRetry(req, &http.Response{StatusCode: http.StatusInternalServerError}, nil))
+	assert.False(shouldRetry(req, &http.Response{StatusCode: http.StatusOK}, nil))
+}
+
+func TestRetryCanRetry(t *testing.T) {
+	assert := assert.New(t)
+
+	var retry Retry
+	assert.True(retry.CanRetry(&http.Request{Method: MethodGet}))
+	assert.True(retry.CanRetry(&http.Request{Method: MethodPut}))
+	assert.True(retry.CanRetry(&http.Request{Method: MethodDelete}))
+	assert.False(retry.CanRetry(&http.Request{Method: MethodPost}))
+	assert.False(retry.CanRetry(&http.Request{Method: MethodPatch}))
+
+	retry.RetryNonIdempotent = true
+	assert.True(retry.CanRetry(&http.Request{Method: MethodPost}))
+}
+
+func TestRetryDelay(t *testing.T) {
+	assert := assert.New(t)
+
+	retry := Retry{Backoff: time.Second, MaxBackoff: 5 * time.Second, DisableJitter: true}
+	assert.Equal(time.Second, retry.Delay(1, nil))
+	assert.Equal(2*time.Second, retry.Delay(2, nil))
+	assert.Equal(4*time.Second, retry.Delay(3, nil))
+	assert.Equal(5*time.Second, retry.Delay(4, nil))
+	assert.Equal(5*time.Second, retry.Delay(64, nil))
+
+	res := &http.Response{Header: http.Header{"Retry-After": []string{"3"}}}
+	assert.Equal(3*time.Second, retry.Delay(1, res))
+	res.Header.Set("Retry-After", "30")
+	assert.Equal(5*time.Second, retry.Delay(1, res))
+
+	retry.IgnoreRetryAfter = true
+	assert.Equal(time.Second, retry.Delay(1, res))
+
+	retry.DisableJitter = false
+	for x := 0; x < 10; x++ {
+		delay := retry.Delay(2, nil)
+		assert.True(delay >= time.Second)
+		assert.True(delay < 2*time.Second)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	assert := assert.New(t)
+
+	_, ok := ParseRetryAfter("")
+	assert.False(ok)
+	_, ok = ParseRetryAfter("not a value")
+	assert.False(ok)
+	_, ok = ParseRetryAfter("-1")
+	assert.False(ok)
+
+	delay, ok := ParseRetryAfter("120")
+	assert.True(ok)
+	assert.Equal(2*time.Minute, delay)
+
+	delay, ok = ParseRetryAfter(time.Now().UTC().Add(time.Hour).Format(http.TimeFormat))
+	assert.True(ok)
+	assert.True(delay > 59*time.Minute)
+
+	delay, ok = ParseRetryAfter(time.Now().UTC().Add(-time.Hour).Format(http.TimeFormat))
+	assert.True(ok)
+	assert.Zero(delay)
+}
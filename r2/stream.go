@@ -0,0 +1,156 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package r2
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/blend/go-sdk/ex"
+)
+
+// StreamItemHandler handles an item decoded from a streamed response.
+//
+// Returning an error stops reading the response, and the error is returned.
+type StreamItemHandler func(item interface{}) error
+
+// NDJSON reads the response as newline delimited json, decoding each item into a new
+// value from `newItem` and passing it to the handler, and returns the response metadata.
+//
+// Items are decoded as they are read, so the response is never buffered in full.
+// Reading stops if the request context is cancelled.
+//
+// A typical use looks like:
+//
+//	res, err := r2.New(remoteURL).NDJSON(
+//		func() interface{} { return new(Foo) },
+//		func(item interface{}) error {
+//			foo := item.(*Foo)
+//			...
+//			return nil
+//		},
+//	)
+func (r Request) NDJSON(newItem func() interface{}, handler StreamItemHandler) (res *http.Response, err error) {
+	return r.stream(func(ctx context.Context, body io.Reader) error {
+		decoder := json.NewDecoder(body)
+		for {
+			if err := ctx.Err(); err != nil {
+				return ex.New(err)
+			}
+			item := newItem()
+			if err := decoder.Decode(item); err != nil {
+				if err == io.EOF {
+					return nil
+				}
+				return ex.New(err)
+			}
+			if err := handler(item); err != nil {
+				return err
+			}
+		}
+	})
+}
+
+// NDJSONChannel reads the response as newline delimited json, sending each item to a channel,
+// and returns the response metadata.
+//
+// The channel is closed when reading stops. Sends block until the item is received
+// or the request context is cancelled.
+func (r Request) NDJSONChannel(newItem func() interface{}, items chan<- interface{}) (*http.Response, error) {
+	defer close(items)
+	return r.NDJSON(newItem, r.sendItem(items))
+}
+
+// JSONArray reads the response as a top level json array, decoding each element into a new
+// value from `newItem` and passing it to the handler, and returns the response metadata.
+//
+// Elements are decoded as they are read, so large arrays are never buffered in full.
+// Reading stops if the request context is cancelled.
+func (r Request) JSONArray(newItem func() interface{}, handler StreamItemHandler) (res *http.Response, err error) {
+	return r.stream(func(ctx context.Context, body io.Reader) error {
+		decoder := json.NewDecoder(body)
+		token, err := decoder.Token()
+		if err != nil {
+			return ex.New(ErrJSONArrayExpected, ex.OptInner(err))
+		}
+		if delim, ok := token.(json.Delim); !ok || delim != '[' {
+			return ex.New(ErrJSONArrayExpected, ex.OptMessagef("first token: %v", token))
+		}
+		for decoder.More() {
+			if err = ctx.Err(); err != nil {
+				return ex.New(err)
+			}
+			item := newItem()
+			if err = decoder.Decode(item); err != nil {
+				return ex.New(err)
+			}
+			if err = handler(item); err != nil {
+				return err
+			}
+		}
+		// read the closing bracket so truncated responses are an error.
+		if _, err = decoder.Token(); err != nil {
+			return ex.New(err)
+		}
+		return nil
+	})
+}
+
+// JSONArrayChannel reads the response as a top level json array, sending each element to a channel,
+// and returns the response metadata.
+//
+// The channel is closed when reading stops. Sends block until the element is received
+// or the request context is cancelled.
+func (r Request) JSONArrayChannel(newItem func() interface{}, items chan<- interface{}) (*http.Response, error) {
+	defer close(items)
+	return r.JSONArray(newItem, r.sendItem(items))
+}
+
+// stream sends the request and passes the response body to a reader, closing both after.
+func (r Request) stream(read func(context.Context, io.Reader) error) (res *http.Response, err error) {
+	defer func() {
+		if closeErr := r.Close(); closeErr != nil {
+			err = ex.Append(err, closeErr)
+		}
+	}()
+
+	res, err = r.Do()
+	if err != nil {
+		res = nil
+		err = ex.New(err)
+		return
+	}
+	defer func() {
+		err = ex.Append(err, res.Body.Close())
+	}()
+	err = read(r.context(), res.Body)
+	return
+}
+
+// sendItem returns a handler that sends items to a channel until the request context is cancelled.
+func (r Request) sendItem(items chan<- interface{}) StreamItemHandler {
+	ctx := r.context()
+	return func(item interface{}) error {
+		select {
+		case <-ctx.Done():
+			return ex.New(ctx.Err())
+		case items <- item:
+			return nil
+		}
+	}
+}
+
+// context returns the request context.
+func (r Request) context() context.Context {
+	if r.Request != nil {
+		return r.Request.Context()
+	}
+	return context.Background()
+}
@@ -0,0 +1,191 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package r2
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/blend/go-sdk/assert"
+	"github.com/blend/go-sdk/ex"
+)
+
+type streamTestItem struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+func newStreamTestItem() interface{} {
+	return new(streamTestItem)
+}
+
+func mockServerString(contents string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprint(w, contents)
+	}))
+}
+
+func TestRequestNDJSON(t *testing.T) {
+	assert := assert.New(t)
+
+	server := mockServerString("{\"id\":1,\"name\":\"one\"}\n{\"id\":2,\"name\":\"two\"}\r\n\n{\"id\":3,\"name\":\"three\"}")
+	defer server.Close()
+
+	var items []streamTestItem
+	res, err := New(server.URL).NDJSON(newStreamTestItem, func(item interface{}) error {
+		items = append(items, *item.(*streamTestItem))
+		return nil
+	})
+	assert.Nil(err)
+	assert.Equal(http.StatusOK, res.StatusCode)
+	assert.Equal([]streamTestItem{{1, "one"}, {2, "two"}, {3, "three"}}, items)
+}
+
+func TestRequestNDJSONErrors(t *testing.T) {
+	assert := assert.New(t)
+
+	server := mockServerString("{\"id\":1}\n{\"id\":2}\n{\"id\":")
+	defer server.Close()
+
+	var count int
+	_, err := New(server.URL).NDJSON(newStreamTestItem, func(_ interface{}) error {
+		count++
+		return nil
+	})
+	assert.NotNil(err)
+	assert.Equal(2, count)
+
+	handlerErr := errors.New("stop")
+	count = 0
+	_, err = New(server.URL).NDJSON(newStreamTestItem, func(_ interface{}) error {
+		count++
+		return handlerErr
+	})
+	assert.True(ex.Is(err, handlerErr))
+	assert.Equal(1, count)
+}
+
+func TestRequestNDJSONCancelled(t *testing.T) {
+	assert := assert.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintf(w, "{\"id\":1}\n")
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var count int
+	_, err := New(server.URL, OptContext(ctx)).NDJSON(newStreamTestItem, func(_ interface{}) error {
+		count++
+		cancel()
+		return nil
+	})
+	assert.True(errors.Is(err, context.Canceled))
+	assert.Equal(1, count)
+}
+
+func TestRequestNDJSONChannel(t *testing.T) {
+	assert := assert.New(t)
+
+	server := mockServerString("{\"id\":1}\n{\"id\":2}\n")
+	defer server.Close()
+
+	items := make(chan interface{})
+	errs := make(chan error, 1)
+	go func() {
+		_, err := New(server.URL).NDJSONChannel(newStreamTestItem, items)
+		errs <- err
+	}()
+	var ids []int
+	for item := range items {
+		ids = append(ids, item.(*streamTestItem).ID)
+	}
+	assert.Nil(<-errs)
+	assert.Equal([]int{1, 2}, ids)
+}
+
+func TestRequestJSONArray(t *testing.T) {
+	assert := assert.New(t)
+
+	server := mockServerString(" [ {\"id\":1,\"name\":\"one\"},\n{\"id\":2,\"name\":\"two\"} ]\n")
+	defer server.Close()
+
+	var items []streamTestItem
+	res, err := New(server.URL).JSONArray(newStreamTestItem, func(item interface{}) error {
+		items = append(items, *item.(*streamTestItem))
+		return nil
+	})
+	assert.Nil(err)
+	assert.Equal(http.StatusOK, res.StatusCode)
+	assert.Equal([]streamTestItem{{1, "one"}, {2, "two"}}, items)
+
+	empty := mockServerString("[]")
+	defer empty.Close()
+	var count int
+	_, err = New(empty.URL).JSONArray(newStreamTestItem, func(_ interface{}) error {
+		count++
+		return nil
+	})
+	assert.Nil(err)
+	assert.Zero(count)
+}
+
+func TestRequestJSONArrayErrors(t *testing.T) {
+	assert := assert.New(t)
+
+	object := mockServerString(`{"id":1}`)
+	defer object.Close()
+	_, err := New(object.URL).JSONArray(newStreamTestItem, func(_ interface{}) error { return nil })
+	assert.True(ex.Is(err, ErrJSONArrayExpected))
+
+	empty := mockServerString("")
+	defer empty.Close()
+	_, err = New(empty.URL).JSONArray(newStreamTestItem, func(_ interface{}) error { return nil })
+	assert.True(ex.Is(err, ErrJSONArrayExpected))
+
+	truncated := mockServerString(`[{"id":1},{"id":2}`)
+	defer truncated.Close()
+	var count int
+	_, err = New(truncated.URL).JSONArray(newStreamTestItem, func(_ interface{}) error {
+		count++
+		return nil
+	})
+	assert.NotNil(err)
+	assert.Equal(2, count)
+}
+
+func TestRequestJSONArrayChannel(t *testing.T) {
+	assert := assert.New(t)
+
+	server := mockServerString(`[{"id":1},{"id":2},{"id":3}]`)
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	items := make(chan interface{})
+	errs := make(chan error, 1)
+	go func() {
+		_, err := New(server.URL, OptContext(ctx)).JSONArrayChannel(newStreamTestItem, items)
+		errs <- err
+	}()
+	item := <-items
+	assert.Equal(1, item.(*streamTestItem).ID)
+	cancel()
+	for range items {
+	}
+	assert.True(errors.Is(<-errs, context.Canceled))
+}
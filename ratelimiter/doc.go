@@ -7,5 +7,8 @@ Use of this source code is governed by a MIT license that can be found in the LI
 
 /*
 Package ratelimiter implements two common rate limiters; queue and token/leaky bucket.
+
+It also implements a fixed window limiter, `Window`, whose counters are held in a pluggable `Store`
+so that limits can be shared across processes (see the `redisstore` package).
 */
 package ratelimiter // import "github.com/blend/go-sdk/ratelimiter"
@@ -0,0 +1,67 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package ratelimiter
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Limiter is a rate limiter that reports the state of the limit for an id.
+//
+// Unlike `RateLimiter`, implementations are safe for concurrent use and can be backed by shared state.
+type Limiter interface {
+	// Take records an action for a given id and returns the resulting state of its limit.
+	Take(ctx context.Context, id string) (Limit, error)
+}
+
+// Limit is the state of a rate limit for an id after an action.
+type Limit struct {
+	// Limit is the number of actions allowed per quantum.
+	Limit int
+	// Remaining is the number of actions remaining in the current quantum, or -1 if it is not known.
+	Remaining int
+	// Reset is the time until the limit resets.
+	Reset time.Duration
+	// Exceeded is if the action exceeded the limit.
+	Exceeded bool
+}
+
+// NewLimiter returns a Limiter for a RateLimiter (e.g. a LeakyBucket or Queue) that
+// allows a given number of actions per quantum.
+//
+// Calls to the rate limiter are serialized, and as rate limiters only report if a limit is
+// exceeded, the remaining count is not known and the reset is the full quantum.
+func NewLimiter(rateLimiter RateLimiter, numberOfActions int, quantum time.Duration) Limiter {
+	return &rateLimiterLimiter{
+		RateLimiter:     rateLimiter,
+		NumberOfActions: numberOfActions,
+		Quantum:         quantum,
+	}
+}
+
+type rateLimiterLimiter struct {
+	sync.Mutex
+	RateLimiter     RateLimiter
+	NumberOfActions int
+	Quantum         time.Duration
+}
+
+// Take implements Limiter.
+func (rll *rateLimiterLimiter) Take(_ context.Context, id string) (Limit, error) {
+	rll.Lock()
+	exceeded := rll.RateLimiter.Check(id)
+	rll.Unlock()
+	return Limit{
+		Limit:     rll.NumberOfActions,
+		Remaining: -1,
+		Reset:     rll.Quantum,
+		Exceeded:  exceeded,
+	}, nil
+}
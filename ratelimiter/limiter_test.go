@@ -0,0 +1,33 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package ratelimiter
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/blend/go-sdk/assert"
+)
+
+func TestNewLimiter(t *testing.T) {
+	its := assert.New(t)
+
+	now := time.Now()
+	lb := NewLeakyBucket(1, time.Second)
+	lb.Now = Clock(now, 0)
+	limiter := NewLimiter(lb, 1, time.Second)
+
+	limit, err := limiter.Take(context.Background(), "a")
+	its.Nil(err)
+	its.Equal(Limit{Limit: 1, Remaining: -1, Reset: time.Second}, limit)
+
+	limit, err = limiter.Take(context.Background(), "a")
+	its.Nil(err)
+	its.True(limit.Exceeded)
+}
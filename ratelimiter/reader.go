@@ -0,0 +1,69 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package ratelimiter
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+// NewReader returns a reader that throttles reads from a source to a given rate, e.g. to
+// throttle a stream that is consumed by something other than `Copy` like an http client.
+//
+// Only the rate and chunk size options apply; reads are limited to the chunk size so that
+// a large read does not exceed the rate in a single burst.
+//
+// Reads are not throttled if the rate is not positive.
+func NewReader(ctx context.Context, src io.Reader, opts ...CopyOption) *Reader {
+	options := CopyOptions{
+		RateBytes:   10 * (1 << 27), // 10gbit in bytes, or (10*(2^30))/8
+		RateQuantum: time.Second,
+		ChunkSize:   DefaultCopyChunkSizeBytes,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return &Reader{
+		ctx:       ctx,
+		src:       src,
+		chunkSize: options.ChunkSize,
+		wait: Wait{
+			NumberOfActions: options.RateBytes,
+			Quantum:         options.RateQuantum,
+		},
+	}
+}
+
+// Reader is a reader that throttles reads from a source.
+type Reader struct {
+	ctx       context.Context
+	src       io.Reader
+	chunkSize int
+	wait      Wait
+}
+
+// Read implements io.Reader.
+//
+// It waits after reading from the source until the observed rate matches the desired rate.
+func (r *Reader) Read(p []byte) (n int, err error) {
+	if r.wait.NumberOfActions <= 0 {
+		return r.src.Read(p)
+	}
+	if r.chunkSize > 0 && len(p) > r.chunkSize {
+		p = p[:r.chunkSize]
+	}
+	ts := time.Now()
+	n, err = r.src.Read(p)
+	if n > 0 {
+		if waitErr := r.wait.Wait(r.ctx, int64(n), time.Since(ts)); waitErr != nil {
+			return n, waitErr
+		}
+	}
+	return n, err
+}
@@ -0,0 +1,62 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package ratelimiter
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/blend/go-sdk/assert"
+)
+
+func Test_Reader(t *testing.T) {
+	its := assert.New(t)
+
+	r := NewReader(context.Background(), bytes.NewBufferString("this is a test"))
+	contents, err := io.ReadAll(r)
+	its.Nil(err)
+	its.Equal("this is a test", string(contents))
+}
+
+func Test_Reader_throttled(t *testing.T) {
+	its := assert.New(t)
+
+	// 64 bytes per 400ms, read in 16 byte chunks, waits ~100ms after each chunk.
+	src := bytes.NewReader(make([]byte, 64))
+	r := NewReader(context.Background(), src, OptCopyRateBytes(64), OptCopyRateQuantum(400*time.Millisecond), OptCopyChunkSize(16))
+	started := time.Now()
+	contents, err := io.ReadAll(r)
+	its.Nil(err)
+	its.Len(contents, 64)
+	its.True(time.Since(started) >= 300*time.Millisecond)
+}
+
+func Test_Reader_unthrottled(t *testing.T) {
+	its := assert.New(t)
+
+	for _, rateBytes := range []int64{0, -1} {
+		r := NewReader(context.Background(), bytes.NewBufferString("this is a test"), OptCopyRateBytes(rateBytes))
+		contents, err := io.ReadAll(r)
+		its.Nil(err)
+		its.Equal("this is a test", string(contents))
+	}
+}
+
+func Test_Reader_cancelled(t *testing.T) {
+	its := assert.New(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	r := NewReader(ctx, bytes.NewReader(make([]byte, 64)), OptCopyRateBytes(1), OptCopyRateQuantum(time.Hour))
+	n, err := r.Read(make([]byte, 64))
+	its.Equal(context.Canceled, err)
+	its.Equal(64, n)
+}
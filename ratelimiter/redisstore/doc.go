@@ -0,0 +1,11 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+/*
+Package redisstore implements a ratelimiter store backed by redis.
+*/
+package redisstore // import "github.com/blend/go-sdk/ratelimiter/redisstore"
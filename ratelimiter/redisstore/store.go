@@ -0,0 +1,77 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package redisstore
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/blend/go-sdk/ex"
+	"github.com/blend/go-sdk/ratelimiter"
+	"github.com/blend/go-sdk/redis"
+)
+
+var (
+	_ ratelimiter.Store = (*Store)(nil)
+)
+
+// Errors
+const (
+	ErrUnexpectedReply ex.Class = "redisstore; unexpected reply from redis"
+)
+
+// IncrementScript increments a counter, setting its expiry when the window starts,
+// and returns the count and the milliseconds until the counter expires.
+//
+// The expiry is set again if it is missing, e.g. if a previous call failed between commands.
+const IncrementScript = `local count = redis.call("INCR", KEYS[1])
+local ttl = redis.call("PTTL", KEYS[1])
+if count == 1 or ttl < 0 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[1])
+	ttl = tonumber(ARGV[1])
+end
+return {count, ttl}`
+
+// New returns a new redis rate limiter store.
+func New(client redis.Client, options ...Option) *Store {
+	s := &Store{
+		Client: client,
+	}
+	for _, opt := range options {
+		opt(s)
+	}
+	return s
+}
+
+// Option mutates a store.
+type Option func(*Store)
+
+// OptPrefix sets the key prefix for counters.
+func OptPrefix(prefix string) Option {
+	return func(s *Store) { s.Prefix = prefix }
+}
+
+// Store is a rate limiter store that keeps counters in redis, such that
+// limits are shared by every process that uses the same redis server.
+type Store struct {
+	Client redis.Client
+	Prefix string
+}
+
+// Increment implements ratelimiter.Store.
+func (s *Store) Increment(ctx context.Context, key string, window time.Duration) (int64, time.Duration, error) {
+	var reply []int64
+	if err := s.Client.Do(ctx, &reply, redis.OpEVAL, IncrementScript, "1", s.Prefix+key, strconv.FormatInt(window.Milliseconds(), 10)); err != nil {
+		return 0, 0, err
+	}
+	if len(reply) != 2 {
+		return 0, 0, ex.New(ErrUnexpectedReply, ex.OptMessagef("reply: %v", reply))
+	}
+	return reply[0], time.Duration(reply[1]) * time.Millisecond, nil
+}
@@ -0,0 +1,54 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package redisstore
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/blend/go-sdk/assert"
+	"github.com/blend/go-sdk/ex"
+	"github.com/blend/go-sdk/redis"
+)
+
+func TestStore_Increment(t *testing.T) {
+	its := assert.New(t)
+
+	var op string
+	var args []string
+	client := redis.MockClientFunc(func(_ context.Context, out interface{}, o string, a ...string) error {
+		op, args = o, a
+		*(out.(*[]int64)) = []int64{3, 1500}
+		return nil
+	})
+
+	count, reset, err := New(client, OptPrefix("ratelimit:")).Increment(context.Background(), "a", 2*time.Second)
+	its.Nil(err)
+	its.Equal(3, count)
+	its.Equal(1500*time.Millisecond, reset)
+	its.Equal(redis.OpEVAL, op)
+	its.Equal([]string{IncrementScript, "1", "ratelimit:a", "2000"}, args)
+}
+
+func TestStore_Increment_errors(t *testing.T) {
+	its := assert.New(t)
+
+	client := redis.MockClientFunc(func(_ context.Context, out interface{}, _ string, _ ...string) error {
+		*(out.(*[]int64)) = []int64{3}
+		return nil
+	})
+	_, _, err := New(client).Increment(context.Background(), "a", time.Second)
+	its.True(ex.Is(err, ErrUnexpectedReply))
+
+	client = redis.MockClientFunc(func(context.Context, interface{}, string, ...string) error {
+		return ex.New("this is only a test")
+	})
+	_, _, err = New(client).Increment(context.Background(), "a", time.Second)
+	its.NotNil(err)
+}
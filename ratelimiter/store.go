@@ -0,0 +1,82 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package ratelimiter
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+var (
+	_ Store = (*MemoryStore)(nil)
+)
+
+// Store holds fixed window counters for rate limiters.
+//
+// Stores can be shared across processes (e.g. backed by redis) so that limits apply to every replica of a service.
+type Store interface {
+	// Increment increments the counter for a key, starting a new window of a given
+	// duration if one is not active, and returns the count and the time until the window ends.
+	Increment(ctx context.Context, key string, window time.Duration) (count int64, reset time.Duration, err error)
+}
+
+// NewMemoryStore returns a new in-memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		Counters: make(map[string]*Counter),
+		Now:      func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// MemoryStore is a store that holds counters in memory.
+//
+// Expired counters are removed periodically as counters are incremented.
+type MemoryStore struct {
+	sync.Mutex
+	Counters  map[string]*Counter
+	Now       func() time.Time
+	lastSweep time.Time
+}
+
+// Counter is the count of actions for a key within a window.
+type Counter struct {
+	Count   int64
+	Expires time.Time
+}
+
+// Increment implements Store.
+func (ms *MemoryStore) Increment(_ context.Context, key string, window time.Duration) (int64, time.Duration, error) {
+	ms.Lock()
+	defer ms.Unlock()
+
+	now := ms.Now()
+	if ms.Counters == nil {
+		ms.Counters = make(map[string]*Counter)
+	}
+	if now.Sub(ms.lastSweep) >= window {
+		ms.sweep(now)
+	}
+
+	counter, ok := ms.Counters[key]
+	if !ok || !now.Before(counter.Expires) {
+		counter = &Counter{Expires: now.Add(window)}
+		ms.Counters[key] = counter
+	}
+	counter.Count++
+	return counter.Count, counter.Expires.Sub(now), nil
+}
+
+func (ms *MemoryStore) sweep(now time.Time) {
+	for key, counter := range ms.Counters {
+		if !now.Before(counter.Expires) {
+			delete(ms.Counters, key)
+		}
+	}
+	ms.lastSweep = now
+}
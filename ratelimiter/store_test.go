@@ -0,0 +1,50 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package ratelimiter
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/blend/go-sdk/assert"
+)
+
+func TestMemoryStore_Increment(t *testing.T) {
+	its := assert.New(t)
+
+	now := time.Now()
+	ms := NewMemoryStore()
+	ms.Now = Clock(now, 0)
+
+	count, reset, err := ms.Increment(context.Background(), "a", time.Second)
+	its.Nil(err)
+	its.Equal(1, count)
+	its.Equal(time.Second, reset)
+
+	ms.Now = Clock(now, 400*time.Millisecond)
+	count, reset, err = ms.Increment(context.Background(), "a", time.Second)
+	its.Nil(err)
+	its.Equal(2, count)
+	its.Equal(600*time.Millisecond, reset)
+
+	count, _, err = ms.Increment(context.Background(), "b", time.Second)
+	its.Nil(err)
+	its.Equal(1, count)
+
+	ms.Now = Clock(now, time.Second)
+	count, reset, err = ms.Increment(context.Background(), "a", time.Second)
+	its.Nil(err)
+	its.Equal(1, count, "a new window should start once the previous window ends")
+	its.Equal(time.Second, reset)
+
+	ms.Now = Clock(now, 3*time.Second)
+	_, _, err = ms.Increment(context.Background(), "c", time.Second)
+	its.Nil(err)
+	its.Len(ms.Counters, 1, "expired counters should be swept")
+}
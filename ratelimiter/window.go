@@ -0,0 +1,68 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package ratelimiter
+
+import (
+	"context"
+	"time"
+)
+
+var (
+	_ Limiter     = (*Window)(nil)
+	_ RateLimiter = (*Window)(nil)
+)
+
+// NewWindow returns a new fixed window rate limiter that allows `numberOfActions` per `quantum`.
+//
+// If the store is nil, an in-memory store is used.
+func NewWindow(numberOfActions int, quantum time.Duration, store Store) *Window {
+	if store == nil {
+		store = NewMemoryStore()
+	}
+	return &Window{
+		NumberOfActions: numberOfActions,
+		Quantum:         quantum,
+		Store:           store,
+	}
+}
+
+// Window is a fixed window rate limiter with pluggable state.
+type Window struct {
+	NumberOfActions int
+	Quantum         time.Duration
+	Store           Store
+}
+
+// Take implements Limiter.
+func (w *Window) Take(ctx context.Context, id string) (Limit, error) {
+	count, reset, err := w.Store.Increment(ctx, id, w.Quantum)
+	if err != nil {
+		return Limit{}, err
+	}
+	remaining := int64(w.NumberOfActions) - count
+	if remaining < 0 {
+		remaining = 0
+	}
+	return Limit{
+		Limit:     w.NumberOfActions,
+		Remaining: int(remaining),
+		Reset:     reset,
+		Exceeded:  count > int64(w.NumberOfActions),
+	}, nil
+}
+
+// Check implements RateLimiter, and returns true if the id has exceeded the rate limit.
+//
+// Errors from the store are treated as not exceeding the limit.
+func (w *Window) Check(id string) bool {
+	limit, err := w.Take(context.Background(), id)
+	if err != nil {
+		return false
+	}
+	return limit.Exceeded
+}
@@ -0,0 +1,58 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package ratelimiter
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/blend/go-sdk/assert"
+)
+
+func TestWindow_Take(t *testing.T) {
+	its := assert.New(t)
+
+	now := time.Now()
+	store := NewMemoryStore()
+	store.Now = Clock(now, 0)
+	w := NewWindow(2, time.Minute, store)
+
+	limit, err := w.Take(context.Background(), "a")
+	its.Nil(err)
+	its.Equal(Limit{Limit: 2, Remaining: 1, Reset: time.Minute}, limit)
+
+	store.Now = Clock(now, 15*time.Second)
+	limit, err = w.Take(context.Background(), "a")
+	its.Nil(err)
+	its.Equal(Limit{Limit: 2, Remaining: 0, Reset: 45 * time.Second}, limit)
+
+	limit, err = w.Take(context.Background(), "a")
+	its.Nil(err)
+	its.Equal(Limit{Limit: 2, Remaining: 0, Reset: 45 * time.Second, Exceeded: true}, limit)
+
+	store.Now = Clock(now, time.Minute)
+	its.False(w.Check("a"))
+}
+
+type errorStore struct{}
+
+func (errorStore) Increment(context.Context, string, time.Duration) (int64, time.Duration, error) {
+	return 0, 0, fmt.Errorf("this is only a test")
+}
+
+func TestWindow_storeError(t *testing.T) {
+	its := assert.New(t)
+
+	w := NewWindow(1, time.Minute, errorStore{})
+	_, err := w.Take(context.Background(), "a")
+	its.NotNil(err)
+	its.False(w.Check("a"))
+	its.False(w.Check("a"))
+}
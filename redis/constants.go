@@ -346,3 +346,17 @@ const (
 	OpRESET              = "RESET"
 	OpSELECT             = "SELECT"
 )
+
+// Scripting Operations
+const (
+	// OpEVAL evaluates a lua script on the server.
+	//
+	// Usage: EVAL script numkeys [key [key ...]] [arg [arg ...]]
+	//
+	// Return value depends on the script that is executed.
+	OpEVAL = "EVAL"
+	// OpEVALSHA evaluates a lua script cached on the server by its sha1 digest.
+	//
+	// Usage: EVALSHA sha1 numkeys [key [key ...]] [arg [arg ...]]
+	OpEVALSHA = "EVALSHA"
+)
@@ -0,0 +1,42 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package redis
+
+import (
+	"context"
+	"strconv"
+	"time"
+)
+
+// NewQueryCache returns a new query cache backed by a redis client.
+func NewQueryCache(client Client) *QueryCache {
+	return &QueryCache{Client: client}
+}
+
+// QueryCache is a db query cache (see `db.OptQueryCache`) backed by a redis client.
+type QueryCache struct {
+	Client Client
+}
+
+// Get gets a cached value, returning false if it is not cached.
+func (qc *QueryCache) Get(ctx context.Context, key string) (value []byte, ok bool, err error) {
+	if err = qc.Client.Do(ctx, &value, OpGET, key); err != nil {
+		return
+	}
+	// a missing key yields a nil value; cached values are never empty.
+	ok = len(value) > 0
+	return
+}
+
+// Set sets a cached value with a ttl, or without expiry if the ttl is zero.
+func (qc *QueryCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	if ttl > 0 {
+		return qc.Client.Do(ctx, nil, OpSET, key, string(value), "PX", strconv.FormatInt(ttl.Milliseconds(), 10))
+	}
+	return qc.Client.Do(ctx, nil, OpSET, key, string(value))
+}
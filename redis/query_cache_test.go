@@ -0,0 +1,56 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package redis_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/blend/go-sdk/assert"
+	"github.com/blend/go-sdk/db"
+	"github.com/blend/go-sdk/redis"
+)
+
+var (
+	_ db.QueryCache = (*redis.QueryCache)(nil)
+)
+
+func Test_QueryCache(t *testing.T) {
+	its := assert.New(t)
+
+	data := map[string]string{}
+	var lastArgs []string
+	qc := redis.NewQueryCache(redis.MockClientFunc(func(_ context.Context, out interface{}, op string, args ...string) error {
+		lastArgs = args
+		switch op {
+		case redis.OpGET:
+			if value, ok := data[args[0]]; ok {
+				*(out.(*[]byte)) = []byte(value)
+			}
+		case redis.OpSET:
+			data[args[0]] = args[1]
+		}
+		return nil
+	}))
+
+	_, ok, err := qc.Get(context.Background(), "foo")
+	its.Nil(err)
+	its.False(ok)
+
+	its.Nil(qc.Set(context.Background(), "foo", []byte("bar"), 1500*time.Millisecond))
+	its.Equal([]string{"foo", "bar", "PX", "1500"}, lastArgs)
+
+	value, ok, err := qc.Get(context.Background(), "foo")
+	its.Nil(err)
+	its.True(ok)
+	its.Equal("bar", string(value))
+
+	its.Nil(qc.Set(context.Background(), "foo", []byte("baz"), 0))
+	its.Equal([]string{"foo", "baz"}, lastArgs)
+}
@@ -0,0 +1,20 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package breakerstats
+
+import "github.com/blend/go-sdk/breaker"
+
+// Metric and tag names etc.
+const (
+	MetricName      string = string(breaker.Flag)
+	MetricNameState string = "breaker.state"
+
+	TagName string = "breaker"
+	TagFrom string = "from"
+	TagTo   string = "to"
+)
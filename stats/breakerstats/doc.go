@@ -0,0 +1,11 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+/*
+Package breakerstats provides shims for writing breaker logger events to a stats collector.
+*/
+package breakerstats // import "github.com/blend/go-sdk/stats/breakerstats"
@@ -0,0 +1,39 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package breakerstats
+
+import (
+	"context"
+
+	"github.com/blend/go-sdk/breaker"
+	"github.com/blend/go-sdk/logger"
+	"github.com/blend/go-sdk/stats"
+)
+
+// AddListeners adds breaker listeners.
+//
+// It increments a counter for each state change, and gauges the new state of
+// the breaker (0 for closed, 1 for half-open and 2 for open).
+func AddListeners(log logger.Listenable, collector stats.Collector, opts ...stats.AddListenerOption) {
+	if log == nil || collector == nil {
+		return
+	}
+
+	options := stats.NewAddListenerOptions(opts...)
+
+	log.Listen(breaker.Flag, stats.ListenerNameStats, breaker.NewStateChangeEventListener(func(ctx context.Context, e breaker.StateChangeEvent) {
+		var tags []string
+		if len(e.Name) > 0 {
+			tags = append(tags, stats.Tag(TagName, e.Name))
+		}
+		tags = append(tags, options.GetLoggerLabelsAsTags(ctx)...)
+
+		_ = collector.Increment(MetricName, append(tags, stats.Tag(TagFrom, e.From.String()), stats.Tag(TagTo, e.To.String()))...)
+		_ = collector.Gauge(MetricNameState, float64(e.To), tags...)
+	}))
+}
@@ -0,0 +1,51 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package breakerstats
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/blend/go-sdk/assert"
+	"github.com/blend/go-sdk/breaker"
+	"github.com/blend/go-sdk/logger"
+	"github.com/blend/go-sdk/stats"
+)
+
+func TestAddListeners(t *testing.T) {
+	assert := assert.New(t)
+
+	log := logger.None()
+	AddListeners(nil, nil)
+	assert.False(log.HasListener(breaker.Flag, stats.ListenerNameStats))
+	AddListeners(log, stats.NewMockCollector(32))
+	assert.True(log.HasListener(breaker.Flag, stats.ListenerNameStats))
+}
+
+func TestAddListenersStats(t *testing.T) {
+	assert := assert.New(t)
+
+	log := logger.All(logger.OptOutput(io.Discard))
+	defer log.Close()
+	collector := stats.NewMockCollector(32)
+
+	AddListeners(log, collector)
+
+	log.TriggerContext(context.Background(), breaker.NewStateChangeEvent("test.invalid", breaker.StateClosed, breaker.StateOpen, 1))
+
+	m := <-collector.Metrics
+	assert.Equal(MetricName, m.Name)
+	assert.Equal(1, m.Count)
+	assert.Equal([]string{"breaker:test.invalid", "from:closed", "to:open"}, m.Tags)
+
+	m = <-collector.Metrics
+	assert.Equal(MetricNameState, m.Name)
+	assert.Equal(breaker.StateOpen, m.Gauge)
+	assert.Equal([]string{"breaker:test.invalid"}, m.Tags)
+}
@@ -14,8 +14,11 @@ const (
 	MetricNameDBQuery            string = string(db.QueryFlag)
 	MetricNameDBQueryElapsed     string = MetricNameDBQuery + ".elapsed"
 	MetricNameDBQueryElapsedLast string = MetricNameDBQueryElapsed + ".last"
+	MetricNameDBCache            string = string(db.CacheFlag)
 
 	TagQuery    string = "query"
 	TagEngine   string = "engine"
 	TagDatabase string = "database"
+	TagNode     string = "node"
+	TagResult   string = "result"
 )
@@ -34,6 +34,9 @@ func AddListeners(log logger.Listenable, collector stats.Collector, opts ...stat
 		if len(qe.Label) > 0 {
 			tags = append(tags, stats.Tag(TagQuery, qe.Label))
 		}
+		if len(qe.Node) > 0 {
+			tags = append(tags, stats.Tag(TagNode, qe.Node))
+		}
 		if qe.Err != nil {
 			tags = append(tags, stats.TagError)
 		}
@@ -44,4 +47,22 @@ func AddListeners(log logger.Listenable, collector stats.Collector, opts ...stat
 		_ = collector.Gauge(MetricNameDBQueryElapsedLast, timeutil.Milliseconds(qe.Elapsed), tags...)
 		_ = collector.Histogram(MetricNameDBQueryElapsed, timeutil.Milliseconds(qe.Elapsed), tags...)
 	}))
+
+	log.Listen(db.CacheFlag, stats.ListenerNameStats, db.NewCacheEventListener(func(ctx context.Context, ce db.CacheEvent) {
+		tags := []string{
+			stats.Tag(TagEngine, ce.Engine),
+			stats.Tag(TagDatabase, ce.Database),
+			stats.Tag(TagResult, ce.Result),
+		}
+		if len(ce.Label) > 0 {
+			tags = append(tags, stats.Tag(TagQuery, ce.Label))
+		}
+		if ce.Err != nil {
+			tags = append(tags, stats.TagError)
+		}
+
+		tags = append(tags, options.GetLoggerLabelsAsTags(ctx)...)
+
+		_ = collector.Increment(MetricNameDBCache, tags...)
+	}))
 }
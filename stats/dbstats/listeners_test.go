@@ -55,3 +55,38 @@ func TestAddListenersStats(t *testing.T) {
 	assert.Equal(1000, qm.Histogram)
 	assert.NotEmpty(qm.Tags)
 }
+
+func TestAddListenersStatsNode(t *testing.T) {
+	assert := assert.New(t)
+
+	log := logger.All(logger.OptOutput(io.Discard))
+	defer log.Close()
+	collector := stats.NewMockCollector(32)
+
+	AddListeners(log, collector)
+
+	log.TriggerContext(context.Background(), db.NewQueryEvent("select 'ok!'", time.Second, db.OptQueryEventNode("replica-1:5432")))
+
+	qm := <-collector.Metrics
+	assert.Equal(MetricNameDBQuery, qm.Name)
+	assert.Any(qm.Tags, func(v interface{}) bool { return v.(string) == stats.Tag(TagNode, "replica-1:5432") })
+}
+
+func TestAddListenersCacheStats(t *testing.T) {
+	assert := assert.New(t)
+
+	log := logger.All(logger.OptOutput(io.Discard))
+	defer log.Close()
+	collector := stats.NewMockCollector(32)
+
+	AddListeners(log, collector)
+	assert.True(log.HasListener(db.CacheFlag, stats.ListenerNameStats))
+
+	log.TriggerContext(context.Background(), db.CacheEvent{Label: "users_get", Result: db.QueryCacheHit})
+
+	cm := <-collector.Metrics
+	assert.Equal(MetricNameDBCache, cm.Name)
+	assert.Equal(1, cm.Count)
+	assert.Any(cm.Tags, func(v interface{}) bool { return v.(string) == stats.Tag(TagResult, db.QueryCacheHit) })
+	assert.Any(cm.Tags, func(v interface{}) bool { return v.(string) == stats.Tag(TagQuery, "users_get") })
+}
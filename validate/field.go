@@ -0,0 +1,27 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package validate
+
+// Field returns a validator that runs the given validators and sets the field name
+// on any validation errors they return.
+//
+// It returns all failing validations, like `All`.
+func Field(name string, validators ...Validator) Validator {
+	return func() error {
+		err := All(validators...)()
+		if err == nil {
+			return nil
+		}
+		for _, verr := range err.(ValidationErrors) {
+			if inner := ErrInner(verr); inner != nil && inner.Field == "" {
+				inner.Field = name
+			}
+		}
+		return err
+	}
+}
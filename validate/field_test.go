@@ -0,0 +1,50 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package validate
+
+import (
+	"testing"
+
+	"github.com/blend/go-sdk/assert"
+)
+
+func TestField(t *testing.T) {
+	assert := assert.New(t)
+
+	name := ""
+	count := 200
+	err := Field("name", String(&name).Required())()
+	assert.NotNil(err)
+	verrs, ok := err.(ValidationErrors)
+	assert.True(ok)
+	assert.Len(verrs, 1)
+	assert.Equal("name", ErrField(verrs[0]))
+	assert.Equal(ErrStringRequired, ErrCause(verrs[0]))
+
+	err = All(
+		Field("name", String(&name).Required()),
+		Field("count", Int(&count).Between(0, 99), Int(&count).Positive()),
+	)()
+	verrs = err.(ValidationErrors)
+	assert.Len(verrs, 2)
+	assert.Equal("name", ErrField(verrs[0]))
+	assert.Equal("count", ErrField(verrs[1]))
+
+	name = "foo"
+	assert.Nil(Field("name", String(&name).Required())())
+}
+
+func TestErrField(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Empty(ErrField(nil))
+	assert.Empty(ErrField(Error(ErrStringRequired, nil)))
+
+	verr := ValidationError{Cause: ErrStringRequired, Field: "name"}
+	assert.Equal("name: string should be set", verr.Error())
+}
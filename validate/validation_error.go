@@ -30,6 +30,8 @@ type ValidationError struct {
 	Message string
 	// Value is the offending value, it can be unset, and is meant to be a common piece of context.
 	Value interface{}
+	// Field is the name of the field that failed validation, it can be unset.
+	Field string
 }
 
 // Class implements
@@ -39,6 +41,9 @@ func (ve ValidationError) Class() error {
 
 // Error implements error.
 func (ve ValidationError) Error() string {
+	if ve.Field != "" {
+		return fmt.Sprintf("%s: %s", ve.Field, ValidationError{Cause: ve.Cause, Message: ve.Message, Value: ve.Value}.Error())
+	}
 	if ve.Value != nil && ve.Message != "" {
 		return fmt.Sprintf("%v; %v; %v", ve.Cause, ve.Message, ve.Value)
 	}
@@ -84,6 +89,14 @@ func ErrMessage(err error) string {
 	return ""
 }
 
+// ErrField returns the name of the field that failed validation.
+func ErrField(err error) string {
+	if inner := ErrInner(err); inner != nil {
+		return inner.Field
+	}
+	return ""
+}
+
 // ErrValue returns the validation error value.
 func ErrValue(err error) interface{} {
 	if inner := ErrInner(err); inner != nil {
@@ -85,6 +85,14 @@ type App struct {
 
 	Statics map[string]*StaticFileServer
 
+	CORSPolicy        *CORSPolicy
+	CORSRoutePolicies map[string]CORSPolicy
+
+	RouteSpecs map[string]*RouteSpec
+	Groups     []*RouteGroup
+
+	openAPIRoute string
+
 	DefaultProvider ResultProvider
 	Views           *ViewCache
 
@@ -267,6 +275,21 @@ func (a *App) SetStaticHeader(route, key, value string) error {
 	return ex.New("no static fileserver mounted at route", ex.OptMessagef("route: %s", mountedRoute))
 }
 
+// SetStaticPrecompressed sets the static fileserver at the given path to serve pre-compressed
+// sibling files (e.g. `app.js.br` for `app.js`) for the given content encodings, in order of preference.
+// If no encodings are given, `DefaultStaticFilePrecompressed` are used.
+func (a *App) SetStaticPrecompressed(route string, encodings ...string) error {
+	mountedRoute := a.formatStaticMountRoute(route)
+	if static, hasRoute := a.Statics[mountedRoute]; hasRoute {
+		if len(encodings) == 0 {
+			encodings = DefaultStaticFilePrecompressed
+		}
+		static.Precompressed = encodings
+		return nil
+	}
+	return ex.New("no static fileserver mounted at route", ex.OptMessagef("route: %s", mountedRoute))
+}
+
 // --------------------------------------------------------------------------------
 // Route Registration / HTTP Methods
 // --------------------------------------------------------------------------------
@@ -308,12 +331,22 @@ func (a *App) DELETE(path string, action Action, middleware ...Middleware) {
 
 // Method registers an action for a given method and path with the given middleware.
 func (a *App) Method(method string, path string, action Action, middleware ...Middleware) {
-	a.RouteTree.Handle(method, path, a.RenderAction(NestMiddleware(action, append(middleware, a.BaseMiddleware...)...)))
+	a.RouteTree.Handle(method, path, a.RenderAction(NestMiddleware(action, a.routeMiddleware(middleware)...)))
 }
 
 // MethodBare registers an action for a given method and path with the given middleware that omits logging and tracing.
 func (a *App) MethodBare(method string, path string, action Action, middleware ...Middleware) {
-	a.RouteTree.Handle(method, path, a.RenderActionBare(NestMiddleware(action, append(middleware, a.BaseMiddleware...)...)))
+	a.RouteTree.Handle(method, path, a.RenderActionBare(NestMiddleware(action, a.routeMiddleware(middleware)...)))
+}
+
+// routeMiddleware returns the middleware for a route, followed by the base middleware
+// and the cors middleware if the app has a cors policy.
+func (a *App) routeMiddleware(middleware []Middleware) []Middleware {
+	middleware = append(middleware, a.BaseMiddleware...)
+	if a.CORSRoutePolicies != nil {
+		middleware = append(middleware, a.corsMiddleware)
+	}
+	return middleware
 }
 
 // Lookup finds the route data for a given method and path.
@@ -0,0 +1,328 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package web
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/blend/go-sdk/ex"
+	"github.com/blend/go-sdk/uuid"
+	"github.com/blend/go-sdk/validate"
+	"github.com/blend/go-sdk/webutil"
+)
+
+// Bind struct tags, and the sources reported on bind field errors.
+const (
+	BindSourceRoute      = "route"
+	BindSourceQuery      = "query"
+	BindSourceHeader     = "header"
+	BindSourceForm       = "form"
+	BindSourceBody       = "body"
+	BindSourceValidation = "validation"
+)
+
+// Bind errors.
+const (
+	ErrBindTarget ex.Class = "bind target must be a non-nil pointer to a struct"
+)
+
+var (
+	_ error = (*BindError)(nil)
+
+	bindTypeDuration        = reflect.TypeOf(time.Duration(0))
+	bindTypeUUID            = reflect.TypeOf(uuid.UUID(nil))
+	bindTypeTextUnmarshaler = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	bindSources             = []string{BindSourceRoute, BindSourceQuery, BindSourceHeader, BindSourceForm}
+)
+
+// Validatable is a type that can validate itself.
+//
+// Bound objects that implement Validatable are validated after binding.
+type Validatable interface {
+	Validate() error
+}
+
+// BindError is returned when a request fails to bind or validate, and
+// lists every field that failed.
+//
+// It is rendered as the body of the bad request result returned by `Ctx.BindOrBadRequest`.
+type BindError struct {
+	Message string           `json:"message" xml:"message"`
+	Fields  []BindFieldError `json:"fields" xml:"field"`
+}
+
+// Error implements error.
+func (be *BindError) Error() string {
+	messages := make([]string, 0, len(be.Fields))
+	for _, field := range be.Fields {
+		messages = append(messages, field.String())
+	}
+	return fmt.Sprintf("%s; %s", be.Message, strings.Join(messages, "; "))
+}
+
+// BindFieldError is a binding or validation failure for a single field.
+type BindFieldError struct {
+	// Field is the name of the field as it appears in the request, and can be unset for object level failures.
+	Field string `json:"field,omitempty" xml:"name,omitempty"`
+	// Source is where the field was read from, or `validation` for validation failures.
+	Source  string `json:"source" xml:"source"`
+	Message string `json:"message" xml:"message"`
+}
+
+// String returns a readable representation of the field error.
+func (bfe BindFieldError) String() string {
+	if bfe.Field != "" {
+		return fmt.Sprintf("%s %s: %s", bfe.Source, bfe.Field, bfe.Message)
+	}
+	return fmt.Sprintf("%s: %s", bfe.Source, bfe.Message)
+}
+
+// bind fills a struct from the request and then validates it.
+func bind(rc *Ctx, obj interface{}, validators ...validate.Validator) error {
+	objValue := reflect.ValueOf(obj)
+	if objValue.Kind() != reflect.Ptr || objValue.IsNil() || objValue.Elem().Kind() != reflect.Struct {
+		return ex.New(ErrBindTarget, ex.OptMessagef("%T", obj))
+	}
+
+	// request bodies without a content type are assumed to be json.
+	var fieldErrors []BindFieldError
+	if bindHasJSONBody(rc.Request) {
+		body, err := rc.PostBody()
+		if err != nil {
+			return err
+		}
+		if len(body) > 0 {
+			if err = json.Unmarshal(body, obj); err != nil {
+				fieldErrors = append(fieldErrors, bindJSONError(err))
+			}
+		}
+	}
+
+	for _, source := range bindSources {
+		if !bindHasTag(objValue.Elem().Type(), source) {
+			continue
+		}
+		getValues, err := bindValues(rc, source)
+		if err != nil {
+			return err
+		}
+		fieldErrors = append(fieldErrors, bindFields(objValue.Elem(), source, getValues)...)
+	}
+
+	// only validate objects that bound cleanly, validation errors on
+	// partially bound objects are just noise.
+	if len(fieldErrors) == 0 {
+		if typed, ok := obj.(Validatable); ok {
+			validators = append([]validate.Validator{typed.Validate}, validators...)
+		}
+		if err := validate.ReturnAll(validators...); err != nil {
+			for _, verr := range err.(validate.ValidationErrors) {
+				fieldErrors = append(fieldErrors, bindValidationErrors(verr)...)
+			}
+		}
+	}
+
+	if len(fieldErrors) > 0 {
+		return &BindError{
+			Message: http.StatusText(http.StatusBadRequest),
+			Fields:  fieldErrors,
+		}
+	}
+	return nil
+}
+
+func bindHasJSONBody(req *http.Request) bool {
+	if req == nil || req.Body == nil || req.Body == http.NoBody {
+		return false
+	}
+	contentType := req.Header.Get(webutil.HeaderContentType)
+	if contentType == "" {
+		return true
+	}
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+func bindJSONError(err error) BindFieldError {
+	if typed, ok := err.(*json.UnmarshalTypeError); ok {
+		return BindFieldError{
+			Field:   typed.Field,
+			Source:  BindSourceBody,
+			Message: fmt.Sprintf("cannot unmarshal %s into %s", typed.Value, typed.Type),
+		}
+	}
+	return BindFieldError{Source: BindSourceBody, Message: err.Error()}
+}
+
+// bindValues returns a getter for the values of a given source.
+func bindValues(rc *Ctx, source string) (func(string) []string, error) {
+	switch source {
+	case BindSourceRoute:
+		return func(key string) []string {
+			if value, ok := rc.RouteParams[key]; ok {
+				return []string{value}
+			}
+			return nil
+		}, nil
+	case BindSourceQuery:
+		query := rc.Request.URL.Query()
+		return func(key string) []string { return query[key] }, nil
+	case BindSourceHeader:
+		return func(key string) []string { return rc.Request.Header.Values(key) }, nil
+	default:
+		if err := rc.EnsureForm(); err != nil {
+			return nil, err
+		}
+		return func(key string) []string { return rc.Form[key] }, nil
+	}
+}
+
+func bindHasTag(t reflect.Type, tag string) bool {
+	for index := 0; index < t.NumField(); index++ {
+		field := t.Field(index)
+		if _, ok := field.Tag.Lookup(tag); ok {
+			return true
+		}
+		if field.Anonymous && field.Type.Kind() == reflect.Struct && bindHasTag(field.Type, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+func bindFields(objValue reflect.Value, source string, getValues func(string) []string) (output []BindFieldError) {
+	objType := objValue.Type()
+	for index := 0; index < objType.NumField(); index++ {
+		field := objType.Field(index)
+		name, ok := field.Tag.Lookup(source)
+		if !ok {
+			if field.Anonymous && field.Type.Kind() == reflect.Struct {
+				output = append(output, bindFields(objValue.Field(index), source, getValues)...)
+			}
+			continue
+		}
+		if name == "" || name == "-" || field.PkgPath != "" {
+			continue
+		}
+		values := getValues(name)
+		if len(values) == 0 {
+			continue
+		}
+		if err := bindValue(objValue.Field(index), values); err != nil {
+			output = append(output, BindFieldError{Field: name, Source: source, Message: err.Error()})
+		}
+	}
+	return
+}
+
+// bindValue sets a field from its raw values; slice fields take every value, others take the first.
+func bindValue(field reflect.Value, values []string) error {
+	fieldType := field.Type()
+	if fieldType.Kind() == reflect.Ptr {
+		elem := reflect.New(fieldType.Elem())
+		if err := bindValue(elem.Elem(), values); err != nil {
+			return err
+		}
+		field.Set(elem)
+		return nil
+	}
+	if fieldType == bindTypeUUID {
+		parsed, err := uuid.Parse(values[0])
+		if err != nil {
+			return fmt.Errorf("invalid uuid")
+		}
+		field.Set(reflect.ValueOf(parsed))
+		return nil
+	}
+	if reflect.PtrTo(fieldType).Implements(bindTypeTextUnmarshaler) {
+		return field.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(values[0]))
+	}
+	if fieldType.Kind() == reflect.Slice && fieldType.Elem().Kind() != reflect.Uint8 {
+		slice := reflect.MakeSlice(fieldType, len(values), len(values))
+		for index, value := range values {
+			if err := bindValue(slice.Index(index), []string{value}); err != nil {
+				return err
+			}
+		}
+		field.Set(slice)
+		return nil
+	}
+	return bindString(field, values[0])
+}
+
+func bindString(field reflect.Value, value string) error {
+	if field.Type() == bindTypeDuration {
+		parsed, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q", value)
+		}
+		field.SetInt(int64(parsed))
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Slice:
+		field.SetBytes([]byte(value))
+	case reflect.Bool:
+		parsed, err := BoolValue(value, nil)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", value)
+		}
+		field.SetBool(parsed)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		parsed, err := strconv.ParseInt(value, 10, field.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", value)
+		}
+		field.SetInt(parsed)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		parsed, err := strconv.ParseUint(value, 10, field.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid unsigned integer %q", value)
+		}
+		field.SetUint(parsed)
+	case reflect.Float32, reflect.Float64:
+		parsed, err := strconv.ParseFloat(value, field.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid number %q", value)
+		}
+		field.SetFloat(parsed)
+	default:
+		return fmt.Errorf("unsupported field type %v", field.Type())
+	}
+	return nil
+}
+
+// bindValidationErrors converts validation failures into field errors.
+func bindValidationErrors(err error) (output []BindFieldError) {
+	if many, ok := err.(validate.ValidationErrors); ok {
+		for _, verr := range many {
+			output = append(output, bindValidationErrors(verr)...)
+		}
+		return
+	}
+	inner := validate.ErrInner(err)
+	if inner == nil {
+		return []BindFieldError{{Source: BindSourceValidation, Message: err.Error()}}
+	}
+	message := inner.Cause.Error()
+	if inner.Message != "" {
+		message = message + "; " + inner.Message
+	}
+	return []BindFieldError{{Field: inner.Field, Source: BindSourceValidation, Message: message}}
+}
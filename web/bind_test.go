@@ -0,0 +1,165 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package web
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/blend/go-sdk/assert"
+	"github.com/blend/go-sdk/ex"
+	"github.com/blend/go-sdk/r2"
+	"github.com/blend/go-sdk/uuid"
+	"github.com/blend/go-sdk/validate"
+	"github.com/blend/go-sdk/webutil"
+)
+
+type bindTestPaging struct {
+	Limit  int `query:"limit"`
+	Offset int `query:"offset"`
+}
+
+type bindTestRequest struct {
+	bindTestPaging
+	ID        uuid.UUID     `route:"id"`
+	Name      string        `json:"name"`
+	Count     int           `json:"count"`
+	Tags      []string      `query:"tag"`
+	Active    *bool         `query:"active"`
+	Timeout   time.Duration `query:"timeout"`
+	Since     time.Time     `query:"since"`
+	RequestID string        `header:"X-Request-ID"`
+}
+
+func (btr bindTestRequest) Validate() error {
+	return validate.ReturnAll(
+		validate.Field("name", validate.String(&btr.Name).Required()),
+		validate.Field("count", validate.Int(&btr.Count).Between(1, 10)),
+	)
+}
+
+type bindTestForm struct {
+	Email string `form:"email"`
+	Age   uint8  `form:"age"`
+}
+
+func Test_Ctx_Bind(t *testing.T) {
+	its := assert.New(t)
+
+	id := uuid.V4()
+	var bound bindTestRequest
+	app := MustNew()
+	app.POST("/things/:id", func(r *Ctx) Result {
+		if result := r.BindOrBadRequest(&bound); result != nil {
+			return result
+		}
+		return NoContent
+	})
+
+	meta, err := MockPostJSON(app, "/things/"+id.String(), map[string]interface{}{"name": "foo", "count": 3},
+		r2.OptQueryValue("limit", "10"),
+		r2.OptQueryValueAdd("tag", "a"),
+		r2.OptQueryValueAdd("tag", "b"),
+		r2.OptQueryValue("active", "true"),
+		r2.OptQueryValue("timeout", "5s"),
+		r2.OptQueryValue("since", "2022-01-02T03:04:05Z"),
+		r2.OptHeaderValue("X-Request-ID", "request-id"),
+	).Discard()
+	its.Nil(err)
+	its.Equal(http.StatusNoContent, meta.StatusCode)
+	its.Equal(id, bound.ID)
+	its.Equal("foo", bound.Name)
+	its.Equal(3, bound.Count)
+	its.Equal(10, bound.Limit)
+	its.Zero(bound.Offset)
+	its.Equal([]string{"a", "b"}, bound.Tags)
+	its.NotNil(bound.Active)
+	its.True(*bound.Active)
+	its.Equal(5*time.Second, bound.Timeout)
+	its.Equal(time.Date(2022, 01, 02, 03, 04, 05, 0, time.UTC), bound.Since.UTC())
+	its.Equal("request-id", bound.RequestID)
+}
+
+func Test_Ctx_Bind_form(t *testing.T) {
+	its := assert.New(t)
+
+	var bound bindTestForm
+	app := MustNew()
+	app.POST("/", func(r *Ctx) Result {
+		if result := r.BindOrBadRequest(&bound, validate.Field("email", validate.String(&bound.Email).IsEmail())); result != nil {
+			return result
+		}
+		return NoContent
+	})
+
+	meta, err := MockPost(app, "/", nil, r2.OptPostForm(url.Values{"email": {"foo@example.com"}, "age": {"30"}})).Discard()
+	its.Nil(err)
+	its.Equal(http.StatusNoContent, meta.StatusCode)
+	its.Equal("foo@example.com", bound.Email)
+	its.Equal(30, bound.Age)
+}
+
+func Test_Ctx_Bind_fieldErrors(t *testing.T) {
+	its := assert.New(t)
+
+	app := MustNew(OptUse(JSONProviderAsDefault))
+	app.POST("/things/:id", func(r *Ctx) Result {
+		var bound bindTestRequest
+		return r.BindOrBadRequest(&bound)
+	})
+
+	var output BindError
+	meta, err := MockPost(app, "/things/not-a-uuid", nil,
+		r2.OptHeaderValue(webutil.HeaderContentType, webutil.ContentTypeApplicationJSON),
+		r2.OptBodyBytes([]byte(`{"name":"foo","count":"three"}`)),
+		r2.OptQueryValue("limit", "ten"),
+		r2.OptQueryValue("active", "maybe"),
+	).JSON(&output)
+	its.Nil(err)
+	its.Equal(http.StatusBadRequest, meta.StatusCode)
+	its.Equal("Bad Request", output.Message)
+	its.Equal([]BindFieldError{
+		{Field: "count", Source: BindSourceBody, Message: "cannot unmarshal string into int"},
+		{Field: "id", Source: BindSourceRoute, Message: "invalid uuid"},
+		{Field: "limit", Source: BindSourceQuery, Message: `invalid integer "ten"`},
+		{Field: "active", Source: BindSourceQuery, Message: `invalid boolean "maybe"`},
+	}, output.Fields)
+}
+
+func Test_Ctx_Bind_validationErrors(t *testing.T) {
+	its := assert.New(t)
+
+	app := MustNew(OptUse(JSONProviderAsDefault))
+	app.POST("/things/:id", func(r *Ctx) Result {
+		var bound bindTestRequest
+		return r.BindOrBadRequest(&bound)
+	})
+
+	var output BindError
+	meta, err := MockPostJSON(app, "/things/"+uuid.V4().String(), map[string]interface{}{"count": 30}).JSON(&output)
+	its.Nil(err)
+	its.Equal(http.StatusBadRequest, meta.StatusCode)
+	its.Len(output.Fields, 2)
+	its.Equal("name", output.Fields[0].Field)
+	its.Equal(BindSourceValidation, output.Fields[0].Source)
+	its.Equal(validate.ErrStringRequired.Error(), output.Fields[0].Message)
+	its.Equal("count", output.Fields[1].Field)
+	its.Contains(output.Fields[1].Message, validate.ErrIntMax.Error())
+}
+
+func Test_Ctx_Bind_invalidTarget(t *testing.T) {
+	its := assert.New(t)
+
+	ctx := MockCtx(http.MethodGet, "/")
+	var bound bindTestForm
+	its.True(ex.Is(ctx.Bind(bound), ErrBindTarget))
+	its.True(ex.Is(ctx.Bind(nil), ErrBindTarget))
+	its.Nil(ctx.Bind(&bound))
+}
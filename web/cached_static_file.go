@@ -57,17 +57,49 @@ type CachedStaticFile struct {
 	ETag     string
 	ModTime  time.Time
 	Contents *bytes.Reader
+
+	// ContentType is the content type of the file; if unset it is determined from the path or contents.
+	ContentType string
+	// ContentEncoding is the content encoding of pre-compressed files.
+	ContentEncoding string
+	// Variants are pre-compressed versions of the file in order of preference.
+	Variants []*CachedStaticFile
 }
 
 // Render implements Result.
 //
+// It honors conditional (If-None-Match, If-Modified-Since, If-Range) and
+// Range requests, including multipart byteranges, the same way as uncached files.
+// If the file has pre-compressed variants, the variant most preferred by the request is rendered.
+//
 // Note: It is safe to ingore the error returned from this method; it only
 // has this signature to satisfy the `Result` interface.
 func (csf CachedStaticFile) Render(ctx *Ctx) error {
+	if len(csf.Variants) > 0 {
+		webutil.AddVary(ctx.Response.Header(), webutil.HeaderAcceptEncoding)
+		encodings := make([]string, 0, len(csf.Variants))
+		for _, variant := range csf.Variants {
+			encodings = append(encodings, variant.ContentEncoding)
+		}
+		if encoding := webutil.NegotiateContentEncoding(ctx.Request.Header.Get(webutil.HeaderAcceptEncoding), encodings...); encoding != "" {
+			for _, variant := range csf.Variants {
+				if variant.ContentEncoding == encoding {
+					return variant.Render(ctx)
+				}
+			}
+		}
+	}
+	if csf.ContentType != "" {
+		ctx.Response.Header().Set(webutil.HeaderContentType, csf.ContentType)
+	}
+	if csf.ContentEncoding != "" {
+		ctx.Response.Header().Set(webutil.HeaderContentEncoding, csf.ContentEncoding)
+	}
 	if csf.ETag != "" {
-		ctx.Response.Header().Set(webutil.HeaderETag, csf.ETag)
+		ctx.Response.Header().Set(webutil.HeaderETag, webutil.QuoteETag(csf.ETag))
 	}
 	ctx.WithContext(logger.WithLabel(ctx.Context(), "web.static_file_cached", csf.Path))
-	http.ServeContent(ctx.Response, ctx.Request, csf.Path, csf.ModTime, csf.Contents)
+	// read through a section reader so concurrent requests don't share a seek offset.
+	http.ServeContent(ctx.Response, ctx.Request, csf.Path, csf.ModTime, io.NewSectionReader(csf.Contents, 0, csf.Contents.Size()))
 	return nil
 }
@@ -9,8 +9,11 @@ package web
 
 import (
 	"bytes"
+	"fmt"
 	"io"
 	"net/http"
+	"os"
+	"sync"
 	"testing"
 
 	"github.com/blend/go-sdk/assert"
@@ -43,9 +46,36 @@ func Test_CachedStaticFile_Render(t *testing.T) {
 	ctx := MockCtxWithBuffer(http.MethodGet, "index.html", buf)
 	err = csf.Render(ctx)
 	its.Nil(err)
-	its.Equal("da9a836ffc32feea4b26a536d3d0eccc", ctx.Response.Header().Get(webutil.HeaderETag))
+	its.Equal(`"da9a836ffc32feea4b26a536d3d0eccc"`, ctx.Response.Header().Get(webutil.HeaderETag))
 	its.Equal("text/html; charset=utf-8", ctx.Response.Header().Get(webutil.HeaderContentType))
 
 	its.Equal("testdata/test_file.html", logger.GetLabels(ctx.Context())["web.static_file_cached"])
 	its.Contains(buf.String(), `<title>Test!</title>`)
 }
+
+func Test_CachedStaticFile_Render_concurrentRanges(t *testing.T) {
+	its := assert.New(t)
+
+	csf, err := NewCachedStaticFile("testdata/test_file.html")
+	its.Nil(err)
+	contents, err := os.ReadFile("testdata/test_file.html")
+	its.Nil(err)
+
+	var wg sync.WaitGroup
+	results := make([]string, 64)
+	for x := 0; x < len(results); x++ {
+		wg.Add(1)
+		go func(offset int) {
+			defer wg.Done()
+			buf := new(bytes.Buffer)
+			ctx := MockCtxWithBuffer(http.MethodGet, "index.html", buf)
+			ctx.Request.Header.Set(webutil.HeaderRange, fmt.Sprintf("bytes=%d-%d", offset, offset+9))
+			_ = csf.Render(ctx)
+			results[offset] = buf.String()
+		}(x)
+	}
+	wg.Wait()
+	for offset, result := range results {
+		its.Equal(string(contents[offset:offset+10]), result)
+	}
+}
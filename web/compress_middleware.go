@@ -0,0 +1,127 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package web
+
+import (
+	"github.com/blend/go-sdk/webutil"
+)
+
+const (
+	// DefaultCompressMinSize is the default minimum response size in bytes for compression.
+	DefaultCompressMinSize = 1024
+)
+
+var (
+	// DefaultCompressContentTypes are the default media types that are compressed.
+	DefaultCompressContentTypes = []string{
+		"text/*",
+		"application/javascript",
+		"application/json",
+		"application/xml",
+		"application/wasm",
+		"image/svg+xml",
+	}
+)
+
+// CompressEncoding is a named content encoding used by the compress middleware.
+type CompressEncoding struct {
+	Name    string
+	Encoder webutil.ContentEncoder
+}
+
+// CompressOptions are options for the compress middleware.
+type CompressOptions struct {
+	// Encodings are the supported content encodings in order of preference.
+	Encodings []CompressEncoding
+	// MinSize is the minimum size of a response in bytes for it to be compressed.
+	MinSize int
+	// ContentTypes are the media types that are compressed, e.g. `text/*`.
+	// If empty, all content types are compressed.
+	ContentTypes []string
+}
+
+// CompressOption mutates compress options.
+type CompressOption func(*CompressOptions)
+
+// OptCompressEncoding adds a content encoding, e.g. `br` or `zstd`.
+//
+// Encodings added with options are preferred over the built in gzip and deflate
+// encodings, and over each other in the order they're added.
+// Adding an encoding with the name of a built in encoding replaces it.
+func OptCompressEncoding(name string, encoder webutil.ContentEncoder) CompressOption {
+	return func(co *CompressOptions) {
+		var added, builtin []CompressEncoding
+		for _, encoding := range co.Encodings {
+			if encoding.Name == name {
+				continue
+			}
+			if encoding.Name == webutil.ContentEncodingGZIP || encoding.Name == webutil.ContentEncodingDeflate {
+				builtin = append(builtin, encoding)
+			} else {
+				added = append(added, encoding)
+			}
+		}
+		added = append(added, CompressEncoding{Name: name, Encoder: encoder})
+		co.Encodings = append(added, builtin...)
+	}
+}
+
+// OptCompressMinSize sets the minimum size of a response in bytes for it to be compressed.
+func OptCompressMinSize(minSize int) CompressOption {
+	return func(co *CompressOptions) { co.MinSize = minSize }
+}
+
+// OptCompressContentTypes sets the media types that are compressed.
+func OptCompressContentTypes(contentTypes ...string) CompressOption {
+	return func(co *CompressOptions) { co.ContentTypes = contentTypes }
+}
+
+// Compress returns a middleware that compresses responses with the best content
+// encoding accepted by the client.
+//
+// Gzip and deflate are supported by default, and other encodings can be added with `OptCompressEncoding`.
+// Responses smaller than the minimum size, with content types outside the allow-list, that
+// already have a content encoding, or that are partial content are not compressed.
+func Compress(options ...CompressOption) Middleware {
+	co := CompressOptions{
+		Encodings: []CompressEncoding{
+			{Name: webutil.ContentEncodingGZIP, Encoder: webutil.GZipContentEncoder},
+			{Name: webutil.ContentEncodingDeflate, Encoder: webutil.DeflateContentEncoder},
+		},
+		MinSize:      DefaultCompressMinSize,
+		ContentTypes: DefaultCompressContentTypes,
+	}
+	for _, option := range options {
+		option(&co)
+	}
+	names := make([]string, 0, len(co.Encodings))
+	encoders := make(map[string]webutil.ContentEncoder, len(co.Encodings))
+	for _, encoding := range co.Encodings {
+		names = append(names, encoding.Name)
+		encoders[encoding.Name] = encoding.Encoder
+	}
+
+	return func(action Action) Action {
+		return func(r *Ctx) Result {
+			// the response varies by encoding whether or not this request is compressed.
+			webutil.AddVary(r.Response.Header(), webutil.HeaderAcceptEncoding)
+			if r.Request.Header.Get(webutil.HeaderUpgrade) != "" {
+				return action(r)
+			}
+			name := webutil.NegotiateContentEncoding(r.Request.Header.Get(webutil.HeaderAcceptEncoding), names...)
+			if name == "" {
+				return action(r)
+			}
+			crw := webutil.NewCompressedResponseWriter(r.Response, name, encoders[name])
+			crw.MinSize = co.MinSize
+			crw.ContentTypes = co.ContentTypes
+			r.Response = crw
+			return action(r)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package web
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/blend/go-sdk/assert"
+	"github.com/blend/go-sdk/r2"
+	"github.com/blend/go-sdk/webutil"
+)
+
+type compressTestEncoder struct {
+	io.Writer
+}
+
+func (compressTestEncoder) Close() error { return nil }
+
+func Test_Compress(t *testing.T) {
+	its := assert.New(t)
+
+	large := strings.Repeat("compress me please ", 200)
+	app := MustNew(OptUse(Compress(
+		OptCompressEncoding("test", func(w io.Writer) (io.WriteCloser, error) {
+			return compressTestEncoder{w}, nil
+		}),
+	)))
+	app.GET("/large", func(_ *Ctx) Result {
+		return Text.Result(large)
+	})
+	app.GET("/small", func(_ *Ctx) Result {
+		return Text.Result("small")
+	})
+	app.GET("/image", func(_ *Ctx) Result {
+		return &RawResult{ContentType: "image/png", Response: []byte(large)}
+	})
+
+	// gzip
+	body, res, err := MockGet(app, "/large", r2.OptHeaderValue(webutil.HeaderAcceptEncoding, "gzip, deflate")).Bytes()
+	its.Nil(err)
+	its.Equal(http.StatusOK, res.StatusCode)
+	its.Equal(webutil.ContentEncodingGZIP, res.Header.Get(webutil.HeaderContentEncoding))
+	its.Equal(webutil.HeaderAcceptEncoding, res.Header.Get(webutil.HeaderVary))
+	reader, err := gzip.NewReader(bytes.NewReader(body))
+	its.Nil(err)
+	decompressed, err := io.ReadAll(reader)
+	its.Nil(err)
+	its.Equal(large, string(decompressed))
+
+	// deflate is preferred by quality
+	body, res, err = MockGet(app, "/large", r2.OptHeaderValue(webutil.HeaderAcceptEncoding, "gzip;q=0.5, deflate")).Bytes()
+	its.Nil(err)
+	its.Equal(webutil.ContentEncodingDeflate, res.Header.Get(webutil.HeaderContentEncoding))
+	decompressed, err = io.ReadAll(flate.NewReader(bytes.NewReader(body)))
+	its.Nil(err)
+	its.Equal(large, string(decompressed))
+
+	// added encodings are preferred over the built in encodings
+	body, res, err = MockGet(app, "/large", r2.OptHeaderValue(webutil.HeaderAcceptEncoding, "gzip, test")).Bytes()
+	its.Nil(err)
+	its.Equal("test", res.Header.Get(webutil.HeaderContentEncoding))
+	its.Equal(large, string(body))
+
+	// below the minimum size
+	body, res, err = MockGet(app, "/small", r2.OptHeaderValue(webutil.HeaderAcceptEncoding, "gzip")).Bytes()
+	its.Nil(err)
+	its.Empty(res.Header.Get(webutil.HeaderContentEncoding))
+	its.Equal(webutil.HeaderAcceptEncoding, res.Header.Get(webutil.HeaderVary))
+	its.Equal("small", string(body))
+
+	// content type not in the allow-list
+	body, res, err = MockGet(app, "/image", r2.OptHeaderValue(webutil.HeaderAcceptEncoding, "gzip")).Bytes()
+	its.Nil(err)
+	its.Empty(res.Header.Get(webutil.HeaderContentEncoding))
+	its.Equal(large, string(body))
+
+	// not accepted
+	body, res, err = MockGet(app, "/large", r2.OptHeaderValue(webutil.HeaderAcceptEncoding, "br")).Bytes()
+	its.Nil(err)
+	its.Empty(res.Header.Get(webutil.HeaderContentEncoding))
+	its.Equal(webutil.HeaderAcceptEncoding, res.Header.Get(webutil.HeaderVary))
+	its.Equal(large, string(body))
+}
+
+func Test_Compress_preservesVary(t *testing.T) {
+	its := assert.New(t)
+
+	for _, middleware := range []Middleware{Compress(OptCompressMinSize(0)), GZip} {
+		// the compression middleware is applied inside of the cors middleware.
+		app := MustNew(
+			OptUse(middleware),
+			OptCORS(CORSPolicy{AllowedOrigins: []string{"https://example.com"}}),
+		)
+		app.GET("/", ok)
+
+		_, res, err := MockGet(app, "/",
+			r2.OptHeaderValue(webutil.HeaderOrigin, "https://example.com"),
+			r2.OptHeaderValue(webutil.HeaderAcceptEncoding, "gzip"),
+		).Bytes()
+		its.Nil(err)
+		its.Equal(webutil.ContentEncodingGZIP, res.Header.Get(webutil.HeaderContentEncoding))
+		its.Equal("https://example.com", res.Header.Get(webutil.HeaderAccessControlAllowOrigin))
+		vary := strings.Join(res.Header.Values(webutil.HeaderVary), ",")
+		its.Contains(vary, webutil.HeaderOrigin)
+		its.Equal(1, strings.Count(vary, webutil.HeaderAcceptEncoding))
+	}
+}
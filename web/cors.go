@@ -0,0 +1,301 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package web
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/blend/go-sdk/webutil"
+)
+
+// CORSOriginAny is an allowed origin value that matches any origin.
+const CORSOriginAny = "*"
+
+// DefaultCORSAllowedMethods are the methods allowed for preflight requests answered by the
+// `CORS` middleware if the policy does not specify `AllowedMethods`; they are the CORS safelisted methods.
+var DefaultCORSAllowedMethods = []string{http.MethodGet, http.MethodHead, http.MethodPost}
+
+// CORSPolicy is a cross-origin resource sharing policy.
+//
+// It can be applied to individual routes with the `CORS` middleware, or
+// to the whole app with `OptCORS` and `OptCORSRoute`, in which case
+// preflight requests are answered automatically for any registered route.
+type CORSPolicy struct {
+	// AllowedOrigins are the origins that may make cross-origin requests.
+	// Values are matched exactly (ignoring case), `*` matches any origin, and
+	// a value with a wildcard subdomain (e.g. `https://*.example.com`) matches any subdomain.
+	//
+	// If `AllowCredentials` is set, `*` does not match any origin, as credentialed requests
+	// from any origin are forbidden by the spec; list the allowed origins explicitly instead.
+	AllowedOrigins []string
+	// AllowOriginFunc is an optional predicate consulted if the origin
+	// does not match any of the `AllowedOrigins`.
+	AllowOriginFunc func(origin string) bool
+	// AllowedMethods are the methods allowed for preflight requests.
+	// If unset, the methods registered for the route are allowed for app level policies,
+	// and the `DefaultCORSAllowedMethods` are allowed for the `CORS` middleware.
+	AllowedMethods []string
+	// AllowedHeaders are the request headers allowed for preflight requests.
+	// A value of `*` allows any requested header.
+	AllowedHeaders []string
+	// ExposedHeaders are the response headers the browser may expose to scripts.
+	ExposedHeaders []string
+	// AllowCredentials indicates if the request may include credentials (i.e. cookies).
+	AllowCredentials bool
+	// MaxAge is how long the results of a preflight request may be cached.
+	MaxAge time.Duration
+}
+
+// IsOriginAllowed returns if a given origin is allowed by the policy.
+func (cp CORSPolicy) IsOriginAllowed(origin string) bool {
+	if origin == "" {
+		return false
+	}
+	origin = strings.ToLower(origin)
+	for _, allowed := range cp.AllowedOrigins {
+		if cp.AllowCredentials && allowed == CORSOriginAny {
+			continue
+		}
+		if corsOriginMatches(strings.ToLower(allowed), origin) {
+			return true
+		}
+	}
+	if cp.AllowOriginFunc != nil {
+		return cp.AllowOriginFunc(origin)
+	}
+	return false
+}
+
+// ApplyHeaders sets the response headers for an actual (i.e. non-preflight) cross-origin request.
+//
+// Any existing cross-origin headers are removed first, such that the last policy applied wins.
+func (cp CORSPolicy) ApplyHeaders(header http.Header, req *http.Request) {
+	corsRemoveHeaders(header)
+	origin := req.Header.Get(webutil.HeaderOrigin)
+	if origin == "" {
+		return
+	}
+	if !cp.applyOrigin(header, origin) {
+		return
+	}
+	if len(cp.ExposedHeaders) > 0 {
+		header.Set(webutil.HeaderAccessControlExposeHeaders, strings.Join(cp.ExposedHeaders, ", "))
+	}
+}
+
+// ApplyPreflightHeaders sets the response headers for a preflight request.
+//
+// The routeMethods are used for the allowed methods if the policy does not specify `AllowedMethods`.
+// It returns if the preflight request was allowed by the policy.
+func (cp CORSPolicy) ApplyPreflightHeaders(header http.Header, req *http.Request, routeMethods []string) bool {
+	corsRemoveHeaders(header)
+	origin := req.Header.Get(webutil.HeaderOrigin)
+	if origin == "" {
+		return false
+	}
+
+	allowedMethods := cp.AllowedMethods
+	if len(allowedMethods) == 0 {
+		allowedMethods = routeMethods
+	}
+	requestMethod := req.Header.Get(webutil.HeaderAccessControlRequestMethod)
+	if !corsContains(allowedMethods, requestMethod, false) {
+		return false
+	}
+	requestHeaders := corsSplit(req.Header.Get(webutil.HeaderAccessControlRequestHeaders))
+	for _, requestHeader := range requestHeaders {
+		if !corsContains(cp.AllowedHeaders, requestHeader, true) {
+			return false
+		}
+	}
+	if !cp.applyOrigin(header, origin) {
+		return false
+	}
+
+	header.Set(webutil.HeaderAccessControlAllowMethods, strings.Join(allowedMethods, ", "))
+	if len(requestHeaders) > 0 {
+		header.Set(webutil.HeaderAccessControlAllowHeaders, strings.Join(requestHeaders, ", "))
+	}
+	if cp.MaxAge > 0 {
+		header.Set(webutil.HeaderAccessControlMaxAge, strconv.Itoa(int(cp.MaxAge/time.Second)))
+	}
+	return true
+}
+
+func (cp CORSPolicy) applyOrigin(header http.Header, origin string) bool {
+	if !cp.AllowCredentials && corsContains(cp.AllowedOrigins, CORSOriginAny, false) {
+		header.Set(webutil.HeaderAccessControlAllowOrigin, CORSOriginAny)
+		return true
+	}
+	if !webutil.HeaderAny(header, webutil.HeaderVary, webutil.HeaderOrigin) {
+		header.Add(webutil.HeaderVary, webutil.HeaderOrigin)
+	}
+	if !cp.IsOriginAllowed(origin) {
+		return false
+	}
+	header.Set(webutil.HeaderAccessControlAllowOrigin, origin)
+	if cp.AllowCredentials {
+		header.Set(webutil.HeaderAccessControlAllowCredentials, "true")
+	}
+	return true
+}
+
+// IsCORSPreflight returns if a request is a cross-origin preflight request.
+func IsCORSPreflight(req *http.Request) bool {
+	return req.Method == http.MethodOptions &&
+		req.Header.Get(webutil.HeaderOrigin) != "" &&
+		req.Header.Get(webutil.HeaderAccessControlRequestMethod) != ""
+}
+
+// CORS returns a middleware that applies a cross-origin resource sharing policy.
+//
+// Preflight requests that reach the middleware (i.e. if an `OPTIONS` route is registered with it)
+// are answered directly with a no content result; all other requests have the policy headers applied.
+//
+// The middleware does not know the methods registered for the path, so preflight requests allow
+// the `DefaultCORSAllowedMethods` unless the policy specifies `AllowedMethods`.
+func CORS(policy CORSPolicy) Middleware {
+	return func(action Action) Action {
+		return func(ctx *Ctx) Result {
+			if IsCORSPreflight(ctx.Request) {
+				policy.ApplyPreflightHeaders(ctx.Response.Header(), ctx.Request, DefaultCORSAllowedMethods)
+				return NoContent
+			}
+			policy.ApplyHeaders(ctx.Response.Header(), ctx.Request)
+			return action(ctx)
+		}
+	}
+}
+
+//
+// app helpers
+//
+
+// corsEnable enables the app level cors middleware, which is added to routes as they
+// are registered, and installs the preflight handler.
+func (a *App) corsEnable() {
+	if a.CORSPolicy != nil || a.CORSRoutePolicies != nil {
+		return
+	}
+	a.CORSRoutePolicies = make(map[string]CORSPolicy)
+	a.MethodOptionsHandler = a.RenderAction(a.corsPreflight)
+}
+
+// corsPolicy returns the cors policy for a given route.
+func (a *App) corsPolicy(route *Route) (CORSPolicy, bool) {
+	if route != nil {
+		if policy, ok := a.CORSRoutePolicies[route.Path]; ok {
+			return policy, true
+		}
+	}
+	if a.CORSPolicy != nil {
+		return *a.CORSPolicy, true
+	}
+	return CORSPolicy{}, false
+}
+
+// corsMiddleware applies the app cors policies to actual requests.
+//
+// Preflight requests to explicitly registered `OPTIONS` routes are passed through
+// so that the route can answer them.
+func (a *App) corsMiddleware(action Action) Action {
+	return func(ctx *Ctx) Result {
+		if !IsCORSPreflight(ctx.Request) {
+			if policy, ok := a.corsPolicy(ctx.Route); ok {
+				policy.ApplyHeaders(ctx.Response.Header(), ctx.Request)
+			}
+		}
+		return action(ctx)
+	}
+}
+
+// corsPreflight answers preflight requests for paths without an explicit `OPTIONS` route.
+//
+// It is called by the route tree with the `Allow` header already set to the methods
+// registered for the path.
+func (a *App) corsPreflight(ctx *Ctx) Result {
+	if !IsCORSPreflight(ctx.Request) {
+		return NoContent
+	}
+	routeMethods := corsSplit(ctx.Response.Header().Get(webutil.HeaderAllow))
+	var route *Route
+	for _, method := range routeMethods {
+		if route, _, _ = a.Lookup(method, ctx.Request.URL.Path); route != nil {
+			break
+		}
+	}
+	if policy, ok := a.corsPolicy(route); ok {
+		policy.ApplyPreflightHeaders(ctx.Response.Header(), ctx.Request, routeMethods)
+	}
+	return NoContent
+}
+
+//
+// internal helpers
+//
+
+var corsHeaders = []string{
+	webutil.HeaderAccessControlAllowCredentials,
+	webutil.HeaderAccessControlAllowHeaders,
+	webutil.HeaderAccessControlAllowMethods,
+	webutil.HeaderAccessControlAllowOrigin,
+	webutil.HeaderAccessControlExposeHeaders,
+	webutil.HeaderAccessControlMaxAge,
+}
+
+func corsRemoveHeaders(header http.Header) {
+	for _, key := range corsHeaders {
+		header.Del(key)
+	}
+}
+
+// corsOriginMatches matches an origin against an allowed origin pattern.
+//
+// Both values are expected to be lower case.
+func corsOriginMatches(pattern, origin string) bool {
+	if pattern == CORSOriginAny || pattern == origin {
+		return true
+	}
+	index := strings.Index(pattern, "*.")
+	if index < 0 {
+		return false
+	}
+	prefix, suffix := pattern[:index], pattern[index+1:]
+	if len(origin) <= len(prefix)+len(suffix) {
+		return false
+	}
+	if !strings.HasPrefix(origin, prefix) || !strings.HasSuffix(origin, suffix) {
+		return false
+	}
+	subdomain := origin[len(prefix) : len(origin)-len(suffix)]
+	return !strings.ContainsAny(subdomain, "/:")
+}
+
+func corsContains(values []string, value string, allowAny bool) bool {
+	for _, v := range values {
+		if allowAny && v == CORSOriginAny {
+			return true
+		}
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func corsSplit(value string) (output []string) {
+	for _, piece := range strings.Split(value, ",") {
+		if piece = strings.TrimSpace(piece); piece != "" {
+			output = append(output, piece)
+		}
+	}
+	return
+}
@@ -0,0 +1,273 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package web
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/blend/go-sdk/assert"
+	"github.com/blend/go-sdk/r2"
+	"github.com/blend/go-sdk/webutil"
+)
+
+func Test_CORSPolicy_IsOriginAllowed(t *testing.T) {
+	its := assert.New(t)
+
+	policy := CORSPolicy{
+		AllowedOrigins: []string{"https://app.example.com", "https://*.example.org"},
+		AllowOriginFunc: func(origin string) bool {
+			return strings.HasSuffix(origin, ".test")
+		},
+	}
+	its.True(policy.IsOriginAllowed("https://app.example.com"))
+	its.True(policy.IsOriginAllowed("https://APP.example.com"))
+	its.False(policy.IsOriginAllowed("http://app.example.com"))
+	its.False(policy.IsOriginAllowed("https://other.example.com"))
+	its.True(policy.IsOriginAllowed("https://foo.example.org"))
+	its.True(policy.IsOriginAllowed("https://foo.bar.example.org"))
+	its.False(policy.IsOriginAllowed("https://example.org"))
+	its.False(policy.IsOriginAllowed("https://.example.org"))
+	its.False(policy.IsOriginAllowed("https://evil.com/.example.org"))
+	its.True(policy.IsOriginAllowed("http://local.test"))
+	its.False(policy.IsOriginAllowed(""))
+
+	its.True(CORSPolicy{AllowedOrigins: []string{CORSOriginAny}}.IsOriginAllowed("https://anything.com"))
+	its.False(CORSPolicy{AllowedOrigins: []string{CORSOriginAny}, AllowCredentials: true}.IsOriginAllowed("https://anything.com"))
+	its.True(CORSPolicy{AllowedOrigins: []string{CORSOriginAny, "https://app.example.com"}, AllowCredentials: true}.IsOriginAllowed("https://app.example.com"))
+}
+
+func Test_CORS_actual(t *testing.T) {
+	its := assert.New(t)
+
+	app := MustNew()
+	app.GET("/", ok, CORS(CORSPolicy{
+		AllowedOrigins:   []string{"https://app.example.com"},
+		ExposedHeaders:   []string{"X-Request-Id"},
+		AllowCredentials: true,
+	}))
+
+	meta, err := MockGet(app, "/", r2.OptHeaderValue(webutil.HeaderOrigin, "https://app.example.com")).Discard()
+	its.Nil(err)
+	its.Equal(http.StatusOK, meta.StatusCode)
+	its.Equal("https://app.example.com", meta.Header.Get(webutil.HeaderAccessControlAllowOrigin))
+	its.Equal("true", meta.Header.Get(webutil.HeaderAccessControlAllowCredentials))
+	its.Equal("X-Request-Id", meta.Header.Get(webutil.HeaderAccessControlExposeHeaders))
+	its.Equal(webutil.HeaderOrigin, meta.Header.Get(webutil.HeaderVary))
+
+	meta, err = MockGet(app, "/", r2.OptHeaderValue(webutil.HeaderOrigin, "https://evil.com")).Discard()
+	its.Nil(err)
+	its.Equal(http.StatusOK, meta.StatusCode)
+	its.Empty(meta.Header.Get(webutil.HeaderAccessControlAllowOrigin))
+	its.Empty(meta.Header.Get(webutil.HeaderAccessControlAllowCredentials))
+}
+
+func Test_CORS_wildcard(t *testing.T) {
+	its := assert.New(t)
+
+	app := MustNew()
+	app.GET("/", ok, CORS(CORSPolicy{AllowedOrigins: []string{CORSOriginAny}}))
+
+	meta, err := MockGet(app, "/", r2.OptHeaderValue(webutil.HeaderOrigin, "https://app.example.com")).Discard()
+	its.Nil(err)
+	its.Equal("*", meta.Header.Get(webutil.HeaderAccessControlAllowOrigin))
+	its.Empty(meta.Header.Get(webutil.HeaderVary))
+}
+
+func Test_CORS_wildcardCredentials(t *testing.T) {
+	its := assert.New(t)
+
+	app := MustNew()
+	app.GET("/", ok, CORS(CORSPolicy{
+		AllowedOrigins:   []string{CORSOriginAny},
+		AllowCredentials: true,
+	}))
+
+	meta, err := MockGet(app, "/", r2.OptHeaderValue(webutil.HeaderOrigin, "https://evil.com")).Discard()
+	its.Nil(err)
+	its.Equal(http.StatusOK, meta.StatusCode)
+	its.Empty(meta.Header.Get(webutil.HeaderAccessControlAllowOrigin))
+	its.Empty(meta.Header.Get(webutil.HeaderAccessControlAllowCredentials))
+}
+
+func Test_CORS_preflightRoute(t *testing.T) {
+	its := assert.New(t)
+
+	var didExecuteHandler bool
+	app := MustNew()
+	app.OPTIONS("/", func(_ *Ctx) Result {
+		didExecuteHandler = true
+		return NoContent
+	}, CORS(CORSPolicy{
+		AllowedOrigins: []string{"https://app.example.com"},
+		AllowedMethods: []string{http.MethodGet, http.MethodPut},
+		AllowedHeaders: []string{"Content-Type"},
+		MaxAge:         time.Hour,
+	}))
+
+	meta, err := MockMethod(app, http.MethodOptions, "/",
+		r2.OptHeaderValue(webutil.HeaderOrigin, "https://app.example.com"),
+		r2.OptHeaderValue(webutil.HeaderAccessControlRequestMethod, http.MethodPut),
+		r2.OptHeaderValue(webutil.HeaderAccessControlRequestHeaders, "content-type"),
+	).Discard()
+	its.Nil(err)
+	its.Equal(http.StatusNoContent, meta.StatusCode)
+	its.False(didExecuteHandler)
+	its.Equal("https://app.example.com", meta.Header.Get(webutil.HeaderAccessControlAllowOrigin))
+	its.Equal("GET, PUT", meta.Header.Get(webutil.HeaderAccessControlAllowMethods))
+	its.Equal("content-type", meta.Header.Get(webutil.HeaderAccessControlAllowHeaders))
+	its.Equal("3600", meta.Header.Get(webutil.HeaderAccessControlMaxAge))
+
+	meta, err = MockMethod(app, http.MethodOptions, "/",
+		r2.OptHeaderValue(webutil.HeaderOrigin, "https://app.example.com"),
+		r2.OptHeaderValue(webutil.HeaderAccessControlRequestMethod, http.MethodPut),
+		r2.OptHeaderValue(webutil.HeaderAccessControlRequestHeaders, "X-Not-Allowed"),
+	).Discard()
+	its.Nil(err)
+	its.Equal(http.StatusNoContent, meta.StatusCode)
+	its.Empty(meta.Header.Get(webutil.HeaderAccessControlAllowOrigin))
+	its.Empty(meta.Header.Get(webutil.HeaderAccessControlAllowMethods))
+
+	meta, err = MockMethod(app, http.MethodOptions, "/").Discard()
+	its.Nil(err)
+	its.Equal(http.StatusNoContent, meta.StatusCode)
+	its.True(didExecuteHandler)
+}
+
+func Test_CORS_preflightDefaultMethods(t *testing.T) {
+	its := assert.New(t)
+
+	app := MustNew()
+	app.OPTIONS("/", ok, CORS(CORSPolicy{
+		AllowedOrigins: []string{"https://app.example.com"},
+	}))
+
+	meta, err := MockMethod(app, http.MethodOptions, "/",
+		r2.OptHeaderValue(webutil.HeaderOrigin, "https://app.example.com"),
+		r2.OptHeaderValue(webutil.HeaderAccessControlRequestMethod, http.MethodPost),
+	).Discard()
+	its.Nil(err)
+	its.Equal(http.StatusNoContent, meta.StatusCode)
+	its.Equal("https://app.example.com", meta.Header.Get(webutil.HeaderAccessControlAllowOrigin))
+	its.Equal("GET, HEAD, POST", meta.Header.Get(webutil.HeaderAccessControlAllowMethods))
+
+	meta, err = MockMethod(app, http.MethodOptions, "/",
+		r2.OptHeaderValue(webutil.HeaderOrigin, "https://app.example.com"),
+		r2.OptHeaderValue(webutil.HeaderAccessControlRequestMethod, http.MethodDelete),
+	).Discard()
+	its.Nil(err)
+	its.Equal(http.StatusNoContent, meta.StatusCode)
+	its.Empty(meta.Header.Get(webutil.HeaderAccessControlAllowOrigin))
+	its.Empty(meta.Header.Get(webutil.HeaderAccessControlAllowMethods))
+}
+
+func Test_OptCORS_preflight(t *testing.T) {
+	its := assert.New(t)
+
+	app := MustNew(
+		OptCORS(CORSPolicy{
+			AllowedOrigins: []string{"https://*.example.com"},
+		}),
+		OptCORSRoute("/admin/:id", CORSPolicy{
+			AllowedOrigins: []string{"https://admin.example.com"},
+			AllowedHeaders: []string{CORSOriginAny},
+		}),
+	)
+	app.GET("/users/:id", ok)
+	app.PUT("/users/:id", ok)
+	app.DELETE("/admin/:id", ok)
+
+	meta, err := MockMethod(app, http.MethodOptions, "/users/1",
+		r2.OptHeaderValue(webutil.HeaderOrigin, "https://app.example.com"),
+		r2.OptHeaderValue(webutil.HeaderAccessControlRequestMethod, http.MethodPut),
+	).Discard()
+	its.Nil(err)
+	its.Equal(http.StatusNoContent, meta.StatusCode)
+	its.Equal("https://app.example.com", meta.Header.Get(webutil.HeaderAccessControlAllowOrigin))
+	its.Contains(meta.Header.Get(webutil.HeaderAccessControlAllowMethods), http.MethodGet)
+	its.Contains(meta.Header.Get(webutil.HeaderAccessControlAllowMethods), http.MethodPut)
+	its.NotEmpty(meta.Header.Get(webutil.HeaderAllow))
+
+	meta, err = MockMethod(app, http.MethodOptions, "/users/1",
+		r2.OptHeaderValue(webutil.HeaderOrigin, "https://app.example.com"),
+		r2.OptHeaderValue(webutil.HeaderAccessControlRequestMethod, http.MethodPatch),
+	).Discard()
+	its.Nil(err)
+	its.Equal(http.StatusNoContent, meta.StatusCode)
+	its.Empty(meta.Header.Get(webutil.HeaderAccessControlAllowOrigin))
+
+	meta, err = MockMethod(app, http.MethodOptions, "/admin/1",
+		r2.OptHeaderValue(webutil.HeaderOrigin, "https://app.example.com"),
+		r2.OptHeaderValue(webutil.HeaderAccessControlRequestMethod, http.MethodDelete),
+	).Discard()
+	its.Nil(err)
+	its.Empty(meta.Header.Get(webutil.HeaderAccessControlAllowOrigin))
+
+	meta, err = MockMethod(app, http.MethodOptions, "/admin/1",
+		r2.OptHeaderValue(webutil.HeaderOrigin, "https://admin.example.com"),
+		r2.OptHeaderValue(webutil.HeaderAccessControlRequestMethod, http.MethodDelete),
+		r2.OptHeaderValue(webutil.HeaderAccessControlRequestHeaders, "X-Anything"),
+	).Discard()
+	its.Nil(err)
+	its.Equal("https://admin.example.com", meta.Header.Get(webutil.HeaderAccessControlAllowOrigin))
+	its.Equal("X-Anything", meta.Header.Get(webutil.HeaderAccessControlAllowHeaders))
+
+	meta, err = MockMethod(app, http.MethodOptions, "/not-found",
+		r2.OptHeaderValue(webutil.HeaderOrigin, "https://app.example.com"),
+		r2.OptHeaderValue(webutil.HeaderAccessControlRequestMethod, http.MethodGet),
+	).Discard()
+	its.Nil(err)
+	its.Equal(http.StatusNotFound, meta.StatusCode)
+}
+
+func Test_OptCORS_actual(t *testing.T) {
+	its := assert.New(t)
+
+	app := MustNew(
+		OptCORS(CORSPolicy{AllowedOrigins: []string{"https://app.example.com"}}),
+	)
+	app.GET("/", ok)
+	app.GET("/override", ok, CORS(CORSPolicy{AllowedOrigins: []string{"https://other.example.com"}}))
+
+	meta, err := MockGet(app, "/", r2.OptHeaderValue(webutil.HeaderOrigin, "https://app.example.com")).Discard()
+	its.Nil(err)
+	its.Equal(http.StatusOK, meta.StatusCode)
+	its.Equal("https://app.example.com", meta.Header.Get(webutil.HeaderAccessControlAllowOrigin))
+
+	meta, err = MockGet(app, "/override", r2.OptHeaderValue(webutil.HeaderOrigin, "https://app.example.com")).Discard()
+	its.Nil(err)
+	its.Empty(meta.Header.Get(webutil.HeaderAccessControlAllowOrigin))
+
+	meta, err = MockGet(app, "/override", r2.OptHeaderValue(webutil.HeaderOrigin, "https://other.example.com")).Discard()
+	its.Nil(err)
+	its.Equal("https://other.example.com", meta.Header.Get(webutil.HeaderAccessControlAllowOrigin))
+}
+
+func Test_OptCORS_baseMiddleware(t *testing.T) {
+	its := assert.New(t)
+
+	var called bool
+	app := MustNew(
+		OptCORS(CORSPolicy{AllowedOrigins: []string{"https://app.example.com"}}),
+		OptBaseMiddleware(func(action Action) Action {
+			return func(ctx *Ctx) Result {
+				called = true
+				return action(ctx)
+			}
+		}),
+	)
+	app.GET("/", ok)
+
+	meta, err := MockGet(app, "/", r2.OptHeaderValue(webutil.HeaderOrigin, "https://app.example.com")).Discard()
+	its.Nil(err)
+	its.Equal(http.StatusOK, meta.StatusCode)
+	its.True(called)
+	its.Equal("https://app.example.com", meta.Header.Get(webutil.HeaderAccessControlAllowOrigin))
+}
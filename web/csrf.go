@@ -0,0 +1,123 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package web
+
+//github:codeowner @blend/infosec
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"html/template"
+	"net/http"
+
+	"github.com/blend/go-sdk/ex"
+)
+
+const (
+	// CSRFHeaderName is the request header the csrf token is read from.
+	CSRFHeaderName = "X-CSRF-Token"
+	// CSRFFormField is the form field the csrf token is read from if the header is unset.
+	CSRFFormField = "_csrf"
+)
+
+const (
+	// ErrCSRFSessionUnset is returned if a csrf token is requested without a session.
+	ErrCSRFSessionUnset ex.Class = "csrf; session is unset"
+	// ErrCSRFTokenInvalid is returned if a request csrf token is missing or does not match the session.
+	ErrCSRFTokenInvalid ex.Class = "csrf; token is missing or invalid"
+)
+
+// CSRFToken returns the csrf token for the session on the ctx.
+//
+// If the session does not have a token yet, one is generated and the session
+// is saved with the auth manager persist handler.
+func CSRFToken(ctx *Ctx) (string, error) {
+	if ctx.Session == nil {
+		return "", ex.New(ErrCSRFSessionUnset)
+	}
+	if ctx.Session.CSRFToken != "" {
+		return ctx.Session.CSRFToken, nil
+	}
+	ctx.Session.CSRFToken = NewSessionID()
+	if ctx.Auth.PersistHandler != nil {
+		if err := ctx.Auth.PersistHandler(ctx.Context(), ctx.Session); err != nil {
+			return "", err
+		}
+	}
+	return ctx.Session.CSRFToken, nil
+}
+
+// CSRFField returns a hidden form input containing the csrf token for the session on the ctx.
+//
+// It is included in the view cache func map as `csrf_field`, and is meant to be
+// used in forms as `{{ csrf_field .Ctx }}`.
+func CSRFField(ctx *Ctx) (template.HTML, error) {
+	token, err := CSRFToken(ctx)
+	if err != nil {
+		return "", err
+	}
+	return template.HTML(fmt.Sprintf(`<input type="hidden" name="%s" value="%s">`, CSRFFormField, template.HTMLEscapeString(token))), nil
+}
+
+// VerifyCSRF verifies the csrf token on a request matches the session on the ctx.
+//
+// The token is read from the `X-CSRF-Token` header, falling back to the `_csrf` form field.
+func VerifyCSRF(ctx *Ctx) error {
+	if ctx.Session == nil || ctx.Session.CSRFToken == "" {
+		return ex.New(ErrCSRFTokenInvalid)
+	}
+	token, err := ctx.HeaderValue(CSRFHeaderName)
+	if err != nil {
+		if token, err = ctx.FormValue(CSRFFormField); err != nil {
+			return ex.New(ErrCSRFTokenInvalid)
+		}
+	}
+	if subtle.ConstantTimeCompare([]byte(token), []byte(ctx.Session.CSRFToken)) != 1 {
+		return ex.New(ErrCSRFTokenInvalid)
+	}
+	return nil
+}
+
+// IsCSRFSafeMethod returns if a method is exempt from csrf verification.
+func IsCSRFSafeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true
+	default:
+		return false
+	}
+}
+
+// CSRFRequired is an action that requires unsafe requests (i.e. not GET, HEAD, OPTIONS or TRACE)
+// to include a csrf token matching the session.
+//
+// It must be applied inside a session middleware (e.g. `SessionRequired`)
+// so that the session is set on the ctx; requests without a session are not verified.
+func CSRFRequired(action Action) Action {
+	return CSRFMiddleware(nil)(action)
+}
+
+// CSRFMiddleware implements a custom forbidden action for csrf verification failures.
+//
+// If the forbidden action is unset, a 403 status result from the default provider is returned.
+func CSRFMiddleware(forbidden Action) Middleware {
+	return func(action Action) Action {
+		return func(ctx *Ctx) Result {
+			if ctx.Session == nil || IsCSRFSafeMethod(ctx.Request.Method) {
+				return action(ctx)
+			}
+			if err := VerifyCSRF(ctx); err != nil {
+				if forbidden != nil {
+					return forbidden(ctx)
+				}
+				return ctx.DefaultProvider.Status(http.StatusForbidden, nil)
+			}
+			return action(ctx)
+		}
+	}
+}
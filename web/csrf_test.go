@@ -0,0 +1,125 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package web
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/blend/go-sdk/assert"
+	"github.com/blend/go-sdk/ex"
+	"github.com/blend/go-sdk/r2"
+)
+
+func Test_CSRFToken(t *testing.T) {
+	its := assert.New(t)
+
+	auth, err := NewLocalAuthManager()
+	its.Nil(err)
+
+	ctx := MockCtx(http.MethodGet, "/", OptCtxAuth(auth))
+	_, err = CSRFToken(ctx)
+	its.True(ex.Is(err, ErrCSRFSessionUnset))
+
+	ctx.Session = &Session{UserID: "example-string", SessionID: NewSessionID()}
+	token, err := CSRFToken(ctx)
+	its.Nil(err)
+	its.NotEmpty(token)
+
+	persisted, err := auth.FetchHandler(context.TODO(), ctx.Session.SessionID)
+	its.Nil(err)
+	its.Equal(token, persisted.CSRFToken)
+
+	again, err := CSRFToken(ctx)
+	its.Nil(err)
+	its.Equal(token, again)
+
+	field, err := CSRFField(ctx)
+	its.Nil(err)
+	its.Equal(`<input type="hidden" name="_csrf" value="`+token+`">`, string(field))
+}
+
+func Test_CSRFRequired(t *testing.T) {
+	its := assert.New(t)
+
+	app := MustNew(OptAuth(NewLocalAuthManager()))
+	var didExecuteHandler bool
+	app.GET("/", func(_ *Ctx) Result {
+		didExecuteHandler = true
+		return NoContent
+	}, CSRFRequired, SessionRequired)
+	app.POST("/", func(_ *Ctx) Result {
+		didExecuteHandler = true
+		return NoContent
+	}, CSRFRequired, SessionRequired)
+
+	sessionID := NewSessionID()
+	session := NewSession("example-string", sessionID)
+	its.Nil(app.Auth.PersistHandler(context.TODO(), session))
+	cookie := r2.OptCookieValue(app.Auth.CookieDefaults.Name, sessionID)
+
+	meta, err := MockGet(app, "/", cookie).Discard()
+	its.Nil(err)
+	its.Equal(http.StatusNoContent, meta.StatusCode)
+	its.True(didExecuteHandler)
+
+	didExecuteHandler = false
+	meta, err = MockMethod(app, http.MethodPost, "/", cookie).Discard()
+	its.Nil(err)
+	its.Equal(http.StatusForbidden, meta.StatusCode)
+	its.False(didExecuteHandler)
+
+	meta, err = MockMethod(app, http.MethodPost, "/", cookie, r2.OptHeaderValue(CSRFHeaderName, "not-the-token")).Discard()
+	its.Nil(err)
+	its.Equal(http.StatusForbidden, meta.StatusCode)
+	its.False(didExecuteHandler)
+
+	meta, err = MockMethod(app, http.MethodPost, "/", cookie, r2.OptHeaderValue(CSRFHeaderName, session.CSRFToken)).Discard()
+	its.Nil(err)
+	its.Equal(http.StatusNoContent, meta.StatusCode)
+	its.True(didExecuteHandler)
+
+	didExecuteHandler = false
+	meta, err = MockMethod(app, http.MethodPost, "/", cookie, r2.OptPostForm(url.Values{CSRFFormField: []string{session.CSRFToken}})).Discard()
+	its.Nil(err)
+	its.Equal(http.StatusNoContent, meta.StatusCode)
+	its.True(didExecuteHandler)
+}
+
+func Test_CSRFMiddleware_forbidden(t *testing.T) {
+	its := assert.New(t)
+
+	app := MustNew(OptAuth(NewLocalAuthManager()))
+	app.POST("/", ok, CSRFMiddleware(func(_ *Ctx) Result {
+		return Text.Status(http.StatusTeapot, nil)
+	}), SessionRequired)
+
+	opts := MockSimulateLogin(context.TODO(), app, "example-string")
+	meta, err := MockMethod(app, http.MethodPost, "/", opts...).Discard()
+	its.Nil(err)
+	its.Equal(http.StatusTeapot, meta.StatusCode)
+}
+
+func Test_ViewCache_csrfField(t *testing.T) {
+	its := assert.New(t)
+
+	app := MustNew(OptAuth(NewLocalAuthManager()))
+	app.Views.AddLiterals(`{{ define "form" }}<form>{{ csrf_field .Ctx }}</form>{{ end }}`)
+	app.GET("/", func(r *Ctx) Result {
+		return r.Views.View("form", nil)
+	}, SessionRequired)
+
+	opts := MockSimulateLogin(context.TODO(), app, "example-string")
+	contents, meta, err := MockGet(app, "/", opts...).Bytes()
+	its.Nil(err)
+	its.Equal(http.StatusOK, meta.StatusCode)
+	its.True(strings.Contains(string(contents), `<input type="hidden" name="_csrf" value="`), string(contents))
+}
@@ -20,6 +20,7 @@ import (
 	"github.com/blend/go-sdk/ex"
 	"github.com/blend/go-sdk/logger"
 	"github.com/blend/go-sdk/reflectutil"
+	"github.com/blend/go-sdk/validate"
 )
 
 var (
@@ -298,6 +299,36 @@ func (rc *Ctx) PostBodyAsForm(response interface{}) error {
 	}, response)
 }
 
+// Bind fills a struct from the request and validates it.
+//
+// Fields are read from the route params, query string, headers and form using the
+// `route`, `query`, `header` and `form` struct tags respectively, and json request bodies
+// are unmarshaled into the struct before those tags are applied.
+// If the struct implements `Validatable` it is validated, followed by any given validators.
+//
+// Binding and validation failures are returned as a `*BindError` listing every failed field.
+func (rc *Ctx) Bind(obj interface{}, validators ...validate.Validator) error {
+	return bind(rc, obj, validators...)
+}
+
+// BindOrBadRequest binds and validates a struct like `Bind`, returning a bad request
+// result from the default provider if it fails, or nil if it succeeds.
+//
+// The bad request result for binding failures has the `*BindError` as its response.
+func (rc *Ctx) BindOrBadRequest(obj interface{}, validators ...validate.Validator) Result {
+	err := bind(rc, obj, validators...)
+	if err == nil {
+		return nil
+	}
+	if typed, ok := err.(*BindError); ok {
+		return rc.DefaultProvider.Status(http.StatusBadRequest, typed)
+	}
+	if ex.Is(err, ErrBindTarget) {
+		return rc.DefaultProvider.InternalError(err)
+	}
+	return rc.DefaultProvider.BadRequest(err)
+}
+
 // Cookie returns a named cookie from the request.
 func (rc *Ctx) Cookie(name string) *http.Cookie {
 	cookie, err := rc.Request.Cookie(name)
@@ -351,6 +382,15 @@ func (rc *Ctx) ExpireCookie(name string, path string) {
 	http.SetCookie(rc.Response, c)
 }
 
+// UpgradeWebSocket upgrades the request to a websocket connection.
+//
+// The `101 Switching Protocols` response is written and the connection is hijacked,
+// so the action should return a nil result after handling the connection.
+// To have the connection handled and closed for you, return a `WebSocket(...)` result instead.
+func (rc *Ctx) UpgradeWebSocket(options ...WebSocketOption) (*WebSocketConn, error) {
+	return NewWebSocketUpgrader(options...).Upgrade(rc)
+}
+
 // Elapsed is the time delta between start and end.
 func (rc *Ctx) Elapsed() time.Duration {
 	return time.Now().UTC().Sub(rc.RequestStarted)
@@ -16,7 +16,7 @@ func GZip(action Action) Action {
 	return func(r *Ctx) Result {
 		if webutil.HeaderAny(r.Request.Header, webutil.HeaderAcceptEncoding, webutil.ContentEncodingGZIP) {
 			r.Response.Header().Set(webutil.HeaderContentEncoding, webutil.ContentEncodingGZIP)
-			r.Response.Header().Set(webutil.HeaderVary, webutil.HeaderAcceptEncoding)
+			webutil.AddVary(r.Response.Header(), webutil.HeaderAcceptEncoding)
 			r.Response = webutil.NewGZipResponseWriter(r.Response)
 		}
 		return action(r)
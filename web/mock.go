@@ -8,14 +8,19 @@ Use of this source code is governed by a MIT license that can be found in the LI
 package web
 
 import (
+	"bufio"
 	"bytes"
 	"context"
+	"crypto/rand"
+	"encoding/base64"
 	"encoding/json"
 	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 
+	"github.com/blend/go-sdk/ex"
 	"github.com/blend/go-sdk/r2"
 	"github.com/blend/go-sdk/webutil"
 )
@@ -107,6 +112,74 @@ func MockPostJSON(app *App, path string, body interface{}, options ...r2.Option)
 	return Mock(app, req, options...)
 }
 
+// MockWebSocket sends a mock websocket handshake request to an app and returns the client connection.
+//
+// If the handshake fails, the error is returned along with the result so the
+// handshake response can be inspected.
+func MockWebSocket(app *App, path string, options ...r2.Option) (*MockWebSocketResult, error) {
+	mock := MockGet(app, path, options...)
+	if mock.Err != nil {
+		return nil, mock.Err
+	}
+	result := &MockWebSocketResult{Server: mock.Server}
+
+	req := mock.Request.Request
+	if req.Header == nil {
+		req.Header = make(http.Header)
+	}
+	key := make([]byte, 16)
+	_, _ = rand.Read(key)
+	encodedKey := base64.StdEncoding.EncodeToString(key)
+	req.Header.Set(webutil.HeaderConnection, "Upgrade")
+	req.Header.Set(webutil.HeaderUpgrade, WebSocketProtocol)
+	req.Header.Set(webutil.HeaderSecWebSocketVersion, WebSocketVersion)
+	req.Header.Set(webutil.HeaderSecWebSocketKey, encodedKey)
+
+	conn, err := net.Dial("tcp", req.URL.Host)
+	if err != nil {
+		_ = mock.Close()
+		return nil, ex.New(err)
+	}
+	if err = req.Write(conn); err != nil {
+		_ = conn.Close()
+		_ = mock.Close()
+		return nil, ex.New(err)
+	}
+	reader := bufio.NewReader(conn)
+	result.Response, err = http.ReadResponse(reader, req)
+	if err != nil {
+		_ = conn.Close()
+		_ = mock.Close()
+		return nil, ex.New(err)
+	}
+	if result.Response.StatusCode != http.StatusSwitchingProtocols ||
+		result.Response.Header.Get(webutil.HeaderSecWebSocketAccept) != WebSocketAccept(encodedKey) {
+		_ = conn.Close()
+		_ = mock.Close()
+		return result, ex.New(ErrWebSocketHandshake, ex.OptMessagef("status code: %d", result.Response.StatusCode))
+	}
+	result.WebSocketConn = NewWebSocketConn(conn, reader, false)
+	result.WebSocketConn.Subprotocol = result.Response.Header.Get(webutil.HeaderSecWebSocketProtocol)
+	return result, nil
+}
+
+// MockWebSocketResult is the client side of a mocked websocket connection.
+type MockWebSocketResult struct {
+	*WebSocketConn
+	Response *http.Response
+	Server   *httptest.Server
+}
+
+// Close closes the connection and stops the app.
+func (mwr *MockWebSocketResult) Close() error {
+	var err error
+	if mwr.WebSocketConn != nil {
+		err = mwr.WebSocketConn.Close()
+	}
+	mwr.Server.Close()
+	return err
+}
+
 // MockResult is a result of a mocked request.
 type MockResult struct {
 	*r2.Request
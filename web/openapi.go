@@ -0,0 +1,233 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package web
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	// OpenAPIVersion is the version of the OpenAPI specification documents are generated for.
+	OpenAPIVersion = "3.0.3"
+	// OpenAPIContentTypeJSON is the media type used for request and response bodies.
+	OpenAPIContentTypeJSON = "application/json"
+)
+
+// OpenAPIDocument is an OpenAPI 3 document.
+type OpenAPIDocument struct {
+	OpenAPI    string                     `json:"openapi"`
+	Info       OpenAPIInfo                `json:"info"`
+	Servers    []OpenAPIServer            `json:"servers,omitempty"`
+	Tags       []OpenAPITag               `json:"tags,omitempty"`
+	Paths      map[string]OpenAPIPathItem `json:"paths"`
+	Components *OpenAPIComponents         `json:"components,omitempty"`
+}
+
+// OpenAPIInfo is the document metadata.
+type OpenAPIInfo struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+// OpenAPIServer is a server the api is available at.
+type OpenAPIServer struct {
+	URL         string `json:"url"`
+	Description string `json:"description,omitempty"`
+}
+
+// OpenAPITag is a tag used to group operations.
+type OpenAPITag struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+// OpenAPIPathItem are the operations for a path keyed by lower case method.
+type OpenAPIPathItem map[string]*OpenAPIOperation
+
+// OpenAPIOperation is a single api operation.
+type OpenAPIOperation struct {
+	Tags        []string                   `json:"tags,omitempty"`
+	Summary     string                     `json:"summary,omitempty"`
+	Description string                     `json:"description,omitempty"`
+	OperationID string                     `json:"operationId,omitempty"`
+	Parameters  []OpenAPIParameter         `json:"parameters,omitempty"`
+	RequestBody *OpenAPIRequestBody        `json:"requestBody,omitempty"`
+	Responses   map[string]OpenAPIResponse `json:"responses"`
+	Deprecated  bool                       `json:"deprecated,omitempty"`
+}
+
+// OpenAPIParameter is an operation parameter.
+type OpenAPIParameter struct {
+	Name        string         `json:"name"`
+	In          string         `json:"in"`
+	Description string         `json:"description,omitempty"`
+	Required    bool           `json:"required,omitempty"`
+	Schema      *OpenAPISchema `json:"schema,omitempty"`
+}
+
+// OpenAPIRequestBody is an operation request body.
+type OpenAPIRequestBody struct {
+	Description string                      `json:"description,omitempty"`
+	Required    bool                        `json:"required,omitempty"`
+	Content     map[string]OpenAPIMediaType `json:"content"`
+}
+
+// OpenAPIResponse is an operation response.
+type OpenAPIResponse struct {
+	Description string                      `json:"description"`
+	Content     map[string]OpenAPIMediaType `json:"content,omitempty"`
+}
+
+// OpenAPIMediaType is the schema for a given content type.
+type OpenAPIMediaType struct {
+	Schema *OpenAPISchema `json:"schema,omitempty"`
+}
+
+// OpenAPIComponents are reusable document components.
+type OpenAPIComponents struct {
+	Schemas map[string]*OpenAPISchema `json:"schemas,omitempty"`
+}
+
+// OpenAPI returns an OpenAPI 3 document describing the routes registered on the app.
+//
+// Every registered route is included; routes annotated with `Describe` include
+// their annotations, and path parameters are always described.
+func (a *App) OpenAPI(info OpenAPIInfo) *OpenAPIDocument {
+	doc := &OpenAPIDocument{
+		OpenAPI: OpenAPIVersion,
+		Info:    info,
+		Paths:   make(map[string]OpenAPIPathItem),
+	}
+	if baseURL := a.Config.BaseURLOrDefault(); baseURL != "" {
+		doc.Servers = append(doc.Servers, OpenAPIServer{URL: baseURL})
+	}
+
+	generator := newOpenAPISchemaGenerator()
+	seenTags := make(map[string]bool)
+	for _, route := range a.RouteTree.AllRoutes() {
+		if route.Method == http.MethodOptions || route.Path == a.openAPIRoute {
+			continue
+		}
+		var spec RouteSpec
+		if described, ok := a.RouteSpecs[route.StringWithMethod()]; ok {
+			spec = *described
+		}
+		path := OpenAPIPath(route.Path)
+		if doc.Paths[path] == nil {
+			doc.Paths[path] = make(OpenAPIPathItem)
+		}
+		doc.Paths[path][strings.ToLower(route.Method)] = generator.operation(route, spec)
+		for _, tag := range spec.Tags {
+			if !seenTags[tag] {
+				seenTags[tag] = true
+				doc.Tags = append(doc.Tags, OpenAPITag{Name: tag})
+			}
+		}
+	}
+	if len(generator.components) > 0 {
+		doc.Components = &OpenAPIComponents{Schemas: generator.components}
+	}
+	return doc
+}
+
+// ServeOpenAPI serves the OpenAPI 3 document for the app as json at a given route.
+//
+// The document is generated on each request, and includes any routes registered after this call.
+func (a *App) ServeOpenAPI(route string, info OpenAPIInfo, middleware ...Middleware) {
+	a.openAPIRoute = route
+	a.GET(route, func(_ *Ctx) Result {
+		return JSON.Result(a.OpenAPI(info))
+	}, middleware...)
+}
+
+// OpenAPIPath converts a route path to an OpenAPI path template,
+// i.e. `/users/:id` becomes `/users/{id}`.
+func OpenAPIPath(path string) string {
+	segments := strings.Split(path, "/")
+	for index, segment := range segments {
+		if len(segment) > 1 && (segment[0] == ':' || segment[0] == '*') {
+			segments[index] = "{" + segment[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+func openAPIPathParams(path string) (output []string) {
+	for _, segment := range strings.Split(path, "/") {
+		if len(segment) > 1 && (segment[0] == ':' || segment[0] == '*') {
+			output = append(output, segment[1:])
+		}
+	}
+	return
+}
+
+func (g *openAPISchemaGenerator) operation(route *Route, spec RouteSpec) *OpenAPIOperation {
+	op := &OpenAPIOperation{
+		Tags:        spec.Tags,
+		Summary:     spec.Summary,
+		Description: spec.Description,
+		OperationID: spec.OperationID,
+		Deprecated:  spec.Deprecated,
+		Responses:   make(map[string]OpenAPIResponse),
+	}
+
+	described := make(map[string]bool)
+	for _, parameter := range spec.Parameters {
+		described[parameter.In+"."+parameter.Name] = true
+		schema := &OpenAPISchema{Type: "string"}
+		if parameter.Type != nil {
+			schema = g.schemaFor(parameter.Type)
+		}
+		op.Parameters = append(op.Parameters, OpenAPIParameter{
+			Name:        parameter.Name,
+			In:          parameter.In,
+			Description: parameter.Description,
+			Required:    parameter.Required || parameter.In == RouteSpecParameterInPath,
+			Schema:      schema,
+		})
+	}
+	for _, param := range openAPIPathParams(route.Path) {
+		if !described[RouteSpecParameterInPath+"."+param] {
+			op.Parameters = append(op.Parameters, OpenAPIParameter{
+				Name:     param,
+				In:       RouteSpecParameterInPath,
+				Required: true,
+				Schema:   &OpenAPISchema{Type: "string"},
+			})
+		}
+	}
+
+	if spec.Request != nil {
+		op.RequestBody = &OpenAPIRequestBody{
+			Required: true,
+			Content: map[string]OpenAPIMediaType{
+				OpenAPIContentTypeJSON: {Schema: g.schemaFor(spec.Request)},
+			},
+		}
+	}
+	for _, response := range spec.Responses {
+		description := response.Description
+		if description == "" {
+			description = http.StatusText(response.StatusCode)
+		}
+		res := OpenAPIResponse{Description: description}
+		if response.Type != nil {
+			res.Content = map[string]OpenAPIMediaType{
+				OpenAPIContentTypeJSON: {Schema: g.schemaFor(response.Type)},
+			}
+		}
+		op.Responses[strconv.Itoa(response.StatusCode)] = res
+	}
+	if len(op.Responses) == 0 {
+		op.Responses["default"] = OpenAPIResponse{Description: "Default response"}
+	}
+	return op
+}
@@ -0,0 +1,187 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package web
+
+import (
+	"encoding"
+	"encoding/json"
+	"path"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// OpenAPISchema is an OpenAPI 3 schema object.
+type OpenAPISchema struct {
+	Ref                  string                    `json:"$ref,omitempty"`
+	Type                 string                    `json:"type,omitempty"`
+	Format               string                    `json:"format,omitempty"`
+	Description          string                    `json:"description,omitempty"`
+	Nullable             bool                      `json:"nullable,omitempty"`
+	Items                *OpenAPISchema            `json:"items,omitempty"`
+	Properties           map[string]*OpenAPISchema `json:"properties,omitempty"`
+	AdditionalProperties *OpenAPISchema            `json:"additionalProperties,omitempty"`
+	Required             []string                  `json:"required,omitempty"`
+}
+
+var (
+	openAPITypeTime            = reflect.TypeOf(time.Time{})
+	openAPITypeDuration        = reflect.TypeOf(time.Duration(0))
+	openAPITypeRawMessage      = reflect.TypeOf(json.RawMessage(nil))
+	openAPITypeJSONMarshaler   = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	openAPITypeTextMarshaler   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	openAPIFieldTagDescription = "description"
+)
+
+func newOpenAPISchemaGenerator() *openAPISchemaGenerator {
+	return &openAPISchemaGenerator{
+		components: make(map[string]*OpenAPISchema),
+		names:      make(map[reflect.Type]string),
+	}
+}
+
+// openAPISchemaGenerator reflects go types into schemas, collecting named structs as components.
+type openAPISchemaGenerator struct {
+	components map[string]*OpenAPISchema
+	names      map[reflect.Type]string
+}
+
+func (g *openAPISchemaGenerator) schemaFor(value interface{}) *OpenAPISchema {
+	if typed, ok := value.(reflect.Type); ok {
+		return g.schema(typed)
+	}
+	return g.schema(reflect.TypeOf(value))
+}
+
+func (g *openAPISchemaGenerator) schema(t reflect.Type) *OpenAPISchema {
+	if t == nil {
+		return &OpenAPISchema{}
+	}
+	if t.Kind() == reflect.Ptr {
+		schema := g.schema(t.Elem())
+		if schema.Ref != "" {
+			return schema
+		}
+		schema.Nullable = true
+		return schema
+	}
+
+	switch t {
+	case openAPITypeTime:
+		return &OpenAPISchema{Type: "string", Format: "date-time"}
+	case openAPITypeDuration:
+		return &OpenAPISchema{Type: "integer", Format: "int64"}
+	case openAPITypeRawMessage:
+		return &OpenAPISchema{}
+	}
+	if t.Implements(openAPITypeTextMarshaler) || reflect.PtrTo(t).Implements(openAPITypeTextMarshaler) {
+		return &OpenAPISchema{Type: "string"}
+	}
+	if t.Implements(openAPITypeJSONMarshaler) || reflect.PtrTo(t).Implements(openAPITypeJSONMarshaler) {
+		return &OpenAPISchema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &OpenAPISchema{Type: "boolean"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16:
+		return &OpenAPISchema{Type: "integer", Format: "int32"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint32, reflect.Uint64:
+		return &OpenAPISchema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &OpenAPISchema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &OpenAPISchema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &OpenAPISchema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &OpenAPISchema{Type: "string", Format: "byte"}
+		}
+		return &OpenAPISchema{Type: "array", Items: g.schema(t.Elem())}
+	case reflect.Map:
+		return &OpenAPISchema{Type: "object", AdditionalProperties: g.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.structSchema(t)
+		}
+		return g.ref(t)
+	default:
+		return &OpenAPISchema{}
+	}
+}
+
+// ref returns a reference to the component schema for a named struct type,
+// generating the component if it has not been seen yet.
+func (g *openAPISchemaGenerator) ref(t reflect.Type) *OpenAPISchema {
+	name, ok := g.names[t]
+	if !ok {
+		name = t.Name()
+		if _, taken := g.components[name]; taken {
+			pkg := path.Base(t.PkgPath())
+			name = strings.ToUpper(pkg[:1]) + pkg[1:] + name
+		}
+		g.names[t] = name
+		// register a placeholder first so recursive types terminate.
+		g.components[name] = &OpenAPISchema{}
+		*g.components[name] = *g.structSchema(t)
+	}
+	return &OpenAPISchema{Ref: "#/components/schemas/" + name}
+}
+
+func (g *openAPISchemaGenerator) structSchema(t reflect.Type) *OpenAPISchema {
+	schema := &OpenAPISchema{Type: "object", Properties: make(map[string]*OpenAPISchema)}
+	g.addFields(schema, t)
+	return schema
+}
+
+func (g *openAPISchemaGenerator) addFields(schema *OpenAPISchema, t reflect.Type) {
+	for index := 0; index < t.NumField(); index++ {
+		field := t.Field(index)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, tagOptions := tag, ""
+		if comma := strings.IndexRune(tag, ','); comma >= 0 {
+			name, tagOptions = tag[:comma], tag[comma+1:]
+		}
+
+		if field.Anonymous && name == "" {
+			fieldType := field.Type
+			if fieldType.Kind() == reflect.Ptr {
+				fieldType = fieldType.Elem()
+			}
+			if fieldType.Kind() == reflect.Struct {
+				g.addFields(schema, fieldType)
+				continue
+			}
+		}
+		if field.PkgPath != "" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		var property *OpenAPISchema
+		if strings.Contains(tagOptions, "string") {
+			property = &OpenAPISchema{Type: "string"}
+		} else {
+			property = g.schema(field.Type)
+		}
+		// sibling keywords of a reference are ignored, so descriptions are only set on inline schemas.
+		if description := field.Tag.Get(openAPIFieldTagDescription); description != "" && property.Ref == "" {
+			property.Description = description
+		}
+		schema.Properties[name] = property
+		if !strings.Contains(tagOptions, "omitempty") && field.Type.Kind() != reflect.Ptr {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package web
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/blend/go-sdk/assert"
+)
+
+type openAPITestAddress struct {
+	Street string `json:"street"`
+}
+
+type openAPITestUser struct {
+	ID        string              `json:"id" description:"The user id."`
+	Email     string              `json:"email"`
+	Age       int                 `json:"age,omitempty"`
+	Score     float64             `json:"score,omitempty"`
+	Admin     bool                `json:"admin"`
+	CreatedAt time.Time           `json:"createdAt"`
+	Address   *openAPITestAddress `json:"address,omitempty"`
+	Tags      []string            `json:"tags,omitempty"`
+	Labels    map[string]string   `json:"labels,omitempty"`
+	Friends   []openAPITestUser   `json:"friends,omitempty"`
+	Secret    string              `json:"-"`
+	internal  string
+}
+
+func Test_OpenAPIPath(t *testing.T) {
+	its := assert.New(t)
+
+	its.Equal("/", OpenAPIPath("/"))
+	its.Equal("/users/{id}", OpenAPIPath("/users/:id"))
+	its.Equal("/users/{id}/posts/{postID}", OpenAPIPath("/users/:id/posts/:postID"))
+	its.Equal("/static/{filepath}", OpenAPIPath("/static/*filepath"))
+}
+
+func Test_App_OpenAPI(t *testing.T) {
+	its := assert.New(t)
+
+	app := MustNew(OptBaseURL("https://api.example.com"))
+	app.GET("/users/:id", ok)
+	app.POST("/users", ok)
+	app.GET("/status", ok)
+	app.Describe(http.MethodGet, "/users/:id",
+		OptRouteSummary("Get a user"),
+		OptRouteTags("users"),
+		OptRoutePathParameter("id", "The user id."),
+		OptRouteQueryParameter("expand", "Fields to expand.", false),
+		OptRouteResponse(http.StatusOK, "", openAPITestUser{}),
+		OptRouteResponse(http.StatusNotFound, "The user was not found.", nil),
+	)
+	app.Describe(http.MethodPost, "/users",
+		OptRouteTags("users"),
+		OptRouteOperationID("createUser"),
+		OptRouteDeprecated(),
+		OptRouteRequest(&openAPITestUser{}),
+		OptRouteResponse(http.StatusCreated, "", openAPITestUser{}),
+	)
+
+	doc := app.OpenAPI(OpenAPIInfo{Title: "Test", Version: "1.0.0"})
+	its.Equal(OpenAPIVersion, doc.OpenAPI)
+	its.Equal("Test", doc.Info.Title)
+	its.Len(doc.Servers, 1)
+	its.Equal("https://api.example.com", doc.Servers[0].URL)
+	its.Len(doc.Tags, 1)
+	its.Len(doc.Paths, 3)
+
+	getUser := doc.Paths["/users/{id}"]["get"]
+	its.NotNil(getUser)
+	its.Equal("Get a user", getUser.Summary)
+	its.Equal([]string{"users"}, getUser.Tags)
+	its.Len(getUser.Parameters, 2)
+	its.Equal("id", getUser.Parameters[0].Name)
+	its.Equal("path", getUser.Parameters[0].In)
+	its.True(getUser.Parameters[0].Required)
+	its.Equal("expand", getUser.Parameters[1].Name)
+	its.False(getUser.Parameters[1].Required)
+	its.Equal("OK", getUser.Responses["200"].Description)
+	its.Equal("#/components/schemas/openAPITestUser", getUser.Responses["200"].Content[OpenAPIContentTypeJSON].Schema.Ref)
+	its.Equal("The user was not found.", getUser.Responses["404"].Description)
+	its.Empty(getUser.Responses["404"].Content)
+
+	createUser := doc.Paths["/users"]["post"]
+	its.NotNil(createUser)
+	its.Equal("createUser", createUser.OperationID)
+	its.True(createUser.Deprecated)
+	its.NotNil(createUser.RequestBody)
+	its.Equal("#/components/schemas/openAPITestUser", createUser.RequestBody.Content[OpenAPIContentTypeJSON].Schema.Ref)
+
+	status := doc.Paths["/status"]["get"]
+	its.NotNil(status)
+	its.Equal("Default response", status.Responses["default"].Description)
+
+	its.NotNil(doc.Components)
+	user := doc.Components.Schemas["openAPITestUser"]
+	its.NotNil(user)
+	its.Equal("object", user.Type)
+	its.Equal([]string{"id", "email", "admin", "createdAt"}, user.Required)
+	its.Equal("string", user.Properties["id"].Type)
+	its.Equal("The user id.", user.Properties["id"].Description)
+	its.Equal("integer", user.Properties["age"].Type)
+	its.Equal("double", user.Properties["score"].Format)
+	its.Equal("boolean", user.Properties["admin"].Type)
+	its.Equal("date-time", user.Properties["createdAt"].Format)
+	its.Equal("#/components/schemas/openAPITestAddress", user.Properties["address"].Ref)
+	its.Equal("array", user.Properties["tags"].Type)
+	its.Equal("string", user.Properties["tags"].Items.Type)
+	its.Equal("string", user.Properties["labels"].AdditionalProperties.Type)
+	its.Equal("#/components/schemas/openAPITestUser", user.Properties["friends"].Items.Ref)
+	its.Nil(user.Properties["Secret"])
+	its.Nil(user.Properties["internal"])
+	its.NotNil(doc.Components.Schemas["openAPITestAddress"])
+}
+
+func Test_App_ServeOpenAPI(t *testing.T) {
+	its := assert.New(t)
+
+	app := MustNew()
+	app.ServeOpenAPI("/openapi.json", OpenAPIInfo{Title: "Test", Version: "1.0.0"})
+	app.GET("/users/:id", ok)
+
+	var doc OpenAPIDocument
+	meta, err := MockGet(app, "/openapi.json").JSON(&doc)
+	its.Nil(err)
+	its.Equal(http.StatusOK, meta.StatusCode)
+	its.Equal("Test", doc.Info.Title)
+	its.Len(doc.Paths, 1)
+	its.NotNil(doc.Paths["/users/{id}"]["get"])
+
+	// components are omitted when there are no schemas.
+	contents, _, err := MockGet(app, "/openapi.json").Bytes()
+	its.Nil(err)
+	its.NotContains(string(contents), "components")
+}
@@ -197,6 +197,30 @@ func OptBaseStateValue(key string, value interface{}) Option {
 	}
 }
 
+// OptCORS sets the app level cross-origin resource sharing policy.
+//
+// The policy is applied to every route, and preflight requests are answered
+// automatically for any registered route that does not have an explicit `OPTIONS` route.
+func OptCORS(policy CORSPolicy) Option {
+	return func(a *App) error {
+		a.corsEnable()
+		a.CORSPolicy = &policy
+		return nil
+	}
+}
+
+// OptCORSRoute sets a cross-origin resource sharing policy for a given route path.
+//
+// The route path should match the path the route is registered with, e.g. `/users/:id`.
+// It overrides the app level policy set with `OptCORS` for that route.
+func OptCORSRoute(route string, policy CORSPolicy) Option {
+	return func(a *App) error {
+		a.corsEnable()
+		a.CORSRoutePolicies[route] = policy
+		return nil
+	}
+}
+
 // OptMethodNotAllowedHandler sets default headers.
 func OptMethodNotAllowedHandler(action Action) Option {
 	return func(a *App) error {
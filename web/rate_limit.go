@@ -0,0 +1,150 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package web
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/blend/go-sdk/logger"
+	"github.com/blend/go-sdk/ratelimiter"
+	"github.com/blend/go-sdk/webutil"
+)
+
+// RateLimitKeyFunc returns the key requests are limited by.
+//
+// If the key is empty the request is not limited.
+type RateLimitKeyFunc func(*Ctx) string
+
+// RateLimitKeyRemoteAddr limits requests by the remote address of the client.
+func RateLimitKeyRemoteAddr(r *Ctx) string {
+	return webutil.GetRemoteAddr(r.Request)
+}
+
+// RateLimitKeySessionUser limits requests by the user id of the session.
+//
+// Requests without a session are not limited; the session middleware must run before the rate limit middleware.
+func RateLimitKeySessionUser(r *Ctx) string {
+	if r.Session == nil {
+		return ""
+	}
+	return r.Session.UserID
+}
+
+// RateLimitKeyHeader limits requests by the value of a given request header, e.g. an api key.
+func RateLimitKeyHeader(name string) RateLimitKeyFunc {
+	return func(r *Ctx) string {
+		return r.Request.Header.Get(name)
+	}
+}
+
+// RateLimitOptions are options for the rate limit middleware.
+type RateLimitOptions struct {
+	// Key returns the key requests are limited by.
+	Key RateLimitKeyFunc
+	// Prefix is prepended to keys, and distinguishes limits that share a store.
+	Prefix string
+	// Store holds the counters for the default fixed window limiter.
+	Store ratelimiter.Store
+	// Limiter, if set, is used instead of a fixed window limiter on the store.
+	Limiter ratelimiter.Limiter
+	// Limited is the action called when a request exceeds the limit.
+	Limited Action
+}
+
+// RateLimitOption mutates rate limit options.
+type RateLimitOption func(*RateLimitOptions)
+
+// OptRateLimitKey sets the function that returns the key requests are limited by.
+func OptRateLimitKey(key RateLimitKeyFunc) RateLimitOption {
+	return func(rlo *RateLimitOptions) { rlo.Key = key }
+}
+
+// OptRateLimitPrefix sets the prefix prepended to keys.
+//
+// Middleware that share a store (e.g. on different routes) should use different prefixes
+// unless they are meant to share limits.
+func OptRateLimitPrefix(prefix string) RateLimitOption {
+	return func(rlo *RateLimitOptions) { rlo.Prefix = prefix }
+}
+
+// OptRateLimitStore sets the store that holds counters, e.g. a redis store to share limits across processes.
+func OptRateLimitStore(store ratelimiter.Store) RateLimitOption {
+	return func(rlo *RateLimitOptions) { rlo.Store = store }
+}
+
+// OptRateLimitLimiter sets the limiter, replacing the default fixed window limiter.
+func OptRateLimitLimiter(limiter ratelimiter.Limiter) RateLimitOption {
+	return func(rlo *RateLimitOptions) { rlo.Limiter = limiter }
+}
+
+// OptRateLimitLimited sets the action called when a request exceeds the limit.
+func OptRateLimitLimited(action Action) RateLimitOption {
+	return func(rlo *RateLimitOptions) { rlo.Limited = action }
+}
+
+// RateLimit returns a middleware that limits requests to a number of actions per quantum.
+//
+// Requests are limited by remote address by default, and counters are held in memory
+// unless a store is provided with `OptRateLimitStore`. Responses include the `RateLimit-Limit`,
+// `RateLimit-Remaining` and `RateLimit-Reset` headers, and requests that exceed the limit
+// are rejected with a 429 and a `Retry-After` header.
+//
+// The middleware can be applied to the whole app with `OptUse`, or to specific routes or groups
+// to give them their own limits. If the limiter returns an error the request is allowed.
+func RateLimit(numberOfActions int, quantum time.Duration, options ...RateLimitOption) Middleware {
+	rlo := RateLimitOptions{
+		Key: RateLimitKeyRemoteAddr,
+	}
+	for _, option := range options {
+		option(&rlo)
+	}
+	if rlo.Limiter == nil {
+		rlo.Limiter = ratelimiter.NewWindow(numberOfActions, quantum, rlo.Store)
+	}
+
+	return func(action Action) Action {
+		return func(r *Ctx) Result {
+			key := rlo.Key(r)
+			if key == "" {
+				return action(r)
+			}
+			limit, err := rlo.Limiter.Take(r.Context(), rlo.Prefix+key)
+			if err != nil {
+				logger.MaybeErrorContext(r.Context(), r.Log, err)
+				return action(r)
+			}
+
+			reset := strconv.FormatInt(rateLimitSeconds(limit.Reset), 10)
+			header := r.Response.Header()
+			header.Set(webutil.HeaderRateLimitLimit, strconv.Itoa(limit.Limit))
+			if limit.Remaining >= 0 {
+				header.Set(webutil.HeaderRateLimitRemaining, strconv.Itoa(limit.Remaining))
+			}
+			header.Set(webutil.HeaderRateLimitReset, reset)
+			if !limit.Exceeded {
+				return action(r)
+			}
+			header.Set(webutil.HeaderRetryAfter, reset)
+			if rlo.Limited != nil {
+				return rlo.Limited(r)
+			}
+			return r.DefaultProvider.Status(http.StatusTooManyRequests, nil)
+		}
+	}
+}
+
+// rateLimitSeconds returns a duration in whole seconds, rounded up.
+func rateLimitSeconds(d time.Duration) int64 {
+	seconds := int64(d / time.Second)
+	if d%time.Second > 0 {
+		seconds++
+	}
+	return seconds
+}
@@ -0,0 +1,125 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package web
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/blend/go-sdk/assert"
+	"github.com/blend/go-sdk/r2"
+	"github.com/blend/go-sdk/ratelimiter"
+	"github.com/blend/go-sdk/webutil"
+)
+
+func Test_RateLimit(t *testing.T) {
+	its := assert.New(t)
+
+	app := MustNew()
+	app.GET("/limited", ok, RateLimit(2, time.Minute))
+	app.GET("/unlimited", ok)
+
+	for x := 0; x < 2; x++ {
+		res, err := MockGet(app, "/limited").Discard()
+		its.Nil(err)
+		its.Equal(http.StatusOK, res.StatusCode)
+		its.Equal("2", res.Header.Get(webutil.HeaderRateLimitLimit))
+		its.Equal(fmt.Sprint(1-x), res.Header.Get(webutil.HeaderRateLimitRemaining))
+		its.Equal("60", res.Header.Get(webutil.HeaderRateLimitReset))
+		its.Empty(res.Header.Get(webutil.HeaderRetryAfter))
+	}
+
+	body, res, err := MockGet(app, "/limited").Bytes()
+	its.Nil(err)
+	its.Equal(http.StatusTooManyRequests, res.StatusCode)
+	its.Equal("0", res.Header.Get(webutil.HeaderRateLimitRemaining))
+	its.NotEmpty(res.Header.Get(webutil.HeaderRetryAfter))
+	its.Contains(string(body), http.StatusText(http.StatusTooManyRequests))
+
+	res, err = MockGet(app, "/limited", r2.OptHeaderValue(webutil.HeaderXForwardedFor, "10.0.0.1")).Discard()
+	its.Nil(err)
+	its.Equal(http.StatusOK, res.StatusCode, "other clients should have their own limit")
+
+	res, err = MockGet(app, "/unlimited").Discard()
+	its.Nil(err)
+	its.Equal(http.StatusOK, res.StatusCode)
+	its.Empty(res.Header.Get(webutil.HeaderRateLimitLimit))
+}
+
+func Test_RateLimit_sharedStore(t *testing.T) {
+	its := assert.New(t)
+
+	store := ratelimiter.NewMemoryStore()
+	limited := func(r *Ctx) Result {
+		return JSON.Status(http.StatusTooManyRequests, "slow down")
+	}
+	app := MustNew()
+	app.GET("/a", ok, RateLimit(1, time.Minute, OptRateLimitStore(store), OptRateLimitKey(RateLimitKeyHeader("X-API-Key")), OptRateLimitLimited(limited)))
+	app.GET("/b", ok, RateLimit(1, time.Minute, OptRateLimitStore(store), OptRateLimitKey(RateLimitKeyHeader("X-API-Key")), OptRateLimitPrefix("b:")))
+
+	res, err := MockGet(app, "/a", r2.OptHeaderValue("X-API-Key", "key")).Discard()
+	its.Nil(err)
+	its.Equal(http.StatusOK, res.StatusCode)
+
+	var message string
+	res, err = MockGet(app, "/a", r2.OptHeaderValue("X-API-Key", "key")).JSON(&message)
+	its.Nil(err)
+	its.Equal(http.StatusTooManyRequests, res.StatusCode)
+	its.Equal("slow down", message)
+
+	res, err = MockGet(app, "/b", r2.OptHeaderValue("X-API-Key", "key")).Discard()
+	its.Nil(err)
+	its.Equal(http.StatusOK, res.StatusCode, "routes with different prefixes should have their own limits")
+
+	res, err = MockGet(app, "/a").Discard()
+	its.Nil(err)
+	its.Equal(http.StatusOK, res.StatusCode, "requests without a key should not be limited")
+	its.Empty(res.Header.Get(webutil.HeaderRateLimitLimit))
+	its.Len(store.Counters, 2)
+}
+
+type rateLimitTestLimiter struct {
+	Limit ratelimiter.Limit
+	Err   error
+}
+
+func (rltl rateLimitTestLimiter) Take(context.Context, string) (ratelimiter.Limit, error) {
+	return rltl.Limit, rltl.Err
+}
+
+func Test_RateLimit_limiter(t *testing.T) {
+	its := assert.New(t)
+
+	app := MustNew()
+	app.GET("/unknown", ok, RateLimit(0, 0, OptRateLimitLimiter(rateLimitTestLimiter{
+		Limit: ratelimiter.Limit{Limit: 5, Remaining: -1, Reset: 1500 * time.Millisecond, Exceeded: true},
+	})))
+	app.GET("/error", ok, RateLimit(0, 0, OptRateLimitLimiter(rateLimitTestLimiter{
+		Err: fmt.Errorf("this is only a test"),
+	})))
+
+	res, err := MockGet(app, "/unknown").Discard()
+	its.Nil(err)
+	its.Equal(http.StatusTooManyRequests, res.StatusCode)
+	its.Empty(res.Header.Get(webutil.HeaderRateLimitRemaining))
+	its.Equal("2", res.Header.Get(webutil.HeaderRetryAfter))
+
+	res, err = MockGet(app, "/error").Discard()
+	its.Nil(err)
+	its.Equal(http.StatusOK, res.StatusCode, "limiter errors should allow the request")
+}
+
+func Test_RateLimitKeySessionUser(t *testing.T) {
+	its := assert.New(t)
+
+	its.Empty(RateLimitKeySessionUser(MockCtx(http.MethodGet, "/")))
+	its.Equal("user", RateLimitKeySessionUser(MockCtx(http.MethodGet, "/", OptCtxSession(&Session{UserID: "user"}))))
+}
@@ -0,0 +1,18 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+/*
+Package redissession implements a session store for `web.AuthManager` backed by redis.
+
+Sessions are stored as json with a ttl derived from the session expiry, and each user's session ids
+are indexed so that all of a user's sessions can be listed or revoked.
+
+	store := redissession.New(client, redissession.OptSlidingExpiration(30*time.Minute))
+	authManager := web.MustNewAuthManager()
+	store.Apply(&authManager)
+*/
+package redissession // import "github.com/blend/go-sdk/web/redissession"
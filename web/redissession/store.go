@@ -0,0 +1,228 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package redissession
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/blend/go-sdk/ex"
+	"github.com/blend/go-sdk/redis"
+	"github.com/blend/go-sdk/web"
+)
+
+// Defaults
+const (
+	DefaultPrefix = "session:"
+)
+
+// PersistScript stores a session and adds its id to the user's index.
+//
+// The index expires with the longest lived session, and never expires if a session does not.
+//
+// KEYS: session key, user index key
+// ARGV: session json, ttl in milliseconds (0 for no expiry), session id
+const PersistScript = `local ttl = tonumber(ARGV[2])
+if ttl > 0 then
+	redis.call("SET", KEYS[1], ARGV[1], "PX", ttl)
+else
+	redis.call("SET", KEYS[1], ARGV[1])
+end
+redis.call("SADD", KEYS[2], ARGV[3])
+local indexTTL = redis.call("PTTL", KEYS[2])
+if ttl <= 0 then
+	redis.call("PERSIST", KEYS[2])
+elseif redis.call("SCARD", KEYS[2]) == 1 or (indexTTL >= 0 and indexTTL < ttl) then
+	redis.call("PEXPIRE", KEYS[2], ttl)
+end
+return 1`
+
+// New returns a new redis session store.
+func New(client redis.Client, options ...Option) *Store {
+	s := &Store{
+		Client: client,
+		Prefix: DefaultPrefix,
+		Now:    func() time.Time { return time.Now().UTC() },
+	}
+	for _, opt := range options {
+		opt(s)
+	}
+	return s
+}
+
+// Option mutates a store.
+type Option func(*Store)
+
+// OptPrefix sets the key prefix for sessions and user indexes.
+func OptPrefix(prefix string) Option {
+	return func(s *Store) { s.Prefix = prefix }
+}
+
+// OptSlidingExpiration sets the sliding expiration.
+//
+// When set, `Apply` sets the session timeout provider of the auth manager such that
+// sessions expire after a period of inactivity, and `VerifyOrExtendSession` extends both
+// the session and its ttl in redis.
+func OptSlidingExpiration(timeout time.Duration) Option {
+	return func(s *Store) { s.SlidingExpiration = timeout }
+}
+
+// Store is a session store backed by redis.
+type Store struct {
+	Client redis.Client
+	// Prefix is prepended to every key the store writes.
+	Prefix string
+	// SlidingExpiration is the inactivity timeout for sessions, if set.
+	SlidingExpiration time.Duration
+	// Now returns the current time.
+	Now func() time.Time
+}
+
+// Apply applies the store to a given auth manager.
+func (s *Store) Apply(am *web.AuthManager) {
+	am.FetchHandler = s.FetchHandler
+	am.PersistHandler = s.PersistHandler
+	am.RemoveHandler = s.RemoveHandler
+	if s.SlidingExpiration > 0 {
+		am.SessionTimeoutProvider = s.SessionTimeoutProvider
+	}
+}
+
+// SessionTimeoutProvider returns a new expiry for a session from the sliding expiration.
+//
+// It can be used as an auth manager session timeout provider.
+func (s *Store) SessionTimeoutProvider(_ *web.Session) time.Time {
+	return s.Now().Add(s.SlidingExpiration)
+}
+
+// FetchHandler is a shim to interface with the auth manager.
+//
+// It returns a nil session if the session does not exist or has expired.
+func (s *Store) FetchHandler(ctx context.Context, sessionID string) (*web.Session, error) {
+	var contents string
+	if err := s.Client.Do(ctx, &contents, redis.OpGET, s.sessionKey(sessionID)); err != nil {
+		return nil, err
+	}
+	if contents == "" {
+		return nil, nil
+	}
+	return s.decode(contents)
+}
+
+// PersistHandler is a shim to interface with the auth manager.
+//
+// The session is stored with a ttl until its expiry, and added to the user's session index.
+func (s *Store) PersistHandler(ctx context.Context, session *web.Session) error {
+	contents, err := json.Marshal(session)
+	if err != nil {
+		return ex.New(err)
+	}
+	var ttl int64
+	if !session.ExpiresUTC.IsZero() {
+		ttl = session.ExpiresUTC.Sub(s.Now()).Milliseconds()
+		// the session is already expired; keep it briefly rather than forever.
+		if ttl < 1 {
+			ttl = 1
+		}
+	}
+	return s.Client.Do(ctx, nil, redis.OpEVAL, PersistScript, "2",
+		s.sessionKey(session.SessionID),
+		s.userKey(session.UserID),
+		string(contents),
+		strconv.FormatInt(ttl, 10),
+		session.SessionID,
+	)
+}
+
+// RemoveHandler is a shim to interface with the auth manager.
+func (s *Store) RemoveHandler(ctx context.Context, sessionID string) error {
+	session, err := s.FetchHandler(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+	if err = s.Client.Do(ctx, nil, redis.OpDEL, s.sessionKey(sessionID)); err != nil {
+		return err
+	}
+	if session != nil {
+		return s.Client.Do(ctx, nil, redis.OpSREM, s.userKey(session.UserID), sessionID)
+	}
+	return nil
+}
+
+// UserSessions returns the active sessions for a user.
+//
+// Ids of sessions that have expired are removed from the user's index.
+func (s *Store) UserSessions(ctx context.Context, userID string) ([]*web.Session, error) {
+	var sessionIDs []string
+	if err := s.Client.Do(ctx, &sessionIDs, redis.OpSMEMBERS, s.userKey(userID)); err != nil {
+		return nil, err
+	}
+	if len(sessionIDs) == 0 {
+		return nil, nil
+	}
+
+	keys := make([]string, 0, len(sessionIDs))
+	for _, sessionID := range sessionIDs {
+		keys = append(keys, s.sessionKey(sessionID))
+	}
+	var values []string
+	if err := s.Client.Do(ctx, &values, redis.OpMGET, keys...); err != nil {
+		return nil, err
+	}
+
+	var sessions []*web.Session
+	var expired []string
+	for index, sessionID := range sessionIDs {
+		if index >= len(values) || values[index] == "" {
+			expired = append(expired, sessionID)
+			continue
+		}
+		session, err := s.decode(values[index])
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+	if len(expired) > 0 {
+		if err := s.Client.Do(ctx, nil, redis.OpSREM, append([]string{s.userKey(userID)}, expired...)...); err != nil {
+			return nil, err
+		}
+	}
+	return sessions, nil
+}
+
+// RevokeUserSessions removes every session for a user, e.g. when they change their password.
+func (s *Store) RevokeUserSessions(ctx context.Context, userID string) error {
+	var sessionIDs []string
+	if err := s.Client.Do(ctx, &sessionIDs, redis.OpSMEMBERS, s.userKey(userID)); err != nil {
+		return err
+	}
+	keys := []string{s.userKey(userID)}
+	for _, sessionID := range sessionIDs {
+		keys = append(keys, s.sessionKey(sessionID))
+	}
+	return s.Client.Do(ctx, nil, redis.OpDEL, keys...)
+}
+
+func (s *Store) decode(contents string) (*web.Session, error) {
+	var session web.Session
+	if err := json.Unmarshal([]byte(contents), &session); err != nil {
+		return nil, ex.New(err)
+	}
+	return &session, nil
+}
+
+func (s *Store) sessionKey(sessionID string) string {
+	return s.Prefix + "id:" + sessionID
+}
+
+func (s *Store) userKey(userID string) string {
+	return s.Prefix + "user:" + userID
+}
@@ -0,0 +1,194 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package redissession
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/blend/go-sdk/assert"
+	"github.com/blend/go-sdk/redis"
+	"github.com/blend/go-sdk/web"
+	"github.com/blend/go-sdk/webutil"
+)
+
+// mockRedis is an in-memory implementation of the commands the store uses.
+type mockRedis struct {
+	Values map[string]string
+	TTLs   map[string]int64
+	Sets   map[string]map[string]bool
+}
+
+func newMockRedis() *mockRedis {
+	return &mockRedis{
+		Values: map[string]string{},
+		TTLs:   map[string]int64{},
+		Sets:   map[string]map[string]bool{},
+	}
+}
+
+func (mr *mockRedis) Client() redis.Client {
+	return redis.MockClientFunc(func(_ context.Context, out interface{}, op string, args ...string) error {
+		switch op {
+		case redis.OpGET:
+			*(out.(*string)) = mr.Values[args[0]]
+		case redis.OpMGET:
+			var values []string
+			for _, key := range args {
+				values = append(values, mr.Values[key])
+			}
+			*(out.(*[]string)) = values
+		case redis.OpSMEMBERS:
+			var members []string
+			for member := range mr.Sets[args[0]] {
+				members = append(members, member)
+			}
+			sort.Strings(members)
+			*(out.(*[]string)) = members
+		case redis.OpSREM:
+			for _, member := range args[1:] {
+				delete(mr.Sets[args[0]], member)
+			}
+		case redis.OpDEL:
+			for _, key := range args {
+				delete(mr.Values, key)
+				delete(mr.Sets, key)
+				delete(mr.TTLs, key)
+			}
+		case redis.OpEVAL:
+			if args[0] != PersistScript {
+				return fmt.Errorf("unexpected script")
+			}
+			sessionKey, userKey, contents, sessionID := args[2], args[3], args[4], args[6]
+			ttl, _ := strconv.ParseInt(args[5], 10, 64)
+			mr.Values[sessionKey] = contents
+			mr.TTLs[sessionKey] = ttl
+			if mr.Sets[userKey] == nil {
+				mr.Sets[userKey] = map[string]bool{}
+			}
+			mr.Sets[userKey][sessionID] = true
+			if ttl == 0 || ttl > mr.TTLs[userKey] {
+				mr.TTLs[userKey] = ttl
+			}
+		default:
+			return fmt.Errorf("unexpected op: %s", op)
+		}
+		return nil
+	})
+}
+
+func loginCtx() *web.Ctx {
+	return web.NewCtx(webutil.NewMockResponse(new(bytes.Buffer)), webutil.NewMockRequest("GET", "/"))
+}
+
+func TestStore(t *testing.T) {
+	its := assert.New(t)
+
+	mr := newMockRedis()
+	now := time.Now().UTC()
+	store := New(mr.Client(), OptSlidingExpiration(time.Hour))
+	store.Now = func() time.Time { return now }
+
+	am, err := web.NewAuthManager()
+	its.Nil(err)
+	store.Apply(&am)
+
+	session, err := am.Login("user", loginCtx())
+	its.Nil(err)
+	its.Equal(now.Add(time.Hour), session.ExpiresUTC)
+	its.Equal(time.Hour.Milliseconds(), mr.TTLs["session:id:"+session.SessionID])
+	its.Equal(time.Hour.Milliseconds(), mr.TTLs["session:user:user"])
+
+	fetched, err := store.FetchHandler(context.Background(), session.SessionID)
+	its.Nil(err)
+	its.NotNil(fetched)
+	its.Equal(session.UserID, fetched.UserID)
+	its.Equal(session.ExpiresUTC, fetched.ExpiresUTC)
+
+	// sliding expiration
+	now = now.Add(30 * time.Minute)
+	r := web.NewCtx(webutil.NewMockResponse(new(bytes.Buffer)), webutil.NewMockRequestWithCookie("GET", "/", am.CookieDefaults.Name, session.SessionID))
+	extended, err := am.VerifyOrExtendSession(r)
+	its.Nil(err)
+	its.NotNil(extended)
+	its.Equal(now.Add(time.Hour), extended.ExpiresUTC)
+	its.Equal(time.Hour.Milliseconds(), mr.TTLs["session:id:"+session.SessionID])
+	fetched, err = store.FetchHandler(context.Background(), session.SessionID)
+	its.Nil(err)
+	its.Equal(now.Add(time.Hour), fetched.ExpiresUTC)
+
+	missing, err := store.FetchHandler(context.Background(), "not-a-session")
+	its.Nil(err)
+	its.Nil(missing)
+}
+
+func TestStore_UserSessions(t *testing.T) {
+	its := assert.New(t)
+
+	mr := newMockRedis()
+	store := New(mr.Client(), OptPrefix("test:"))
+	am, err := web.NewAuthManager()
+	its.Nil(err)
+	store.Apply(&am)
+	its.Nil(am.SessionTimeoutProvider, "the timeout provider should be unchanged without a sliding expiration")
+
+	first, err := am.Login("user", loginCtx())
+	its.Nil(err)
+	second, err := am.Login("user", loginCtx())
+	its.Nil(err)
+	other, err := am.Login("other-user", loginCtx())
+	its.Nil(err)
+	its.Zero(mr.TTLs["test:id:"+first.SessionID], "sessions without an expiry should not have a ttl")
+
+	sessions, err := store.UserSessions(context.Background(), "user")
+	its.Nil(err)
+	its.Len(sessions, 2)
+
+	// expired sessions are pruned from the index
+	delete(mr.Values, "test:id:"+first.SessionID)
+	sessions, err = store.UserSessions(context.Background(), "user")
+	its.Nil(err)
+	its.Len(sessions, 1)
+	its.Equal(second.SessionID, sessions[0].SessionID)
+	its.Len(mr.Sets["test:user:user"], 1)
+
+	// remove
+	its.Nil(store.RemoveHandler(context.Background(), second.SessionID))
+	its.Empty(mr.Values["test:id:"+second.SessionID])
+	its.Empty(mr.Sets["test:user:user"])
+
+	// revoke
+	_, err = am.Login("user", loginCtx())
+	its.Nil(err)
+	_, err = am.Login("user", loginCtx())
+	its.Nil(err)
+	its.Nil(store.RevokeUserSessions(context.Background(), "user"))
+	sessions, err = store.UserSessions(context.Background(), "user")
+	its.Nil(err)
+	its.Empty(sessions)
+
+	fetched, err := store.FetchHandler(context.Background(), other.SessionID)
+	its.Nil(err)
+	its.NotNil(fetched, "other users' sessions should not be revoked")
+}
+
+func TestStore_PersistHandler_expired(t *testing.T) {
+	its := assert.New(t)
+
+	mr := newMockRedis()
+	store := New(mr.Client())
+	session := web.NewSession("user", "session-id")
+	session.ExpiresUTC = time.Now().UTC().Add(-time.Minute)
+	its.Nil(store.PersistHandler(context.Background(), session))
+	its.Equal(1, mr.TTLs["session:id:session-id"])
+}
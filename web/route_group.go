@@ -0,0 +1,173 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package web
+
+import (
+	"net/http"
+	"strings"
+)
+
+// RouteGroupController is a controller that registers its routes on a route group.
+type RouteGroupController interface {
+	RegisterGroup(group *RouteGroup)
+}
+
+// Group returns a new route group with a given path prefix and middleware.
+//
+// Routes registered on the group are added to the app route tree with the prefix
+// prepended to their path, and the group middleware applied outside of the route middleware.
+func (a *App) Group(prefix string, middleware ...Middleware) *RouteGroup {
+	group := &RouteGroup{
+		App:        a,
+		Prefix:     prefix,
+		Middleware: middleware,
+	}
+	a.Groups = append(a.Groups, group)
+	return group
+}
+
+// AllGroups returns every route group registered on the app, including nested groups,
+// with parent groups before their children.
+func (a *App) AllGroups() (output []*RouteGroup) {
+	for _, group := range a.Groups {
+		output = append(output, group)
+		output = append(output, group.AllGroups()...)
+	}
+	return
+}
+
+// RouteGroup is a set of routes that share a path prefix and middleware.
+type RouteGroup struct {
+	// App is the app routes are registered on.
+	App *App
+	// Parent is the parent group for nested groups.
+	Parent *RouteGroup
+	// Prefix is the path prefix for the group relative to its parent.
+	Prefix string
+	// Middleware is the middleware applied to every route in the group.
+	Middleware []Middleware
+	// Routes are the routes registered directly on the group, with full paths.
+	Routes []Route
+	// Groups are the groups nested within the group.
+	Groups []*RouteGroup
+}
+
+// FullPrefix returns the path prefix for the group including the prefixes of its parents.
+func (g *RouteGroup) FullPrefix() string {
+	if g.Parent != nil {
+		return joinRoutePath(g.Parent.FullPrefix(), g.Prefix)
+	}
+	return joinRoutePath(g.Prefix, "")
+}
+
+// Group returns a new nested route group with a given path prefix and middleware.
+func (g *RouteGroup) Group(prefix string, middleware ...Middleware) *RouteGroup {
+	group := &RouteGroup{
+		App:        g.App,
+		Parent:     g,
+		Prefix:     prefix,
+		Middleware: middleware,
+	}
+	g.Groups = append(g.Groups, group)
+	return group
+}
+
+// AllGroups returns the groups nested within the group at any depth.
+func (g *RouteGroup) AllGroups() (output []*RouteGroup) {
+	for _, group := range g.Groups {
+		output = append(output, group)
+		output = append(output, group.AllGroups()...)
+	}
+	return
+}
+
+// AllRoutes returns the routes registered on the group and any nested groups.
+func (g *RouteGroup) AllRoutes() (output []Route) {
+	output = append(output, g.Routes...)
+	for _, group := range g.Groups {
+		output = append(output, group.AllRoutes()...)
+	}
+	return
+}
+
+// Register registers controllers with the group.
+func (g *RouteGroup) Register(controllers ...RouteGroupController) {
+	for _, c := range controllers {
+		c.RegisterGroup(g)
+	}
+}
+
+// GET registers a GET request route handler with the given middleware.
+func (g *RouteGroup) GET(path string, action Action, middleware ...Middleware) {
+	g.Method(http.MethodGet, path, action, middleware...)
+}
+
+// OPTIONS registers a OPTIONS request route handler the given middleware.
+func (g *RouteGroup) OPTIONS(path string, action Action, middleware ...Middleware) {
+	g.Method(http.MethodOptions, path, action, middleware...)
+}
+
+// HEAD registers a HEAD request route handler with the given middleware.
+func (g *RouteGroup) HEAD(path string, action Action, middleware ...Middleware) {
+	g.Method(http.MethodHead, path, action, middleware...)
+}
+
+// PUT registers a PUT request route handler with the given middleware.
+func (g *RouteGroup) PUT(path string, action Action, middleware ...Middleware) {
+	g.Method(http.MethodPut, path, action, middleware...)
+}
+
+// PATCH registers a PATCH request route handler with the given middleware.
+func (g *RouteGroup) PATCH(path string, action Action, middleware ...Middleware) {
+	g.Method(http.MethodPatch, path, action, middleware...)
+}
+
+// POST registers a POST request route handler with the given middleware.
+func (g *RouteGroup) POST(path string, action Action, middleware ...Middleware) {
+	g.Method(http.MethodPost, path, action, middleware...)
+}
+
+// DELETE registers a DELETE request route handler with the given middleware.
+func (g *RouteGroup) DELETE(path string, action Action, middleware ...Middleware) {
+	g.Method(http.MethodDelete, path, action, middleware...)
+}
+
+// Method registers an action for a given method and path relative to the group prefix with the given middleware.
+func (g *RouteGroup) Method(method string, path string, action Action, middleware ...Middleware) {
+	fullPath := joinRoutePath(g.FullPrefix(), path)
+	g.App.Method(method, fullPath, action, g.middleware(middleware)...)
+	g.Routes = append(g.Routes, Route{Method: method, Path: fullPath})
+}
+
+// Describe annotates a route on the group for generated api documentation, see `App.Describe`.
+func (g *RouteGroup) Describe(method, path string, options ...RouteSpecOption) {
+	g.App.Describe(method, joinRoutePath(g.FullPrefix(), path), options...)
+}
+
+// middleware returns the route middleware followed by the middleware of the group
+// and each of its parents, such that the outermost group middleware is called first.
+func (g *RouteGroup) middleware(route []Middleware) []Middleware {
+	output := make([]Middleware, 0, len(route)+len(g.Middleware))
+	output = append(output, route...)
+	for group := g; group != nil; group = group.Parent {
+		output = append(output, group.Middleware...)
+	}
+	return output
+}
+
+// joinRoutePath joins a prefix and a path with a single slash, and a leading slash.
+func joinRoutePath(prefix, path string) string {
+	joined := prefix
+	if path != "" {
+		joined = strings.TrimSuffix(prefix, "/") + "/" + strings.TrimPrefix(path, "/")
+	}
+	if !strings.HasPrefix(joined, "/") {
+		joined = "/" + joined
+	}
+	return joined
+}
@@ -0,0 +1,108 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package web
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/blend/go-sdk/assert"
+)
+
+func routeGroupTestMiddleware(name string) Middleware {
+	return func(action Action) Action {
+		return func(r *Ctx) Result {
+			r.Response.Header().Add("X-Middleware", name)
+			return action(r)
+		}
+	}
+}
+
+type routeGroupTestController struct{}
+
+func (routeGroupTestController) RegisterGroup(group *RouteGroup) {
+	group.GET("/widgets", ok)
+}
+
+func Test_RouteGroup(t *testing.T) {
+	its := assert.New(t)
+
+	app := MustNew(OptUse(routeGroupTestMiddleware("base")))
+	api := app.Group("/api", routeGroupTestMiddleware("api"))
+	v1 := api.Group("/v1/", routeGroupTestMiddleware("v1"))
+	v1.GET("/users/:id", func(r *Ctx) Result {
+		return Text.Result(r.RouteParams.Get("id"))
+	}, routeGroupTestMiddleware("route"))
+	v1.POST("users", ok)
+	api.GET("", ok)
+	v1.Register(routeGroupTestController{})
+
+	its.Equal("/api", api.FullPrefix())
+	its.Equal("/api/v1/", v1.FullPrefix())
+
+	res, err := MockGet(app, "/api/v1/users/foo").Discard()
+	its.Nil(err)
+	its.Equal(http.StatusOK, res.StatusCode)
+	its.Equal("base,api,v1,route", strings.Join(res.Header.Values("X-Middleware"), ","))
+
+	meta, err := MockMethod(app, http.MethodPost, "/api/v1/users").Discard()
+	its.Nil(err)
+	its.Equal(http.StatusOK, meta.StatusCode)
+	meta, err = MockGet(app, "/api").Discard()
+	its.Nil(err)
+	its.Equal(http.StatusOK, meta.StatusCode)
+	meta, err = MockGet(app, "/api/v1/widgets").Discard()
+	its.Nil(err)
+	its.Equal(http.StatusOK, meta.StatusCode)
+
+	its.Equal([]*RouteGroup{api, v1}, app.AllGroups())
+	its.Equal([]Route{
+		{Method: http.MethodGet, Path: "/api"},
+		{Method: http.MethodGet, Path: "/api/v1/users/:id"},
+		{Method: http.MethodPost, Path: "/api/v1/users"},
+		{Method: http.MethodGet, Path: "/api/v1/widgets"},
+	}, api.AllRoutes())
+	its.Len(app.AllRoutes(), 4)
+}
+
+func Test_RouteGroup_Describe(t *testing.T) {
+	its := assert.New(t)
+
+	app := MustNew()
+	users := app.Group("/users")
+	users.GET("/:id", ok)
+	users.Describe(http.MethodGet, "/:id", OptRouteSummary("Get a user"))
+
+	doc := app.OpenAPI(OpenAPIInfo{Title: "Test"})
+	its.Equal("Get a user", doc.Paths["/users/{id}"]["get"].Summary)
+}
+
+func Test_RouteGroup_leadingSlash(t *testing.T) {
+	its := assert.New(t)
+
+	app := MustNew()
+	api := app.Group("api")
+	api.GET("users", ok)
+	root := app.Group("")
+	root.GET("", ok)
+
+	its.Equal("/api", api.FullPrefix())
+	its.Equal("/", root.FullPrefix())
+	meta, err := MockGet(app, "/api/users").Discard()
+	its.Nil(err)
+	its.Equal(http.StatusOK, meta.StatusCode)
+	meta, err = MockGet(app, "/").Discard()
+	its.Nil(err)
+	its.Equal(http.StatusOK, meta.StatusCode)
+
+	its.Equal("/api/users", joinRoutePath("api", "users"))
+	its.Equal("/api", joinRoutePath("api", ""))
+	its.Equal("/", joinRoutePath("", ""))
+	its.Equal("/users", joinRoutePath("", "/users"))
+}
@@ -47,6 +47,17 @@ func (n *RouteNode) GetPath(path string) (route *Route, p RouteParameters, tsr b
 	return n.getValue(path)
 }
 
+// AllRoutes returns the routes for the node and all of its children.
+func (n *RouteNode) AllRoutes() (output []*Route) {
+	if n.Route != nil {
+		output = append(output, n.Route)
+	}
+	for _, child := range n.Children {
+		output = append(output, child.AllRoutes()...)
+	}
+	return
+}
+
 // incrementChildPriority increments priority of the given child and reorders if necessary
 func (n *RouteNode) incrementChildPriority(index int) int {
 	n.Children[index].Priority++
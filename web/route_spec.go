@@ -0,0 +1,130 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package web
+
+// RouteSpec describes a route for generated api documentation.
+//
+// Request, response and parameter types are given as example values (typically
+// the zero value of a type) and are reflected into schemas.
+type RouteSpec struct {
+	Summary     string
+	Description string
+	OperationID string
+	Tags        []string
+	Deprecated  bool
+	Parameters  []RouteSpecParameter
+	Request     interface{}
+	Responses   []RouteSpecResponse
+}
+
+// RouteSpecParameter describes a route parameter.
+type RouteSpecParameter struct {
+	// Name is the parameter name.
+	Name string
+	// In is where the parameter is read from, one of `path`, `query`, `header` or `cookie`.
+	In          string
+	Description string
+	Required    bool
+	// Type is an example value of the parameter type; if unset the parameter is a string.
+	Type interface{}
+}
+
+// RouteSpecResponse describes a route response.
+type RouteSpecResponse struct {
+	StatusCode  int
+	Description string
+	// Type is an example value of the response body; if unset the response has no body.
+	Type interface{}
+}
+
+// RouteSpec parameter locations.
+const (
+	RouteSpecParameterInPath   = "path"
+	RouteSpecParameterInQuery  = "query"
+	RouteSpecParameterInHeader = "header"
+	RouteSpecParameterInCookie = "cookie"
+)
+
+// RouteSpecOption is an option for route specs.
+type RouteSpecOption func(*RouteSpec)
+
+// OptRouteSummary sets the route summary.
+func OptRouteSummary(summary string) RouteSpecOption {
+	return func(rs *RouteSpec) { rs.Summary = summary }
+}
+
+// OptRouteDescription sets the route description.
+func OptRouteDescription(description string) RouteSpecOption {
+	return func(rs *RouteSpec) { rs.Description = description }
+}
+
+// OptRouteOperationID sets the route operation id.
+func OptRouteOperationID(operationID string) RouteSpecOption {
+	return func(rs *RouteSpec) { rs.OperationID = operationID }
+}
+
+// OptRouteTags adds tags to the route.
+func OptRouteTags(tags ...string) RouteSpecOption {
+	return func(rs *RouteSpec) { rs.Tags = append(rs.Tags, tags...) }
+}
+
+// OptRouteDeprecated marks the route as deprecated.
+func OptRouteDeprecated() RouteSpecOption {
+	return func(rs *RouteSpec) { rs.Deprecated = true }
+}
+
+// OptRouteParameter adds a parameter to the route.
+func OptRouteParameter(parameter RouteSpecParameter) RouteSpecOption {
+	return func(rs *RouteSpec) { rs.Parameters = append(rs.Parameters, parameter) }
+}
+
+// OptRoutePathParameter describes a path parameter on the route.
+func OptRoutePathParameter(name, description string) RouteSpecOption {
+	return OptRouteParameter(RouteSpecParameter{Name: name, In: RouteSpecParameterInPath, Description: description, Required: true})
+}
+
+// OptRouteQueryParameter describes a query parameter on the route.
+func OptRouteQueryParameter(name, description string, required bool) RouteSpecOption {
+	return OptRouteParameter(RouteSpecParameter{Name: name, In: RouteSpecParameterInQuery, Description: description, Required: required})
+}
+
+// OptRouteHeaderParameter describes a header parameter on the route.
+func OptRouteHeaderParameter(name, description string, required bool) RouteSpecOption {
+	return OptRouteParameter(RouteSpecParameter{Name: name, In: RouteSpecParameterInHeader, Description: description, Required: required})
+}
+
+// OptRouteRequest sets the request body type from an example value.
+func OptRouteRequest(request interface{}) RouteSpecOption {
+	return func(rs *RouteSpec) { rs.Request = request }
+}
+
+// OptRouteResponse adds a response for a status code with a body type from an example value.
+func OptRouteResponse(statusCode int, description string, response interface{}) RouteSpecOption {
+	return func(rs *RouteSpec) {
+		rs.Responses = append(rs.Responses, RouteSpecResponse{StatusCode: statusCode, Description: description, Type: response})
+	}
+}
+
+// Describe annotates the route registered (or to be registered) for a given method and path.
+//
+// The path should match the path the route is registered with, e.g. `/users/:id`.
+// Describing the same route again adds to the existing description.
+func (a *App) Describe(method, path string, options ...RouteSpecOption) {
+	if a.RouteSpecs == nil {
+		a.RouteSpecs = make(map[string]*RouteSpec)
+	}
+	key := Route{Method: method, Path: path}.StringWithMethod()
+	spec, ok := a.RouteSpecs[key]
+	if !ok {
+		spec = new(RouteSpec)
+		a.RouteSpecs[key] = spec
+	}
+	for _, option := range options {
+		option(spec)
+	}
+}
@@ -9,6 +9,7 @@ package web
 
 import (
 	"net/http"
+	"sort"
 
 	"github.com/blend/go-sdk/webutil"
 )
@@ -46,6 +47,11 @@ type RouteTree struct {
 	// for methods that do not have a route tree with
 	// a specific 405 response, and will instead return a 404.
 	SkipMethodNotAllowed bool
+	// MethodOptionsHandler is an optional handler to set
+	// to customize results for `OPTIONS` requests to paths that
+	// have routes for other methods but no explicit `OPTIONS` route.
+	// The `Allow` header is set on the response before it is called.
+	MethodOptionsHandler Handler
 	// NotFoundHandler is an optional handler to set
 	// to customize not found (404) results.
 	NotFoundHandler Handler
@@ -74,6 +80,20 @@ func (rt *RouteTree) Handle(method, path string, handler Handler) {
 	root.AddRoute(method, path, handler)
 }
 
+// AllRoutes returns every route registered on the tree, sorted by path and then method.
+func (rt *RouteTree) AllRoutes() (output []*Route) {
+	for _, root := range rt.Routes {
+		output = append(output, root.AllRoutes()...)
+	}
+	sort.Slice(output, func(i, j int) bool {
+		if output[i].Path == output[j].Path {
+			return output[i].Method < output[j].Method
+		}
+		return output[i].Path < output[j].Path
+	})
+	return
+}
+
 // Route gets the route and parameters for a given request
 // if it matches a registered handler.
 //
@@ -116,6 +136,10 @@ func (rt *RouteTree) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 		if !rt.SkipHandlingMethodOptions {
 			if allow := rt.allowed(path, req.Method); allow != "" {
 				w.Header().Set(webutil.HeaderAllow, allow)
+				if rt.MethodOptionsHandler != nil {
+					rt.MethodOptionsHandler(w, req, nil, nil)
+					return
+				}
 				// just return the allowed header
 				return
 			}
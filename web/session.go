@@ -16,6 +16,7 @@ func NewSession(userID string, sessionID string) *Session {
 	return &Session{
 		UserID:     userID,
 		SessionID:  sessionID,
+		CSRFToken:  NewSessionID(),
 		CreatedUTC: time.Now().UTC(),
 		State:      map[string]interface{}{},
 	}
@@ -30,6 +31,7 @@ type Session struct {
 	ExpiresUTC time.Time              `json:"expiresUTC" yaml:"expiresUTC"`
 	UserAgent  string                 `json:"userAgent" yaml:"userAgent"`
 	RemoteAddr string                 `json:"remoteAddr" yaml:"remoteAddr"`
+	CSRFToken  string                 `json:"csrfToken,omitempty" yaml:"csrfToken,omitempty"`
 	State      map[string]interface{} `json:"state,omitempty" yaml:"state,omitempty"`
 }
 
@@ -9,9 +9,12 @@ package web
 
 import (
 	"bytes"
+	"fmt"
 	"io"
+	"mime"
 	"net/http"
 	"os"
+	"path"
 	"regexp"
 	"sync"
 
@@ -52,10 +55,38 @@ func OptStaticFileServerCacheDisabled(cacheDisabled bool) StaticFileserverOption
 	}
 }
 
+// OptStaticFileServerPrecompressed sets the content encodings of pre-compressed sibling files
+// the static fileserver should serve, in order of preference.
+func OptStaticFileServerPrecompressed(encodings ...string) StaticFileserverOption {
+	return func(sfs *StaticFileServer) {
+		sfs.Precompressed = encodings
+	}
+}
+
+var (
+	// DefaultStaticFilePrecompressed are the default content encodings for pre-compressed static files.
+	DefaultStaticFilePrecompressed = []string{
+		webutil.ContentEncodingBrotli,
+		webutil.ContentEncodingZstd,
+		webutil.ContentEncodingGZIP,
+	}
+
+	// StaticFilePrecompressedExtensions are the file extensions of pre-compressed sibling files by content encoding.
+	StaticFilePrecompressedExtensions = map[string]string{
+		webutil.ContentEncodingBrotli: ".br",
+		webutil.ContentEncodingZstd:   ".zst",
+		webutil.ContentEncodingGZIP:   ".gz",
+	}
+)
+
 // StaticFileServer is a cache of static files.
 // It can operate in cached mode, or with `CacheDisabled` set to `true`
 // it will read from disk for each request.
 // In cached mode, it automatically adds etags for files it caches.
+//
+// If `Precompressed` encodings are set, it serves pre-compressed sibling files
+// (e.g. `app.js.br` for `app.js`) to clients that accept them, preferring encodings
+// in the order they're given. Siblings older than the original file are ignored.
 type StaticFileServer struct {
 	sync.RWMutex
 
@@ -64,6 +95,7 @@ type StaticFileServer struct {
 	Headers       http.Header
 	CacheDisabled bool
 	Cache         map[string]*CachedStaticFile
+	Precompressed []string
 }
 
 // AddHeader adds a header to the static cache results.
@@ -117,7 +149,10 @@ func (sc *StaticFileServer) Action(r *Ctx) Result {
 }
 
 // ServeFile writes the file to the response by reading from disk
-// for each request (i.e. skipping the cache)
+// for each request (i.e. skipping the cache).
+//
+// The etag for the file is derived from its modification time and size
+// so that conditional and range requests are honored without reading the whole file.
 func (sc *StaticFileServer) ServeFile(r *Ctx, filePath string) Result {
 	f, finalPath, err := sc.ResolveFile(filePath)
 	if err != nil {
@@ -133,6 +168,20 @@ func (sc *StaticFileServer) ServeFile(r *Ctx, filePath string) Result {
 		return r.DefaultProvider.NotFound()
 	}
 
+	if len(sc.Precompressed) > 0 {
+		webutil.AddVary(r.Response.Header(), webutil.HeaderAcceptEncoding)
+		variant, variantInfo, variantPath, encoding := sc.resolvePrecompressedFile(r.Request, filePath, finfo)
+		if variant != nil {
+			defer variant.Close()
+			f, finfo, finalPath = variant, variantInfo, variantPath
+			r.Response.Header().Set(webutil.HeaderContentType, mime.TypeByExtension(path.Ext(filePath)))
+			r.Response.Header().Set(webutil.HeaderContentEncoding, encoding)
+			r.Response.Header().Set(webutil.HeaderETag, staticFileETag(finfo, encoding))
+		}
+	}
+	if r.Response.Header().Get(webutil.HeaderETag) == "" {
+		r.Response.Header().Set(webutil.HeaderETag, staticFileETag(finfo, ""))
+	}
 	r.WithContext(logger.WithLabel(r.Context(), "web.static_file", finalPath))
 	http.ServeContent(r.Response, r.Request, filePath, finfo.ModTime(), f)
 	return nil
@@ -148,6 +197,9 @@ func (sc *StaticFileServer) ServeCachedFile(r *Ctx, filepath string) Result {
 	if file == nil {
 		return r.DefaultProvider.NotFound()
 	}
+	if len(sc.Precompressed) > 0 {
+		webutil.AddVary(r.Response.Header(), webutil.HeaderAcceptEncoding)
+	}
 	_ = file.Render(r)
 	return nil
 }
@@ -156,11 +208,19 @@ func (sc *StaticFileServer) ServeCachedFile(r *Ctx, filepath string) Result {
 // First the file path is modified according to the rewrite rules.
 // Then each search path is checked for the resolved file path.
 func (sc *StaticFileServer) ResolveFile(filePath string) (f http.File, finalPath string, err error) {
+	return sc.openFile(sc.rewritePath(filePath))
+}
+
+func (sc *StaticFileServer) rewritePath(filePath string) string {
 	for _, rule := range sc.RewriteRules {
 		if matched, newFilePath := rule.Apply(filePath); matched {
 			filePath = newFilePath
 		}
 	}
+	return filePath
+}
+
+func (sc *StaticFileServer) openFile(filePath string) (f http.File, finalPath string, err error) {
 	for _, searchPath := range sc.SearchPaths {
 		f, err = searchPath.Open(filePath)
 		if typed, ok := f.(*os.File); ok && typed != nil {
@@ -237,11 +297,93 @@ func (sc *StaticFileServer) ResolveCachedFile(filepath string) (*CachedStaticFil
 		ETag:     webutil.ETag(contents),
 		Size:     len(contents),
 	}
+	if file.Variants, err = sc.resolveCachedPrecompressedFiles(filepath, finfo); err != nil {
+		return nil, err
+	}
 
 	sc.Cache[filepath] = file
 	return file, nil
 }
 
+// resolveCachedPrecompressedFiles reads the pre-compressed siblings of a file into memory.
+func (sc *StaticFileServer) resolveCachedPrecompressedFiles(filePath string, finfo os.FileInfo) (output []*CachedStaticFile, err error) {
+	contentType := mime.TypeByExtension(path.Ext(filePath))
+	if contentType == "" {
+		return
+	}
+	for _, encoding := range sc.Precompressed {
+		variant, variantInfo := sc.openPrecompressedFile(filePath, encoding, finfo)
+		if variant == nil {
+			continue
+		}
+		contents, readErr := io.ReadAll(variant)
+		_ = variant.Close()
+		if readErr != nil {
+			return nil, readErr
+		}
+		output = append(output, &CachedStaticFile{
+			Path:            filePath,
+			ContentType:     contentType,
+			ContentEncoding: encoding,
+			Contents:        bytes.NewReader(contents),
+			ModTime:         variantInfo.ModTime(),
+			ETag:            webutil.ETag(contents),
+			Size:            len(contents),
+		})
+	}
+	return
+}
+
+// resolvePrecompressedFile opens the most preferred pre-compressed sibling of a file accepted by the request.
+//
+// Siblings are only served for files with a content type known from their extension,
+// as the content type cannot be sniffed from compressed contents.
+func (sc *StaticFileServer) resolvePrecompressedFile(req *http.Request, filePath string, finfo os.FileInfo) (http.File, os.FileInfo, string, string) {
+	if mime.TypeByExtension(path.Ext(filePath)) == "" {
+		return nil, nil, "", ""
+	}
+	acceptEncoding := req.Header.Get(webutil.HeaderAcceptEncoding)
+	candidates := append([]string(nil), sc.Precompressed...)
+	for {
+		encoding := webutil.NegotiateContentEncoding(acceptEncoding, candidates...)
+		if encoding == "" {
+			return nil, nil, "", ""
+		}
+		if variant, variantInfo := sc.openPrecompressedFile(filePath, encoding, finfo); variant != nil {
+			var finalPath string
+			if typed, ok := variant.(*os.File); ok {
+				finalPath = typed.Name()
+			}
+			return variant, variantInfo, finalPath, encoding
+		}
+		for index, candidate := range candidates {
+			if candidate == encoding {
+				candidates = append(candidates[:index], candidates[index+1:]...)
+				break
+			}
+		}
+	}
+}
+
+// openPrecompressedFile opens the sibling of a file for a given encoding if it exists,
+// and is at least as new as the original file.
+func (sc *StaticFileServer) openPrecompressedFile(filePath, encoding string, finfo os.FileInfo) (http.File, os.FileInfo) {
+	extension, ok := StaticFilePrecompressedExtensions[encoding]
+	if !ok {
+		return nil, nil
+	}
+	variant, _, err := sc.openFile(sc.rewritePath(filePath) + extension)
+	if err != nil || variant == nil {
+		return nil, nil
+	}
+	variantInfo, err := variant.Stat()
+	if err != nil || variantInfo.IsDir() || variantInfo.ModTime().Before(finfo.ModTime()) {
+		_ = variant.Close()
+		return nil, nil
+	}
+	return variant, variantInfo
+}
+
 func (sc *StaticFileServer) fileError(r *Ctx, err error) Result {
 	if os.IsNotExist(err) {
 		if r.DefaultProvider != nil {
@@ -256,3 +398,11 @@ func (sc *StaticFileServer) fileError(r *Ctx, err error) Result {
 	http.Error(r.Response, err.Error(), http.StatusInternalServerError)
 	return nil
 }
+
+// staticFileETag returns an etag for a file from its modification time, size and content encoding.
+func staticFileETag(finfo os.FileInfo, contentEncoding string) string {
+	if contentEncoding != "" {
+		return fmt.Sprintf(`"%x-%x-%s"`, finfo.ModTime().UnixNano(), finfo.Size(), contentEncoding)
+	}
+	return fmt.Sprintf(`"%x-%x"`, finfo.ModTime().UnixNano(), finfo.Size())
+}
@@ -10,10 +10,16 @@ package web
 import (
 	"bytes"
 	"fmt"
+	"mime"
 	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/blend/go-sdk/assert"
+	"github.com/blend/go-sdk/r2"
 	"github.com/blend/go-sdk/uuid"
 	"github.com/blend/go-sdk/webutil"
 )
@@ -220,3 +226,162 @@ func TestStaticFileserverAddsETag(t *testing.T) {
 	assert.NotEmpty(buffer.Bytes())
 	assert.NotEmpty(res.Header().Get(webutil.HeaderETag))
 }
+
+func TestStaticFileserverRangeAndConditionals(t *testing.T) {
+	for _, cached := range []bool{true, false} {
+		t.Run(fmt.Sprintf("cached=%v", cached), func(t *testing.T) {
+			its := assert.New(t)
+
+			app := MustNew()
+			if cached {
+				app.ServeStaticCached("/static", []string{"testdata"})
+			} else {
+				app.ServeStatic("/static", []string{"testdata"})
+			}
+			contents, err := os.ReadFile("testdata/test_file.html")
+			its.Nil(err)
+
+			full, res, err := MockGet(app, "/static/test_file.html").Bytes()
+			its.Nil(err)
+			its.Equal(http.StatusOK, res.StatusCode)
+			its.Equal(contents, full)
+			its.Equal("bytes", res.Header.Get(webutil.HeaderAcceptRanges))
+			etag := res.Header.Get(webutil.HeaderETag)
+			its.True(strings.HasPrefix(etag, `"`) && strings.HasSuffix(etag, `"`), etag)
+			lastModified := res.Header.Get(webutil.HeaderLastModified)
+			its.NotEmpty(lastModified)
+
+			// single range
+			partial, res, err := MockGet(app, "/static/test_file.html", r2.OptHeaderValue(webutil.HeaderRange, "bytes=10-19")).Bytes()
+			its.Nil(err)
+			its.Equal(http.StatusPartialContent, res.StatusCode)
+			its.Equal(fmt.Sprintf("bytes 10-19/%d", len(contents)), res.Header.Get(webutil.HeaderContentRange))
+			its.Equal(contents[10:20], partial)
+
+			// multiple ranges
+			multipart, res, err := MockGet(app, "/static/test_file.html", r2.OptHeaderValue(webutil.HeaderRange, "bytes=0-4,-5")).Bytes()
+			its.Nil(err)
+			its.Equal(http.StatusPartialContent, res.StatusCode)
+			its.True(strings.HasPrefix(res.Header.Get(webutil.HeaderContentType), "multipart/byteranges; boundary="))
+			its.Contains(string(multipart), string(contents[0:5]))
+			its.Contains(string(multipart), string(contents[len(contents)-5:]))
+			its.Contains(string(multipart), fmt.Sprintf("bytes %d-%d/%d", len(contents)-5, len(contents)-1, len(contents)))
+
+			// unsatisfiable range
+			meta, err := MockGet(app, "/static/test_file.html", r2.OptHeaderValue(webutil.HeaderRange, "bytes=100000-")).Discard()
+			its.Nil(err)
+			its.Equal(http.StatusRequestedRangeNotSatisfiable, meta.StatusCode)
+
+			// if-none-match
+			meta, err = MockGet(app, "/static/test_file.html", r2.OptHeaderValue(webutil.HeaderIfNoneMatch, etag)).Discard()
+			its.Nil(err)
+			its.Equal(http.StatusNotModified, meta.StatusCode)
+			meta, err = MockGet(app, "/static/test_file.html", r2.OptHeaderValue(webutil.HeaderIfNoneMatch, `"other"`)).Discard()
+			its.Nil(err)
+			its.Equal(http.StatusOK, meta.StatusCode)
+
+			// if-modified-since
+			meta, err = MockGet(app, "/static/test_file.html", r2.OptHeaderValue(webutil.HeaderIfModifiedSince, lastModified)).Discard()
+			its.Nil(err)
+			its.Equal(http.StatusNotModified, meta.StatusCode)
+
+			// if-range with a matching etag returns the range, otherwise the full file
+			partial, res, err = MockGet(app, "/static/test_file.html",
+				r2.OptHeaderValue(webutil.HeaderRange, "bytes=10-19"),
+				r2.OptHeaderValue(webutil.HeaderIfRange, etag),
+			).Bytes()
+			its.Nil(err)
+			its.Equal(http.StatusPartialContent, res.StatusCode)
+			its.Equal(contents[10:20], partial)
+			full, res, err = MockGet(app, "/static/test_file.html",
+				r2.OptHeaderValue(webutil.HeaderRange, "bytes=10-19"),
+				r2.OptHeaderValue(webutil.HeaderIfRange, `"stale"`),
+			).Bytes()
+			its.Nil(err)
+			its.Equal(http.StatusOK, res.StatusCode)
+			its.Equal(contents, full)
+		})
+	}
+}
+
+func TestStaticFileserverPrecompressed(t *testing.T) {
+	for _, cached := range []bool{true, false} {
+		t.Run(fmt.Sprintf("cached=%v", cached), func(t *testing.T) {
+			its := assert.New(t)
+
+			dir := t.TempDir()
+			modTime := time.Now().Add(-time.Hour)
+			for name, contents := range map[string]string{
+				"app.js":         "console.log('original');",
+				"app.js.br":      "brotli contents",
+				"app.js.gz":      "gzip contents",
+				"stale.css":      "body {}",
+				"stale.css.gz":   "stale gzip contents",
+				"unknown.xyz":    "unknown",
+				"unknown.xyz.gz": "unknown gzip contents",
+			} {
+				its.Nil(os.WriteFile(filepath.Join(dir, name), []byte(contents), 0644))
+				its.Nil(os.Chtimes(filepath.Join(dir, name), modTime, modTime))
+			}
+			staleTime := modTime.Add(-time.Hour)
+			its.Nil(os.Chtimes(filepath.Join(dir, "stale.css.gz"), staleTime, staleTime))
+
+			app := MustNew()
+			if cached {
+				app.ServeStaticCached("/static", []string{dir})
+			} else {
+				app.ServeStatic("/static", []string{dir})
+			}
+			its.Nil(app.SetStaticPrecompressed("/static"))
+			its.NotNil(app.SetStaticPrecompressed("/not-static"))
+
+			get := func(path, acceptEncoding string, options ...r2.Option) (string, *http.Response) {
+				options = append(options, r2.OptHeaderValue(webutil.HeaderAcceptEncoding, acceptEncoding))
+				body, res, err := MockGet(app, path, options...).Bytes()
+				its.Nil(err)
+				its.Equal(webutil.HeaderAcceptEncoding, res.Header.Get(webutil.HeaderVary))
+				return string(body), res
+			}
+
+			body, res := get("/static/app.js", "gzip, br")
+			its.Equal(http.StatusOK, res.StatusCode)
+			its.Equal("brotli contents", body)
+			its.Equal(webutil.ContentEncodingBrotli, res.Header.Get(webutil.HeaderContentEncoding))
+			its.Equal(mime.TypeByExtension(".js"), res.Header.Get(webutil.HeaderContentType))
+			brotliETag := res.Header.Get(webutil.HeaderETag)
+
+			body, res = get("/static/app.js", "gzip, br;q=0.5")
+			its.Equal("gzip contents", body)
+			its.Equal(webutil.ContentEncodingGZIP, res.Header.Get(webutil.HeaderContentEncoding))
+			gzipETag := res.Header.Get(webutil.HeaderETag)
+
+			body, res = get("/static/app.js", "zstd")
+			its.Equal("console.log('original');", body)
+			its.Empty(res.Header.Get(webutil.HeaderContentEncoding))
+			identityETag := res.Header.Get(webutil.HeaderETag)
+
+			its.NotEqual(brotliETag, gzipETag)
+			its.NotEqual(brotliETag, identityETag)
+			its.NotEqual(gzipETag, identityETag)
+
+			// conditional requests are evaluated against the negotiated representation
+			_, res = get("/static/app.js", "br", r2.OptHeaderValue(webutil.HeaderIfNoneMatch, brotliETag))
+			its.Equal(http.StatusNotModified, res.StatusCode)
+			_, res = get("/static/app.js", "gzip", r2.OptHeaderValue(webutil.HeaderIfNoneMatch, brotliETag))
+			its.Equal(http.StatusOK, res.StatusCode)
+
+			// ranges apply to the encoded representation
+			body, res = get("/static/app.js", "br", r2.OptHeaderValue(webutil.HeaderRange, "bytes=0-5"))
+			its.Equal(http.StatusPartialContent, res.StatusCode)
+			its.Equal("brotli", body)
+
+			body, res = get("/static/stale.css", "gzip")
+			its.Equal("body {}", body)
+			its.Empty(res.Header.Get(webutil.HeaderContentEncoding))
+
+			body, res = get("/static/unknown.xyz", "gzip")
+			its.Equal("unknown", body)
+			its.Empty(res.Header.Get(webutil.HeaderContentEncoding))
+		})
+	}
+}
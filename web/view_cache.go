@@ -66,6 +66,8 @@ func NewViewCache(options ...ViewCacheOption) (*ViewCache, error) {
 		NotAuthorizedTemplateName: DefaultTemplateNameNotAuthorized,
 		StatusTemplateName:        DefaultTemplateNameStatus,
 	}
+	vc.FuncMap["csrf_token"] = CSRFToken
+	vc.FuncMap["csrf_field"] = CSRFField
 	var err error
 	for _, option := range options {
 		if err = option(vc); err != nil {
@@ -0,0 +1,251 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package web
+
+import (
+	"crypto/sha1"
+	"encoding/base64"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/blend/go-sdk/ex"
+	"github.com/blend/go-sdk/webutil"
+)
+
+const (
+	// WebSocketVersion is the websocket protocol version supported.
+	WebSocketVersion = "13"
+	// WebSocketProtocol is the value of the `Upgrade` header for websocket connections.
+	WebSocketProtocol = "websocket"
+	// webSocketAcceptGUID is the GUID used to compute the `Sec-WebSocket-Accept` header.
+	webSocketAcceptGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+)
+
+const (
+	// ErrWebSocketHandshake is returned if a request is not a valid websocket handshake.
+	ErrWebSocketHandshake ex.Class = "websocket; invalid handshake"
+	// ErrWebSocketVersion is returned if a handshake requests an unsupported protocol version.
+	ErrWebSocketVersion ex.Class = "websocket; unsupported version"
+	// ErrWebSocketOrigin is returned if a handshake origin is rejected.
+	ErrWebSocketOrigin ex.Class = "websocket; origin not allowed"
+)
+
+// IsWebSocketRequest returns if a request is requesting a websocket upgrade.
+func IsWebSocketRequest(req *http.Request) bool {
+	return webSocketHeaderContains(req.Header, webutil.HeaderConnection, webutil.ConnectionUpgrade) &&
+		webSocketHeaderContains(req.Header, webutil.HeaderUpgrade, WebSocketProtocol)
+}
+
+// WebSocketAccept returns the `Sec-WebSocket-Accept` value for a given `Sec-WebSocket-Key`.
+func WebSocketAccept(key string) string {
+	hash := sha1.New()
+	hash.Write([]byte(key + webSocketAcceptGUID))
+	return base64.StdEncoding.EncodeToString(hash.Sum(nil))
+}
+
+// NewWebSocketUpgrader returns a new websocket upgrader.
+func NewWebSocketUpgrader(options ...WebSocketOption) *WebSocketUpgrader {
+	wsu := WebSocketUpgrader{
+		ReadLimit: DefaultWebSocketReadLimit,
+	}
+	for _, option := range options {
+		option(&wsu)
+	}
+	return &wsu
+}
+
+// WebSocketOption is an option for websocket upgraders.
+type WebSocketOption func(*WebSocketUpgrader)
+
+// OptWebSocketSubprotocols sets the subprotocols the server supports in order of preference.
+func OptWebSocketSubprotocols(subprotocols ...string) WebSocketOption {
+	return func(wsu *WebSocketUpgrader) { wsu.Subprotocols = subprotocols }
+}
+
+// OptWebSocketCheckOrigin sets the origin check.
+func OptWebSocketCheckOrigin(checkOrigin func(*http.Request) bool) WebSocketOption {
+	return func(wsu *WebSocketUpgrader) { wsu.CheckOrigin = checkOrigin }
+}
+
+// OptWebSocketReadLimit sets the maximum message size in bytes; it defaults to `DefaultWebSocketReadLimit`.
+func OptWebSocketReadLimit(readLimit int64) WebSocketOption {
+	return func(wsu *WebSocketUpgrader) { wsu.ReadLimit = readLimit }
+}
+
+// OptWebSocketReadWait sets the read deadline that is extended each time a frame is received.
+func OptWebSocketReadWait(d time.Duration) WebSocketOption {
+	return func(wsu *WebSocketUpgrader) { wsu.ReadWait = d }
+}
+
+// OptWebSocketWriteWait sets the write deadline applied to each frame.
+func OptWebSocketWriteWait(d time.Duration) WebSocketOption {
+	return func(wsu *WebSocketUpgrader) { wsu.WriteWait = d }
+}
+
+// OptWebSocketPingInterval sets the interval pings are sent on to keep the connection alive.
+//
+// It is typically paired with `OptWebSocketReadWait` set to a slightly longer duration
+// such that a connection is closed if the peer stops replying to pings.
+func OptWebSocketPingInterval(d time.Duration) WebSocketOption {
+	return func(wsu *WebSocketUpgrader) { wsu.PingInterval = d }
+}
+
+// WebSocketUpgrader upgrades requests to websocket connections.
+type WebSocketUpgrader struct {
+	// Subprotocols are the subprotocols the server supports in order of preference.
+	Subprotocols []string
+	// CheckOrigin is an optional check of the request origin.
+	// If unset, any origin is allowed; browser clients should set this.
+	CheckOrigin func(*http.Request) bool
+	// ReadLimit is the maximum message size in bytes.
+	// If it is unset, `DefaultWebSocketReadLimit` is used.
+	ReadLimit int64
+	// ReadWait is the read deadline that is extended each time a frame is received.
+	ReadWait time.Duration
+	// WriteWait is the write deadline applied to each frame.
+	WriteWait time.Duration
+	// PingInterval is the interval pings are sent on to keep the connection alive.
+	PingInterval time.Duration
+}
+
+// Upgrade validates the websocket handshake on the ctx request, writes the `101 Switching Protocols`
+// response and returns the hijacked connection.
+//
+// If the handshake is invalid, an error is returned and nothing is written to the response.
+func (wsu WebSocketUpgrader) Upgrade(ctx *Ctx) (*WebSocketConn, error) {
+	req := ctx.Request
+	if req.Method != http.MethodGet || !IsWebSocketRequest(req) {
+		return nil, ex.New(ErrWebSocketHandshake, ex.OptMessage("request is not a websocket upgrade"))
+	}
+	if req.Header.Get(webutil.HeaderSecWebSocketVersion) != WebSocketVersion {
+		ctx.Response.Header().Set(webutil.HeaderSecWebSocketVersion, WebSocketVersion)
+		return nil, ex.New(ErrWebSocketVersion)
+	}
+	key := req.Header.Get(webutil.HeaderSecWebSocketKey)
+	if decoded, err := base64.StdEncoding.DecodeString(key); err != nil || len(decoded) != 16 {
+		return nil, ex.New(ErrWebSocketHandshake, ex.OptMessage("invalid websocket key"))
+	}
+	if wsu.CheckOrigin != nil && !wsu.CheckOrigin(req) {
+		return nil, ex.New(ErrWebSocketOrigin)
+	}
+
+	subprotocol := wsu.selectSubprotocol(req)
+	header := ctx.Response.Header()
+	header.Set(webutil.HeaderUpgrade, WebSocketProtocol)
+	header.Set(webutil.HeaderConnection, "Upgrade")
+	header.Set(webutil.HeaderSecWebSocketAccept, WebSocketAccept(key))
+	if subprotocol != "" {
+		header.Set(webutil.HeaderSecWebSocketProtocol, subprotocol)
+	}
+
+	hijacker, ok := ctx.Response.(http.Hijacker)
+	if !ok {
+		return nil, ex.New(ErrWebSocketHandshake, ex.OptMessage("response writer does not support hijacking"))
+	}
+	// write the header first such that the status is recorded on the response writer
+	// for logging, the status line and headers are flushed by the hijack.
+	ctx.Response.WriteHeader(http.StatusSwitchingProtocols)
+	netConn, rw, err := hijacker.Hijack()
+	if err != nil {
+		return nil, ex.New(err)
+	}
+	if err = rw.Writer.Flush(); err != nil {
+		_ = netConn.Close()
+		return nil, ex.New(err)
+	}
+	// clear any deadlines set by the server.
+	_ = netConn.SetDeadline(time.Time{})
+
+	conn := NewWebSocketConn(netConn, rw.Reader, true)
+	conn.Subprotocol = subprotocol
+	conn.ReadLimit = wsu.ReadLimit
+	conn.ReadWait = wsu.ReadWait
+	conn.WriteWait = wsu.WriteWait
+	conn.extendReadDeadline()
+	if wsu.PingInterval > 0 {
+		go conn.KeepAlive(wsu.PingInterval)
+	}
+	return conn, nil
+}
+
+func (wsu WebSocketUpgrader) selectSubprotocol(req *http.Request) string {
+	for _, supported := range wsu.Subprotocols {
+		for _, requested := range strings.Split(req.Header.Get(webutil.HeaderSecWebSocketProtocol), ",") {
+			if strings.TrimSpace(requested) == supported {
+				return supported
+			}
+		}
+	}
+	return ""
+}
+
+// WebSocketHandler handles an upgraded websocket connection.
+type WebSocketHandler func(*Ctx, *WebSocketConn) error
+
+// WebSocket returns a result that upgrades the request to a websocket connection
+// and calls the handler with it.
+func WebSocket(handler WebSocketHandler, options ...WebSocketOption) *WebSocketResult {
+	return &WebSocketResult{
+		Upgrader: *NewWebSocketUpgrader(options...),
+		Handler:  handler,
+	}
+}
+
+// WebSocketResult is a result that upgrades the request to a websocket connection.
+//
+// The handler is called synchronously, and the connection is closed when it returns.
+// The request event and trace for the handshake are completed once the connection
+// is closed, with the `101` status code.
+type WebSocketResult struct {
+	Upgrader WebSocketUpgrader
+	Handler  WebSocketHandler
+}
+
+// Render implements Result.
+func (wsr *WebSocketResult) Render(ctx *Ctx) error {
+	conn, err := wsr.Upgrader.Upgrade(ctx)
+	if err != nil {
+		switch {
+		case ex.Is(err, ErrWebSocketVersion):
+			return ctx.DefaultProvider.Status(http.StatusUpgradeRequired, nil).Render(ctx)
+		case ex.Is(err, ErrWebSocketOrigin):
+			return ctx.DefaultProvider.Status(http.StatusForbidden, nil).Render(ctx)
+		case ex.Is(err, ErrWebSocketHandshake):
+			return ctx.DefaultProvider.BadRequest(err).Render(ctx)
+		default:
+			return err
+		}
+	}
+
+	err = wsr.Handler(ctx, conn)
+	switch {
+	case err == nil:
+		return conn.Close()
+	case IsWebSocketCloseError(err):
+		_ = conn.CloseWithCode(WebSocketCloseNormal, "")
+		if IsWebSocketCloseError(err, WebSocketCloseNormal, WebSocketCloseGoingAway, WebSocketCloseNoStatus) {
+			return nil
+		}
+		return err
+	default:
+		_ = conn.CloseWithCode(WebSocketCloseInternalError, "")
+		return err
+	}
+}
+
+func webSocketHeaderContains(header http.Header, key, value string) bool {
+	for _, headerValue := range header.Values(key) {
+		for _, piece := range strings.Split(headerValue, ",") {
+			if strings.EqualFold(strings.TrimSpace(piece), value) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,508 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package web
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/blend/go-sdk/ex"
+)
+
+// WebSocketOpcode is a websocket frame opcode.
+type WebSocketOpcode byte
+
+// IsControl returns if the opcode is for a control frame.
+func (op WebSocketOpcode) IsControl() bool {
+	return op&0x8 != 0
+}
+
+// WebSocketOpcodes as defined in RFC 6455 section 5.2.
+const (
+	WebSocketOpcodeContinuation WebSocketOpcode = 0x0
+	WebSocketOpcodeText         WebSocketOpcode = 0x1
+	WebSocketOpcodeBinary       WebSocketOpcode = 0x2
+	WebSocketOpcodeClose        WebSocketOpcode = 0x8
+	WebSocketOpcodePing         WebSocketOpcode = 0x9
+	WebSocketOpcodePong         WebSocketOpcode = 0xA
+)
+
+// WebSocket close codes as defined in RFC 6455 section 7.4.1.
+const (
+	WebSocketCloseNormal             = 1000
+	WebSocketCloseGoingAway          = 1001
+	WebSocketCloseProtocolError      = 1002
+	WebSocketCloseUnsupportedData    = 1003
+	WebSocketCloseNoStatus           = 1005
+	WebSocketCloseAbnormal           = 1006
+	WebSocketCloseInvalidPayload     = 1007
+	WebSocketClosePolicyViolation    = 1008
+	WebSocketCloseMessageTooBig      = 1009
+	WebSocketCloseMandatoryExtension = 1010
+	WebSocketCloseInternalError      = 1011
+)
+
+// DefaultWebSocketReadLimit is the default maximum size of a message in bytes.
+const DefaultWebSocketReadLimit = 32 << 20
+
+const (
+	// ErrWebSocketClosed is returned when writing to a connection after a close frame has been sent.
+	ErrWebSocketClosed ex.Class = "websocket; connection is closed"
+)
+
+const (
+	webSocketFinalBit    = 0x80
+	webSocketReserved    = 0x70
+	webSocketMaskBit     = 0x80
+	webSocketMaxControl  = 125
+	webSocketCloseReplay = time.Second
+)
+
+// WebSocketCloseError is returned by reads when a close frame is received or the connection
+// is failed because of a protocol error.
+type WebSocketCloseError struct {
+	Code   int
+	Reason string
+}
+
+// Error implements error.
+func (wce *WebSocketCloseError) Error() string {
+	if wce.Reason != "" {
+		return fmt.Sprintf("websocket; close %d: %s", wce.Code, wce.Reason)
+	}
+	return fmt.Sprintf("websocket; close %d", wce.Code)
+}
+
+// IsWebSocketCloseError returns if an error is a websocket close error with one of the given codes.
+// If no codes are given, it returns if the error is any websocket close error.
+func IsWebSocketCloseError(err error, codes ...int) bool {
+	var typed *WebSocketCloseError
+	if exErr := ex.As(err); exErr != nil {
+		typed, _ = exErr.Class.(*WebSocketCloseError)
+	} else {
+		typed, _ = err.(*WebSocketCloseError)
+	}
+	if typed == nil {
+		return false
+	}
+	if len(codes) == 0 {
+		return true
+	}
+	for _, code := range codes {
+		if typed.Code == code {
+			return true
+		}
+	}
+	return false
+}
+
+// NewWebSocketConn returns a new websocket connection for an established network connection.
+//
+// The reader should wrap the connection and may hold bytes that were buffered during the handshake.
+// Server connections expect masked frames from the client, and client connections mask the frames they send.
+func NewWebSocketConn(conn net.Conn, reader *bufio.Reader, isServer bool) *WebSocketConn {
+	if reader == nil {
+		reader = bufio.NewReader(conn)
+	}
+	return &WebSocketConn{
+		Conn:      conn,
+		ReadLimit: DefaultWebSocketReadLimit,
+		reader:    reader,
+		isServer:  isServer,
+		done:      make(chan struct{}),
+	}
+}
+
+// WebSocketConn is a websocket connection that implements RFC 6455 framing.
+//
+// Reads should be made from a single goroutine; writes are safe to make concurrently.
+type WebSocketConn struct {
+	// Conn is the underlying network connection.
+	Conn net.Conn
+	// Subprotocol is the subprotocol negotiated during the handshake.
+	Subprotocol string
+	// ReadLimit is the maximum size of a message in bytes; larger messages fail the connection.
+	// If it is unset, `DefaultWebSocketReadLimit` is used.
+	ReadLimit int64
+	// ReadWait is how long reads may be idle before the read deadline is reached.
+	// It is extended each time a frame is received.
+	ReadWait time.Duration
+	// WriteWait is the write deadline applied to each frame written.
+	WriteWait time.Duration
+	// PongHandler is called with the application data of received pong frames.
+	PongHandler func(appData []byte)
+
+	reader     *bufio.Reader
+	isServer   bool
+	writeMu    sync.Mutex
+	closeSent  bool
+	peerClosed bool
+	closeOnce  sync.Once
+	done       chan struct{}
+}
+
+// ReadLimitOrDefault returns the read limit or a default.
+func (wsc *WebSocketConn) ReadLimitOrDefault() int64 {
+	if wsc.ReadLimit > 0 {
+		return wsc.ReadLimit
+	}
+	return DefaultWebSocketReadLimit
+}
+
+// SetReadDeadline sets the read deadline on the underlying connection.
+func (wsc *WebSocketConn) SetReadDeadline(t time.Time) error {
+	return wsc.Conn.SetReadDeadline(t)
+}
+
+// SetWriteDeadline sets the write deadline on the underlying connection.
+func (wsc *WebSocketConn) SetWriteDeadline(t time.Time) error {
+	return wsc.Conn.SetWriteDeadline(t)
+}
+
+// ReadMessage reads the next complete data message, reassembling fragmented messages.
+//
+// Ping frames are answered with pong frames automatically. If a close frame is received
+// it is echoed to the peer and a `*WebSocketCloseError` is returned.
+func (wsc *WebSocketConn) ReadMessage() (messageType WebSocketOpcode, message []byte, err error) {
+	var started bool
+	for {
+		var fin bool
+		var opcode WebSocketOpcode
+		var payload []byte
+		fin, opcode, payload, err = wsc.readFrame()
+		if err != nil {
+			return
+		}
+		wsc.extendReadDeadline()
+
+		switch opcode {
+		case WebSocketOpcodePing:
+			if err = wsc.writeFrame(WebSocketOpcodePong, payload); err != nil {
+				return
+			}
+			continue
+		case WebSocketOpcodePong:
+			if wsc.PongHandler != nil {
+				wsc.PongHandler(payload)
+			}
+			continue
+		case WebSocketOpcodeClose:
+			err = wsc.handleClose(payload)
+			return
+		case WebSocketOpcodeText, WebSocketOpcodeBinary:
+			if started {
+				err = wsc.fail(WebSocketCloseProtocolError, "expected continuation frame")
+				return
+			}
+			started = true
+			messageType = opcode
+		case WebSocketOpcodeContinuation:
+			if !started {
+				err = wsc.fail(WebSocketCloseProtocolError, "unexpected continuation frame")
+				return
+			}
+		default:
+			err = wsc.fail(WebSocketCloseProtocolError, fmt.Sprintf("unknown opcode %d", opcode))
+			return
+		}
+
+		message = append(message, payload...)
+		if int64(len(message)) > wsc.ReadLimitOrDefault() {
+			err = wsc.fail(WebSocketCloseMessageTooBig, "")
+			return
+		}
+		if fin {
+			if messageType == WebSocketOpcodeText && !utf8.Valid(message) {
+				err = wsc.fail(WebSocketCloseInvalidPayload, "invalid utf-8")
+				return
+			}
+			return
+		}
+	}
+}
+
+// ReadJSON reads the next message and deserializes it as json into the given object.
+func (wsc *WebSocketConn) ReadJSON(v interface{}) error {
+	_, message, err := wsc.ReadMessage()
+	if err != nil {
+		return err
+	}
+	return ex.New(json.Unmarshal(message, v))
+}
+
+// WriteMessage writes a data message as a single frame.
+func (wsc *WebSocketConn) WriteMessage(messageType WebSocketOpcode, data []byte) error {
+	if messageType != WebSocketOpcodeText && messageType != WebSocketOpcodeBinary {
+		return ex.New("websocket; invalid message type", ex.OptMessagef("opcode: %d", messageType))
+	}
+	return wsc.writeFrame(messageType, data)
+}
+
+// WriteText writes a text message.
+func (wsc *WebSocketConn) WriteText(text string) error {
+	return wsc.writeFrame(WebSocketOpcodeText, []byte(text))
+}
+
+// WriteJSON serializes the given object as json and writes it as a text message.
+func (wsc *WebSocketConn) WriteJSON(v interface{}) error {
+	contents, err := json.Marshal(v)
+	if err != nil {
+		return ex.New(err)
+	}
+	return wsc.writeFrame(WebSocketOpcodeText, contents)
+}
+
+// Ping writes a ping frame with optional application data.
+func (wsc *WebSocketConn) Ping(appData []byte) error {
+	if len(appData) > webSocketMaxControl {
+		return ex.New("websocket; control frame payload too large")
+	}
+	return wsc.writeFrame(WebSocketOpcodePing, appData)
+}
+
+// Close sends a normal close frame and closes the underlying connection.
+func (wsc *WebSocketConn) Close() error {
+	return wsc.CloseWithCode(WebSocketCloseNormal, "")
+}
+
+// CloseWithCode sends a close frame with a given code and reason, and closes the underlying connection.
+//
+// If the peer has not already sent a close frame, it waits a short time for it to
+// acknowledge the close before closing the underlying connection, and as a result
+// should not be called while another goroutine is reading from the connection.
+func (wsc *WebSocketConn) CloseWithCode(code int, reason string) (err error) {
+	wsc.closeOnce.Do(func() {
+		close(wsc.done)
+		if writeErr := wsc.writeClose(code, reason); writeErr == nil && !wsc.closeReceived() {
+			_ = wsc.Conn.SetReadDeadline(time.Now().Add(webSocketCloseReplay))
+			for {
+				if _, opcode, _, readErr := wsc.readFrame(); readErr != nil || opcode == WebSocketOpcodeClose {
+					break
+				}
+			}
+		}
+		err = wsc.Conn.Close()
+	})
+	return
+}
+
+// KeepAlive sends ping frames on a given interval until the connection is closed or a ping fails.
+//
+// It blocks, and should be called in a separate goroutine. It is typically paired
+// with `ReadWait` such that the read deadline is extended by the pong replies.
+func (wsc *WebSocketConn) KeepAlive(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-wsc.done:
+			return
+		case <-ticker.C:
+			if err := wsc.Ping(nil); err != nil {
+				return
+			}
+		}
+	}
+}
+
+//
+// internal helpers
+//
+
+func (wsc *WebSocketConn) closeReceived() bool {
+	wsc.writeMu.Lock()
+	defer wsc.writeMu.Unlock()
+	return wsc.peerClosed
+}
+
+func (wsc *WebSocketConn) extendReadDeadline() {
+	if wsc.ReadWait > 0 {
+		_ = wsc.Conn.SetReadDeadline(time.Now().Add(wsc.ReadWait))
+	}
+}
+
+func (wsc *WebSocketConn) handleClose(payload []byte) error {
+	wsc.writeMu.Lock()
+	wsc.peerClosed = true
+	wsc.writeMu.Unlock()
+
+	closeErr := &WebSocketCloseError{Code: WebSocketCloseNoStatus}
+	switch {
+	case len(payload) == 1:
+		return wsc.fail(WebSocketCloseProtocolError, "invalid close payload")
+	case len(payload) >= 2:
+		closeErr.Code = int(binary.BigEndian.Uint16(payload))
+		closeErr.Reason = string(payload[2:])
+		if !isWebSocketCloseCodeValid(closeErr.Code) {
+			return wsc.fail(WebSocketCloseProtocolError, "invalid close code")
+		}
+		if !utf8.Valid(payload[2:]) {
+			return wsc.fail(WebSocketCloseInvalidPayload, "invalid utf-8")
+		}
+	}
+	// echo the close frame back to the peer to complete the close handshake.
+	if closeErr.Code == WebSocketCloseNoStatus {
+		_ = wsc.writeFrame(WebSocketOpcodeClose, nil)
+	} else {
+		_ = wsc.writeClose(closeErr.Code, "")
+	}
+	return closeErr
+}
+
+// isWebSocketCloseCodeValid returns if a close code can be sent in a close frame, i.e. it is
+// a defined code that is not reserved for local use (e.g. 1005, 1006 and 1015), or a code in the
+// range for libraries, frameworks and applications (3000-4999), per RFC 6455 section 7.4.
+func isWebSocketCloseCodeValid(code int) bool {
+	switch {
+	case code >= 1000 && code <= 1003:
+		return true
+	case code >= 1007 && code <= 1014:
+		return true
+	case code >= 3000 && code <= 4999:
+		return true
+	default:
+		return false
+	}
+}
+
+// fail sends a close frame for a given code and returns the corresponding close error.
+func (wsc *WebSocketConn) fail(code int, reason string) error {
+	_ = wsc.writeClose(code, reason)
+	return &WebSocketCloseError{Code: code, Reason: reason}
+}
+
+func (wsc *WebSocketConn) writeClose(code int, reason string) error {
+	payload := make([]byte, 2+len(reason))
+	binary.BigEndian.PutUint16(payload, uint16(code))
+	copy(payload[2:], reason)
+	if len(payload) > webSocketMaxControl {
+		payload = payload[:webSocketMaxControl]
+	}
+	return wsc.writeFrame(WebSocketOpcodeClose, payload)
+}
+
+func (wsc *WebSocketConn) readFrame() (fin bool, opcode WebSocketOpcode, payload []byte, err error) {
+	var header [2]byte
+	if _, err = io.ReadFull(wsc.reader, header[:]); err != nil {
+		return
+	}
+	fin = header[0]&webSocketFinalBit != 0
+	opcode = WebSocketOpcode(header[0] & 0x0f)
+	if header[0]&webSocketReserved != 0 {
+		err = wsc.fail(WebSocketCloseProtocolError, "reserved bits set")
+		return
+	}
+	masked := header[1]&webSocketMaskBit != 0
+	if masked != wsc.isServer {
+		err = wsc.fail(WebSocketCloseProtocolError, "invalid frame masking")
+		return
+	}
+
+	length := uint64(header[1] & 0x7f)
+	switch length {
+	case 126:
+		var extended [2]byte
+		if _, err = io.ReadFull(wsc.reader, extended[:]); err != nil {
+			return
+		}
+		length = uint64(binary.BigEndian.Uint16(extended[:]))
+	case 127:
+		var extended [8]byte
+		if _, err = io.ReadFull(wsc.reader, extended[:]); err != nil {
+			return
+		}
+		length = binary.BigEndian.Uint64(extended[:])
+	}
+	if opcode.IsControl() && (!fin || length > webSocketMaxControl) {
+		err = wsc.fail(WebSocketCloseProtocolError, "invalid control frame")
+		return
+	}
+	// the length is set by the peer, so it must be checked before the payload is allocated.
+	if length > uint64(wsc.ReadLimitOrDefault()) {
+		err = wsc.fail(WebSocketCloseMessageTooBig, "")
+		return
+	}
+
+	var mask [4]byte
+	if masked {
+		if _, err = io.ReadFull(wsc.reader, mask[:]); err != nil {
+			return
+		}
+	}
+	payload = make([]byte, length)
+	if _, err = io.ReadFull(wsc.reader, payload); err != nil {
+		return
+	}
+	if masked {
+		webSocketMask(mask, payload)
+	}
+	return
+}
+
+func (wsc *WebSocketConn) writeFrame(opcode WebSocketOpcode, payload []byte) error {
+	wsc.writeMu.Lock()
+	defer wsc.writeMu.Unlock()
+
+	if wsc.closeSent {
+		return ex.New(ErrWebSocketClosed)
+	}
+	if opcode == WebSocketOpcodeClose {
+		wsc.closeSent = true
+	}
+
+	frame := make([]byte, 0, len(payload)+14)
+	frame = append(frame, webSocketFinalBit|byte(opcode))
+	var maskBit byte
+	if !wsc.isServer {
+		maskBit = webSocketMaskBit
+	}
+	switch length := len(payload); {
+	case length <= 125:
+		frame = append(frame, maskBit|byte(length))
+	case length <= 0xffff:
+		frame = append(frame, maskBit|126, 0, 0)
+		binary.BigEndian.PutUint16(frame[len(frame)-2:], uint16(length))
+	default:
+		frame = append(frame, maskBit|127, 0, 0, 0, 0, 0, 0, 0, 0)
+		binary.BigEndian.PutUint64(frame[len(frame)-8:], uint64(length))
+	}
+	if wsc.isServer {
+		frame = append(frame, payload...)
+	} else {
+		var mask [4]byte
+		if _, err := rand.Read(mask[:]); err != nil {
+			return ex.New(err)
+		}
+		frame = append(frame, mask[:]...)
+		offset := len(frame)
+		frame = append(frame, payload...)
+		webSocketMask(mask, frame[offset:])
+	}
+
+	if wsc.WriteWait > 0 {
+		_ = wsc.Conn.SetWriteDeadline(time.Now().Add(wsc.WriteWait))
+	}
+	if _, err := wsc.Conn.Write(frame); err != nil {
+		return ex.New(err)
+	}
+	return nil
+}
+
+func webSocketMask(mask [4]byte, data []byte) {
+	for index := range data {
+		data[index] ^= mask[index%4]
+	}
+}
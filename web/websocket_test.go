@@ -0,0 +1,278 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package web
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/blend/go-sdk/assert"
+	"github.com/blend/go-sdk/ex"
+	"github.com/blend/go-sdk/logger"
+	"github.com/blend/go-sdk/r2"
+	"github.com/blend/go-sdk/webutil"
+)
+
+func Test_WebSocketAccept(t *testing.T) {
+	its := assert.New(t)
+	// from RFC 6455 section 1.3
+	its.Equal("s3pPLMBiTxaQ9kYGzzhZRbK+xOo=", WebSocketAccept("dGhlIHNhbXBsZSBub25jZQ=="))
+}
+
+func Test_WebSocket_echo(t *testing.T) {
+	its := assert.New(t)
+
+	log := logger.MustNew(logger.OptAll(), logger.OptOutput(io.Discard))
+	events := make(chan webutil.HTTPRequestEvent, 1)
+	log.Listen(webutil.FlagHTTPRequest, "test", webutil.NewHTTPRequestEventListener(func(_ context.Context, e webutil.HTTPRequestEvent) {
+		events <- e
+	}))
+	defer log.Close()
+
+	handlerErrors := make(chan error, 1)
+	app := MustNew(OptLog(log))
+	app.GET("/ws", func(_ *Ctx) Result {
+		return WebSocket(func(_ *Ctx, conn *WebSocketConn) error {
+			for {
+				messageType, message, err := conn.ReadMessage()
+				if err != nil {
+					handlerErrors <- err
+					return err
+				}
+				if err = conn.WriteMessage(messageType, message); err != nil {
+					return err
+				}
+			}
+		}, OptWebSocketSubprotocols("chat"))
+	})
+
+	client, err := MockWebSocket(app, "/ws", r2.OptHeaderValue(webutil.HeaderSecWebSocketProtocol, "other, chat"))
+	its.Nil(err)
+	defer client.Close()
+	its.Equal(http.StatusSwitchingProtocols, client.Response.StatusCode)
+	its.Equal("chat", client.Subprotocol)
+
+	its.Nil(client.WriteText("hello"))
+	messageType, message, err := client.ReadMessage()
+	its.Nil(err)
+	its.Equal(WebSocketOpcodeText, messageType)
+	its.Equal("hello", string(message))
+
+	large := []byte(strings.Repeat("a", 70000))
+	its.Nil(client.WriteMessage(WebSocketOpcodeBinary, large))
+	messageType, message, err = client.ReadMessage()
+	its.Nil(err)
+	its.Equal(WebSocketOpcodeBinary, messageType)
+	its.Equal(large, message)
+
+	type payload struct {
+		Value string `json:"value"`
+	}
+	its.Nil(client.WriteJSON(payload{Value: "json"}))
+	var output payload
+	its.Nil(client.ReadJSON(&output))
+	its.Equal("json", output.Value)
+
+	its.Nil(client.CloseWithCode(WebSocketCloseGoingAway, "bye"))
+
+	handlerErr := <-handlerErrors
+	its.True(IsWebSocketCloseError(handlerErr, WebSocketCloseGoingAway))
+
+	select {
+	case e := <-events:
+		its.Equal(http.StatusSwitchingProtocols, e.StatusCode)
+		its.Equal("/ws", e.Route)
+	case <-time.After(5 * time.Second):
+		its.FailNow("request event was not triggered")
+	}
+}
+
+func Test_WebSocket_ctxUpgrade(t *testing.T) {
+	its := assert.New(t)
+
+	app := MustNew()
+	app.GET("/ws", func(r *Ctx) Result {
+		conn, err := r.UpgradeWebSocket()
+		if err != nil {
+			return r.DefaultProvider.BadRequest(err)
+		}
+		defer conn.Close()
+		_ = conn.WriteText("greetings")
+		return nil
+	})
+
+	client, err := MockWebSocket(app, "/ws")
+	its.Nil(err)
+	defer client.Close()
+	_, message, err := client.ReadMessage()
+	its.Nil(err)
+	its.Equal("greetings", string(message))
+
+	_, _, err = client.ReadMessage()
+	its.True(IsWebSocketCloseError(err, WebSocketCloseNormal))
+}
+
+func Test_WebSocket_handshakeFailures(t *testing.T) {
+	its := assert.New(t)
+
+	app := MustNew()
+	app.GET("/ws", func(_ *Ctx) Result {
+		return WebSocket(func(_ *Ctx, _ *WebSocketConn) error {
+			return nil
+		}, OptWebSocketCheckOrigin(func(req *http.Request) bool {
+			return req.Header.Get(webutil.HeaderOrigin) != "https://evil.com"
+		}))
+	})
+
+	meta, err := MockGet(app, "/ws").Discard()
+	its.Nil(err)
+	its.Equal(http.StatusBadRequest, meta.StatusCode)
+
+	res, err := MockWebSocket(app, "/ws", r2.OptHeaderValue(webutil.HeaderOrigin, "https://evil.com"))
+	its.True(ex.Is(err, ErrWebSocketHandshake))
+	its.Equal(http.StatusForbidden, res.Response.StatusCode)
+
+	meta, err = MockGet(app, "/ws",
+		r2.OptHeaderValue(webutil.HeaderConnection, "Upgrade"),
+		r2.OptHeaderValue(webutil.HeaderUpgrade, "websocket"),
+		r2.OptHeaderValue(webutil.HeaderSecWebSocketVersion, "8"),
+		r2.OptHeaderValue(webutil.HeaderSecWebSocketKey, "dGhlIHNhbXBsZSBub25jZQ=="),
+	).Discard()
+	its.Nil(err)
+	its.Equal(http.StatusUpgradeRequired, meta.StatusCode)
+	its.Equal(WebSocketVersion, meta.Header.Get(webutil.HeaderSecWebSocketVersion))
+}
+
+func Test_WebSocket_keepAlive(t *testing.T) {
+	its := assert.New(t)
+
+	app := MustNew()
+	app.GET("/ws", func(_ *Ctx) Result {
+		return WebSocket(func(_ *Ctx, conn *WebSocketConn) error {
+			_, _, err := conn.ReadMessage()
+			return err
+		}, OptWebSocketPingInterval(10*time.Millisecond), OptWebSocketReadWait(time.Second))
+	})
+
+	client, err := MockWebSocket(app, "/ws")
+	its.Nil(err)
+	defer client.Close()
+
+	// read the raw frames to observe the pings from the server.
+	_ = client.SetReadDeadline(time.Now().Add(5 * time.Second))
+	fin, opcode, _, err := client.readFrame()
+	its.Nil(err)
+	its.True(fin)
+	its.Equal(WebSocketOpcodePing, opcode)
+}
+
+func Test_WebSocketConn_protocolErrors(t *testing.T) {
+	its := assert.New(t)
+
+	server, client := net.Pipe()
+	defer server.Close()
+	defer client.Close()
+
+	serverConn := NewWebSocketConn(server, bufio.NewReader(server), true)
+	serverConn.ReadLimit = 4
+
+	go func() {
+		// an unmasked frame from a client is a protocol error.
+		_, _ = client.Write([]byte{0x81, 0x02, 'h', 'i'})
+	}()
+	go func() {
+		// drain the close frame written by the server.
+		buffer := make([]byte, 32)
+		_, _ = client.Read(buffer)
+	}()
+	_, _, err := serverConn.ReadMessage()
+	its.True(IsWebSocketCloseError(err, WebSocketCloseProtocolError))
+	its.NotNil(serverConn.WriteText("closed"))
+}
+
+func Test_WebSocketConn_closeCodes(t *testing.T) {
+	testCases := [...]struct {
+		Code     int
+		Expected int
+	}{
+		{Code: WebSocketCloseNormal, Expected: WebSocketCloseNormal},
+		{Code: WebSocketCloseInternalError, Expected: WebSocketCloseInternalError},
+		{Code: 3000, Expected: 3000},
+		{Code: 4999, Expected: 4999},
+		{Code: 0, Expected: WebSocketCloseProtocolError},
+		{Code: 999, Expected: WebSocketCloseProtocolError},
+		{Code: 1004, Expected: WebSocketCloseProtocolError},
+		{Code: WebSocketCloseNoStatus, Expected: WebSocketCloseProtocolError},
+		{Code: WebSocketCloseAbnormal, Expected: WebSocketCloseProtocolError},
+		{Code: 1015, Expected: WebSocketCloseProtocolError},
+		{Code: 1016, Expected: WebSocketCloseProtocolError},
+		{Code: 2999, Expected: WebSocketCloseProtocolError},
+		{Code: 5000, Expected: WebSocketCloseProtocolError},
+	}
+	for _, tc := range testCases {
+		t.Run(fmt.Sprint(tc.Code), func(t *testing.T) {
+			its := assert.New(t)
+
+			server, client := net.Pipe()
+			defer server.Close()
+			defer client.Close()
+
+			serverConn := NewWebSocketConn(server, bufio.NewReader(server), true)
+			go func() {
+				// a masked close frame with a zero mask key.
+				_, _ = client.Write([]byte{0x88, 0x82, 0, 0, 0, 0, byte(tc.Code >> 8), byte(tc.Code)})
+			}()
+			echoed := make(chan int, 1)
+			go func() {
+				header := make([]byte, 4)
+				if _, err := io.ReadFull(client, header); err != nil {
+					echoed <- 0
+					return
+				}
+				echoed <- int(header[2])<<8 | int(header[3])
+				_, _ = io.Copy(io.Discard, client)
+			}()
+			_, _, err := serverConn.ReadMessage()
+			its.True(IsWebSocketCloseError(err, tc.Expected))
+			its.Equal(tc.Expected, <-echoed)
+		})
+	}
+}
+
+func Test_WebSocketConn_frameTooBig(t *testing.T) {
+	its := assert.New(t)
+
+	server, client := net.Pipe()
+	defer server.Close()
+	defer client.Close()
+
+	its.Equal(DefaultWebSocketReadLimit, NewWebSocketUpgrader().ReadLimit)
+
+	// the default read limit applies even though the limit is unset.
+	serverConn := NewWebSocketConn(server, bufio.NewReader(server), true)
+	serverConn.ReadLimit = 0
+
+	go func() {
+		// a masked binary frame header with a 64-bit length of 2^63-1.
+		_, _ = client.Write([]byte{0x82, 0xff, 0x7f, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff})
+	}()
+	go func() {
+		// drain the close frame written by the server.
+		buffer := make([]byte, 32)
+		_, _ = client.Read(buffer)
+	}()
+	_, _, err := serverConn.ReadMessage()
+	its.True(IsWebSocketCloseError(err, WebSocketCloseMessageTooBig))
+}
@@ -0,0 +1,262 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package webutil
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/blend/go-sdk/ex"
+)
+
+var (
+	_ ResponseWriter      = (*CompressedResponseWriter)(nil)
+	_ http.ResponseWriter = (*CompressedResponseWriter)(nil)
+	_ http.Flusher        = (*CompressedResponseWriter)(nil)
+	_ http.Hijacker       = (*CompressedResponseWriter)(nil)
+	_ io.Closer           = (*CompressedResponseWriter)(nil)
+)
+
+// ContentEncoder returns a writer that encodes its input to a given writer.
+type ContentEncoder func(io.Writer) (io.WriteCloser, error)
+
+// GZipContentEncoder is a content encoder for gzip.
+func GZipContentEncoder(w io.Writer) (io.WriteCloser, error) {
+	return gzip.NewWriter(w), nil
+}
+
+// DeflateContentEncoder is a content encoder for deflate.
+func DeflateContentEncoder(w io.Writer) (io.WriteCloser, error) {
+	return flate.NewWriter(w, flate.DefaultCompression)
+}
+
+// NewCompressedResponseWriter returns a new response writer that encodes its output with a given content encoding.
+func NewCompressedResponseWriter(w http.ResponseWriter, contentEncoding string, encoder ContentEncoder) *CompressedResponseWriter {
+	return &CompressedResponseWriter{
+		innerResponse:   w,
+		contentEncoding: contentEncoding,
+		encoder:         encoder,
+	}
+}
+
+// CompressedResponseWriter is a response writer that encodes output with a given content encoding.
+//
+// Output is buffered until it reaches `MinSize` bytes before deciding whether to encode it,
+// and responses that are too small, that have a content type not in `ContentTypes`, that are
+// already encoded, or that are partial content are written as is.
+type CompressedResponseWriter struct {
+	// MinSize is the minimum size of a response in bytes for it to be encoded.
+	MinSize int
+	// ContentTypes are the media types that are encoded, e.g. `text/*` or `application/json`.
+	// If empty, all content types are encoded.
+	ContentTypes []string
+
+	innerResponse   http.ResponseWriter
+	contentEncoding string
+	encoder         ContentEncoder
+	writer          io.WriteCloser
+	buffer          []byte
+	decided         bool
+	headerPending   bool
+	statusCode      int
+	contentLength   int
+}
+
+// InnerResponse returns the underlying response.
+func (crw *CompressedResponseWriter) InnerResponse() http.ResponseWriter {
+	return crw.innerResponse
+}
+
+// Header returns the headers for the response.
+func (crw *CompressedResponseWriter) Header() http.Header {
+	return crw.innerResponse.Header()
+}
+
+// WriteHeader writes a status code.
+//
+// The status code is held until the writer decides whether to encode the response.
+func (crw *CompressedResponseWriter) WriteHeader(code int) {
+	if crw.statusCode != 0 {
+		return
+	}
+	crw.statusCode = code
+	crw.headerPending = true
+	if !bodyAllowedForStatus(code) || code == http.StatusPartialContent {
+		_ = crw.decide(false)
+	}
+}
+
+// Write writes the bytes to the response, buffering them until the encoding is decided.
+func (crw *CompressedResponseWriter) Write(b []byte) (int, error) {
+	if crw.statusCode == 0 {
+		crw.WriteHeader(http.StatusOK)
+	}
+	crw.contentLength += len(b)
+	if crw.decided {
+		return crw.write(b)
+	}
+	crw.buffer = append(crw.buffer, b...)
+	if len(crw.buffer) >= crw.MinSize {
+		if err := crw.decide(true); err != nil {
+			return 0, err
+		}
+	}
+	return len(b), nil
+}
+
+// StatusCode returns the status code for the request.
+func (crw *CompressedResponseWriter) StatusCode() int {
+	return crw.statusCode
+}
+
+// ContentLength returns the (unencoded) content length for the request.
+func (crw *CompressedResponseWriter) ContentLength() int {
+	return crw.contentLength
+}
+
+// ContentEncoding returns the content encoding of the response if it was encoded.
+func (crw *CompressedResponseWriter) ContentEncoding() string {
+	if crw.writer != nil {
+		return crw.contentEncoding
+	}
+	return ""
+}
+
+// Flush decides the encoding if it has not been decided and pushes any buffered data out to the response.
+func (crw *CompressedResponseWriter) Flush() {
+	if !crw.decided && crw.statusCode != 0 {
+		_ = crw.decide(len(crw.buffer) >= crw.MinSize)
+	}
+	if typed, ok := crw.writer.(interface{ Flush() error }); ok {
+		_ = typed.Flush()
+	}
+	if typed, ok := crw.innerResponse.(http.Flusher); ok {
+		typed.Flush()
+	}
+}
+
+// Hijack wraps the inner response writer's Hijack function.
+func (crw *CompressedResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := crw.innerResponse.(http.Hijacker)
+	if !ok {
+		return nil, nil, ex.New("Inner responseWriter doesn't support Hijacker interface")
+	}
+	return hijacker.Hijack()
+}
+
+// Close writes any buffered output and closes the encoder.
+//
+// It does not close the inner response.
+func (crw *CompressedResponseWriter) Close() error {
+	if !crw.decided && crw.statusCode != 0 {
+		if err := crw.decide(len(crw.buffer) >= crw.MinSize); err != nil {
+			return err
+		}
+	}
+	if crw.writer != nil {
+		return crw.writer.Close()
+	}
+	return nil
+}
+
+func (crw *CompressedResponseWriter) write(b []byte) (int, error) {
+	if crw.writer != nil {
+		if _, err := crw.writer.Write(b); err != nil {
+			return 0, err
+		}
+		return len(b), nil
+	}
+	return crw.innerResponse.Write(b)
+}
+
+// decide determines if the response should be encoded, writes the status code, and writes any buffered output.
+func (crw *CompressedResponseWriter) decide(sizeOK bool) (err error) {
+	crw.decided = true
+	if sizeOK && crw.shouldEncode() {
+		header := crw.innerResponse.Header()
+		header.Set(HeaderContentEncoding, crw.contentEncoding)
+		header.Del(HeaderContentLength)
+		AddVary(header, HeaderAcceptEncoding)
+		// the encoded response is not byte for byte identical to the original.
+		if etag := header.Get(HeaderETag); etag != "" && !strings.HasPrefix(etag, "W/") {
+			header.Set(HeaderETag, "W/"+etag)
+		}
+		if crw.writer, err = crw.encoder(crw.innerResponse); err != nil {
+			return ex.New(err)
+		}
+	}
+	if crw.headerPending {
+		crw.headerPending = false
+		crw.innerResponse.WriteHeader(crw.statusCode)
+	}
+	if len(crw.buffer) > 0 {
+		buffer := crw.buffer
+		crw.buffer = nil
+		_, err = crw.write(buffer)
+	}
+	return
+}
+
+func (crw *CompressedResponseWriter) shouldEncode() bool {
+	if crw.statusCode == http.StatusPartialContent || !bodyAllowedForStatus(crw.statusCode) {
+		return false
+	}
+	header := crw.innerResponse.Header()
+	if header.Get(HeaderContentEncoding) != "" {
+		return false
+	}
+	contentType := header.Get(HeaderContentType)
+	if contentType == "" {
+		// set the sniffed content type, otherwise it would be sniffed from the encoded output.
+		contentType = http.DetectContentType(crw.buffer)
+		header.Set(HeaderContentType, contentType)
+	}
+	return MatchesMediaType(contentType, crw.ContentTypes...)
+}
+
+// MatchesMediaType returns if a content type matches any of a list of media types.
+//
+// Media types can be exact (`application/json`) or have a wildcard subtype (`text/*`); parameters are ignored.
+// An empty list matches every content type.
+func MatchesMediaType(contentType string, mediaTypes ...string) bool {
+	if len(mediaTypes) == 0 {
+		return true
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	for _, candidate := range mediaTypes {
+		candidate = strings.ToLower(candidate)
+		if candidate == mediaType {
+			return true
+		}
+		if strings.HasSuffix(candidate, "/*") && strings.HasPrefix(mediaType, strings.TrimSuffix(candidate, "*")) {
+			return true
+		}
+	}
+	return false
+}
+
+func bodyAllowedForStatus(status int) bool {
+	switch {
+	case status >= 100 && status <= 199:
+		return false
+	case status == http.StatusNoContent:
+		return false
+	case status == http.StatusNotModified:
+		return false
+	}
+	return true
+}
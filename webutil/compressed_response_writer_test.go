@@ -0,0 +1,121 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package webutil
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/blend/go-sdk/assert"
+)
+
+func TestCompressedResponseWriter(t *testing.T) {
+	its := assert.New(t)
+
+	recorder := httptest.NewRecorder()
+	crw := NewCompressedResponseWriter(recorder, ContentEncodingGZIP, GZipContentEncoder)
+	crw.MinSize = 10
+	crw.ContentTypes = []string{"text/*"}
+	crw.Header().Set(HeaderContentType, ContentTypeText)
+	crw.Header().Set(HeaderContentLength, "1000")
+	crw.Header().Set(HeaderETag, `"etag"`)
+	crw.WriteHeader(http.StatusCreated)
+
+	contents := strings.Repeat("hello world ", 100)
+	_, err := crw.Write([]byte(contents[:5]))
+	its.Nil(err)
+	its.False(recorder.Flushed)
+	its.Empty(recorder.Body.Bytes(), "output should be buffered until the minimum size")
+	_, err = crw.Write([]byte(contents[5:]))
+	its.Nil(err)
+	its.Nil(crw.Close())
+
+	its.Equal(http.StatusCreated, recorder.Code)
+	its.Equal(http.StatusCreated, crw.StatusCode())
+	its.Equal(len(contents), crw.ContentLength())
+	its.Equal(ContentEncodingGZIP, crw.ContentEncoding())
+	its.Equal(ContentEncodingGZIP, recorder.Header().Get(HeaderContentEncoding))
+	its.Empty(recorder.Header().Get(HeaderContentLength))
+	its.Equal(HeaderAcceptEncoding, recorder.Header().Get(HeaderVary))
+	its.Equal(`W/"etag"`, recorder.Header().Get(HeaderETag))
+
+	reader, err := gzip.NewReader(recorder.Body)
+	its.Nil(err)
+	decompressed, err := io.ReadAll(reader)
+	its.Nil(err)
+	its.Equal(contents, string(decompressed))
+}
+
+func TestCompressedResponseWriterSkips(t *testing.T) {
+	its := assert.New(t)
+
+	testCases := [...]struct {
+		Name        string
+		StatusCode  int
+		ContentType string
+		Encoding    string
+		Body        string
+	}{
+		{Name: "too small", StatusCode: http.StatusOK, ContentType: ContentTypeText, Body: "hi"},
+		{Name: "content type", StatusCode: http.StatusOK, ContentType: "image/png", Body: strings.Repeat("a", 100)},
+		{Name: "already encoded", StatusCode: http.StatusOK, ContentType: ContentTypeText, Encoding: ContentEncodingBrotli, Body: strings.Repeat("a", 100)},
+		{Name: "partial content", StatusCode: http.StatusPartialContent, ContentType: ContentTypeText, Body: strings.Repeat("a", 100)},
+		{Name: "not modified", StatusCode: http.StatusNotModified, ContentType: ContentTypeText},
+	}
+	for _, tc := range testCases {
+		recorder := httptest.NewRecorder()
+		crw := NewCompressedResponseWriter(recorder, ContentEncodingGZIP, GZipContentEncoder)
+		crw.MinSize = 10
+		crw.ContentTypes = []string{"text/*", "application/json"}
+		crw.Header().Set(HeaderContentType, tc.ContentType)
+		if tc.Encoding != "" {
+			crw.Header().Set(HeaderContentEncoding, tc.Encoding)
+		}
+		crw.WriteHeader(tc.StatusCode)
+		if tc.Body != "" {
+			_, err := crw.Write([]byte(tc.Body))
+			its.Nil(err, tc.Name)
+		}
+		its.Nil(crw.Close(), tc.Name)
+
+		its.Equal(tc.StatusCode, recorder.Code, tc.Name)
+		its.Empty(crw.ContentEncoding(), tc.Name)
+		its.Equal(tc.Encoding, recorder.Header().Get(HeaderContentEncoding), tc.Name)
+		its.Equal(tc.Body, recorder.Body.String(), tc.Name)
+	}
+}
+
+func TestCompressedResponseWriterFlush(t *testing.T) {
+	its := assert.New(t)
+
+	recorder := httptest.NewRecorder()
+	crw := NewCompressedResponseWriter(recorder, ContentEncodingGZIP, GZipContentEncoder)
+	crw.MinSize = 1024
+	_, err := crw.Write([]byte("data: streaming\n\n"))
+	its.Nil(err)
+	crw.Flush()
+
+	its.True(recorder.Flushed)
+	its.Empty(recorder.Header().Get(HeaderContentEncoding), "small flushed output should not be encoded")
+	its.Equal("data: streaming\n\n", recorder.Body.String())
+	its.Nil(crw.Close())
+}
+
+func TestMatchesMediaType(t *testing.T) {
+	its := assert.New(t)
+
+	its.True(MatchesMediaType(ContentTypeApplicationJSON))
+	its.True(MatchesMediaType(ContentTypeApplicationJSON, "text/*", "application/json"))
+	its.True(MatchesMediaType("TEXT/HTML", "text/*"))
+	its.False(MatchesMediaType("image/png", "text/*", "application/json"))
+	its.False(MatchesMediaType("not a media type;;", "text/*"))
+}
@@ -45,34 +45,60 @@ const (
 
 // Header names in canonical form.
 var (
-	HeaderAccept                  = http.CanonicalHeaderKey("Accept")
-	HeaderAcceptEncoding          = http.CanonicalHeaderKey("Accept-Encoding")
-	HeaderAllow                   = http.CanonicalHeaderKey("Allow")
-	HeaderAuthorization           = http.CanonicalHeaderKey("Authorization")
-	HeaderCacheControl            = http.CanonicalHeaderKey("Cache-Control")
-	HeaderConnection              = http.CanonicalHeaderKey("Connection")
-	HeaderContentEncoding         = http.CanonicalHeaderKey("Content-Encoding")
-	HeaderContentLength           = http.CanonicalHeaderKey("Content-Length")
-	HeaderContentType             = http.CanonicalHeaderKey("Content-Type")
-	HeaderCookie                  = http.CanonicalHeaderKey("Cookie")
-	HeaderDate                    = http.CanonicalHeaderKey("Date")
-	HeaderETag                    = http.CanonicalHeaderKey("etag")
-	HeaderForwarded               = http.CanonicalHeaderKey("Forwarded")
-	HeaderServer                  = http.CanonicalHeaderKey("Server")
-	HeaderSetCookie               = http.CanonicalHeaderKey("Set-Cookie")
-	HeaderStrictTransportSecurity = http.CanonicalHeaderKey("Strict-Transport-Security")
-	HeaderUserAgent               = http.CanonicalHeaderKey("User-Agent")
-	HeaderVary                    = http.CanonicalHeaderKey("Vary")
-	HeaderXContentTypeOptions     = http.CanonicalHeaderKey("X-Content-Type-Options")
-	HeaderXForwardedFor           = http.CanonicalHeaderKey("X-Forwarded-For")
-	HeaderXForwardedHost          = http.CanonicalHeaderKey("X-Forwarded-Host")
-	HeaderXForwardedPort          = http.CanonicalHeaderKey("X-Forwarded-Port")
-	HeaderXForwardedProto         = http.CanonicalHeaderKey("X-Forwarded-Proto")
-	HeaderXForwardedScheme        = http.CanonicalHeaderKey("X-Forwarded-Scheme")
-	HeaderXFrameOptions           = http.CanonicalHeaderKey("X-Frame-Options")
-	HeaderXRealIP                 = http.CanonicalHeaderKey("X-Real-IP")
-	HeaderXServedBy               = http.CanonicalHeaderKey("X-Served-By")
-	HeaderXXSSProtection          = http.CanonicalHeaderKey("X-Xss-Protection")
+	HeaderAccept                        = http.CanonicalHeaderKey("Accept")
+	HeaderAcceptEncoding                = http.CanonicalHeaderKey("Accept-Encoding")
+	HeaderAcceptRanges                  = http.CanonicalHeaderKey("Accept-Ranges")
+	HeaderAccessControlAllowCredentials = http.CanonicalHeaderKey("Access-Control-Allow-Credentials")
+	HeaderAccessControlAllowHeaders     = http.CanonicalHeaderKey("Access-Control-Allow-Headers")
+	HeaderAccessControlAllowMethods     = http.CanonicalHeaderKey("Access-Control-Allow-Methods")
+	HeaderAccessControlAllowOrigin      = http.CanonicalHeaderKey("Access-Control-Allow-Origin")
+	HeaderAccessControlExposeHeaders    = http.CanonicalHeaderKey("Access-Control-Expose-Headers")
+	HeaderAccessControlMaxAge           = http.CanonicalHeaderKey("Access-Control-Max-Age")
+	HeaderAccessControlRequestHeaders   = http.CanonicalHeaderKey("Access-Control-Request-Headers")
+	HeaderAccessControlRequestMethod    = http.CanonicalHeaderKey("Access-Control-Request-Method")
+	HeaderAllow                         = http.CanonicalHeaderKey("Allow")
+	HeaderAuthorization                 = http.CanonicalHeaderKey("Authorization")
+	HeaderCacheControl                  = http.CanonicalHeaderKey("Cache-Control")
+	HeaderConnection                    = http.CanonicalHeaderKey("Connection")
+	HeaderContentEncoding               = http.CanonicalHeaderKey("Content-Encoding")
+	HeaderContentLength                 = http.CanonicalHeaderKey("Content-Length")
+	HeaderContentRange                  = http.CanonicalHeaderKey("Content-Range")
+	HeaderContentType                   = http.CanonicalHeaderKey("Content-Type")
+	HeaderCookie                        = http.CanonicalHeaderKey("Cookie")
+	HeaderDate                          = http.CanonicalHeaderKey("Date")
+	HeaderETag                          = http.CanonicalHeaderKey("etag")
+	HeaderForwarded                     = http.CanonicalHeaderKey("Forwarded")
+	HeaderIfModifiedSince               = http.CanonicalHeaderKey("If-Modified-Since")
+	HeaderIfNoneMatch                   = http.CanonicalHeaderKey("If-None-Match")
+	HeaderIfRange                       = http.CanonicalHeaderKey("If-Range")
+	HeaderLastEventID                   = http.CanonicalHeaderKey("Last-Event-ID")
+	HeaderLastModified                  = http.CanonicalHeaderKey("Last-Modified")
+	HeaderOrigin                        = http.CanonicalHeaderKey("Origin")
+	HeaderRange                         = http.CanonicalHeaderKey("Range")
+	HeaderRateLimitLimit                = http.CanonicalHeaderKey("RateLimit-Limit")
+	HeaderRateLimitRemaining            = http.CanonicalHeaderKey("RateLimit-Remaining")
+	HeaderRateLimitReset                = http.CanonicalHeaderKey("RateLimit-Reset")
+	HeaderRetryAfter                    = http.CanonicalHeaderKey("Retry-After")
+	HeaderSecWebSocketAccept            = http.CanonicalHeaderKey("Sec-WebSocket-Accept")
+	HeaderSecWebSocketKey               = http.CanonicalHeaderKey("Sec-WebSocket-Key")
+	HeaderSecWebSocketProtocol          = http.CanonicalHeaderKey("Sec-WebSocket-Protocol")
+	HeaderSecWebSocketVersion           = http.CanonicalHeaderKey("Sec-WebSocket-Version")
+	HeaderServer                        = http.CanonicalHeaderKey("Server")
+	HeaderSetCookie                     = http.CanonicalHeaderKey("Set-Cookie")
+	HeaderStrictTransportSecurity       = http.CanonicalHeaderKey("Strict-Transport-Security")
+	HeaderUpgrade                       = http.CanonicalHeaderKey("Upgrade")
+	HeaderUserAgent                     = http.CanonicalHeaderKey("User-Agent")
+	HeaderVary                          = http.CanonicalHeaderKey("Vary")
+	HeaderXContentTypeOptions           = http.CanonicalHeaderKey("X-Content-Type-Options")
+	HeaderXForwardedFor                 = http.CanonicalHeaderKey("X-Forwarded-For")
+	HeaderXForwardedHost                = http.CanonicalHeaderKey("X-Forwarded-Host")
+	HeaderXForwardedPort                = http.CanonicalHeaderKey("X-Forwarded-Port")
+	HeaderXForwardedProto               = http.CanonicalHeaderKey("X-Forwarded-Proto")
+	HeaderXForwardedScheme              = http.CanonicalHeaderKey("X-Forwarded-Scheme")
+	HeaderXFrameOptions                 = http.CanonicalHeaderKey("X-Frame-Options")
+	HeaderXRealIP                       = http.CanonicalHeaderKey("X-Real-IP")
+	HeaderXServedBy                     = http.CanonicalHeaderKey("X-Served-By")
+	HeaderXXSSProtection                = http.CanonicalHeaderKey("X-Xss-Protection")
 )
 
 /*
@@ -117,6 +143,9 @@ const (
 	// indicates the server should keep the tcp connection open
 	// after the last byte of the response is sent.
 	ConnectionKeepAlive = "keep-alive"
+	// ConnectionUpgrade is a value for the "Connection" header and
+	// indicates the client is requesting a protocol upgrade.
+	ConnectionUpgrade = "upgrade"
 )
 
 const (
@@ -145,12 +174,24 @@ const (
 	// We specify chartset=utf-8 so that clients know to use the UTF-8 string encoding.
 	ContentTypeText = "text/plain; charset=utf-8"
 
+	// ContentTypeEventStream is a content type for server-sent event streams.
+	ContentTypeEventStream = "text/event-stream"
+
 	// ContentEncodingIdentity is the identity (uncompressed) content encoding.
 	ContentEncodingIdentity = "identity"
 
 	// ContentEncodingGZIP is the gzip (compressed) content encoding.
 	ContentEncodingGZIP = "gzip"
 
+	// ContentEncodingDeflate is the deflate (compressed) content encoding.
+	ContentEncodingDeflate = "deflate"
+
+	// ContentEncodingBrotli is the brotli (compressed) content encoding.
+	ContentEncodingBrotli = "br"
+
+	// ContentEncodingZstd is the zstandard (compressed) content encoding.
+	ContentEncodingZstd = "zstd"
+
 	// ConnectionClose is the connection value of "close"
 	ConnectionClose = "close"
 )
@@ -0,0 +1,73 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package webutil
+
+import (
+	"strconv"
+	"strings"
+)
+
+// NegotiateContentEncoding returns the best of a list of supported content encodings
+// for an Accept-Encoding header value, or an empty string if the response should not be encoded.
+//
+// Encodings are ranked by their quality value in the header, and ties are broken
+// by the order of the supported encodings. Identity is only preferred over a supported
+// encoding if it is explicitly given a higher quality value.
+func NegotiateContentEncoding(acceptEncoding string, supported ...string) string {
+	qualities := make(map[string]float64)
+	wildcard := -1.0
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		coding, quality := parseQualityValue(part)
+		if coding == "" {
+			continue
+		}
+		if coding == "*" {
+			wildcard = quality
+			continue
+		}
+		qualities[coding] = quality
+	}
+
+	var best string
+	var bestQuality float64
+	for _, encoding := range supported {
+		quality, ok := qualities[strings.ToLower(encoding)]
+		if !ok {
+			if wildcard < 0 {
+				continue
+			}
+			quality = wildcard
+		}
+		if quality > bestQuality {
+			best, bestQuality = encoding, quality
+		}
+	}
+	if identity, ok := qualities[ContentEncodingIdentity]; ok && identity > bestQuality {
+		return ""
+	}
+	return best
+}
+
+// parseQualityValue parses a header list element of the form `value;q=0.5`.
+// Values without a quality have a quality of 1, and invalid qualities are treated as 0.
+func parseQualityValue(part string) (value string, quality float64) {
+	quality = 1
+	pieces := strings.Split(part, ";")
+	value = strings.ToLower(strings.TrimSpace(pieces[0]))
+	for _, param := range pieces[1:] {
+		param = strings.TrimSpace(param)
+		if len(param) > 2 && (param[0] == 'q' || param[0] == 'Q') && param[1] == '=' {
+			parsed, err := strconv.ParseFloat(param[2:], 64)
+			if err != nil || parsed < 0 || parsed > 1 {
+				parsed = 0
+			}
+			quality = parsed
+		}
+	}
+	return
+}
@@ -0,0 +1,41 @@
+/*
+
+Copyright (c) 2022 - Present. Blend Labs, Inc. All rights reserved
+Use of this source code is governed by a MIT license that can be found in the LICENSE file.
+
+*/
+
+package webutil
+
+import (
+	"testing"
+
+	"github.com/blend/go-sdk/assert"
+)
+
+func TestNegotiateContentEncoding(t *testing.T) {
+	its := assert.New(t)
+
+	supported := []string{ContentEncodingBrotli, ContentEncodingZstd, ContentEncodingGZIP}
+	testCases := [...]struct {
+		AcceptEncoding string
+		Expected       string
+	}{
+		{AcceptEncoding: "", Expected: ""},
+		{AcceptEncoding: "gzip", Expected: ContentEncodingGZIP},
+		{AcceptEncoding: "gzip, deflate, br", Expected: ContentEncodingBrotli},
+		{AcceptEncoding: "GZIP, ZSTD", Expected: ContentEncodingZstd},
+		{AcceptEncoding: "br;q=0.5, gzip", Expected: ContentEncodingGZIP},
+		{AcceptEncoding: "br;q=0, gzip;q=0.1", Expected: ContentEncodingGZIP},
+		{AcceptEncoding: "br;q=0, gzip;q=0", Expected: ""},
+		{AcceptEncoding: "*", Expected: ContentEncodingBrotli},
+		{AcceptEncoding: "*;q=0.5, br;q=0", Expected: ContentEncodingZstd},
+		{AcceptEncoding: "deflate", Expected: ""},
+		{AcceptEncoding: "identity, gzip;q=0.5", Expected: ""},
+		{AcceptEncoding: "identity;q=0.5, gzip", Expected: ContentEncodingGZIP},
+		{AcceptEncoding: "gzip;q=bogus, br;q=0.1", Expected: ContentEncodingBrotli},
+	}
+	for _, tc := range testCases {
+		its.Equal(tc.Expected, NegotiateContentEncoding(tc.AcceptEncoding, supported...), tc.AcceptEncoding)
+	}
+}
@@ -10,6 +10,7 @@ package webutil
 import (
 	"crypto/md5"
 	"encoding/hex"
+	"strings"
 )
 
 // ETag creates an etag for a given blob.
@@ -18,3 +19,12 @@ func ETag(contents []byte) string {
 	_, _ = hash.Write(contents)
 	return hex.EncodeToString(hash.Sum(nil))
 }
+
+// QuoteETag returns an etag formatted as a header value, i.e. in double quotes,
+// leaving etags that are already quoted or weak unchanged.
+func QuoteETag(etag string) string {
+	if etag == "" || strings.HasPrefix(etag, `"`) || strings.HasPrefix(etag, `W/"`) {
+		return etag
+	}
+	return `"` + etag + `"`
+}
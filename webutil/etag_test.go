@@ -22,3 +22,12 @@ func TestETag(t *testing.T) {
 	etag = ETag([]byte("something else that is really cool"))
 	assert.Equal("a8c90c3202be46c1d766b2c63d38332b", etag)
 }
+
+func TestQuoteETag(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal("", QuoteETag(""))
+	assert.Equal(`"abc"`, QuoteETag("abc"))
+	assert.Equal(`"abc"`, QuoteETag(`"abc"`))
+	assert.Equal(`W/"abc"`, QuoteETag(`W/"abc"`))
+}
@@ -39,7 +39,7 @@ func (es *EventSource) StartSession() error {
 	es.Lock()
 	defer es.Unlock()
 
-	es.output.Header().Set(HeaderContentType, "text/event-stream")
+	es.output.Header().Set(HeaderContentType, ContentTypeEventStream)
 	es.output.Header().Set(HeaderVary, "Content-Type")
 	es.output.Header().Set(HeaderCacheControl, "no-cache")
 	es.output.WriteHeader(http.StatusOK)
@@ -48,3 +48,27 @@ func Headers(from map[string]string) http.Header {
 	}
 	return output
 }
+
+// AddVary adds values to the Vary header of a response, skipping any
+// values (compared case insensitively) that are already present.
+func AddVary(headers http.Header, values ...string) {
+	var existing []string
+	for _, rawHeaderValue := range headers.Values(HeaderVary) {
+		for _, headerValue := range strings.Split(rawHeaderValue, ",") {
+			existing = append(existing, strings.TrimSpace(headerValue))
+		}
+	}
+	for _, value := range values {
+		var found bool
+		for _, existingValue := range existing {
+			if existingValue == "*" || strings.EqualFold(existingValue, value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			headers.Add(HeaderVary, value)
+			existing = append(existing, value)
+		}
+	}
+}
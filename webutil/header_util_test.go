@@ -24,3 +24,20 @@ func TestHeaderAny(t *testing.T) {
 	assert.True(HeaderAny(http.Header{"Foo": []string{"bar,example-string"}}, "foo", "bar"))
 	assert.True(HeaderAny(http.Header{"fuzz": []string{"buzz"}, "Foo": []string{"bar,example-string"}}, "foo", "bar"))
 }
+
+func TestAddVary(t *testing.T) {
+	assert := assert.New(t)
+
+	header := http.Header{}
+	AddVary(header, HeaderOrigin)
+	AddVary(header, HeaderAcceptEncoding, "origin")
+	assert.Equal([]string{HeaderOrigin, HeaderAcceptEncoding}, header.Values(HeaderVary))
+
+	header = http.Header{HeaderVary: []string{"Origin, Accept-Encoding"}}
+	AddVary(header, HeaderAcceptEncoding, "Cookie")
+	assert.Equal([]string{"Origin, Accept-Encoding", "Cookie"}, header.Values(HeaderVary))
+
+	header = http.Header{HeaderVary: []string{"*"}}
+	AddVary(header, HeaderAcceptEncoding)
+	assert.Equal([]string{"*"}, header.Values(HeaderVary))
+}